@@ -0,0 +1,283 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/fees"
+)
+
+// maxBatchRecipients bounds the number of recipients settled by a single batch task
+const maxBatchRecipients = 256
+
+// BatchRewardDistributionTask represents a multi-recipient reward distribution for a pool,
+// mirroring RewardFlowHook.AVSTask
+type BatchRewardDistributionTask struct {
+	PoolID      string     `json:"pool_id"`
+	Recipients  []string   `json:"recipients"`
+	Amounts     []*big.Int `json:"amounts"`
+	TotalAmount *big.Int   `json:"total_amount"`
+	ChainID     uint64     `json:"chain_id,omitempty"` // Source chain, not carried by the ABI layout
+	TargetChain uint64     `json:"target_chain"`
+	RewardType  RewardType `json:"reward_type"`
+	Timestamp   int64      `json:"timestamp"` // AVSTask.createdAt
+	HookAddress string     `json:"hook_address,omitempty"`
+	TaskHash    string     `json:"task_hash,omitempty"`
+}
+
+// RecipientDistributionResult represents the outcome for a single recipient of a batch task
+type RecipientDistributionResult struct {
+	Recipient         string          `json:"recipient"`
+	Amount            *big.Int        `json:"amount"`
+	DistributedAmount *big.Int        `json:"distributed_amount"`
+	FeeAmount         *big.Int        `json:"fee_amount"`
+	FeeBreakdown      *fees.Breakdown `json:"fee_breakdown,omitempty"`
+	TransactionHash   string          `json:"transaction_hash,omitempty"`
+	// Bridge is the bridge the share was sent through, unset when simulated
+	Bridge  *BridgeRoute `json:"bridge,omitempty"`
+	Success bool         `json:"success"`
+	Error   string       `json:"error,omitempty"`
+}
+
+// validateBatchTaskParameters validates the parameters of a batch reward distribution task
+func (rf *RewardFlowTaskWorker) validateBatchTaskParameters(task *BatchRewardDistributionTask) error {
+	// Validate array shapes
+	if len(task.Recipients) == 0 {
+		return invalid(errInvalidBatch, "batch has no recipients")
+	}
+	if len(task.Recipients) != len(task.Amounts) {
+		return invalid(errInvalidBatch, "recipients and amounts length mismatch: %d != %d", len(task.Recipients), len(task.Amounts))
+	}
+	if len(task.Recipients) > maxBatchRecipients {
+		return invalid(errInvalidBatch, "batch exceeds maximum of %d recipients", maxBatchRecipients)
+	}
+
+	// Validate recipients and amounts
+	seen := make(map[common.Address]int, len(task.Recipients))
+	sum := new(big.Int)
+	for i, recipient := range task.Recipients {
+		if !common.IsHexAddress(recipient) {
+			return invalid(errInvalidBatch, "invalid recipient address at index %d", i)
+		}
+		addr := common.HexToAddress(recipient)
+		if addr == (common.Address{}) {
+			return invalid(errInvalidBatch, "invalid recipient address at index %d", i)
+		}
+		if prev, ok := seen[addr]; ok {
+			return invalid(errInvalidBatch, "duplicate recipient %s at indexes %d and %d", addr.Hex(), prev, i)
+		}
+		seen[addr] = i
+
+		amount := task.Amounts[i]
+		if amount == nil || amount.Sign() <= 0 {
+			return invalid(errInvalidAmount, "invalid reward amount at index %d", i)
+		}
+		sum.Add(sum, amount)
+	}
+
+	// Validate total amount
+	if task.TotalAmount == nil || task.TotalAmount.Sign() <= 0 {
+		return invalid(errInvalidAmount, "invalid total amount")
+	}
+	if sum.Cmp(task.TotalAmount) != 0 {
+		return invalid(errInvalidBatch, "amounts sum %s does not equal total amount %s", sum.String(), task.TotalAmount.String())
+	}
+
+	// Validate minimum reward amount
+	limits := rf.policy.Limits()
+	if task.TotalAmount.Cmp(limits.MinRewardAmount) < 0 {
+		return errAmountBelowMinimum
+	}
+
+	// Validate maximum reward amount
+	if task.TotalAmount.Cmp(limits.MaxRewardAmount) > 0 {
+		return errAmountAboveMaximum
+	}
+
+	// Validate target chain
+	if task.TargetChain == 0 {
+		return invalid(errMissingChain, "target chain is required")
+	}
+	if err := rf.chains.Check(task.TargetChain); err != nil {
+		return invalid(errUnsupportedChain, "target %w", err)
+	}
+	if task.ChainID != 0 {
+		if err := rf.chains.Check(task.ChainID); err != nil {
+			return invalid(errUnsupportedChain, "source %w", err)
+		}
+	} else if rf.bridges != nil {
+		// Deposits are sent from the source chain
+		return errMissingChain
+	}
+
+	// Validate reward type; types that need per-user parameters cannot be batched
+	rule, ok := rewardTypeRules[task.RewardType]
+	if !ok {
+		return invalid(errInvalidRewardType, "invalid reward type: %s", task.RewardType)
+	}
+	if !rule.batchable {
+		return invalid(errInvalidRewardType, "%s rewards cannot be distributed in a batch", task.RewardType)
+	}
+
+	// Validate timestamp
+	if task.Timestamp <= 0 {
+		return errInvalidTimestamp
+	}
+
+	// Validate timestamp is not too old
+	if rf.taskTooOld(task.Timestamp) {
+		return errStaleTimestamp
+	}
+
+	return nil
+}
+
+// processBatchRewardDistribution settles every recipient of a batch task and aggregates
+// the outcomes. Recipients settled by an earlier delivery, in settled, are not sent again.
+func (rf *RewardFlowTaskWorker) processBatchRewardDistribution(taskID string, task *BatchRewardDistributionTask, settled []RecipientDistributionResult) (*RewardDistributionResult, error) {
+	rf.logger.Sugar().Infow("Processing batch reward distribution",
+		zap.String("task_id", taskID),
+		zap.String("pool_id", task.PoolID),
+		zap.Int("recipients", len(task.Recipients)),
+		zap.String("total_amount", task.TotalAmount.String()),
+		zap.Uint64("target_chain", task.TargetChain),
+	)
+
+	totalDistributed := new(big.Int)
+	totalFees := new(big.Int)
+	breakdown := fees.Zero()
+	recipients := make([]RecipientDistributionResult, 0, len(task.Recipients))
+	var failures []string
+	var resumed *resumedShares
+
+	for i, recipient := range task.Recipients {
+		outcome, ok := settledOutcome(settled, i, recipient, task.Amounts[i])
+		if ok {
+			if resumed == nil {
+				resumed = &resumedShares{distributed: new(big.Int), fee: new(big.Int)}
+			}
+			resumed.distributed.Add(resumed.distributed, outcome.DistributedAmount)
+			resumed.fee.Add(resumed.fee, outcome.FeeAmount)
+		} else {
+			outcome = rf.distributeToRecipient(taskID, i, recipient, task.Amounts[i], task.ChainID, task.TargetChain)
+		}
+		if outcome.Success {
+			totalDistributed.Add(totalDistributed, outcome.DistributedAmount)
+			totalFees.Add(totalFees, outcome.FeeAmount)
+			breakdown.Add(*outcome.FeeBreakdown)
+		} else {
+			failures = append(failures, fmt.Sprintf("%s: %s", outcome.Recipient, outcome.Error))
+		}
+		recipients = append(recipients, outcome)
+	}
+
+	if rf.bridges == nil {
+		// Simulate processing delay
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	result := &RewardDistributionResult{
+		TaskID:            taskID,
+		Success:           len(failures) == 0,
+		DistributedAmount: totalDistributed,
+		FeeAmount:         totalFees,
+		FeeBreakdown:      &breakdown,
+		TargetChain:       task.TargetChain,
+		RoutingReason:     RoutingReasonTaskTarget,
+		Recipients:        recipients,
+		ProcessedAt:       time.Now().Unix(),
+		resumed:           resumed,
+	}
+	if len(failures) > 0 {
+		result.ErrorCode = ResultErrorPartialFailure
+		result.Error = fmt.Sprintf("%d of %d recipients failed: %s", len(failures), len(recipients), strings.Join(failures, "; "))
+	}
+
+	rf.logger.Sugar().Infow("Batch reward distribution completed",
+		zap.String("task_id", taskID),
+		zap.Int("recipients", len(recipients)),
+		zap.Int("failed", len(failures)),
+		zap.String("distributed_amount", totalDistributed.String()),
+		zap.String("fee_amount", totalFees.String()),
+	)
+
+	return result, nil
+}
+
+// resumedShares totals the recipients of a batch settled by an earlier delivery
+type resumedShares struct {
+	distributed *big.Int
+	fee         *big.Int
+}
+
+// settledOutcome returns the outcome of the recipient at index if an earlier
+// delivery of the task settled it
+func settledOutcome(settled []RecipientDistributionResult, index int, recipient string, amount *big.Int) (RecipientDistributionResult, bool) {
+	if index >= len(settled) {
+		return RecipientDistributionResult{}, false
+	}
+	outcome := settled[index]
+	if !outcome.Success || outcome.Recipient != common.HexToAddress(recipient).Hex() || outcome.Amount == nil || outcome.Amount.Cmp(amount) != 0 ||
+		outcome.DistributedAmount == nil || outcome.FeeAmount == nil || outcome.FeeBreakdown == nil {
+		return RecipientDistributionResult{}, false
+	}
+	return outcome, true
+}
+
+// distributeToRecipient computes the share of a single batch recipient and
+// sends it through the cheapest healthy bridge like a single distribution, or
+// simulates it without bridges. The deposit is tracked under the task ID and
+// the index of the recipient.
+func (rf *RewardFlowTaskWorker) distributeToRecipient(taskID string, index int, recipient string, amount *big.Int, sourceChain, targetChain uint64) RecipientDistributionResult {
+	outcome := RecipientDistributionResult{
+		Recipient: common.HexToAddress(recipient).Hex(),
+		Amount:    new(big.Int).Set(amount),
+	}
+	breakdown, err := rf.fees.Calculate(amount, targetChain)
+	if err != nil {
+		outcome.Error = err.Error()
+		return outcome
+	}
+	feeAmount := breakdown.Total()
+	distributedAmount := new(big.Int).Sub(amount, feeAmount)
+
+	if rf.bridges != nil {
+		deposit, err := rf.deposit(recipient, sourceChain, targetChain, distributedAmount)
+		if err != nil {
+			rf.logger.Warn("Failed to bridge batch distribution",
+				zap.String("task_id", taskID),
+				zap.Int("recipient_index", index),
+				zap.String("recipient", outcome.Recipient),
+				zap.Error(err),
+			)
+			outcome.Error = fmt.Sprintf("failed to bridge distribution: %v", err)
+			return outcome
+		}
+		outcome.TransactionHash = deposit.TransactionHash.Hex()
+		outcome.Bridge = &BridgeRoute{Name: deposit.Bridge, Fee: deposit.Fee}
+		message := "Bridge deposit sent"
+		if !rf.mode.Sends() {
+			message = "Bridge deposit built, not sent"
+		}
+		rf.logger.Sugar().Infow(message,
+			zap.String("task_id", taskID),
+			zap.Int("recipient_index", index),
+			zap.String("bridge", deposit.Bridge),
+			zap.Uint64("source_chain", sourceChain),
+			zap.Uint64("target_chain", targetChain),
+			zap.String("transaction_hash", outcome.TransactionHash),
+		)
+		rf.trackRecipientTransfer(taskID, index, deposit)
+	}
+
+	outcome.DistributedAmount = distributedAmount
+	outcome.FeeAmount = feeAmount
+	outcome.FeeBreakdown = &breakdown
+	outcome.Success = true
+	return outcome
+}
@@ -0,0 +1,476 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/bridge"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/config"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/transfers"
+)
+
+func newBatchTask() BatchRewardDistributionTask {
+	return BatchRewardDistributionTask{
+		PoolID: "0x" + strings.Repeat("ab", 32),
+		Recipients: []string{
+			"0x1234567890123456789012345678901234567890",
+			"0xabcdef1234567890abcdef1234567890abcdef12",
+			"0x9876543210987654321098765432109876543210",
+		},
+		Amounts: []*big.Int{
+			big.NewInt(500000000000000000), // 0.5 ETH
+			big.NewInt(300000000000000000), // 0.3 ETH
+			big.NewInt(200000000000000000), // 0.2 ETH
+		},
+		TotalAmount: big.NewInt(1000000000000000000), // 1 ETH
+		ChainID:     1,
+		TargetChain: 42161,
+		RewardType:  "liquidity",
+		Timestamp:   time.Now().Unix(),
+		HookAddress: "0x9876543210987654321098765432109876543210",
+		TaskHash:    "0x" + strings.Repeat("33", 32),
+	}
+}
+
+func TestRewardFlowTaskWorker_ValidateBatchTask(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	worker := NewRewardFlowTaskWorker(logger)
+
+	tests := []struct {
+		name     string
+		mutate   func(task *BatchRewardDistributionTask)
+		errorMsg string
+	}{
+		{
+			name:   "valid batch task",
+			mutate: func(task *BatchRewardDistributionTask) {},
+		},
+		{
+			name: "no recipients",
+			mutate: func(task *BatchRewardDistributionTask) {
+				task.Recipients = nil
+				task.Amounts = nil
+			},
+			errorMsg: "batch has no recipients",
+		},
+		{
+			name: "length mismatch",
+			mutate: func(task *BatchRewardDistributionTask) {
+				task.Amounts = task.Amounts[:2]
+			},
+			errorMsg: "recipients and amounts length mismatch: 3 != 2",
+		},
+		{
+			name: "sum does not equal total",
+			mutate: func(task *BatchRewardDistributionTask) {
+				task.TotalAmount = big.NewInt(900000000000000000)
+			},
+			errorMsg: "amounts sum 1000000000000000000 does not equal total amount 900000000000000000",
+		},
+		{
+			name: "duplicate recipient with different casing",
+			mutate: func(task *BatchRewardDistributionTask) {
+				task.Recipients[2] = "0xABCDEF1234567890ABCDEF1234567890ABCDEF12"
+			},
+			errorMsg: "duplicate recipient 0xabCDEF1234567890ABcDEF1234567890aBCDeF12 at indexes 1 and 2",
+		},
+		{
+			name: "invalid recipient address",
+			mutate: func(task *BatchRewardDistributionTask) {
+				task.Recipients[0] = "not-an-address"
+			},
+			errorMsg: "invalid recipient address at index 0",
+		},
+		{
+			name: "zero amount",
+			mutate: func(task *BatchRewardDistributionTask) {
+				task.Amounts[1] = big.NewInt(0)
+				task.TotalAmount = big.NewInt(700000000000000000)
+			},
+			errorMsg: "invalid reward amount at index 1",
+		},
+		{
+			name: "missing target chain",
+			mutate: func(task *BatchRewardDistributionTask) {
+				task.TargetChain = 0
+			},
+			errorMsg: "target chain is required",
+		},
+		{
+			name: "timestamp too old",
+			mutate: func(task *BatchRewardDistributionTask) {
+				task.Timestamp = time.Now().Unix() - 25*60*60
+			},
+			errorMsg: "task timestamp too old",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := newBatchTask()
+			tt.mutate(&task)
+
+			taskData, err := json.Marshal(task)
+			if err != nil {
+				t.Fatalf("Failed to marshal task: %v", err)
+			}
+
+			err = worker.ValidateTask(&performerV1.TaskRequest{
+				TaskId:  []byte("test-batch-task-id-" + tt.name),
+				Payload: taskData,
+			})
+			if tt.errorMsg != "" {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				} else if err.Error() != tt.errorMsg {
+					t.Errorf("Expected error message '%s', got '%s'", tt.errorMsg, err.Error())
+				}
+			} else if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestRewardFlowTaskWorker_HandleBatchTask(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	worker := NewRewardFlowTaskWorker(logger)
+	task := newBatchTask()
+
+	for _, format := range []PayloadFormat{PayloadFormatBatchJSON, PayloadFormatBatchABI} {
+		t.Run(format.String(), func(t *testing.T) {
+			payload, err := EncodeBatchTaskPayload(&task, format)
+			if err != nil {
+				t.Fatalf("EncodeBatchTaskPayload failed: %v", err)
+			}
+			if detected := DetectPayloadFormat(payload); detected != format {
+				t.Fatalf("Expected format %s, got %s", format, detected)
+			}
+
+			taskRequest := &performerV1.TaskRequest{
+				TaskId:  []byte("test-batch-task-id-" + format.String()),
+				Payload: payload,
+			}
+			if err := worker.ValidateTask(taskRequest); err != nil {
+				t.Fatalf("ValidateTask failed: %v", err)
+			}
+
+			response, err := worker.HandleTask(taskRequest)
+			if err != nil {
+				t.Fatalf("HandleTask failed: %v", err)
+			}
+
+			var result RewardDistributionResult
+			if err := json.Unmarshal(response.Result, &result); err != nil {
+				t.Fatalf("Failed to unmarshal result: %v", err)
+			}
+
+			if !result.Success {
+				t.Errorf("Expected successful processing, but got error: %s", result.Error)
+			}
+			if result.TargetChain != task.TargetChain {
+				t.Errorf("Expected target chain %d, got %d", task.TargetChain, result.TargetChain)
+			}
+			if len(result.Recipients) != len(task.Recipients) {
+				t.Fatalf("Expected %d recipient results, got %d", len(task.Recipients), len(result.Recipients))
+			}
+
+			totalDistributed := new(big.Int)
+			totalFees := new(big.Int)
+			for i, r := range result.Recipients {
+				expectedFee := new(big.Int).Div(task.Amounts[i], big.NewInt(1000))
+				if r.FeeAmount.Cmp(expectedFee) != 0 {
+					t.Errorf("Recipient %d: expected fee %v, got %v", i, expectedFee, r.FeeAmount)
+				}
+				expectedDistributed := new(big.Int).Sub(task.Amounts[i], expectedFee)
+				if r.DistributedAmount.Cmp(expectedDistributed) != 0 {
+					t.Errorf("Recipient %d: expected distributed %v, got %v", i, expectedDistributed, r.DistributedAmount)
+				}
+				if !strings.EqualFold(r.Recipient, task.Recipients[i]) {
+					t.Errorf("Recipient %d: expected %s, got %s", i, task.Recipients[i], r.Recipient)
+				}
+				totalDistributed.Add(totalDistributed, r.DistributedAmount)
+				totalFees.Add(totalFees, r.FeeAmount)
+			}
+
+			if result.DistributedAmount.Cmp(totalDistributed) != 0 {
+				t.Errorf("Expected distributed amount %v, got %v", totalDistributed, result.DistributedAmount)
+			}
+			if result.FeeAmount.Cmp(totalFees) != 0 {
+				t.Errorf("Expected fee amount %v, got %v", totalFees, result.FeeAmount)
+			}
+		})
+	}
+}
+
+func TestRewardFlowTaskWorker_BridgedBatch(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	tests := []struct {
+		mode    string
+		tracked bool
+	}{
+		{mode: config.ExecutionModeLive, tracked: true},
+		{mode: config.ExecutionModeDryRun, tracked: false},
+		{mode: config.ExecutionModeShadow, tracked: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			mock := bridge.NewMock("mock", big.NewInt(1e14))
+			router, err := bridge.NewRouter(bridge.DefaultCooldown, mock)
+			if err != nil {
+				t.Fatalf("NewRouter failed: %v", err)
+			}
+			store, err := transfers.Open(filepath.Join(t.TempDir(), "transfers.db"))
+			if err != nil {
+				t.Fatalf("Failed to open transfer store: %v", err)
+			}
+			defer store.Close()
+			tracker := transfers.NewTracker(router, store, transfers.DefaultConfig())
+			processed := openTestStore(t)
+
+			cfg := config.Default()
+			cfg.Execution.Mode = tt.mode
+			worker := NewRewardFlowTaskWorker(logger, withConfig(t, cfg), WithBridgeRouter(router), WithTransferTracker(tracker), WithIdempotencyStore(processed))
+
+			task := newBatchTask()
+			payload, err := EncodeBatchTaskPayload(&task, PayloadFormatBatchJSON)
+			if err != nil {
+				t.Fatalf("EncodeBatchTaskPayload failed: %v", err)
+			}
+			response, err := worker.HandleTask(&performerV1.TaskRequest{TaskId: []byte("batch"), Payload: payload})
+			if err != nil {
+				t.Fatalf("HandleTask failed: %v", err)
+			}
+			var result RewardDistributionResult
+			if err := json.Unmarshal(response.Result, &result); err != nil {
+				t.Fatalf("Failed to unmarshal result: %v", err)
+			}
+			if !result.Success || result.Mode != ExecutionMode(tt.mode) || len(result.Recipients) != len(task.Recipients) {
+				t.Fatalf("Expected a successful %s batch, got %+v", tt.mode, result)
+			}
+
+			// Every recipient is sent its own deposit through the router
+			deposits := mock.Deposits()
+			if len(deposits) != len(task.Recipients) {
+				t.Fatalf("Expected %d deposits, got %d", len(task.Recipients), len(deposits))
+			}
+			tracked, err := tracker.Status("batch")
+			if err != nil {
+				t.Fatalf("Status failed: %v", err)
+			}
+			expected := 0
+			if tt.tracked {
+				expected = len(task.Recipients)
+			}
+			if len(tracked) != expected {
+				t.Fatalf("Expected %d tracked transfers, got %d", expected, len(tracked))
+			}
+			for i, r := range result.Recipients {
+				d := deposits[i]
+				if d.Transfer.Recipient != common.HexToAddress(task.Recipients[i]) || d.Transfer.Amount.Cmp(r.DistributedAmount) != 0 {
+					t.Errorf("Recipient %d: expected %s sent to %s, got %s to %s", i, r.DistributedAmount, task.Recipients[i], d.Transfer.Amount, d.Transfer.Recipient.Hex())
+				}
+				if d.Transfer.SourceChain != task.ChainID || d.Transfer.TargetChain != task.TargetChain {
+					t.Errorf("Recipient %d: expected chain %d to %d, got %d to %d", i, task.ChainID, task.TargetChain, d.Transfer.SourceChain, d.Transfer.TargetChain)
+				}
+				if r.TransactionHash != d.TransactionHash.Hex() || r.Bridge == nil || r.Bridge.Name != "mock" {
+					t.Errorf("Recipient %d: expected deposit %s through mock, got %s through %+v", i, d.TransactionHash.Hex(), r.TransactionHash, r.Bridge)
+				}
+
+				// Only deposits that were sent are followed, under the task ID and the index of their recipient
+				if !tt.tracked {
+					continue
+				}
+				if transfer := tracked[i]; transfer.Recipient == nil || *transfer.Recipient != i || transfer.Deposit().TransactionHash != d.TransactionHash {
+					t.Errorf("Recipient %d: expected deposit %s tracked, got %+v", i, d.TransactionHash.Hex(), transfer)
+				}
+			}
+			if _, ok, err := processed.Lookup("batch", ""); ok != tt.tracked || err != nil {
+				t.Errorf("Expected stored to be %t, got %t (%v)", tt.tracked, ok, err)
+			}
+		})
+	}
+}
+
+func TestRewardFlowTaskWorker_BridgedBatchFailure(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	mock := bridge.NewMock("mock", big.NewInt(1e14))
+	mock.FailDeposits(errors.New("insufficient funds"))
+	router, err := bridge.NewRouter(bridge.DefaultCooldown, mock)
+	if err != nil {
+		t.Fatalf("NewRouter failed: %v", err)
+	}
+	worker := NewRewardFlowTaskWorker(logger, WithBridgeRouter(router))
+
+	// Recipients whose deposit fails are reported, not counted as distributed.
+	// The failing bridge cools down, so later recipients find no bridge at all.
+	task := newBatchTask()
+	result, err := worker.processBatchRewardDistribution("batch", &task, nil)
+	if err != nil {
+		t.Fatalf("processBatchRewardDistribution failed: %v", err)
+	}
+	if result.Success || result.ErrorCode != ResultErrorPartialFailure || result.DistributedAmount.Sign() != 0 {
+		t.Fatalf("Expected every recipient to fail, got %+v", result)
+	}
+	for i, r := range result.Recipients {
+		if r.Success || r.TransactionHash != "" || !strings.HasPrefix(r.Error, "failed to bridge distribution: ") {
+			t.Errorf("Recipient %d: expected a failed deposit, got %+v", i, r)
+		}
+	}
+	if !strings.Contains(result.Recipients[0].Error, "insufficient funds") {
+		t.Errorf("Expected the deposit error, got '%s'", result.Recipients[0].Error)
+	}
+
+	// Deposits are sent from the source chain, so bridged batches need one
+	task.ChainID = 0
+	if err := worker.validateBatchTaskParameters(&task); err == nil || err.Error() != "chain ID is required" {
+		t.Errorf("Expected error message 'chain ID is required', got '%v'", err)
+	}
+}
+
+// recipientFailingBridge is a mock bridge whose deposits to one recipient fail
+type recipientFailingBridge struct {
+	*bridge.Mock
+	fail common.Address
+}
+
+func (b *recipientFailingBridge) Deposit(ctx context.Context, t bridge.Transfer, q bridge.Quote) (bridge.Deposit, error) {
+	if t.Recipient == b.fail {
+		return bridge.Deposit{}, errors.New("insufficient funds")
+	}
+	return b.Mock.Deposit(ctx, t, q)
+}
+
+func TestRewardFlowTaskWorker_BatchPartialFailureRetry(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	task := newBatchTask()
+	mock := &recipientFailingBridge{Mock: bridge.NewMock("mock", big.NewInt(1e14)), fail: common.HexToAddress(task.Recipients[1])}
+	router, err := bridge.NewRouter(0, mock)
+	if err != nil {
+		t.Fatalf("NewRouter failed: %v", err)
+	}
+	worker := NewRewardFlowTaskWorker(logger, WithBridgeRouter(router), WithIdempotencyStore(openTestStore(t)))
+
+	payload, err := EncodeBatchTaskPayload(&task, PayloadFormatBatchJSON)
+	if err != nil {
+		t.Fatalf("EncodeBatchTaskPayload failed: %v", err)
+	}
+	deliver := func() RewardDistributionResult {
+		t.Helper()
+		response, err := worker.HandleTask(&performerV1.TaskRequest{TaskId: []byte("batch"), Payload: payload})
+		if err != nil {
+			t.Fatalf("HandleTask failed: %v", err)
+		}
+		var result RewardDistributionResult
+		if err := json.Unmarshal(response.Result, &result); err != nil {
+			t.Fatalf("Failed to unmarshal result: %v", err)
+		}
+		return result
+	}
+
+	// The second recipient fails, the others are paid
+	first := deliver()
+	if first.Success || first.ErrorCode != ResultErrorPartialFailure || first.Recipients[1].Success {
+		t.Fatalf("Expected the second recipient to fail, got %+v", first)
+	}
+	if deposits := len(mock.Deposits()); deposits != 2 {
+		t.Fatalf("Expected 2 deposits, got %d", deposits)
+	}
+
+	// A redelivery pays only the recipient that failed, and keeps the others' outcomes
+	mock.fail = common.Address{}
+	retried := deliver()
+	if !retried.Success || retried.ErrorCode != ResultErrorNone {
+		t.Fatalf("Expected the retry to succeed, got %+v", retried)
+	}
+	deposits := mock.Deposits()
+	if len(deposits) != 3 || deposits[2].Transfer.Recipient != common.HexToAddress(task.Recipients[1]) {
+		t.Fatalf("Expected one more deposit to %s, got %+v", task.Recipients[1], deposits)
+	}
+	for _, i := range []int{0, 2} {
+		if retried.Recipients[i].TransactionHash != first.Recipients[i].TransactionHash {
+			t.Errorf("Recipient %d: expected deposit %s kept, got %s", i, first.Recipients[i].TransactionHash, retried.Recipients[i].TransactionHash)
+		}
+	}
+	expected := new(big.Int)
+	for _, r := range retried.Recipients {
+		expected.Add(expected, r.DistributedAmount)
+	}
+	if retried.DistributedAmount.Cmp(expected) != 0 {
+		t.Errorf("Expected %v distributed, got %v", expected, retried.DistributedAmount)
+	}
+
+	// Once every recipient is paid, redeliveries return the stored result
+	if again := deliver(); again.ProcessedAt != retried.ProcessedAt || len(mock.Deposits()) != 3 {
+		t.Errorf("Expected the stored result without new deposits, got %+v", again)
+	}
+}
+
+func TestDecodeBatchTaskPayload_ABIRoundTrip(t *testing.T) {
+	task := newBatchTask()
+	task.ChainID = 0 // Not carried by the ABI layout
+
+	payload, err := EncodeBatchTaskPayload(&task, PayloadFormatBatchABI)
+	if err != nil {
+		t.Fatalf("EncodeBatchTaskPayload failed: %v", err)
+	}
+
+	decoded, format, err := DecodeBatchTaskPayload(payload)
+	if err != nil {
+		t.Fatalf("DecodeBatchTaskPayload failed: %v", err)
+	}
+	if format != PayloadFormatBatchABI {
+		t.Errorf("Expected format %s, got %s", PayloadFormatBatchABI, format)
+	}
+	if decoded.PoolID != task.PoolID || decoded.TaskHash != task.TaskHash {
+		t.Errorf("Expected pool %s / hash %s, got %s / %s", task.PoolID, task.TaskHash, decoded.PoolID, decoded.TaskHash)
+	}
+	if decoded.TargetChain != task.TargetChain || decoded.Timestamp != task.Timestamp {
+		t.Errorf("Expected target %d / timestamp %d, got %d / %d", task.TargetChain, task.Timestamp, decoded.TargetChain, decoded.Timestamp)
+	}
+	if decoded.TotalAmount.Cmp(task.TotalAmount) != 0 {
+		t.Errorf("Expected total %v, got %v", task.TotalAmount, decoded.TotalAmount)
+	}
+	for i := range task.Recipients {
+		if !strings.EqualFold(decoded.Recipients[i], task.Recipients[i]) {
+			t.Errorf("Recipient %d: expected %s, got %s", i, task.Recipients[i], decoded.Recipients[i])
+		}
+		if decoded.Amounts[i].Cmp(task.Amounts[i]) != 0 {
+			t.Errorf("Amount %d: expected %v, got %v", i, task.Amounts[i], decoded.Amounts[i])
+		}
+	}
+
+	// Single-task decoding must refuse batch payloads
+	if _, _, err := DecodeTaskPayload(payload); err == nil {
+		t.Errorf("Expected DecodeTaskPayload to reject a batch payload")
+	}
+}
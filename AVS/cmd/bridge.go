@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/bridge"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/config"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/fees"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/signer"
+)
+
+// bridgeDepositTimeout bounds the RPC calls of a single deposit
+const bridgeDepositTimeout = 30 * time.Second
+
+// BridgeRoute is the bridge a distribution was sent through
+type BridgeRoute struct {
+	Name string   `json:"name"`
+	Fee  *big.Int `json:"fee"`
+}
+
+// WithBridgeRouter sends distributions through the cheapest healthy bridge of
+// router. They are simulated without one.
+func WithBridgeRouter(router *bridge.Router) WorkerOption {
+	return func(rf *RewardFlowTaskWorker) {
+		rf.bridges = router
+	}
+}
+
+// newBridgeRouter routes between the enabled bridges, sending from signers,
+// and returns nil when none is enabled. Across is listed first so that it wins
+// ties, though it never quotes the same-chain routes direct transfers serve.
+func newBridgeRouter(cfg *config.Config, signers map[uint64]*signer.Signer, chainFees fees.ChainFees) (*bridge.Router, error) {
+	if !cfg.Across.Enabled && !cfg.Direct.Enabled {
+		return nil, nil
+	}
+	acrossChains := make(map[uint64]bridge.AcrossChain)
+	directChains := make(map[uint64]bridge.DirectChain)
+	for _, chain := range cfg.Chains {
+		s, ok := signers[chain.ChainID]
+		if !chain.IsEnabled() || !ok {
+			continue
+		}
+		token := common.HexToAddress(chain.RewardToken)
+		acrossChains[chain.ChainID] = bridge.AcrossChain{Signer: s, SpokePool: common.HexToAddress(chain.SpokePool), Token: token}
+		directChains[chain.ChainID] = bridge.DirectChain{Signer: s, Token: token}
+	}
+
+	var bridges []bridge.Bridge
+	if cfg.Across.Enabled {
+		bridges = append(bridges, bridge.NewAcross(acrossChains, chainFees, cfg.Across.FillDeadline, cfg.Across.Native))
+	}
+	if cfg.Direct.Enabled {
+		bridges = append(bridges, bridge.NewDirect(directChains, cfg.Direct.Native))
+	}
+	return bridge.NewRouter(bridge.DefaultCooldown, bridges...)
+}
+
+// deposit sends distributed to recipient on targetChain through the cheapest
+// healthy bridge. The bridge fee is paid on top, out of the distribution fee.
+func (rf *RewardFlowTaskWorker) deposit(recipient string, sourceChain, targetChain uint64, distributed *big.Int) (bridge.Deposit, error) {
+	if !common.IsHexAddress(recipient) {
+		return bridge.Deposit{}, fmt.Errorf("invalid recipient %q", recipient)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), bridgeDepositTimeout)
+	defer cancel()
+	return rf.bridges.Deposit(ctx, bridge.Transfer{
+		Recipient:   common.HexToAddress(recipient),
+		SourceChain: sourceChain,
+		TargetChain: targetChain,
+		Amount:      distributed,
+	})
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/params"
+	"go.uber.org/zap"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/across"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/bridge"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/config"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/preferences"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/signer"
+)
+
+// mockSpokePoolCode accepts any deposit and logs its arguments, see pkg/across
+func mockSpokePoolCode() []byte {
+	return common.FromHex("0x600436036004600037600436036000a000")
+}
+
+// chainIDClient reports another chain ID than its backend, so that one
+// simulated backend can stand in for a second chain
+type chainIDClient struct {
+	signer.Client
+	id uint64
+}
+
+func (c chainIDClient) ChainID(context.Context) (*big.Int, error) {
+	return new(big.Int).SetUint64(c.id), nil
+}
+
+// simulatedBridges is a simulated chain 1337 with a mock SpokePool and a funded
+// depositor, configured with Optimism as the only other chain
+type simulatedBridges struct {
+	backend *simulated.Backend
+	cfg     *config.Config
+	clients map[uint64]signer.Client
+}
+
+var (
+	acrossSpokePool   = common.HexToAddress("0x00000000000000000000000000000000000000a0")
+	localRewardToken  = common.HexToAddress("0x00000000000000000000000000000000000000c0")
+	remoteRewardToken = common.HexToAddress("0x00000000000000000000000000000000000000c1")
+)
+
+func newSimulatedBridges(t *testing.T) (*simulatedBridges, *bridge.Router) {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	backend := simulated.NewBackend(types.GenesisAlloc{
+		crypto.PubkeyToAddress(key.PublicKey): {Balance: big.NewInt(5 * params.Ether)},
+		acrossSpokePool:                       {Code: mockSpokePoolCode()},
+	})
+	t.Cleanup(func() { backend.Close() })
+
+	cfg := config.Default()
+	cfg.Rewards.FeeModel = config.FeeModelBasePlusBps
+	cfg.Across = config.AcrossConfig{Enabled: true, FillDeadline: across.DefaultFillDeadline, Native: true}
+	cfg.Direct = config.DirectConfig{Enabled: true, Native: true}
+	cfg.Chains = []config.ChainConfig{
+		{ChainID: 1337, Name: "local", SpokePool: acrossSpokePool.Hex(), RewardToken: localRewardToken.Hex()},
+		{ChainID: 10, Name: "optimism", SpokePool: acrossSpokePool.Hex(), RewardToken: remoteRewardToken.Hex(), BaseFee: config.NewAmount(big.NewInt(1e15))},
+	}
+	sim := &simulatedBridges{
+		backend: backend,
+		cfg:     cfg,
+		clients: map[uint64]signer.Client{
+			1337: backend.Client(),
+			10:   chainIDClient{Client: backend.Client(), id: 10},
+		},
+	}
+	registry, err := newChainRegistry(cfg.Chains)
+	if err != nil {
+		t.Fatalf("Failed to build chain registry: %v", err)
+	}
+	signers, err := buildSigners(context.Background(), cfg, key, sim.clients)
+	if err != nil {
+		t.Fatalf("Failed to build signers: %v", err)
+	}
+	router, err := newBridgeRouter(cfg, signers, registry)
+	if err != nil {
+		t.Fatalf("Failed to build bridge router: %v", err)
+	}
+	return sim, router
+}
+
+// handleTask runs task through worker and decodes its result
+func handleTask(t *testing.T, worker *RewardFlowTaskWorker, id string, task RewardDistributionTask) RewardDistributionResult {
+	t.Helper()
+	response, err := worker.HandleTask(&performerV1.TaskRequest{TaskId: []byte(id), Payload: []byte(marshalTask(t, task))})
+	if err != nil {
+		t.Fatalf("HandleTask failed: %v", err)
+	}
+	var result RewardDistributionResult
+	if err := json.Unmarshal(response.Result, &result); err != nil {
+		t.Fatalf("Failed to decode result: %v", err)
+	}
+	return result
+}
+
+func TestRewardFlowTaskWorker_AcrossDeposit(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	sim, router := newSimulatedBridges(t)
+	worker := NewRewardFlowTaskWorker(logger, withConfig(t, sim.cfg), WithBridgeRouter(router))
+
+	task := newCLITask()
+	task.ChainID = 1337
+	result := handleTask(t, worker, "across", task)
+	if !result.Success || result.TargetChain != 10 || result.TransactionHash == "" {
+		t.Fatalf("Expected a deposit to Optimism, got %+v", result)
+	}
+	if result.Bridge == nil || result.Bridge.Name != bridge.NameAcross || result.Bridge.Fee.Cmp(result.FeeBreakdown.BridgeFee) != 0 {
+		t.Errorf("Expected the result to report Across at the bridge fee, got %+v", result.Bridge)
+	}
+	sim.backend.Commit()
+
+	ctx := context.Background()
+	client := sim.backend.Client()
+	hash := common.HexToHash(result.TransactionHash)
+	receipt, err := client.TransactionReceipt(ctx, hash)
+	if err != nil {
+		t.Fatalf("Failed to get receipt: %v", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful || len(receipt.Logs) != 1 {
+		t.Fatalf("Expected the SpokePool to accept the deposit, got status %d with %d logs", receipt.Status, len(receipt.Logs))
+	}
+	tx, _, err := client.TransactionByHash(ctx, hash)
+	if err != nil {
+		t.Fatalf("Failed to get transaction: %v", err)
+	}
+	deposit, err := across.ParseCalldata(tx.Data())
+	if err != nil {
+		t.Fatalf("ParseCalldata failed: %v", err)
+	}
+
+	// The relayer keeps Optimism's base fee and delivers the distributed amount
+	if deposit.Recipient != common.HexToAddress(task.User) || deposit.DestinationChainID != 10 {
+		t.Errorf("Unexpected recipient or destination: %+v", deposit)
+	}
+	if deposit.InputToken != localRewardToken || deposit.OutputToken != remoteRewardToken {
+		t.Errorf("Expected %s bridged to %s, got %s to %s", localRewardToken, remoteRewardToken, deposit.InputToken, deposit.OutputToken)
+	}
+	if deposit.OutputAmount().Cmp(result.DistributedAmount) != 0 || deposit.RelayerFee.Cmp(result.FeeBreakdown.BridgeFee) != 0 {
+		t.Errorf("Expected output %s and relayer fee %s, got %s and %s", result.DistributedAmount, result.FeeBreakdown.BridgeFee, deposit.OutputAmount(), deposit.RelayerFee)
+	}
+	if tx.Value().Cmp(deposit.InputAmount) != 0 {
+		t.Errorf("Expected %s sent with the deposit, got %s", deposit.InputAmount, tx.Value())
+	}
+	if deposit.FillDeadline-deposit.QuoteTimestamp != 1800 {
+		t.Errorf("Expected a 30 minute fill deadline, got %d seconds", deposit.FillDeadline-deposit.QuoteTimestamp)
+	}
+}
+
+func TestRewardFlowTaskWorker_DirectTransfer(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	sim, router := newSimulatedBridges(t)
+	task := newCLITask()
+	task.ChainID = 1337
+	store := preferences.NewMemoryStore()
+	prefs := preferences.Default()
+	prefs.PreferredChain = 1337
+	if err := store.Set(task.User, prefs); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	worker := NewRewardFlowTaskWorker(logger, withConfig(t, sim.cfg), WithPreferenceStore(store), WithBridgeRouter(router))
+
+	// Rewards routed to the chain they were earned on are paid without a bridge
+	result := handleTask(t, worker, "direct", task)
+	if !result.Success || result.TargetChain != 1337 || result.TransactionHash == "" {
+		t.Fatalf("Expected a transfer on the local chain, got %+v", result)
+	}
+	if result.Bridge == nil || result.Bridge.Name != bridge.NameDirect || result.Bridge.Fee.Sign() != 0 {
+		t.Errorf("Expected the result to report a free direct transfer, got %+v", result.Bridge)
+	}
+	sim.backend.Commit()
+
+	balance, err := sim.backend.Client().BalanceAt(context.Background(), common.HexToAddress(task.User), nil)
+	if err != nil {
+		t.Fatalf("Failed to get balance: %v", err)
+	}
+	if balance.Cmp(result.DistributedAmount) != 0 {
+		t.Errorf("Expected the user to receive %s, got %s", result.DistributedAmount, balance)
+	}
+}
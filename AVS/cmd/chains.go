@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/chains"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/config"
+)
+
+// WithChainRegistry sets the registry of chains rewards can be routed to and from
+func WithChainRegistry(registry *chains.Registry) WorkerOption {
+	return func(rf *RewardFlowTaskWorker) {
+		rf.chains = registry
+	}
+}
+
+// newChainRegistry builds a chain registry from the configured chains
+func newChainRegistry(configured []config.ChainConfig) (*chains.Registry, error) {
+	list := make([]chains.Chain, 0, len(configured))
+	for _, c := range configured {
+		chain := chains.Chain{
+			ID:             c.ChainID,
+			Name:           c.Name,
+			NativeCurrency: c.Currency(),
+			Confirmations:  c.Confirmations,
+			Enabled:        c.IsEnabled(),
+			Paused:         c.Paused,
+			Fee:            chains.FeeParams{Bps: c.FeeBps},
+		}
+		if c.SpokePool != "" {
+			chain.SpokePool = common.HexToAddress(c.SpokePool)
+		}
+		if c.BaseFee != nil {
+			chain.Fee.BaseFee = &c.BaseFee.Int
+		}
+		list = append(list, chain)
+	}
+	return chains.NewRegistry(list)
+}
+
+// newChainFollower keeps registry in line with the RewardDistributor when the
+// events chain support source is configured, and returns nil otherwise. The
+// distributor is read once here so that an unreachable RPC fails startup; the
+// returned function releases its RPC client.
+func newChainFollower(ctx context.Context, cfg config.ChainSupportConfig, registry *chains.Registry) (*chains.Follower, []chains.Change, func(), error) {
+	if cfg.Source != config.ChainSupportSourceEvents {
+		return nil, nil, func() {}, nil
+	}
+
+	client, err := ethclient.DialContext(ctx, cfg.RPC)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to connect to %s: %w", cfg.RPC, err)
+	}
+	follower := chains.NewFollower(registry, client, common.HexToAddress(cfg.DistributorAddress))
+	changes, err := follower.Sync(ctx)
+	if err != nil {
+		client.Close()
+		return nil, nil, nil, fmt.Errorf("failed to load chain support: %w", err)
+	}
+	return follower, changes, client.Close, nil
+}
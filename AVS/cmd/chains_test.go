@@ -0,0 +1,119 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"go.uber.org/zap"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/config"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/preferences"
+)
+
+func TestRewardFlowTaskWorker_ChainRegistry(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	disabled := false
+	cfg := config.Default()
+	cfg.Chains[1].Enabled = &disabled // optimism
+	cfg.Chains[3].Paused = true       // polygon
+	registry, err := newChainRegistry(cfg.Chains)
+	if err != nil {
+		t.Fatalf("Failed to build chain registry: %v", err)
+	}
+
+	worker := NewRewardFlowTaskWorker(logger, WithChainRegistry(registry))
+
+	tests := []struct {
+		name     string
+		payload  func() interface{}
+		errorMsg string
+	}{
+		{
+			name: "enabled source chain",
+			payload: func() interface{} {
+				return newCLITask()
+			},
+		},
+		{
+			name: "disabled source chain",
+			payload: func() interface{} {
+				task := newCLITask()
+				task.ChainID = 10
+				return task
+			},
+			errorMsg: "source chain 10 (optimism) is disabled",
+		},
+		{
+			name: "unknown source chain",
+			payload: func() interface{} {
+				task := newCLITask()
+				task.ChainID = 56
+				return task
+			},
+			errorMsg: "source chain 56 is not supported",
+		},
+		{
+			name: "paused batch target chain",
+			payload: func() interface{} {
+				task := newBatchTask()
+				task.TargetChain = 137
+				return task
+			},
+			errorMsg: "target chain 137 (polygon) is paused",
+		},
+		{
+			name: "disabled batch source chain",
+			payload: func() interface{} {
+				task := newBatchTask()
+				task.ChainID = 10
+				return task
+			},
+			errorMsg: "source chain 10 (optimism) is disabled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := worker.ValidateTask(&performerV1.TaskRequest{
+				TaskId:  []byte("chains-" + tt.name),
+				Payload: []byte(marshalTask(t, tt.payload())),
+			})
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("Expected task to be valid, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("Expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+
+	// Routing skips inactive chains, whether preferred or in the fallback order
+	store := preferences.NewMemoryStore()
+	prefs := preferences.Default()
+	prefs.PreferredChain = 137
+	if err := store.Set("0x00000000000000000000000000000000000000b1", prefs); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	WithPreferenceStore(store)(worker)
+
+	chain, reason := worker.determineTargetChain(1, "0x00000000000000000000000000000000000000b1", big.NewInt(1e18))
+	if chain != 8453 || reason != RoutingReasonPreferenceUnsupported {
+		t.Errorf("Expected fallback to 8453 for a paused preferred chain, got %d (%s)", chain, reason)
+	}
+
+	// Toggling a chain takes effect on the next task
+	if _, err := registry.SetPaused(137, false); err != nil {
+		t.Fatalf("SetPaused failed: %v", err)
+	}
+	if chain, reason := worker.determineTargetChain(1, "0x00000000000000000000000000000000000000b1", big.NewInt(1e18)); chain != 137 || reason != RoutingReasonPreferredChain {
+		t.Errorf("Expected the resumed preferred chain 137, got %d (%s)", chain, reason)
+	}
+}
@@ -0,0 +1,683 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/performer/server"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/rpcServer"
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/chains"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/config"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/fees"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/idempotency"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/metrics"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/policy"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/scheduler"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/stats"
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// statsPath serves the JSON stats snapshot on the metrics listener
+const statsPath = "/stats"
+
+// exitDrainIncomplete is the exit code when tasks were still running at the drain deadline
+const exitDrainIncomplete = 2
+
+// configFlags select and override the operator configuration
+func configFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "config",
+			Usage:   "Operator configuration file (YAML)",
+			EnvVars: []string{"CONFIG_FILE"},
+		},
+		&cli.StringFlag{
+			Name:  "environment",
+			Usage: "Environment profile (devnet, testnet, mainnet); overrides $ENVIRONMENT",
+		},
+	}
+}
+
+// newApp builds the operator command tree
+func newApp() *cli.App {
+	return &cli.App{
+		Name:    "rewardflow-performer",
+		Usage:   "RewardFlow AVS performer: Uniswap V4 Hook Reward Distribution AVS",
+		Version: "1.0.0",
+		// Containers launch the binary without arguments, so that still starts the server
+		DefaultCommand: "start",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "log-level",
+				Usage: "Log level (debug, info, warn, error); overrides the configuration",
+			},
+		},
+		Commands: []*cli.Command{
+			{
+				Name:   "start",
+				Usage:  "Run the performer server",
+				Action: runStart,
+				Flags: append(configFlags(),
+					&cli.IntFlag{
+						Name:  "port",
+						Usage: "Performer gRPC port; overrides server.port",
+					},
+					&cli.DurationFlag{
+						Name:  "timeout",
+						Usage: "Task execution timeout; overrides server.timeout",
+					},
+					&cli.IntFlag{
+						Name:  "metrics-port",
+						Usage: "Port serving /metrics and /stats; overrides metrics.port",
+					},
+					&cli.StringFlag{
+						Name:  "idempotency-db",
+						Usage: "Path of the processed task store; overrides idempotency.path",
+					},
+					&cli.DurationFlag{
+						Name:  "idempotency-retention",
+						Usage: "How long processed tasks are remembered (0 keeps them forever); overrides idempotency.retention",
+					},
+					&cli.StringFlag{
+						Name:  "result-encoding",
+						Usage: "Task result encoding (json, abi); overrides server.result_encoding",
+					},
+				),
+			},
+			{
+				Name:      "validate",
+				Usage:     "Validate a task payload file without processing it",
+				ArgsUsage: "<payload-file>",
+				Action:    runValidate,
+				Flags:     configFlags(),
+			},
+			{
+				Name:      "simulate",
+				Usage:     "Run a JSONL file of task payloads through the performer offline",
+				ArgsUsage: "<tasks.jsonl>",
+				Action:    runSimulate,
+				Flags:     configFlags(),
+			},
+			{
+				Name:  "stats",
+				Usage: "Show the statistics of a running performer",
+				Action: func(c *cli.Context) error {
+					return runStats(c.Context, c.String("endpoint"), c.App.Writer)
+				},
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "endpoint",
+						Usage: "Metrics listener of the running performer",
+						Value: fmt.Sprintf("http://localhost:%d", metrics.DefaultPort),
+					},
+				},
+			},
+		},
+	}
+}
+
+// loadConfig loads the operator configuration and applies the command line
+// flags that were set explicitly, which take precedence over everything else
+func loadConfig(c *cli.Context) (*config.Config, error) {
+	cfg, err := config.Load(c.String("config"), c.String("environment"))
+	if err != nil {
+		return nil, err
+	}
+
+	if c.IsSet("log-level") {
+		cfg.Logging.Level = c.String("log-level")
+	}
+	if c.IsSet("port") {
+		cfg.Server.Port = c.Int("port")
+	}
+	if c.IsSet("timeout") {
+		cfg.Server.Timeout = c.Duration("timeout")
+	}
+	if c.IsSet("metrics-port") {
+		cfg.Metrics.Port = c.Int("metrics-port")
+	}
+	if c.IsSet("idempotency-db") {
+		cfg.Idempotency.Path = c.String("idempotency-db")
+	}
+	if c.IsSet("idempotency-retention") {
+		cfg.Idempotency.Retention = c.Duration("idempotency-retention")
+	}
+	if c.IsSet("result-encoding") {
+		cfg.Server.ResultEncoding = c.String("result-encoding")
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// newLogger creates the RewardFlow production logger
+func newLogger(cfg config.LoggingConfig) (*zap.Logger, error) {
+	lvl, err := zapcore.ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log level %q: %w", cfg.Level, err)
+	}
+
+	zc := zap.NewProductionConfig()
+	zc.Level = zap.NewAtomicLevelAt(lvl)
+	if cfg.Format == "text" {
+		zc.Encoding = "console"
+	}
+	zc.EncoderConfig.TimeKey = "timestamp"
+	zc.EncoderConfig.EncodeTime = zapcore.TimeEncoderOfLayout(time.RFC3339)
+	return zc.Build()
+}
+
+// runStart runs the performer server until SIGINT or SIGTERM, then drains the
+// in-flight tasks before exiting
+func runStart(c *cli.Context) error {
+	// A second signal falls back to the default handler and kills the process
+	ctx, stopSignals := signal.NotifyContext(c.Context, os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	cfg, err := loadConfig(c)
+	if err != nil {
+		return err
+	}
+
+	l, err := newLogger(cfg.Logging)
+	if err != nil {
+		return err
+	}
+	defer l.Sync()
+
+	// Build the worker settings first so a configuration they reject fails startup
+	configured, err := WithConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	l.Info("Starting RewardFlow AVS Performer",
+		zap.String("version", c.App.Version),
+		zap.String("description", "Uniswap V4 Hook Reward Distribution AVS"),
+		zap.String("environment", cfg.Environment),
+		zap.String("execution_mode", cfg.Execution.Mode),
+	)
+	if cfg.Execution.Mode != config.ExecutionModeLive {
+		l.Warn("Distributions are built but not sent", zap.String("execution_mode", cfg.Execution.Mode))
+	}
+
+	// Open the idempotency store so retried tasks are not distributed twice
+	store, err := idempotency.Open(cfg.Idempotency.Path, cfg.Idempotency.Retention)
+	if err != nil {
+		return fmt.Errorf("failed to open idempotency store: %w", err)
+	}
+	defer func() {
+		if err := store.Close(); err != nil {
+			l.Error("Failed to close idempotency store", zap.Error(err))
+		}
+	}()
+
+	pruneDone := make(chan struct{})
+	defer close(pruneDone)
+	go pruneProcessedTasks(store, cfg.Idempotency.PruneInterval, l, pruneDone)
+
+	// Load the reward limits and keep them in sync with their source
+	validationPolicy, closePolicy, err := newValidationPolicy(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer closePolicy()
+	l.Info("Validation policy loaded",
+		zap.String("policy", validationPolicy.Name()),
+		zap.Stringer("min_reward_amount", validationPolicy.Limits().MinRewardAmount),
+		zap.Stringer("max_reward_amount", validationPolicy.Limits().MaxRewardAmount),
+		zap.Stringer("task_fee", validationPolicy.Limits().TaskFee),
+	)
+	go policy.Watch(ctx, validationPolicy, cfg.ValidationPolicy.RefreshInterval, l)
+
+	// Load user routing preferences
+	prefs, err := newPreferenceSource(ctx, cfg.Preferences)
+	if err != nil {
+		return err
+	}
+	defer prefs.close()
+	if prefs.events != nil {
+		l.Info("User preferences loaded", zap.Int("users", prefs.events.Len()))
+		go prefs.events.Run(ctx, cfg.Preferences.PollInterval, l)
+	}
+
+	// Load user activity for engagement and loyalty scores
+	activity, err := newEngagementSource(ctx, cfg.Engagement)
+	if err != nil {
+		return err
+	}
+	defer activity.close()
+	if activity.events != nil {
+		l.Info("User activity loaded", zap.Int("users", activity.events.Len()))
+		go activity.events.Run(ctx, cfg.Engagement.PollInterval, l)
+	}
+
+	// Build the chain registry and keep its support in sync with the distributor
+	registry, err := newChainRegistry(cfg.Chains)
+	if err != nil {
+		return err
+	}
+	follower, changes, closeFollower, err := newChainFollower(ctx, cfg.ChainSupport, registry)
+	if err != nil {
+		return err
+	}
+	defer closeFollower()
+	chains.LogChanges(l, changes)
+	if follower != nil {
+		go follower.Run(ctx, cfg.ChainSupport.PollInterval, l)
+	}
+	feeEngine, err := fees.New(cfg.Rewards.FeeModel, registry, cfg.Rewards.FeeBps, cfg.Rewards.ProtocolFeeShareBps)
+	if err != nil {
+		return fmt.Errorf("invalid fee configuration: %w", err)
+	}
+	distributionScheduler, err := scheduler.New(schedulerConfig(cfg.Scheduler))
+	if err != nil {
+		return fmt.Errorf("invalid scheduler configuration: %w", err)
+	}
+	gasPrices, closeGas, err := newGasSource(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer closeGas()
+	signers, closeSigners, err := newSigners(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer closeSigners()
+	for _, s := range signers {
+		l.Info("Signer ready", zap.Uint64("chain_id", s.ChainID()), zap.String("address", s.Address().Hex()), zap.Bool("dry_run", s.DryRun()))
+		go s.Run(ctx, cfg.Signer.RecoverInterval, l)
+	}
+	bridges, err := newBridgeRouter(cfg, signers, registry)
+	if err != nil {
+		return err
+	}
+	// Follow bridge deposits until they are delivered, across restarts
+	tracker, closeTransfers, err := newTransferTracker(cfg, bridges, l)
+	if err != nil {
+		return fmt.Errorf("failed to open transfer store: %w", err)
+	}
+	defer closeTransfers()
+	if tracker != nil {
+		go tracker.Run(ctx, cfg.Transfers.PollInterval, l)
+	}
+
+	// Create RewardFlow task worker
+	m := metrics.New()
+	m.SetExecutionMode(cfg.Execution.Mode)
+	w := NewRewardFlowTaskWorker(l,
+		configured,
+		WithValidationPolicy(validationPolicy),
+		WithPreferenceStore(prefs.store),
+		WithEngagementTracker(activity.tracker),
+		WithChainRegistry(registry),
+		WithFeeEngine(feeEngine),
+		WithScheduler(distributionScheduler),
+		WithGasPriceSource(gasPrices),
+		WithBridgeRouter(bridges),
+		WithTransferTracker(tracker),
+		WithShadow(cfg.Execution),
+		WithIdempotencyStore(store),
+		WithMetrics(m),
+	)
+
+	// The servers outlive the signal so that draining tasks can still answer
+	serveCtx, stopServing := context.WithCancel(context.Background())
+	defer stopServing()
+
+	// Expose Prometheus metrics and the stats snapshot
+	metricsDone := make(chan struct{})
+	go func() {
+		defer close(metricsDone)
+		if err := m.Serve(serveCtx, cfg.Metrics.Port, metrics.Route{Path: statsPath, Handler: w.statsHandler()},
+			metrics.Route{Path: engagementPath, Handler: w.engagementHandler()},
+			metrics.Route{Path: transfersPath, Handler: w.transfersHandler()},
+			metrics.Route{Path: resultsPath, Handler: w.resultsHandler()}); err != nil {
+			l.Error("Metrics server stopped", zap.Error(err))
+		}
+	}()
+
+	// Start the performer server
+	rpc, err := rpcServer.NewRpcServer(&rpcServer.RpcServerConfig{GrpcPort: cfg.Server.Port}, l)
+	if err != nil {
+		return fmt.Errorf("failed to create RewardFlow performer: %w", err)
+	}
+	pp := server.NewPonosPerformer(&server.PonosPerformerConfig{
+		Port:    cfg.Server.Port,
+		Timeout: cfg.Server.Timeout,
+	}, rpc, w, l)
+	go func() {
+		if err := pp.Start(serveCtx); err != nil {
+			l.Error("Performer server stopped", zap.Error(err))
+		}
+	}()
+
+	l.Info("RewardFlow AVS Performer started successfully",
+		zap.Int("port", cfg.Server.Port),
+		zap.Int("metrics_port", cfg.Metrics.Port),
+		zap.Duration("timeout", cfg.Server.Timeout),
+		zap.Uint64s("active_chains", registry.ActiveIDs()),
+		zap.String("fee_model", feeEngine.Model()),
+	)
+
+	<-ctx.Done()
+	stopSignals()
+
+	l.Info("Shutdown requested, draining in-flight tasks",
+		zap.Int("in_flight", w.gate.active()),
+		zap.Duration("drain_timeout", cfg.Server.DrainTimeout),
+	)
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), cfg.Server.DrainTimeout)
+	defer cancelDrain()
+	drainErr := w.Drain(drainCtx)
+
+	// Tasks still running after the deadline are cut off
+	stopServing()
+	stopped := make(chan struct{})
+	go func() {
+		rpc.GetGrpcServer().GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-drainCtx.Done():
+		rpc.GetGrpcServer().Stop()
+		<-stopped
+	}
+	<-metricsDone
+
+	snapshot := w.GetStats()
+	l.Info("Final task statistics",
+		zap.Int64("tasks_processed", snapshot.TotalTasksProcessed),
+		zap.Int64("tasks_succeeded", snapshot.TotalSucceeded),
+		zap.Int64("tasks_failed", snapshot.TotalFailed),
+		zap.Stringer("rewards_distributed", bigOrZero(snapshot.TotalRewardsDistributed)),
+	)
+
+	if drainErr != nil {
+		l.Error("Shutdown drain incomplete", zap.Error(drainErr))
+		return cli.Exit(fmt.Sprintf("drain incomplete: %s", drainErr), exitDrainIncomplete)
+	}
+	l.Info("RewardFlow AVS Performer stopped")
+	return nil
+}
+
+// runValidate checks a payload file against the task validation rules
+func runValidate(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf("expected exactly one payload file")
+	}
+
+	cfg, err := loadConfig(c)
+	if err != nil {
+		return err
+	}
+
+	l, err := newLogger(cfg.Logging)
+	if err != nil {
+		return err
+	}
+	defer l.Sync()
+
+	payload, err := readPayloadFile(c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	configured, err := WithConfig(cfg)
+	if err != nil {
+		return err
+	}
+	w := NewRewardFlowTaskWorker(l, configured)
+	if err := w.ValidateTask(&performerV1.TaskRequest{TaskId: []byte("validate"), Payload: payload}); err != nil {
+		return cli.Exit(fmt.Sprintf("invalid payload: %s", err), 1)
+	}
+
+	fmt.Fprintf(c.App.Writer, "payload is valid (%s)\n", DetectPayloadFormat(payload))
+	return nil
+}
+
+// readPayloadFile reads a task payload. Files holding 0x-prefixed hex are
+// decoded, so ABI payloads can be kept as text.
+func readPayloadFile(path string) ([]byte, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read payload file: %w", err)
+	}
+
+	trimmed := strings.TrimSpace(string(raw))
+	if strings.HasPrefix(trimmed, "0x") {
+		payload, err := hex.DecodeString(trimmed[2:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex payload: %w", err)
+		}
+		return payload, nil
+	}
+	return raw, nil
+}
+
+// simulationOutcome is the JSONL record written for each simulated task
+type simulationOutcome struct {
+	Line   int             `json:"line"`
+	TaskID string          `json:"task_id"`
+	Valid  bool            `json:"valid"`
+	Error  string          `json:"error,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+}
+
+// runSimulate runs every task of a JSONL file through ValidateTask and HandleTask
+func runSimulate(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf("expected exactly one JSONL file")
+	}
+
+	cfg, err := loadConfig(c)
+	if err != nil {
+		return err
+	}
+
+	l, err := newLogger(cfg.Logging)
+	if err != nil {
+		return err
+	}
+	defer l.Sync()
+
+	f, err := os.Open(c.Args().First())
+	if err != nil {
+		return fmt.Errorf("failed to open tasks file: %w", err)
+	}
+	defer f.Close()
+
+	// Simulation never touches the idempotency store or metrics of a running performer
+	configured, err := WithConfig(cfg)
+	if err != nil {
+		return err
+	}
+	w := NewRewardFlowTaskWorker(l, configured)
+	if err := simulateTasks(w, f, c.App.Writer); err != nil {
+		return err
+	}
+	renderStats(c.App.ErrWriter, w.GetStats())
+	return nil
+}
+
+// simulateTasks processes each non-empty line of r as a task payload and writes one outcome per line to out
+func simulateTasks(w *RewardFlowTaskWorker, r io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	encoder := json.NewEncoder(out)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		payload := strings.TrimSpace(scanner.Text())
+		if payload == "" || strings.HasPrefix(payload, "#") {
+			continue
+		}
+
+		request := &performerV1.TaskRequest{
+			TaskId:  []byte("simulate-" + strconv.Itoa(line)),
+			Payload: []byte(payload),
+		}
+		outcome := simulationOutcome{Line: line, TaskID: string(request.TaskId)}
+
+		if err := w.ValidateTask(request); err != nil {
+			outcome.Error = err.Error()
+		} else if response, err := w.HandleTask(request); err != nil {
+			outcome.Error = err.Error()
+		} else {
+			outcome.Valid = true
+			outcome.Result = response.Result
+		}
+
+		if err := encoder.Encode(outcome); err != nil {
+			return fmt.Errorf("failed to write outcome: %w", err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read tasks file: %w", err)
+	}
+	return nil
+}
+
+// statsHandler serves the worker's stats snapshot as JSON
+func (rf *RewardFlowTaskWorker) statsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(rf.GetStats()); err != nil {
+			rf.logger.Error("Failed to encode stats", zap.Error(err))
+		}
+	})
+}
+
+// runStats fetches the stats snapshot of a running performer and renders it
+func runStats(ctx context.Context, endpoint string, out io.Writer) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(endpoint, "/")+statsPath, nil)
+	if err != nil {
+		return fmt.Errorf("invalid endpoint: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to query performer: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("performer returned %s", resp.Status)
+	}
+
+	var snapshot stats.Snapshot
+	if err := json.NewDecoder(resp.Body).Decode(&snapshot); err != nil {
+		return fmt.Errorf("failed to decode stats: %w", err)
+	}
+	renderStats(out, snapshot)
+	return nil
+}
+
+// renderStats writes a snapshot as a summary table followed by the breakdown tables
+func renderStats(out io.Writer, snapshot stats.Snapshot) {
+	summary := tablewriter.NewWriter(out)
+	summary.SetHeader([]string{"Metric", "Value"})
+	rows := [][]string{
+		{"Tasks processed", strconv.FormatInt(snapshot.TotalTasksProcessed, 10)},
+		{"Succeeded", strconv.FormatInt(snapshot.TotalSucceeded, 10)},
+		{"Failed", strconv.FormatInt(snapshot.TotalFailed, 10)},
+		{"Deferred", strconv.FormatInt(snapshot.TotalDeferred, 10)},
+		{"Success rate", fmt.Sprintf("%.2f%%", snapshot.SuccessRate)},
+		{"Rewards distributed (wei)", bigOrZero(snapshot.TotalRewardsDistributed).String()},
+		{"MEV captured (wei)", bigOrZero(snapshot.TotalMEVCaptured).String()},
+		{"Average processing time", fmt.Sprintf("%.1fms", snapshot.AverageProcessingTime)},
+		{"Latency p50/p95/p99", fmt.Sprintf("%.1fms / %.1fms / %.1fms", snapshot.Latency.P50, snapshot.Latency.P95, snapshot.Latency.P99)},
+		{fmt.Sprintf("Tasks in last %s", snapshot.Window.Duration), strconv.FormatInt(snapshot.Window.Tasks, 10)},
+		{fmt.Sprintf("Success rate in last %s", snapshot.Window.Duration), fmt.Sprintf("%.2f%%", snapshot.Window.SuccessRate)},
+		{"Queue depth", fmt.Sprintf("%d / %d", snapshot.Queue.Depth, snapshot.Queue.Capacity)},
+		{"Running distributions", fmt.Sprintf("%d / %d", snapshot.Queue.Running, snapshot.Queue.Concurrency)},
+		{"Rejected (queue full)", strconv.FormatInt(snapshot.Queue.Rejected, 10)},
+		{"Queue wait p50/p95/p99", fmt.Sprintf("%.1fms / %.1fms / %.1fms", snapshot.Queue.Wait.P50, snapshot.Queue.Wait.P95, snapshot.Queue.Wait.P99)},
+	}
+	summary.AppendBulk(rows)
+	summary.Render()
+
+	rewardTypes := make(map[string]stats.Breakdown, len(snapshot.ByRewardType))
+	for k, v := range snapshot.ByRewardType {
+		rewardTypes[k] = v
+	}
+	renderBreakdown(out, "Reward type", rewardTypes)
+	renderBreakdown(out, "Source chain", chainBreakdowns(snapshot.BySourceChain))
+	renderBreakdown(out, "Target chain", chainBreakdowns(snapshot.ByTargetChain))
+	renderEarnings(out, snapshot.ByBeneficiary)
+}
+
+// renderEarnings writes the fee split totals of every beneficiary
+func renderEarnings(out io.Writer, earnings map[string]stats.Earnings) {
+	if len(earnings) == 0 {
+		return
+	}
+
+	table := tablewriter.NewWriter(out)
+	table.SetHeader([]string{"Beneficiary", "Fees (wei)", "MEV (wei)", "Total (wei)"})
+	for _, beneficiary := range fees.Beneficiaries {
+		e, ok := earnings[beneficiary]
+		if !ok {
+			continue
+		}
+		table.Append([]string{beneficiary, bigOrZero(e.Fees).String(), bigOrZero(e.MEV).String(), bigOrZero(e.Total).String()})
+	}
+	table.Render()
+}
+
+func chainBreakdowns(m map[uint64]stats.Breakdown) map[string]stats.Breakdown {
+	out := make(map[string]stats.Breakdown, len(m))
+	for chain, b := range m {
+		out[strconv.FormatUint(chain, 10)] = b
+	}
+	return out
+}
+
+// renderBreakdown writes one breakdown table, sorted by key
+func renderBreakdown(out io.Writer, title string, breakdowns map[string]stats.Breakdown) {
+	if len(breakdowns) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(breakdowns))
+	for k := range breakdowns {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	table := tablewriter.NewWriter(out)
+	table.SetHeader([]string{title, "Tasks", "Succeeded", "Failed", "Distributed (wei)"})
+	for _, k := range keys {
+		b := breakdowns[k]
+		table.Append([]string{
+			k,
+			strconv.FormatInt(b.Tasks, 10),
+			strconv.FormatInt(b.Succeeded, 10),
+			strconv.FormatInt(b.Failed, 10),
+			bigOrZero(b.Distributed).String(),
+		})
+	}
+	table.Render()
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+)
+
+func writeTaskFile(t *testing.T, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func marshalTask(t *testing.T, task interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(task)
+	if err != nil {
+		t.Fatalf("Failed to marshal task: %v", err)
+	}
+	return string(data)
+}
+
+func newCLITask() RewardDistributionTask {
+	return RewardDistributionTask{
+		User:            "0x1234567890123456789012345678901234567890",
+		Amount:          big.NewInt(1000000000000000000), // 1 ETH
+		ChainID:         1,
+		PoolID:          "0xabcdef1234567890abcdef1234567890abcdef12",
+		RewardType:      RewardTypeLiquidity,
+		Timestamp:       time.Now().Unix(),
+		HookAddress:     "0x9876543210987654321098765432109876543210",
+		TransactionHash: "0x1111111111111111111111111111111111111111111111111111111111111111",
+	}
+}
+
+func TestCLI_Validate(t *testing.T) {
+	valid := newCLITask()
+	invalid := newCLITask()
+	invalid.User = ""
+
+	abiPayload, err := EncodeTaskPayload(&valid, PayloadFormatABIExtended)
+	if err != nil {
+		t.Fatalf("EncodeTaskPayload failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		contents string
+		output   string
+		errorMsg string
+	}{
+		{name: "valid JSON", contents: marshalTask(t, valid), output: "payload is valid (json)"},
+		{name: "valid hex ABI", contents: fmt.Sprintf("0x%x\n", abiPayload), output: "payload is valid (abi_extended)"},
+		{name: "invalid task", contents: marshalTask(t, invalid), errorMsg: "invalid payload: user address is required"},
+		{name: "bad hex", contents: "0xzz", errorMsg: "invalid hex payload: encoding/hex: invalid byte: U+007A 'z'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			app := newApp()
+			app.Writer = &out
+			app.ExitErrHandler = func(_ *cli.Context, _ error) {}
+
+			err := app.Run([]string{"rewardflow-performer", "--log-level", "error", "validate", writeTaskFile(t, "payload", tt.contents)})
+			if tt.errorMsg != "" {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				} else if err.Error() != tt.errorMsg {
+					t.Errorf("Expected error message '%s', got '%s'", tt.errorMsg, err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !strings.Contains(out.String(), tt.output) {
+				t.Errorf("Expected output %q, got %q", tt.output, out.String())
+			}
+		})
+	}
+}
+
+func TestCLI_Simulate(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	mev := newCLITask()
+	mev.RewardType = RewardTypeMEV
+	stale := newCLITask()
+	stale.Timestamp = time.Now().Add(-48 * time.Hour).Unix()
+
+	input := strings.Join([]string{
+		marshalTask(t, newCLITask()),
+		"",
+		"# comments are skipped",
+		marshalTask(t, mev),
+		marshalTask(t, stale),
+		"not json",
+	}, "\n")
+
+	worker := NewRewardFlowTaskWorker(logger)
+	var out bytes.Buffer
+	if err := simulateTasks(worker, strings.NewReader(input), &out); err != nil {
+		t.Fatalf("simulateTasks failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected 4 outcomes, got %d:\n%s", len(lines), out.String())
+	}
+
+	expected := []struct {
+		line  int
+		valid bool
+		error string
+	}{
+		{line: 1, valid: true},
+		{line: 4, valid: true},
+		{line: 5, error: "task timestamp too old"},
+		{line: 6, error: "invalid task data format"},
+	}
+	for i, want := range expected {
+		var outcome simulationOutcome
+		if err := json.Unmarshal([]byte(lines[i]), &outcome); err != nil {
+			t.Fatalf("Failed to unmarshal outcome: %v", err)
+		}
+		if outcome.Line != want.line || outcome.Valid != want.valid || !strings.HasPrefix(outcome.Error, want.error) {
+			t.Errorf("Unexpected outcome %d: %+v", i, outcome)
+		}
+		if want.valid {
+			var result RewardDistributionResult
+			if err := json.Unmarshal(outcome.Result, &result); err != nil || !result.Success {
+				t.Errorf("Expected successful result on line %d, got %s (%v)", want.line, outcome.Result, err)
+			}
+		}
+	}
+
+	if processed := worker.GetStats().TotalTasksProcessed; processed != 2 {
+		t.Errorf("Expected 2 tasks processed, got %d", processed)
+	}
+}
+
+func TestCLI_Stats(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	worker := NewRewardFlowTaskWorker(logger)
+	if err := simulateTasks(worker, strings.NewReader(marshalTask(t, newCLITask())), &bytes.Buffer{}); err != nil {
+		t.Fatalf("simulateTasks failed: %v", err)
+	}
+
+	srv := httptest.NewServer(worker.statsHandler())
+	defer srv.Close()
+
+	var out bytes.Buffer
+	if err := runStats(context.Background(), srv.URL, &out); err != nil {
+		t.Fatalf("runStats failed: %v", err)
+	}
+
+	for _, want := range []string{"TASKS PROCESSED", "100.00%", "999000000000000000", "REWARD TYPE", "liquidity", "SOURCE CHAIN", "TARGET CHAIN", "BENEFICIARY", "850000000000000"} {
+		if !strings.Contains(strings.ToUpper(out.String()), strings.ToUpper(want)) {
+			t.Errorf("Expected stats table to contain %q:\n%s", want, out.String())
+		}
+	}
+
+	if err := runStats(context.Background(), "http://127.0.0.1:1", &out); err == nil {
+		t.Errorf("Expected error for unreachable performer")
+	}
+}
@@ -0,0 +1,552 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// PayloadFormat identifies the wire layout of a task payload
+type PayloadFormat int
+
+const (
+	// PayloadFormatUnknown is returned when the payload matches no known layout
+	PayloadFormatUnknown PayloadFormat = iota
+	// PayloadFormatJSON is the JSON encoding of RewardDistributionTask
+	PayloadFormatJSON
+	// PayloadFormatABI is the layout decoded by RewardFlowTaskHook.validatePreTaskCreation:
+	// abi.encode(address user, uint256 amount, uint256 chainId)
+	PayloadFormatABI
+	// PayloadFormatABIExtended extends the on-chain layout with the remaining task fields:
+	// abi.encode(address user, uint256 amount, uint256 chainId, bytes32 poolId,
+	//            uint8 rewardType, address hook, bytes32 txHash, uint256 timestamp)
+	PayloadFormatABIExtended
+	// PayloadFormatABIRewardParams appends the reward type specific parameters to the extended layout:
+	// abi.encode(..., uint256 timestamp, uint256 loyaltyScore, uint8 tierLevel)
+	PayloadFormatABIRewardParams
+	// PayloadFormatBatchJSON is the JSON encoding of BatchRewardDistributionTask
+	PayloadFormatBatchJSON
+	// PayloadFormatBatchABI mirrors RewardFlowHook.AVSTask without its status field:
+	// abi.encode(bytes32 poolId, address[] recipients, uint256[] amounts,
+	//            uint256 totalAmount, uint256 createdAt, uint256 targetChain, bytes32 taskHash)
+	PayloadFormatBatchABI
+)
+
+// String returns the human readable name of the payload format
+func (f PayloadFormat) String() string {
+	switch f {
+	case PayloadFormatJSON:
+		return "json"
+	case PayloadFormatABI:
+		return "abi"
+	case PayloadFormatABIExtended:
+		return "abi_extended"
+	case PayloadFormatABIRewardParams:
+		return "abi_reward_params"
+	case PayloadFormatBatchJSON:
+		return "batch_json"
+	case PayloadFormatBatchABI:
+		return "batch_abi"
+	default:
+		return "unknown"
+	}
+}
+
+// IsBatch reports whether the format carries a multi-recipient batch task
+func (f PayloadFormat) IsBatch() bool {
+	return f == PayloadFormatBatchJSON || f == PayloadFormatBatchABI
+}
+
+// defaultABIRewardType is assigned to tasks decoded from the basic on-chain layout,
+// which carries no reward type of its own
+const defaultABIRewardType = RewardTypeLiquidity
+
+var (
+	abiAddressType = mustNewABIType("address")
+	abiUint256Type = mustNewABIType("uint256")
+	abiUint8Type   = mustNewABIType("uint8")
+	abiBytes32Type = mustNewABIType("bytes32")
+	abiAddressArr  = mustNewABIType("address[]")
+	abiUint256Arr  = mustNewABIType("uint256[]")
+
+	// taskPayloadArgs mirrors abi.decode(data, (address, uint256, uint256)) in RewardFlowTaskHook
+	taskPayloadArgs = abi.Arguments{
+		{Name: "user", Type: abiAddressType},
+		{Name: "amount", Type: abiUint256Type},
+		{Name: "chainId", Type: abiUint256Type},
+	}
+
+	// extendedTaskPayloadArgs appends the fields needed to fully describe a reward task
+	extendedTaskPayloadArgs = append(append(abi.Arguments{}, taskPayloadArgs...),
+		abi.Argument{Name: "poolId", Type: abiBytes32Type},
+		abi.Argument{Name: "rewardType", Type: abiUint8Type},
+		abi.Argument{Name: "hook", Type: abiAddressType},
+		abi.Argument{Name: "txHash", Type: abiBytes32Type},
+		abi.Argument{Name: "timestamp", Type: abiUint256Type},
+	)
+
+	// rewardParamsTaskPayloadArgs appends loyalty and tier parameters to the extended layout
+	rewardParamsTaskPayloadArgs = append(append(abi.Arguments{}, extendedTaskPayloadArgs...),
+		abi.Argument{Name: "loyaltyScore", Type: abiUint256Type},
+		abi.Argument{Name: "tierLevel", Type: abiUint8Type},
+	)
+
+	// batchTaskPayloadArgs mirrors the fields of RewardFlowHook.AVSTask
+	batchTaskPayloadArgs = abi.Arguments{
+		{Name: "poolId", Type: abiBytes32Type},
+		{Name: "recipients", Type: abiAddressArr},
+		{Name: "amounts", Type: abiUint256Arr},
+		{Name: "totalAmount", Type: abiUint256Type},
+		{Name: "createdAt", Type: abiUint256Type},
+		{Name: "targetChain", Type: abiUint256Type},
+		{Name: "taskHash", Type: abiBytes32Type},
+	}
+)
+
+func mustNewABIType(t string) abi.Type {
+	typ, err := abi.NewType(t, "", nil)
+	if err != nil {
+		panic(fmt.Errorf("failed to create ABI type %s: %w", t, err))
+	}
+	return typ
+}
+
+// abiWordSize is the size in bytes of a single ABI-encoded static value
+const abiWordSize = 32
+
+// minBatchPayloadSize is the size of a batch payload with empty arrays:
+// the tuple head plus the length word of each array
+var minBatchPayloadSize = (len(batchTaskPayloadArgs) + 2) * abiWordSize
+
+// DetectPayloadFormat determines the wire layout of a task payload without decoding it.
+// The single-task ABI layouts are fully static tuples, so they are identified by their
+// exact size; a batch payload is only recognised when its array offsets and lengths
+// account for every word of it.
+func DetectPayloadFormat(payload []byte) PayloadFormat {
+	trimmed := bytes.TrimSpace(payload)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(trimmed, &fields); err == nil {
+			if _, ok := fields["recipients"]; ok {
+				return PayloadFormatBatchJSON
+			}
+		}
+		return PayloadFormatJSON
+	}
+
+	switch {
+	case len(payload) == len(taskPayloadArgs)*abiWordSize:
+		return PayloadFormatABI
+	case len(payload) == len(extendedTaskPayloadArgs)*abiWordSize:
+		return PayloadFormatABIExtended
+	case len(payload) == len(rewardParamsTaskPayloadArgs)*abiWordSize:
+		return PayloadFormatABIRewardParams
+	case isBatchABIPayload(payload):
+		return PayloadFormatBatchABI
+	default:
+		return PayloadFormatUnknown
+	}
+}
+
+// isBatchABIPayload reports whether payload has the layout abi.encode gives a batch:
+// the recipients array right after the tuple head, the amounts array right after
+// the recipients, and nothing after the amounts
+func isBatchABIPayload(payload []byte) bool {
+	if len(payload) < minBatchPayloadSize || len(payload)%abiWordSize != 0 {
+		return false
+	}
+	words := len(payload) / abiWordSize
+	// word reads a word as an offset or length, which never exceeds the payload
+	word := func(i int) (int, bool) {
+		v := new(big.Int).SetBytes(payload[i*abiWordSize : (i+1)*abiWordSize])
+		if !v.IsUint64() || v.Uint64() > uint64(len(payload)) {
+			return 0, false
+		}
+		return int(v.Uint64()), true
+	}
+
+	head := len(batchTaskPayloadArgs)
+	recipientsAt, ok := word(1)
+	if !ok || recipientsAt != head*abiWordSize {
+		return false
+	}
+	recipients, ok := word(head)
+	if !ok {
+		return false
+	}
+	amountsAt, ok := word(2)
+	if !ok || amountsAt != (head+1+recipients)*abiWordSize || amountsAt/abiWordSize >= words {
+		return false
+	}
+	amounts, ok := word(amountsAt / abiWordSize)
+	return ok && words == amountsAt/abiWordSize+1+amounts
+}
+
+// DecodeTaskPayload decodes a task payload in any supported format.
+// Tasks decoded from PayloadFormatABI carry no timestamp; callers decide how to stamp them.
+func DecodeTaskPayload(payload []byte) (*RewardDistributionTask, PayloadFormat, error) {
+	if len(payload) == 0 {
+		return nil, PayloadFormatUnknown, fmt.Errorf("empty task payload")
+	}
+
+	format := DetectPayloadFormat(payload)
+	var (
+		task *RewardDistributionTask
+		err  error
+	)
+	switch format {
+	case PayloadFormatJSON:
+		task = &RewardDistributionTask{}
+		err = json.Unmarshal(payload, task)
+	case PayloadFormatABI:
+		task, err = decodeABITaskPayload(payload)
+	case PayloadFormatABIExtended, PayloadFormatABIRewardParams:
+		task, err = decodeExtendedABITaskPayload(payload, format)
+	case PayloadFormatBatchJSON, PayloadFormatBatchABI:
+		return nil, format, fmt.Errorf("payload is a batch task (%s)", format)
+	default:
+		return nil, format, fmt.Errorf("unrecognized task payload layout (%d bytes)", len(payload))
+	}
+	if err != nil {
+		return nil, format, err
+	}
+
+	return task, format, nil
+}
+
+// EncodeTaskPayload encodes a task in the requested format
+func EncodeTaskPayload(task *RewardDistributionTask, format PayloadFormat) ([]byte, error) {
+	if task == nil {
+		return nil, fmt.Errorf("task is nil")
+	}
+
+	switch format {
+	case PayloadFormatJSON:
+		return json.Marshal(task)
+	case PayloadFormatABI, PayloadFormatABIExtended, PayloadFormatABIRewardParams:
+	default:
+		return nil, fmt.Errorf("unsupported payload format: %s", format)
+	}
+
+	user, err := parseAddress("user", task.User)
+	if err != nil {
+		return nil, err
+	}
+	amount := task.Amount
+	if amount == nil {
+		amount = new(big.Int)
+	}
+	chainID := new(big.Int).SetUint64(task.ChainID)
+
+	if format == PayloadFormatABI {
+		return taskPayloadArgs.Pack(user, amount, chainID)
+	}
+
+	poolID, err := parseBytes32("pool id", task.PoolID)
+	if err != nil {
+		return nil, err
+	}
+	rewardType, err := task.RewardType.Enum()
+	if err != nil {
+		return nil, err
+	}
+	hook, err := parseAddress("hook address", task.HookAddress)
+	if err != nil {
+		return nil, err
+	}
+	txHash, err := parseBytes32("transaction hash", task.TransactionHash)
+	if err != nil {
+		return nil, err
+	}
+	if task.Timestamp < 0 {
+		return nil, fmt.Errorf("invalid timestamp: %d", task.Timestamp)
+	}
+	timestamp := big.NewInt(task.Timestamp)
+
+	if format == PayloadFormatABIExtended {
+		return extendedTaskPayloadArgs.Pack(user, amount, chainID, poolID, rewardType, hook, txHash, timestamp)
+	}
+
+	var tierLevel uint8
+	if task.TierLevel != nil {
+		tierLevel = *task.TierLevel
+	}
+	loyaltyScore := new(big.Int).SetUint64(task.LoyaltyScore)
+
+	return rewardParamsTaskPayloadArgs.Pack(user, amount, chainID, poolID, rewardType, hook, txHash, timestamp,
+		loyaltyScore, tierLevel)
+}
+
+// DecodeBatchTaskPayload decodes a multi-recipient batch task payload
+func DecodeBatchTaskPayload(payload []byte) (*BatchRewardDistributionTask, PayloadFormat, error) {
+	if len(payload) == 0 {
+		return nil, PayloadFormatUnknown, fmt.Errorf("empty task payload")
+	}
+
+	format := DetectPayloadFormat(payload)
+	switch format {
+	case PayloadFormatBatchJSON:
+		var task BatchRewardDistributionTask
+		if err := json.Unmarshal(payload, &task); err != nil {
+			return nil, format, err
+		}
+		return &task, format, nil
+	case PayloadFormatBatchABI:
+		task, err := decodeBatchABITaskPayload(payload)
+		if err != nil {
+			return nil, format, err
+		}
+		return task, format, nil
+	default:
+		return nil, format, fmt.Errorf("payload is not a batch task (%s)", format)
+	}
+}
+
+// EncodeBatchTaskPayload encodes a batch task in the requested format
+func EncodeBatchTaskPayload(task *BatchRewardDistributionTask, format PayloadFormat) ([]byte, error) {
+	if task == nil {
+		return nil, fmt.Errorf("task is nil")
+	}
+
+	switch format {
+	case PayloadFormatBatchJSON:
+		return json.Marshal(task)
+	case PayloadFormatBatchABI:
+	default:
+		return nil, fmt.Errorf("unsupported batch payload format: %s", format)
+	}
+
+	poolID, err := parseBytes32("pool id", task.PoolID)
+	if err != nil {
+		return nil, err
+	}
+	recipients := make([]common.Address, len(task.Recipients))
+	for i, r := range task.Recipients {
+		if recipients[i], err = parseAddress(fmt.Sprintf("recipient %d", i), r); err != nil {
+			return nil, err
+		}
+	}
+	amounts := make([]*big.Int, len(task.Amounts))
+	for i, a := range task.Amounts {
+		if a == nil {
+			return nil, fmt.Errorf("amount %d is nil", i)
+		}
+		amounts[i] = a
+	}
+	totalAmount := task.TotalAmount
+	if totalAmount == nil {
+		totalAmount = new(big.Int)
+	}
+	if task.Timestamp < 0 {
+		return nil, fmt.Errorf("invalid timestamp: %d", task.Timestamp)
+	}
+	taskHash, err := parseBytes32("task hash", task.TaskHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return batchTaskPayloadArgs.Pack(
+		poolID,
+		recipients,
+		amounts,
+		totalAmount,
+		big.NewInt(task.Timestamp),
+		new(big.Int).SetUint64(task.TargetChain),
+		taskHash,
+	)
+}
+
+// decodeABITaskPayload decodes the basic (address, uint256, uint256) layout
+func decodeABITaskPayload(payload []byte) (*RewardDistributionTask, error) {
+	values, err := taskPayloadArgs.Unpack(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode ABI task payload: %w", err)
+	}
+	if err := checkAddressPadding(payload, 0); err != nil {
+		return nil, err
+	}
+
+	chainID, err := uint64FromBig("chain ID", values[2].(*big.Int))
+	if err != nil {
+		return nil, err
+	}
+
+	return &RewardDistributionTask{
+		User:       values[0].(common.Address).Hex(),
+		Amount:     values[1].(*big.Int),
+		ChainID:    chainID,
+		RewardType: defaultABIRewardType,
+	}, nil
+}
+
+// decodeExtendedABITaskPayload decodes the extended reward task layouts
+func decodeExtendedABITaskPayload(payload []byte, format PayloadFormat) (*RewardDistributionTask, error) {
+	args := extendedTaskPayloadArgs
+	if format == PayloadFormatABIRewardParams {
+		args = rewardParamsTaskPayloadArgs
+	}
+	values, err := args.Unpack(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode extended ABI task payload: %w", err)
+	}
+	if err := checkAddressPadding(payload, 0); err != nil {
+		return nil, err
+	}
+	if err := checkAddressPadding(payload, 5); err != nil {
+		return nil, err
+	}
+	if err := checkUint8Padding(payload, 4); err != nil {
+		return nil, err
+	}
+	if format == PayloadFormatABIRewardParams {
+		if err := checkUint8Padding(payload, 9); err != nil {
+			return nil, err
+		}
+	}
+
+	chainID, err := uint64FromBig("chain ID", values[2].(*big.Int))
+	if err != nil {
+		return nil, err
+	}
+	rewardType, err := RewardTypeFromEnum(values[4].(uint8))
+	if err != nil {
+		return nil, err
+	}
+	timestamp := values[7].(*big.Int)
+	if !timestamp.IsInt64() {
+		return nil, fmt.Errorf("timestamp out of range: %s", timestamp.String())
+	}
+
+	poolID := values[3].([32]byte)
+	txHash := values[6].([32]byte)
+
+	task := &RewardDistributionTask{
+		User:            values[0].(common.Address).Hex(),
+		Amount:          values[1].(*big.Int),
+		ChainID:         chainID,
+		PoolID:          common.Hash(poolID).Hex(),
+		RewardType:      rewardType,
+		Timestamp:       timestamp.Int64(),
+		HookAddress:     values[5].(common.Address).Hex(),
+		TransactionHash: common.Hash(txHash).Hex(),
+	}
+
+	if format == PayloadFormatABIRewardParams {
+		if task.LoyaltyScore, err = uint64FromBig("loyalty score", values[8].(*big.Int)); err != nil {
+			return nil, err
+		}
+		// BRONZE is ordinal zero, so the tier level is only meaningful for tier rewards
+		if rewardType == RewardTypeTier {
+			tierLevel := values[9].(uint8)
+			task.TierLevel = &tierLevel
+		}
+	}
+
+	return task, nil
+}
+
+// decodeBatchABITaskPayload decodes the AVSTask-shaped batch layout
+func decodeBatchABITaskPayload(payload []byte) (*BatchRewardDistributionTask, error) {
+	values, err := batchTaskPayloadArgs.Unpack(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode batch ABI task payload: %w", err)
+	}
+
+	createdAt := values[4].(*big.Int)
+	if !createdAt.IsInt64() {
+		return nil, fmt.Errorf("timestamp out of range: %s", createdAt.String())
+	}
+	targetChain, err := uint64FromBig("target chain", values[5].(*big.Int))
+	if err != nil {
+		return nil, err
+	}
+
+	// The recipient words follow the length word of the array, right after the tuple head
+	addresses := values[1].([]common.Address)
+	recipients := make([]string, len(addresses))
+	for i, a := range addresses {
+		if err := checkAddressPadding(payload, len(batchTaskPayloadArgs)+1+i); err != nil {
+			return nil, err
+		}
+		recipients[i] = a.Hex()
+	}
+
+	poolID := values[0].([32]byte)
+	taskHash := values[6].([32]byte)
+
+	return &BatchRewardDistributionTask{
+		PoolID:      common.Hash(poolID).Hex(),
+		Recipients:  recipients,
+		Amounts:     values[2].([]*big.Int),
+		TotalAmount: values[3].(*big.Int),
+		TargetChain: targetChain,
+		RewardType:  defaultABIRewardType,
+		Timestamp:   createdAt.Int64(),
+		TaskHash:    common.Hash(taskHash).Hex(),
+	}, nil
+}
+
+// checkAddressPadding rejects address words with dirty upper bytes, matching abi.decode
+func checkAddressPadding(payload []byte, word int) error {
+	if !isPadded(payload, word, common.AddressLength) {
+		return fmt.Errorf("invalid address encoding in word %d", word)
+	}
+	return nil
+}
+
+// checkUint8Padding rejects uint8 words with dirty upper bytes, matching abi.decode
+func checkUint8Padding(payload []byte, word int) error {
+	if !isPadded(payload, word, 1) {
+		return fmt.Errorf("invalid uint8 encoding in word %d", word)
+	}
+	return nil
+}
+
+// isPadded reports whether all but the low size bytes of a word are zero
+func isPadded(payload []byte, word, size int) bool {
+	start := word * abiWordSize
+	for _, b := range payload[start : start+abiWordSize-size] {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func uint64FromBig(field string, v *big.Int) (uint64, error) {
+	if !v.IsUint64() {
+		return 0, fmt.Errorf("%s out of range: %s", field, v.String())
+	}
+	return v.Uint64(), nil
+}
+
+func parseAddress(field, s string) (common.Address, error) {
+	if !common.IsHexAddress(s) {
+		return common.Address{}, fmt.Errorf("invalid %s: %q", field, s)
+	}
+	return common.HexToAddress(s), nil
+}
+
+func parseBytes32(field, s string) ([32]byte, error) {
+	if s == "" {
+		return [32]byte{}, nil
+	}
+	raw := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if len(raw) > 2*common.HashLength {
+		return [32]byte{}, fmt.Errorf("invalid %s: %q exceeds 32 bytes", field, s)
+	}
+	if len(raw)%2 == 1 {
+		raw = "0" + raw
+	}
+	b, err := hex.DecodeString(raw)
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("invalid %s: %w", field, err)
+	}
+	return common.BytesToHash(b), nil
+}
@@ -0,0 +1,385 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"go.uber.org/zap"
+)
+
+// abiWords builds an ABI payload from hex words, left-padding each one to 32 bytes
+func abiWords(t *testing.T, words ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	for _, w := range words {
+		w = strings.Repeat("0", 64-len(w)) + w
+		b, err := hex.DecodeString(w)
+		if err != nil {
+			t.Fatalf("Invalid hex word %q: %v", w, err)
+		}
+		buf.Write(b)
+	}
+	return buf.Bytes()
+}
+
+// payloadVectorsPath is shared with test/unit/TaskPayloadVectors.t.sol, which
+// checks the same payloads against abi.encode and makeAddr
+var payloadVectorsPath = filepath.Join("..", "..", "test", "vectors", "payloads.json")
+
+// solidityUser1 is makeAddr("user1"), the user of the payload vectors
+const solidityUser1 = "0x29E3b139f4393aDda86303fcdAa35F60Bb7092bF"
+
+type payloadVector struct {
+	Name        string `json:"name"`
+	User        string `json:"user"`
+	UserAddress string `json:"userAddress"`
+	Amount      string `json:"amount"`
+	ChainID     uint64 `json:"chainId"`
+	PoolID      string `json:"poolId"`
+	RewardType  uint8  `json:"rewardType"`
+	Hook        string `json:"hook"`
+	TxHash      string `json:"txHash"`
+	Timestamp   int64  `json:"timestamp"`
+	Encoded     string `json:"encoded"`
+}
+
+type payloadVectors struct {
+	Basic    []payloadVector `json:"basic"`
+	Extended []payloadVector `json:"extended"`
+}
+
+func loadPayloadVectors(t *testing.T) payloadVectors {
+	t.Helper()
+	data, err := os.ReadFile(payloadVectorsPath)
+	if err != nil {
+		t.Fatalf("Failed to read payload vectors: %v", err)
+	}
+	var vectors payloadVectors
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		t.Fatalf("Failed to parse payload vectors: %v", err)
+	}
+	if len(vectors.Basic) == 0 || len(vectors.Extended) == 0 {
+		t.Fatalf("Payload vectors are missing a section")
+	}
+	return vectors
+}
+
+func TestDecodeTaskPayload_SolidityVectors(t *testing.T) {
+	vectors := loadPayloadVectors(t)
+
+	type vectorCase struct {
+		vector   payloadVector
+		format   PayloadFormat
+		expected RewardDistributionTask
+	}
+	var tests []vectorCase
+	for _, v := range vectors.Basic {
+		tests = append(tests, vectorCase{
+			vector: v,
+			format: PayloadFormatABI,
+			expected: RewardDistributionTask{
+				User:       v.UserAddress,
+				ChainID:    v.ChainID,
+				RewardType: defaultABIRewardType,
+			},
+		})
+	}
+	for _, v := range vectors.Extended {
+		rewardType, err := RewardTypeFromEnum(v.RewardType)
+		if err != nil {
+			t.Fatalf("Invalid reward type in vector %q: %v", v.Name, err)
+		}
+		tests = append(tests, vectorCase{
+			vector: v,
+			format: PayloadFormatABIExtended,
+			expected: RewardDistributionTask{
+				User:            v.UserAddress,
+				ChainID:         v.ChainID,
+				PoolID:          v.PoolID,
+				RewardType:      rewardType,
+				Timestamp:       v.Timestamp,
+				HookAddress:     v.Hook,
+				TransactionHash: v.TxHash,
+			},
+		})
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.vector.Name, func(t *testing.T) {
+			amount, ok := new(big.Int).SetString(tt.vector.Amount, 10)
+			if !ok {
+				t.Fatalf("Invalid amount %q", tt.vector.Amount)
+			}
+			tt.expected.Amount = amount
+			payload, err := hex.DecodeString(strings.TrimPrefix(tt.vector.Encoded, "0x"))
+			if err != nil {
+				t.Fatalf("Invalid encoded payload: %v", err)
+			}
+
+			task, format, err := DecodeTaskPayload(payload)
+			if err != nil {
+				t.Fatalf("DecodeTaskPayload failed: %v", err)
+			}
+			if format != tt.format {
+				t.Errorf("Expected format %s, got %s", tt.format, format)
+			}
+			assertTaskEqual(t, &tt.expected, task)
+
+			// Re-encoding must reproduce the Solidity bytes exactly
+			encoded, err := EncodeTaskPayload(task, format)
+			if err != nil {
+				t.Fatalf("EncodeTaskPayload failed: %v", err)
+			}
+			if !bytes.Equal(encoded, payload) {
+				t.Errorf("Round trip mismatch:\nexpected %x\ngot      %x", payload, encoded)
+			}
+		})
+	}
+}
+
+func TestDecodeTaskPayload_JSONRoundTrip(t *testing.T) {
+	task := &RewardDistributionTask{
+		User:            "0x1234567890123456789012345678901234567890",
+		Amount:          big.NewInt(1000000000000000000),
+		ChainID:         10,
+		PoolID:          "0xabcdef1234567890abcdef1234567890abcdef12",
+		RewardType:      "swap",
+		Timestamp:       1700000000,
+		HookAddress:     "0x9876543210987654321098765432109876543210",
+		TransactionHash: "0x2222222222222222222222222222222222222222222222222222222222222222",
+	}
+
+	payload, err := EncodeTaskPayload(task, PayloadFormatJSON)
+	if err != nil {
+		t.Fatalf("EncodeTaskPayload failed: %v", err)
+	}
+
+	decoded, format, err := DecodeTaskPayload(payload)
+	if err != nil {
+		t.Fatalf("DecodeTaskPayload failed: %v", err)
+	}
+	if format != PayloadFormatJSON {
+		t.Errorf("Expected format %s, got %s", PayloadFormatJSON, format)
+	}
+	assertTaskEqual(t, task, decoded)
+}
+
+func TestDecodeTaskPayload_Errors(t *testing.T) {
+	validWords := []string{
+		"29e3b139f4393adda86303fcdaa35f60bb7092bf",
+		"0de0b6b3a7640000",
+		"01",
+	}
+
+	tests := []struct {
+		name    string
+		payload []byte
+	}{
+		{
+			name:    "empty payload",
+			payload: nil,
+		},
+		{
+			name:    "truncated ABI payload",
+			payload: abiWords(t, validWords...)[:95],
+		},
+		{
+			name:    "dirty address padding",
+			payload: abiWords(t, "ff"+strings.Repeat("0", 22)+validWords[0], validWords[1], validWords[2]),
+		},
+		{
+			name:    "chain ID overflows uint64",
+			payload: abiWords(t, validWords[0], validWords[1], "010000000000000000"),
+		},
+		{
+			name: "unknown reward type enum",
+			payload: abiWords(t, validWords[0], validWords[1], validWords[2],
+				"", "07", "", "", "6553f100"),
+		},
+		{
+			name: "reward type exceeds uint8",
+			payload: abiWords(t, validWords[0], validWords[1], validWords[2],
+				"", "0100", "", "", "6553f100"),
+		},
+		{
+			name: "dirty tier level padding",
+			payload: abiWords(t, validWords[0], validWords[1], validWords[2],
+				"", "03", "", "", "6553f100", "", "ff"+strings.Repeat("0", 62)),
+		},
+		{
+			name:    "malformed JSON",
+			payload: []byte(`{"user": `),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := DecodeTaskPayload(tt.payload); err == nil {
+				t.Errorf("Expected error but got none")
+			}
+		})
+	}
+}
+
+func TestDetectPayloadFormat_BatchLayout(t *testing.T) {
+	task := newBatchTask()
+	batch, err := EncodeBatchTaskPayload(&task, PayloadFormatBatchABI)
+	if err != nil {
+		t.Fatalf("EncodeBatchTaskPayload failed: %v", err)
+	}
+	single := []string{"29e3b139f4393adda86303fcdaa35f60bb7092bf", "0de0b6b3a7640000", "01"}
+	withWord := func(payload []byte, word int, value string) []byte {
+		changed := bytes.Clone(payload)
+		copy(changed[word*abiWordSize:], abiWords(t, value))
+		return changed
+	}
+
+	tests := []struct {
+		name    string
+		payload []byte
+		format  PayloadFormat
+	}{
+		{
+			name:    "batch",
+			payload: batch,
+			format:  PayloadFormatBatchABI,
+		},
+		{
+			name:    "11-word single payload",
+			payload: abiWords(t, append(single, "", "01", "", "", "6553f100", "", "", "")...),
+			format:  PayloadFormatUnknown,
+		},
+		{
+			name:    "12-word single payload",
+			payload: abiWords(t, append(single, "", "01", "", "", "6553f100", "", "", "", "")...),
+			format:  PayloadFormatUnknown,
+		},
+		{
+			name:    "recipients offset past the head",
+			payload: withWord(batch, 1, "0100"),
+			format:  PayloadFormatUnknown,
+		},
+		{
+			name:    "amounts offset inside the recipients",
+			payload: withWord(batch, 2, "0120"),
+			format:  PayloadFormatUnknown,
+		},
+		{
+			name:    "recipients length past the payload",
+			payload: withWord(batch, 7, "ffffffffffffffffffff"),
+			format:  PayloadFormatUnknown,
+		},
+		{
+			name:    "trailing word",
+			payload: append(bytes.Clone(batch), abiWords(t, "")...),
+			format:  PayloadFormatUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if format := DetectPayloadFormat(tt.payload); format != tt.format {
+				t.Fatalf("Expected format %s, got %s", tt.format, format)
+			}
+			if tt.format != PayloadFormatUnknown {
+				return
+			}
+			_, _, err := DecodeTaskPayload(tt.payload)
+			if err == nil || !strings.HasPrefix(err.Error(), "unrecognized task payload layout") {
+				t.Errorf("Expected error message 'unrecognized task payload layout', got '%v'", err)
+			}
+		})
+	}
+
+	// Recipient words are addresses, whose upper bytes must be zero
+	dirty := withWord(batch, len(batchTaskPayloadArgs)+2, "ff"+strings.Repeat("0", 22)+strings.TrimPrefix(strings.ToLower(task.Recipients[1]), "0x"))
+	if _, _, err := DecodeBatchTaskPayload(dirty); err == nil || err.Error() != "invalid address encoding in word 9" {
+		t.Errorf("Expected error message 'invalid address encoding in word 9', got '%v'", err)
+	}
+}
+
+func TestRewardFlowTaskWorker_ABIPayload(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	worker := NewRewardFlowTaskWorker(logger)
+
+	// abi.encode(user1, 1 ether, 1) as submitted through the TaskMailbox
+	taskRequest := &performerV1.TaskRequest{
+		TaskId:  []byte("test-task-id-abi"),
+		Payload: abiWords(t, "29e3b139f4393adda86303fcdaa35f60bb7092bf", "0de0b6b3a7640000", "01"),
+	}
+
+	if err := worker.ValidateTask(taskRequest); err != nil {
+		t.Fatalf("ValidateTask failed for on-chain payload: %v", err)
+	}
+
+	response, err := worker.HandleTask(taskRequest)
+	if err != nil {
+		t.Fatalf("HandleTask failed: %v", err)
+	}
+
+	var result RewardDistributionResult
+	if err := json.Unmarshal(response.Result, &result); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+	if !result.Success {
+		t.Errorf("Expected successful processing, but got error: %s", result.Error)
+	}
+
+	// Extended payloads with a stale timestamp are still rejected
+	stale := &RewardDistributionTask{
+		User:        solidityUser1,
+		Amount:      big.NewInt(1e18),
+		ChainID:     1,
+		RewardType:  "liquidity",
+		Timestamp:   time.Now().Unix() - 25*60*60,
+		HookAddress: "0x9876543210987654321098765432109876543210",
+	}
+	payload, err := EncodeTaskPayload(stale, PayloadFormatABIExtended)
+	if err != nil {
+		t.Fatalf("EncodeTaskPayload failed: %v", err)
+	}
+	err = worker.ValidateTask(&performerV1.TaskRequest{TaskId: []byte("test-task-id-abi-stale"), Payload: payload})
+	if err == nil || err.Error() != "task timestamp too old" {
+		t.Errorf("Expected 'task timestamp too old', got %v", err)
+	}
+}
+
+func assertTaskEqual(t *testing.T, expected, actual *RewardDistributionTask) {
+	t.Helper()
+	if actual.User != expected.User {
+		t.Errorf("Expected user %s, got %s", expected.User, actual.User)
+	}
+	if actual.Amount == nil || actual.Amount.Cmp(expected.Amount) != 0 {
+		t.Errorf("Expected amount %v, got %v", expected.Amount, actual.Amount)
+	}
+	if actual.ChainID != expected.ChainID {
+		t.Errorf("Expected chain ID %d, got %d", expected.ChainID, actual.ChainID)
+	}
+	if actual.PoolID != expected.PoolID {
+		t.Errorf("Expected pool ID %s, got %s", expected.PoolID, actual.PoolID)
+	}
+	if actual.RewardType != expected.RewardType {
+		t.Errorf("Expected reward type %s, got %s", expected.RewardType, actual.RewardType)
+	}
+	if actual.Timestamp != expected.Timestamp {
+		t.Errorf("Expected timestamp %d, got %d", expected.Timestamp, actual.Timestamp)
+	}
+	if actual.HookAddress != expected.HookAddress {
+		t.Errorf("Expected hook address %s, got %s", expected.HookAddress, actual.HookAddress)
+	}
+	if actual.TransactionHash != expected.TransactionHash {
+		t.Errorf("Expected transaction hash %s, got %s", expected.TransactionHash, actual.TransactionHash)
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/config"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/engagement"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/fees"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/gas"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/policy"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/scheduler"
+)
+
+// WithConfig applies a validated operator configuration, failing when part of it cannot be built
+func WithConfig(cfg *config.Config) (WorkerOption, error) {
+	registry, err := newChainRegistry(cfg.Chains)
+	if err != nil {
+		return nil, fmt.Errorf("invalid chains: %w", err)
+	}
+	engine, err := fees.New(cfg.Rewards.FeeModel, registry, cfg.Rewards.FeeBps, cfg.Rewards.ProtocolFeeShareBps)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fee model: %w", err)
+	}
+	splitter, err := fees.NewSplitter(splitShares(cfg.Rewards.Split))
+	if err != nil {
+		return nil, fmt.Errorf("invalid fee split: %w", err)
+	}
+	s, err := scheduler.New(schedulerConfig(cfg.Scheduler))
+	if err != nil {
+		return nil, fmt.Errorf("invalid scheduler: %w", err)
+	}
+	encoding, err := parseResultEncoding(cfg.Server.ResultEncoding)
+	if err != nil {
+		return nil, err
+	}
+	static, err := policy.NewStatic(staticLimits(cfg.Rewards))
+	if err != nil {
+		return nil, fmt.Errorf("invalid reward limits: %w", err)
+	}
+
+	return func(rf *RewardFlowTaskWorker) {
+		rf.rewards = cfg.Rewards
+		rf.fallbackChain = cfg.Routing.FallbackChain
+		rf.chains = registry
+		rf.fees = engine
+		rf.splitter = splitter
+		rf.scheduler = s
+		rf.resultEncoding = encoding
+		rf.policy = static
+		rf.mode = ExecutionMode(cfg.Execution.Mode)
+		rf.gasPolicy = gasPolicy(cfg.Gas)
+		rf.gasPrices = nil
+		if cfg.Gas.Source == config.GasSourceStatic && cfg.Gas.StaticPrice != nil {
+			rf.gasPrices = gas.NewStatic(&cfg.Gas.StaticPrice.Int)
+		}
+		rf.engagementConfig = cfg.Engagement
+		rf.engagement = nil
+		if cfg.Engagement.Source == config.EngagementSourceTasks {
+			rf.engagement = engagement.NewTracker()
+		}
+	}, nil
+}
+
+// WithFeeEngine sets how distribution fees are calculated
+func WithFeeEngine(engine fees.Engine) WorkerOption {
+	return func(rf *RewardFlowTaskWorker) {
+		rf.fees = engine
+	}
+}
+
+// WithFeeSplitter sets how collected fees and MEV are divided between beneficiaries
+func WithFeeSplitter(splitter *fees.Splitter) WorkerOption {
+	return func(rf *RewardFlowTaskWorker) {
+		rf.splitter = splitter
+	}
+}
+
+// splitShares converts the configured fee split into splitter shares
+func splitShares(split config.SplitConfig) fees.Shares {
+	return fees.Shares{
+		LP:              split.LPBps,
+		Operator:        split.OperatorBps,
+		Protocol:        split.ProtocolBps,
+		GasCompensation: split.GasCompensationBps,
+		Remainder:       split.Remainder,
+	}
+}
+
+// WithValidationPolicy sets the source of the reward limits tasks are validated against
+func WithValidationPolicy(p policy.ValidationPolicy) WorkerOption {
+	return func(rf *RewardFlowTaskWorker) {
+		rf.policy = p
+	}
+}
+
+// newValidationPolicy builds the configured validation policy. The registrar
+// policy is read once here so that an unreachable registrar fails startup; the
+// returned function releases its RPC client.
+func newValidationPolicy(ctx context.Context, cfg *config.Config) (policy.ValidationPolicy, func(), error) {
+	if cfg.ValidationPolicy.Source != config.PolicySourceRegistrar {
+		static, err := policy.NewStatic(staticLimits(cfg.Rewards))
+		if err != nil {
+			return nil, nil, err
+		}
+		return static, func() {}, nil
+	}
+
+	client, err := ethclient.DialContext(ctx, cfg.EigenLayer.L1RPC)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to %s: %w", cfg.EigenLayer.L1RPC, err)
+	}
+	registrar, err := policy.NewRegistrar(ctx, client, common.HexToAddress(cfg.ValidationPolicy.RegistrarAddress))
+	if err != nil {
+		client.Close()
+		return nil, nil, fmt.Errorf("failed to load registrar validation policy: %w", err)
+	}
+	return registrar, client.Close, nil
+}
+
+// staticLimits converts the configured reward limits into policy limits
+func staticLimits(rewards config.RewardsConfig) policy.Limits {
+	var limits policy.Limits
+	if rewards.MinAmount != nil {
+		limits.MinRewardAmount = &rewards.MinAmount.Int
+	}
+	if rewards.MaxAmount != nil {
+		limits.MaxRewardAmount = &rewards.MaxAmount.Int
+	}
+	if rewards.TaskFee != nil {
+		limits.TaskFee = &rewards.TaskFee.Int
+	}
+	return limits
+}
+
+// taskTooOld reports whether a task timestamp is older than the configured maximum age
+func (rf *RewardFlowTaskWorker) taskTooOld(timestamp int64) bool {
+	return time.Since(time.Unix(timestamp, 0)) > rf.rewards.MaxTaskAge
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"go.uber.org/zap"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/config"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/policy"
+)
+
+// withConfig returns the worker option of a configuration the test expects to be valid
+func withConfig(t *testing.T, cfg *config.Config) WorkerOption {
+	t.Helper()
+	opt, err := WithConfig(cfg)
+	if err != nil {
+		t.Fatalf("WithConfig failed: %v", err)
+	}
+	return opt
+}
+
+func TestRewardFlowTaskWorker_WithConfig(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	cfg := config.Default()
+	cfg.Rewards.MinAmount = config.NewAmount(big.NewInt(2000000000000000000)) // 2 ETH
+	cfg.Rewards.FeeBps = 50
+	cfg.Rewards.MaxTaskAge = time.Hour
+	cfg.Chains = []config.ChainConfig{{ChainID: 1, Name: "ethereum"}, {ChainID: 8453, Name: "base"}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Invalid test config: %v", err)
+	}
+
+	worker := NewRewardFlowTaskWorker(logger, withConfig(t, cfg))
+
+	tests := []struct {
+		name     string
+		mutate   func(task *RewardDistributionTask)
+		errorMsg string
+	}{
+		{
+			name:     "amount below configured minimum",
+			mutate:   func(task *RewardDistributionTask) {},
+			errorMsg: "reward amount below minimum threshold",
+		},
+		{
+			name: "timestamp older than configured age",
+			mutate: func(task *RewardDistributionTask) {
+				task.Amount = big.NewInt(3000000000000000000)
+				task.Timestamp = time.Now().Add(-2 * time.Hour).Unix()
+			},
+			errorMsg: "task timestamp too old",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := newCLITask()
+			tt.mutate(&task)
+			err := worker.ValidateTask(&performerV1.TaskRequest{
+				TaskId:  []byte("config-" + tt.name),
+				Payload: []byte(marshalTask(t, task)),
+			})
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("Expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+
+	task := newCLITask()
+	task.Amount = big.NewInt(4000000000000000000) // 4 ETH
+	response, err := worker.HandleTask(&performerV1.TaskRequest{
+		TaskId:  []byte("config-handle"),
+		Payload: []byte(marshalTask(t, task)),
+	})
+	if err != nil {
+		t.Fatalf("HandleTask failed: %v", err)
+	}
+
+	var result RewardDistributionResult
+	if err := json.Unmarshal(response.Result, &result); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+	// 0.5% of 4 ETH
+	if result.FeeAmount.Cmp(big.NewInt(20000000000000000)) != 0 {
+		t.Errorf("Expected fee 20000000000000000, got %v", result.FeeAmount)
+	}
+	if result.TargetChain != 8453 {
+		t.Errorf("Expected the only other configured chain 8453, got %d", result.TargetChain)
+	}
+}
+
+func TestWithConfig_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		mutate   func(cfg *config.Config)
+		errorMsg string
+	}{
+		{
+			name:     "unknown fee model",
+			mutate:   func(cfg *config.Config) { cfg.Rewards.FeeModel = "flat" },
+			errorMsg: `invalid fee model: unknown fee model "flat"`,
+		},
+		{
+			name:     "unknown result encoding",
+			mutate:   func(cfg *config.Config) { cfg.Server.ResultEncoding = "cbor" },
+			errorMsg: "unsupported result encoding: cbor",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			tt.mutate(cfg)
+			opt, err := WithConfig(cfg)
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("Expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+			if opt != nil {
+				t.Errorf("Expected no option for an invalid configuration")
+			}
+		})
+	}
+}
+
+func TestRewardFlowTaskWorker_WithValidationPolicy(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	limits, err := policy.NewStatic(policy.Limits{
+		MinRewardAmount: big.NewInt(10),
+		MaxRewardAmount: big.NewInt(500000000000000000), // 0.5 ETH
+		TaskFee:         big.NewInt(0),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create policy: %v", err)
+	}
+
+	worker := NewRewardFlowTaskWorker(logger, withConfig(t, config.Default()), WithValidationPolicy(limits))
+
+	task := newCLITask()
+	err = worker.ValidateTask(&performerV1.TaskRequest{
+		TaskId:  []byte("policy-max"),
+		Payload: []byte(marshalTask(t, task)),
+	})
+	if err == nil || err.Error() != "reward amount exceeds maximum threshold" {
+		t.Errorf("Expected error message '%s', got '%v'", "reward amount exceeds maximum threshold", err)
+	}
+
+	task.Amount = big.NewInt(100) // below the configured minimum, above the policy's
+	if err := worker.ValidateTask(&performerV1.TaskRequest{
+		TaskId:  []byte("policy-min"),
+		Payload: []byte(marshalTask(t, task)),
+	}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// errShuttingDown rejects tasks that arrive once the performer started draining
+var errShuttingDown = errors.New("performer is shutting down")
+
+// taskGate tracks in-flight tasks and the background work they start, and
+// stops admitting new tasks once draining starts
+type taskGate struct {
+	mu       sync.Mutex
+	draining bool
+	inFlight int
+	idle     chan struct{} // closed once draining and no task is in flight
+
+	// ctx is the lifetime of background work, cancelled once a drain runs out of time
+	ctx        context.Context
+	cancel     context.CancelFunc
+	background sync.WaitGroup
+}
+
+func newTaskGate() *taskGate {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &taskGate{idle: make(chan struct{}), ctx: ctx, cancel: cancel}
+}
+
+// enter admits a task, returning false once draining has started
+func (g *taskGate) enter() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.draining {
+		return false
+	}
+	g.inFlight++
+	return true
+}
+
+// leave marks an admitted task as finished
+func (g *taskGate) leave() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.inFlight--
+	if g.draining && g.inFlight == 0 {
+		close(g.idle)
+	}
+}
+
+// goBackground runs fn outside the task that calls it, which must be in
+// flight, until fn returns or a drain cancels its context
+func (g *taskGate) goBackground(fn func(ctx context.Context)) {
+	g.background.Add(1)
+	go func() {
+		defer g.background.Done()
+		fn(g.ctx)
+	}()
+}
+
+// active returns the number of tasks in flight
+func (g *taskGate) active() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.inFlight
+}
+
+// drain stops admitting tasks and waits until the in-flight ones finish or ctx
+// is done, then waits for their background work, cancelling it once ctx is done
+func (g *taskGate) drain(ctx context.Context) error {
+	g.mu.Lock()
+	if !g.draining {
+		g.draining = true
+		if g.inFlight == 0 {
+			close(g.idle)
+		}
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-g.idle:
+	case <-ctx.Done():
+		g.cancel()
+		return fmt.Errorf("%d tasks still in flight: %w", g.active(), ctx.Err())
+	}
+
+	// No task is left to start background work, which gets until the deadline
+	done := make(chan struct{})
+	go func() {
+		g.background.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		g.cancel()
+		<-done
+	}
+	return nil
+}
+
+// Drain stops the worker from accepting new tasks and waits for in-flight
+// HandleTask calls to finish. It returns an error if ctx ends first. Shadow
+// comparisons still running when ctx ends are cancelled.
+func (rf *RewardFlowTaskWorker) Drain(ctx context.Context) error {
+	return rf.gate.drain(ctx)
+}
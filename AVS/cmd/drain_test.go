@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+)
+
+func TestTaskGate_Drain(t *testing.T) {
+	gate := newTaskGate()
+	if !gate.enter() {
+		t.Fatalf("Expected the gate to admit a task before draining")
+	}
+
+	// The in-flight task outlives the deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := gate.drain(ctx)
+	if err == nil || !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "1 tasks still in flight") {
+		t.Errorf("Expected drain deadline error, got %v", err)
+	}
+	if gate.enter() {
+		t.Errorf("Expected the gate to refuse tasks while draining")
+	}
+
+	// Draining again completes once the task finishes
+	done := make(chan error, 1)
+	go func() { done <- gate.drain(context.Background()) }()
+	select {
+	case err := <-done:
+		t.Fatalf("Drain returned before the task finished: %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	gate.leave()
+	if err := <-done; err != nil {
+		t.Errorf("Unexpected drain error: %v", err)
+	}
+}
+
+func TestTaskGate_DrainBackground(t *testing.T) {
+	gate := newTaskGate()
+	if !gate.enter() {
+		t.Fatalf("Expected the gate to admit a task before draining")
+	}
+	finished := make(chan struct{})
+	gate.goBackground(func(context.Context) {
+		time.Sleep(20 * time.Millisecond)
+		close(finished)
+	})
+	cancelled := make(chan error, 1)
+	gate.goBackground(func(ctx context.Context) {
+		<-ctx.Done()
+		cancelled <- ctx.Err()
+	})
+	gate.leave()
+
+	// Background work that finishes in time is waited for, the rest is cancelled
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := gate.drain(ctx); err != nil {
+		t.Fatalf("Unexpected drain error: %v", err)
+	}
+	select {
+	case <-finished:
+	default:
+		t.Errorf("Expected drain to wait for background work")
+	}
+	select {
+	case err := <-cancelled:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected background work to be cancelled, got %v", err)
+		}
+	default:
+		t.Errorf("Expected drain to return after background work was cancelled")
+	}
+}
+
+func TestRewardFlowTaskWorker_Drain(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	worker := NewRewardFlowTaskWorker(logger)
+	request := &performerV1.TaskRequest{
+		TaskId:  []byte("drain-before"),
+		Payload: []byte(marshalTask(t, newCLITask())),
+	}
+	if _, err := worker.HandleTask(request); err != nil {
+		t.Fatalf("HandleTask failed: %v", err)
+	}
+
+	if err := worker.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain failed: %v", err)
+	}
+
+	request.TaskId = []byte("drain-after")
+	if _, err := worker.HandleTask(request); !errors.Is(err, errShuttingDown) {
+		t.Errorf("Expected error message '%s', got '%v'", errShuttingDown, err)
+	}
+	if got := worker.GetStats().TotalTasksProcessed; got != 1 {
+		t.Errorf("Expected 1 processed task, got %d", got)
+	}
+}
+
+func freePort(t *testing.T) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to find a free port: %v", err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestCLI_StartShutdown(t *testing.T) {
+	metricsPort := freePort(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	app := newApp()
+	app.ExitErrHandler = func(_ *cli.Context, _ error) {}
+	done := make(chan error, 1)
+	go func() {
+		done <- app.RunContext(ctx, []string{"rewardflow-performer", "--log-level", "error", "start",
+			"--port", strconv.Itoa(freePort(t)),
+			"--metrics-port", strconv.Itoa(metricsPort),
+			"--idempotency-db", filepath.Join(t.TempDir(), "idempotency.db"),
+		})
+	}()
+
+	// Wait for the metrics listener, which starts with the performer
+	url := fmt.Sprintf("http://127.0.0.1:%d%s", metricsPort, statsPath)
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		resp, err := http.Get(url)
+		if err == nil {
+			resp.Body.Close()
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Performer did not start: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected a clean shutdown, got %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Performer did not shut down")
+	}
+
+	if _, err := http.Get(url); err == nil {
+		t.Errorf("Expected the metrics listener to be closed")
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"go.uber.org/zap"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/config"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/engagement"
+)
+
+// engagementPath serves the engagement summary of a user on the metrics listener
+const engagementPath = "/engagement"
+
+// taskActivities maps the reward types recorded by the tasks engagement source
+// onto the activity that earned them
+var taskActivities = map[RewardType]engagement.ActivityType{
+	RewardTypeLiquidity: engagement.LiquidityProvision,
+	RewardTypeSwap:      engagement.Swap,
+}
+
+// WithEngagementTracker sets where user activity is looked up
+func WithEngagementTracker(tracker *engagement.Tracker) WorkerOption {
+	return func(rf *RewardFlowTaskWorker) {
+		rf.engagement = tracker
+	}
+}
+
+// verifyLoyaltyScore rejects loyalty tasks claiming more loyalty than the
+// user's tracked activity earned. Users without tracked activity are not checked.
+func (rf *RewardFlowTaskWorker) verifyLoyaltyScore(task *RewardDistributionTask) error {
+	if task.RewardType != RewardTypeLoyalty || rf.engagement == nil {
+		return nil
+	}
+	score, ok := rf.engagement.LoyaltyScore(task.User)
+	if !ok {
+		return nil
+	}
+	if task.LoyaltyScore > score {
+		return invalid(errRewardParameters, "loyalty score %d exceeds the tracked score %d of %s", task.LoyaltyScore, score, task.User)
+	}
+	return nil
+}
+
+// recordActivity records the activity behind a distributed task for the tasks
+// engagement source. Reward tasks carry the reward rather than the liquidity
+// or volume, so only the activity itself is recorded.
+func (rf *RewardFlowTaskWorker) recordActivity(task *RewardDistributionTask) {
+	if rf.engagement == nil || rf.engagementConfig.Source != config.EngagementSourceTasks {
+		return
+	}
+	activityType, ok := taskActivities[task.RewardType]
+	if !ok {
+		return
+	}
+	if err := rf.engagement.Record(task.User, engagement.Event{Type: activityType, Timestamp: task.Timestamp}); err != nil {
+		rf.logger.Warn("Failed to record user activity", zap.String("user", task.User), zap.Error(err))
+	}
+}
+
+// engagementHandler serves the engagement summary of the user query parameter as JSON
+func (rf *RewardFlowTaskWorker) engagementHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := r.URL.Query().Get("user")
+		if user == "" {
+			http.Error(w, "user is required", http.StatusBadRequest)
+			return
+		}
+		if rf.engagement == nil {
+			http.Error(w, "engagement tracking is disabled", http.StatusNotFound)
+			return
+		}
+		threshold := uint64(rf.engagementConfig.InactiveThreshold / time.Second)
+		summary, ok := rf.engagement.Summary(user, threshold, time.Now().Unix())
+		if !ok {
+			http.Error(w, fmt.Sprintf("no activity for %s", user), http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(summary); err != nil {
+			rf.logger.Error("Failed to encode engagement summary", zap.Error(err))
+		}
+	})
+}
+
+// engagementSource is an activity tracker built from the configuration, with
+// the hooks start needs to keep it current and release it
+type engagementSource struct {
+	tracker *engagement.Tracker
+	// events is set for the events source, which must be synced periodically
+	events *engagement.EventStore
+	close  func()
+}
+
+// newEngagementSource opens the configured activity source. The events source
+// is synced once here so that an unreachable RPC fails startup.
+func newEngagementSource(ctx context.Context, cfg config.EngagementConfig) (*engagementSource, error) {
+	switch cfg.Source {
+	case config.EngagementSourceTasks:
+		return &engagementSource{tracker: engagement.NewTracker(), close: func() {}}, nil
+
+	case config.EngagementSourceEvents:
+		client, err := ethclient.DialContext(ctx, cfg.RPC)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w", cfg.RPC, err)
+		}
+		store := engagement.NewEventStore(client, common.HexToAddress(cfg.ContractAddress), cfg.FromBlock)
+		if _, err := store.Sync(ctx); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("failed to load user activity: %w", err)
+		}
+		return &engagementSource{tracker: store.Tracker, events: store, close: client.Close}, nil
+
+	default:
+		return &engagementSource{close: func() {}}, nil
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"go.uber.org/zap"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/config"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/engagement"
+)
+
+func TestRewardFlowTaskWorker_EngagementFromTasks(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	cfg := config.Default()
+	cfg.Engagement.Source = config.EngagementSourceTasks
+	worker := NewRewardFlowTaskWorker(logger, withConfig(t, cfg))
+
+	// A liquidity provision and a swap earn 2 + 1 loyalty; MEV captures are no activity
+	for i, rewardType := range []RewardType{RewardTypeLiquidity, RewardTypeSwap, RewardTypeMEV} {
+		task := newCLITask()
+		task.RewardType = rewardType
+		task.TransactionHash = "0x" + string(rune('a'+i)) + "111111111111111111111111111111111111111111111111111111111111111"
+		if _, err := worker.HandleTask(&performerV1.TaskRequest{
+			TaskId:  []byte("activity-" + string(rewardType)),
+			Payload: []byte(marshalTask(t, task)),
+		}); err != nil {
+			t.Fatalf("HandleTask failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		user     string
+		score    uint64
+		errorMsg string
+	}{
+		{name: "earned loyalty", score: 3},
+		{name: "less than earned", score: 1},
+		{
+			name:     "more than earned",
+			score:    4,
+			errorMsg: "loyalty score 4 exceeds the tracked score 3 of 0x1234567890123456789012345678901234567890",
+		},
+		{name: "user without activity", user: "0x00000000000000000000000000000000000000ab", score: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := newCLITask()
+			task.RewardType = RewardTypeLoyalty
+			task.LoyaltyScore = tt.score
+			if tt.user != "" {
+				task.User = tt.user
+			}
+			err := worker.ValidateTask(&performerV1.TaskRequest{
+				TaskId:  []byte("loyalty-" + tt.name),
+				Payload: []byte(marshalTask(t, task)),
+			})
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("Expected task to be valid, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("Expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+
+	activity, ok := worker.engagement.Activity(newCLITask().User)
+	if !ok || activity.TransactionCount != 2 {
+		t.Errorf("Expected 2 recorded activities, got %+v", activity)
+	}
+}
+
+func TestRewardFlowTaskWorker_EngagementHandler(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	const user = "0x1234567890123456789012345678901234567890"
+
+	tracker := engagement.NewTracker()
+	if err := tracker.Record(user, engagement.Event{Type: engagement.Swap, Amount: new(big.Int).Mul(big.NewInt(10000), big.NewInt(1e18)), Timestamp: 1}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	worker := NewRewardFlowTaskWorker(logger, WithEngagementTracker(tracker))
+	disabled := NewRewardFlowTaskWorker(logger)
+
+	tests := []struct {
+		name   string
+		worker *RewardFlowTaskWorker
+		query  string
+		status int
+	}{
+		{name: "known user", worker: worker, query: "?user=" + user, status: http.StatusOK},
+		{name: "unknown user", worker: worker, query: "?user=0x00000000000000000000000000000000000000ab", status: http.StatusNotFound},
+		{name: "missing user", worker: worker, query: "", status: http.StatusBadRequest},
+		{name: "tracking disabled", worker: disabled, query: "?user=" + user, status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.worker.engagementHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, engagementPath+tt.query, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, rec.Code, rec.Body)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var summary engagement.Summary
+			if err := json.Unmarshal(rec.Body.Bytes(), &summary); err != nil {
+				t.Fatalf("Failed to decode summary: %v", err)
+			}
+			// 100 volume * 25 + 1 loyalty * 25 + 100 consistency * 20, over 100;
+			// the last activity at 1970 is far past the 7 day threshold
+			if summary.EngagementScore != 45 || summary.TierName != "Silver" || summary.Active {
+				t.Errorf("Unexpected summary %+v", summary)
+			}
+		})
+	}
+}
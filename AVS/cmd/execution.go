@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/config"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/metrics"
+)
+
+// resultsPath serves the stored result of a task on the metrics listener, for
+// shadow operators to compare theirs with
+const resultsPath = "/results"
+
+// shadowRequestTimeout bounds a single request for a live result
+const shadowRequestTimeout = 10 * time.Second
+
+// ExecutionMode selects whether distributions move funds
+type ExecutionMode string
+
+const (
+	// ExecutionModeLive sends distributions
+	ExecutionModeLive ExecutionMode = config.ExecutionModeLive
+	// ExecutionModeDryRun builds and signs the transactions of distributions without sending them
+	ExecutionModeDryRun ExecutionMode = config.ExecutionModeDryRun
+	// ExecutionModeShadow runs as dry-run and compares every result with the live operator's
+	ExecutionModeShadow ExecutionMode = config.ExecutionModeShadow
+)
+
+// Sends reports whether distributions in the mode are sent on-chain
+func (m ExecutionMode) Sends() bool {
+	return m == ExecutionModeLive
+}
+
+// shadowComparer compares the results of a shadow operator with those the
+// live operator serves on resultsPath
+type shadowComparer struct {
+	liveURL  string
+	client   *http.Client
+	timeout  time.Duration
+	interval time.Duration
+}
+
+// WithShadow compares every result with the one of the live operator at
+// cfg.LiveURL, in shadow mode only
+func WithShadow(cfg config.ExecutionConfig) WorkerOption {
+	return func(rf *RewardFlowTaskWorker) {
+		rf.shadow = nil
+		if cfg.Mode != config.ExecutionModeShadow {
+			return
+		}
+		rf.shadow = &shadowComparer{
+			liveURL:  strings.TrimRight(cfg.LiveURL, "/"),
+			client:   &http.Client{Timeout: shadowRequestTimeout},
+			timeout:  cfg.CompareTimeout,
+			interval: cfg.CompareInterval,
+		}
+	}
+}
+
+// compareWithLive compares a result with the live one in the background, as
+// the live operator may not have processed the task yet. The comparison runs
+// until Drain gives up on it.
+func (rf *RewardFlowTaskWorker) compareWithLive(result *RewardDistributionResult) {
+	if rf.shadow == nil {
+		return
+	}
+	shadow := *result
+	rf.gate.goBackground(func(ctx context.Context) {
+		rf.recordShadowComparison(ctx, &shadow)
+	})
+}
+
+// recordShadowComparison compares a result with the live one and reports the outcome
+func (rf *RewardFlowTaskWorker) recordShadowComparison(ctx context.Context, result *RewardDistributionResult) string {
+	diffs, err := rf.shadow.compare(ctx, result)
+	switch {
+	case err != nil:
+		rf.logger.Warn("Live result unavailable for shadow comparison", zap.String("task_id", result.TaskID), zap.Error(err))
+		rf.metrics.ShadowCompared(metrics.ShadowUnavailable)
+		return metrics.ShadowUnavailable
+	case len(diffs) > 0:
+		rf.logger.Warn("Shadow result differs from live",
+			zap.String("task_id", result.TaskID),
+			zap.Strings("differences", diffs),
+		)
+		rf.metrics.ShadowCompared(metrics.ShadowMismatch)
+		return metrics.ShadowMismatch
+	default:
+		rf.logger.Debug("Shadow result matches live", zap.String("task_id", result.TaskID))
+		rf.metrics.ShadowCompared(metrics.ShadowMatch)
+		return metrics.ShadowMatch
+	}
+}
+
+// compare returns the consensus fields of result that differ from the live
+// result of its task, waiting up to the timeout for that result
+func (s *shadowComparer) compare(ctx context.Context, result *RewardDistributionResult) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		raw, err := s.fetch(ctx, result.TaskID)
+		if err == nil {
+			live, err := decodeStoredResult(raw)
+			if err != nil {
+				return nil, err
+			}
+			return resultDiff(result, live), nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("no live result within %s: %w", s.timeout, err)
+		case <-ticker.C:
+		}
+	}
+}
+
+// fetch asks the live operator for the stored result of a task
+func (s *shadowComparer) fetch(ctx context.Context, taskID string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.liveURL+resultsPath+"?task_id="+url.QueryEscape(taskID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid live URL: %w", err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query live operator: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("live operator returned %s", resp.Status)
+	}
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read live result: %w", err)
+	}
+	return raw, nil
+}
+
+// decodeStoredResult decodes a result in either encoding
+func decodeStoredResult(raw []byte) (*RewardDistributionResult, error) {
+	if !json.Valid(raw) {
+		return DecodeTaskResult(raw)
+	}
+	var result RewardDistributionResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode task result: %w", err)
+	}
+	return &result, nil
+}
+
+// resultDiff lists the fields of the canonical ABI result, which operators
+// sign, that differ between a shadow and a live result
+func resultDiff(shadow, live *RewardDistributionResult) []string {
+	var diffs []string
+	if shadow.Success != live.Success {
+		diffs = append(diffs, fmt.Sprintf("success: %t, live %t", shadow.Success, live.Success))
+	}
+	if a, b := bigOrZero(shadow.DistributedAmount), bigOrZero(live.DistributedAmount); a.Cmp(b) != 0 {
+		diffs = append(diffs, fmt.Sprintf("distributed_amount: %s, live %s", a, b))
+	}
+	if a, b := bigOrZero(shadow.FeeAmount), bigOrZero(live.FeeAmount); a.Cmp(b) != 0 {
+		diffs = append(diffs, fmt.Sprintf("fee_amount: %s, live %s", a, b))
+	}
+	if shadow.TargetChain != live.TargetChain {
+		diffs = append(diffs, fmt.Sprintf("target_chain: %d, live %d", shadow.TargetChain, live.TargetChain))
+	}
+	if shadow.ErrorCode != live.ErrorCode {
+		diffs = append(diffs, fmt.Sprintf("error_code: %s, live %s", shadow.ErrorCode, live.ErrorCode))
+	}
+	return diffs
+}
+
+// resultsHandler serves the stored result of the task_id query parameter in
+// the result encoding of the worker
+func (rf *RewardFlowTaskWorker) resultsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		taskID := r.URL.Query().Get("task_id")
+		if taskID == "" {
+			http.Error(w, "task_id is required", http.StatusBadRequest)
+			return
+		}
+		if rf.processed == nil {
+			http.Error(w, "results are not stored", http.StatusNotFound)
+			return
+		}
+		record, ok, err := rf.processed.Lookup(taskID, "")
+		if err != nil {
+			rf.logger.Error("Failed to look up result", zap.String("task_id", taskID), zap.Error(err))
+			http.Error(w, "failed to look up result", http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			http.Error(w, fmt.Sprintf("no result for task %s", taskID), http.StatusNotFound)
+			return
+		}
+
+		contentType := "application/json"
+		if rf.resultEncoding == ResultEncodingABI {
+			contentType = "application/octet-stream"
+		}
+		w.Header().Set("Content-Type", contentType)
+		if _, err := w.Write(record.Result); err != nil {
+			rf.logger.Error("Failed to write result", zap.Error(err))
+		}
+	})
+}
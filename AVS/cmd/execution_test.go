@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"go.uber.org/zap"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/config"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/idempotency"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/metrics"
+)
+
+// openTestStore opens an idempotency store in a temporary directory
+func openTestStore(t *testing.T) *idempotency.BoltStore {
+	t.Helper()
+	store, err := idempotency.Open(filepath.Join(t.TempDir(), "idempotency.db"), time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestRewardFlowTaskWorker_ExecutionModes(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	tests := []struct {
+		mode   string
+		stored bool
+		status int
+	}{
+		{mode: config.ExecutionModeLive, stored: true, status: http.StatusOK},
+		{mode: config.ExecutionModeDryRun, stored: false, status: http.StatusNotFound},
+		{mode: config.ExecutionModeShadow, stored: false, status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Execution.Mode = tt.mode
+			store := openTestStore(t)
+			m := metrics.New()
+			worker := NewRewardFlowTaskWorker(logger, withConfig(t, cfg), WithIdempotencyStore(store), WithMetrics(m))
+
+			// Every mode computes the same result and records the mode in it
+			result := handleTask(t, worker, "task-1", newCLITask())
+			if !result.Success || result.Mode != ExecutionMode(tt.mode) {
+				t.Fatalf("Expected a successful %s result, got %+v", tt.mode, result)
+			}
+
+			// Only live results are kept, so switching to live processes the task again
+			if _, ok, err := store.Lookup("task-1", ""); ok != tt.stored || err != nil {
+				t.Errorf("Expected stored to be %t, got %t (%v)", tt.stored, ok, err)
+			}
+			rec := httptest.NewRecorder()
+			worker.resultsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, resultsPath+"?task_id=task-1", nil))
+			if rec.Code != tt.status {
+				t.Errorf("Expected status %d, got %d: %s", tt.status, rec.Code, rec.Body)
+			}
+
+			// Every mode counts the task, but only live distributions move funds
+			snapshot := worker.stats.Snapshot()
+			if snapshot.TotalSucceeded != 1 || (snapshot.TotalRewardsDistributed.Sign() > 0) != tt.stored {
+				t.Errorf("Expected one task with distributed counted %t, got %d tasks distributing %s", tt.stored, snapshot.TotalSucceeded, snapshot.TotalRewardsDistributed)
+			}
+			if len(snapshot.ByBeneficiary) > 0 != tt.stored {
+				t.Errorf("Expected earnings counted %t, got %v", tt.stored, snapshot.ByBeneficiary)
+			}
+			rec = httptest.NewRecorder()
+			m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, metrics.Path, nil))
+			for _, name := range []string{"rewardflow_distributed_wei_total", "rewardflow_fees_wei_total"} {
+				zero := regexp.MustCompile(name + `\{[^}]*\} 0\n`)
+				if zero.MatchString(rec.Body.String()) == tt.stored {
+					t.Errorf("Expected %s counted %t, got %s", name, tt.stored, rec.Body)
+				}
+			}
+			if !strings.Contains(rec.Body.String(), `rewardflow_tasks_total{reward_type="liquidity",status="success"} 1`) {
+				t.Errorf("Expected the task to be counted in every mode")
+			}
+		})
+	}
+}
+
+func TestRewardFlowTaskWorker_ResultsHandler(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	worker := NewRewardFlowTaskWorker(logger, WithIdempotencyStore(openTestStore(t)))
+	handleTask(t, worker, "task-1", newCLITask())
+
+	tests := []struct {
+		name   string
+		worker *RewardFlowTaskWorker
+		query  string
+		status int
+	}{
+		{name: "stored task", worker: worker, query: "?task_id=task-1", status: http.StatusOK},
+		{name: "unknown task", worker: worker, query: "?task_id=other", status: http.StatusNotFound},
+		{name: "missing task", worker: worker, query: "", status: http.StatusBadRequest},
+		{name: "no store", worker: NewRewardFlowTaskWorker(logger), query: "?task_id=task-1", status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.worker.resultsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, resultsPath+tt.query, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, rec.Code, rec.Body)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			result, err := decodeStoredResult(rec.Body.Bytes())
+			if err != nil {
+				t.Fatalf("Failed to decode result: %v", err)
+			}
+			if result.TaskID != "task-1" || result.Mode != ExecutionModeLive {
+				t.Errorf("Unexpected result %+v", result)
+			}
+		})
+	}
+}
+
+func TestRewardFlowTaskWorker_ShadowComparison(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		encoding ResultEncoding
+		feeBps   uint64
+		taskID   string
+		outcome  string
+	}{
+		{name: "matching json result", encoding: ResultEncodingJSON, feeBps: 10, taskID: "task-1", outcome: metrics.ShadowMatch},
+		{name: "matching abi result", encoding: ResultEncodingABI, feeBps: 10, taskID: "task-1", outcome: metrics.ShadowMatch},
+		{name: "different fee", encoding: ResultEncodingJSON, feeBps: 20, taskID: "task-1", outcome: metrics.ShadowMismatch},
+		{name: "task unknown to live", encoding: ResultEncodingJSON, feeBps: 10, taskID: "task-2", outcome: metrics.ShadowUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			live := NewRewardFlowTaskWorker(logger, WithIdempotencyStore(openTestStore(t)), WithResultEncoding(tt.encoding))
+			if _, err := live.HandleTask(&performerV1.TaskRequest{TaskId: []byte("task-1"), Payload: []byte(marshalTask(t, newCLITask()))}); err != nil {
+				t.Fatalf("HandleTask failed: %v", err)
+			}
+			server := httptest.NewServer(live.resultsHandler())
+			defer server.Close()
+
+			cfg := config.Default()
+			cfg.Rewards.FeeBps = tt.feeBps
+			cfg.Execution = config.ExecutionConfig{
+				Mode:            config.ExecutionModeShadow,
+				LiveURL:         server.URL,
+				CompareTimeout:  200 * time.Millisecond,
+				CompareInterval: 50 * time.Millisecond,
+			}
+			shadow := NewRewardFlowTaskWorker(logger, withConfig(t, cfg), WithShadow(cfg.Execution))
+			result := handleTask(t, shadow, tt.taskID, newCLITask())
+
+			if outcome := shadow.recordShadowComparison(context.Background(), &result); outcome != tt.outcome {
+				t.Errorf("Expected outcome %s, got %s", tt.outcome, outcome)
+			}
+		})
+	}
+}
+
+func TestRewardFlowTaskWorker_DrainShadowComparison(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	requests := make(chan struct{}, 100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- struct{}{}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	cfg := config.Default()
+	cfg.Execution = config.ExecutionConfig{
+		Mode:            config.ExecutionModeShadow,
+		LiveURL:         server.URL,
+		CompareTimeout:  time.Hour,
+		CompareInterval: 10 * time.Millisecond,
+	}
+	m := metrics.New()
+	worker := NewRewardFlowTaskWorker(logger, withConfig(t, cfg), WithShadow(cfg.Execution), WithMetrics(m))
+	handleTask(t, worker, "task-1", newCLITask())
+	<-requests
+
+	// A comparison still waiting for the live result at the drain deadline is
+	// cancelled instead of outliving the worker
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := worker.Drain(ctx); err != nil {
+		t.Fatalf("Drain failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected drain to stop at its deadline, took %s", elapsed)
+	}
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, metrics.Path, nil))
+	if series := `rewardflow_shadow_comparisons_total{outcome="unavailable"} 1`; !strings.Contains(rec.Body.String(), series) {
+		t.Errorf("Expected metrics to contain %q", series)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"go.uber.org/zap"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/config"
+)
+
+func TestRewardFlowTaskWorker_FeeModels(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		model       string
+		bridgeFee   int64
+		protocolFee int64
+		operatorFee int64
+	}{
+		// Batch of 1 ETH to Arbitrum over three recipients
+		{name: "flat bps", model: config.FeeModelFlatBps, protocolFee: 200000000000000, operatorFee: 800000000000000},
+		{name: "chain base", model: config.FeeModelChainBase, bridgeFee: 300000000000000},
+		{name: "base plus bps", model: config.FeeModelBasePlusBps, bridgeFee: 300000000000000, protocolFee: 200000000000000, operatorFee: 800000000000000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Rewards.FeeModel = tt.model
+			cfg.Rewards.FeeBps = 10
+			cfg.Rewards.ProtocolFeeShareBps = 2000
+			if err := cfg.Validate(); err != nil {
+				t.Fatalf("Invalid test config: %v", err)
+			}
+			worker := NewRewardFlowTaskWorker(logger, withConfig(t, cfg))
+
+			response, err := worker.HandleTask(&performerV1.TaskRequest{
+				TaskId:  []byte("fees-" + tt.name),
+				Payload: []byte(marshalTask(t, newBatchTask())),
+			})
+			if err != nil {
+				t.Fatalf("HandleTask failed: %v", err)
+			}
+			var result RewardDistributionResult
+			if err := json.Unmarshal(response.Result, &result); err != nil {
+				t.Fatalf("Failed to unmarshal result: %v", err)
+			}
+
+			breakdown := result.FeeBreakdown
+			if breakdown == nil {
+				t.Fatalf("Expected a fee breakdown in the result")
+			}
+			if breakdown.BridgeFee.Int64() != tt.bridgeFee || breakdown.ProtocolFee.Int64() != tt.protocolFee || breakdown.OperatorFee.Int64() != tt.operatorFee {
+				t.Errorf("Expected bridge/protocol/operator fees %d/%d/%d, got %s/%s/%s",
+					tt.bridgeFee, tt.protocolFee, tt.operatorFee, breakdown.BridgeFee, breakdown.ProtocolFee, breakdown.OperatorFee)
+			}
+			if result.FeeAmount.Cmp(breakdown.Total()) != 0 {
+				t.Errorf("Expected fee amount %s to be the breakdown total, got %s", breakdown.Total(), result.FeeAmount)
+			}
+			want := new(big.Int).Sub(big.NewInt(1000000000000000000), breakdown.Total())
+			if result.DistributedAmount.Cmp(want) != 0 {
+				t.Errorf("Expected distributed amount %s, got %s", want, result.DistributedAmount)
+			}
+			for _, recipient := range result.Recipients {
+				if recipient.FeeBreakdown == nil || recipient.FeeBreakdown.Total().Cmp(recipient.FeeAmount) != 0 {
+					t.Errorf("Expected recipient %s to carry its fee breakdown", recipient.Recipient)
+				}
+			}
+		})
+	}
+}
+
+func TestRewardFlowTaskWorker_FeeSplit(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	worker := NewRewardFlowTaskWorker(logger)
+
+	task := newCLITask()
+	task.RewardType = RewardTypeMEV
+	response, err := worker.HandleTask(&performerV1.TaskRequest{
+		TaskId:  []byte("fee-split"),
+		Payload: []byte(marshalTask(t, task)),
+	})
+	if err != nil {
+		t.Fatalf("HandleTask failed: %v", err)
+	}
+	var result RewardDistributionResult
+	if err := json.Unmarshal(response.Result, &result); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+
+	// Default Constants.sol shares, with the unallocated 35% to LPs
+	if result.FeeSplit == nil || result.FeeSplit.LP.Int64() != 850000000000000 || result.FeeSplit.Operator.Int64() != 100000000000000 ||
+		result.FeeSplit.Protocol.Int64() != 30000000000000 || result.FeeSplit.GasCompensation.Int64() != 20000000000000 {
+		t.Errorf("Unexpected fee split of %s: %+v", result.FeeAmount, result.FeeSplit)
+	}
+	if result.MEVSplit == nil || result.MEVSplit.Total().Cmp(task.Amount) != 0 {
+		t.Errorf("Expected the captured MEV %s to be split, got %+v", task.Amount, result.MEVSplit)
+	}
+
+	earnings := worker.GetStats().ByBeneficiary
+	if lp := earnings["lp"]; lp.Fees.Int64() != 850000000000000 || lp.MEV.Cmp(big.NewInt(850000000000000000)) != 0 {
+		t.Errorf("Unexpected lp earnings: %+v", lp)
+	}
+	if gas := earnings["gas_compensation"]; gas.Total.Int64() != 20000000000000+20000000000000000 {
+		t.Errorf("Unexpected gas compensation earnings: %+v", gas)
+	}
+}
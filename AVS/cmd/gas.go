@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"go.uber.org/zap"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/config"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/gas"
+)
+
+// Deferral describes a distribution waiting for cheaper gas
+type Deferral struct {
+	Reason   gas.Reason `json:"reason"`
+	Until    int64      `json:"until"` // unix seconds
+	GasPrice *big.Int   `json:"gas_price"`
+	GasCost  *big.Int   `json:"gas_cost"`
+}
+
+// WithGasPriceSource sets where gas prices are read for deferral decisions.
+// Nothing is deferred without one.
+func WithGasPriceSource(source gas.PriceSource) WorkerOption {
+	return func(rf *RewardFlowTaskWorker) {
+		rf.gasPrices = source
+	}
+}
+
+// gasPolicy converts the configured deferral limits
+func gasPolicy(cfg config.GasConfig) gas.Policy {
+	policy := gas.Policy{GasLimit: cfg.GasLimit, MaxDelay: cfg.MaxDelay}
+	if cfg.MaxPrice != nil {
+		policy.MaxGasPrice = &cfg.MaxPrice.Int
+	}
+	if cfg.MinProfit != nil {
+		policy.MinProfit = &cfg.MinProfit.Int
+	}
+	return policy
+}
+
+// deferDistribution decides whether a distribution of amount less fee waits for
+// cheaper gas. The deposit is sent on the source chain of the task, so that is
+// the chain whose gas price counts. Distributions go ahead when the gas price
+// cannot be read.
+func (rf *RewardFlowTaskWorker) deferDistribution(task *RewardDistributionTask, amount, fee *big.Int) *Deferral {
+	if rf.gasPrices == nil {
+		return nil
+	}
+	price, err := rf.gasPrices.GasPrice(context.Background(), task.ChainID)
+	if err != nil {
+		rf.logger.Warn("Failed to read gas price, distributing now", zap.Uint64("chain_id", task.ChainID), zap.Error(err))
+		return nil
+	}
+
+	quote := gas.Quote{Amount: amount, Fee: fee, Created: time.Unix(task.Timestamp, 0)}
+	if rf.preferences != nil {
+		if prefs, ok := rf.preferences.Get(task.User); ok && prefs.AutoClaimEnabled {
+			quote.UserDelay = time.Duration(prefs.ClaimFrequency) * time.Second
+		}
+	}
+	decision := rf.gasPolicy.Decide(quote, price, time.Now())
+	if !decision.Defer {
+		return nil
+	}
+	return &Deferral{
+		Reason:   decision.Reason,
+		Until:    decision.Until.Unix(),
+		GasPrice: decision.GasPrice,
+		GasCost:  decision.GasCost,
+	}
+}
+
+// newGasSource builds the configured gas price source, nil for none. The rpc
+// source dials every enabled chain; the returned function releases the clients.
+func newGasSource(ctx context.Context, cfg *config.Config) (gas.PriceSource, func(), error) {
+	switch cfg.Gas.Source {
+	case config.GasSourceStatic:
+		return gas.NewStatic(&cfg.Gas.StaticPrice.Int), func() {}, nil
+
+	case config.GasSourceRPC:
+		clients := make(gas.Clients)
+		var dialed []*ethclient.Client
+		closeAll := func() {
+			for _, client := range dialed {
+				client.Close()
+			}
+		}
+		for _, chain := range cfg.Chains {
+			if !chain.IsEnabled() || chain.RPC == "" {
+				continue
+			}
+			client, err := ethclient.DialContext(ctx, chain.RPC)
+			if err != nil {
+				closeAll()
+				return nil, nil, fmt.Errorf("failed to connect to %s: %w", chain.RPC, err)
+			}
+			dialed = append(dialed, client)
+			clients[chain.ChainID] = client
+		}
+		return clients, closeAll, nil
+
+	default:
+		return nil, func() {}, nil
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"go.uber.org/zap"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/config"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/gas"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/idempotency"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/preferences"
+)
+
+func TestRewardFlowTaskWorker_GasDeferral(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	const frequentClaimer = "0x00000000000000000000000000000000000000cc"
+	prefs := preferences.NewMemoryStore()
+	if err := prefs.Set(frequentClaimer, preferences.Preferences{PreferredChain: 10, ClaimThreshold: big.NewInt(1e16), ClaimFrequency: 600, AutoClaimEnabled: true}); err != nil {
+		t.Fatalf("Failed to set preferences: %v", err)
+	}
+
+	// Optimism is the fallback target; 150000 gas at 2 gwei costs 0.0003 ETH,
+	// more than a 0.0002 ETH reward
+	cfg := config.Default()
+	cfg.Routing.FallbackChain = 10
+	cfg.Gas.Source = config.GasSourceStatic
+	cfg.Gas.StaticPrice = config.NewAmount(big.NewInt(2e9))
+	prices := gas.NewStatic(big.NewInt(2e9))
+	prices.Set(137, big.NewInt(150e9))
+
+	tests := []struct {
+		name   string
+		user   string
+		amount *big.Int
+		source uint64
+		age    time.Duration
+		reason gas.Reason
+		eta    time.Duration
+	}{
+		{name: "cheap gas", amount: big.NewInt(1e18)},
+		{name: "high gas on the source chain", amount: big.NewInt(1e18), source: 137, reason: gas.ReasonHighGas, eta: time.Hour},
+		{name: "high gas on the target chain only", amount: big.NewInt(1e18), source: 10},
+		{name: "fee and gas exceed the reward", amount: big.NewInt(2e14), reason: gas.ReasonUnprofitable, eta: 45 * time.Minute},
+		{name: "user claim frequency", user: frequentClaimer, amount: big.NewInt(2e14), reason: gas.ReasonUnprofitable, eta: 20 * time.Minute},
+		{name: "retry capped at the maximum delay", amount: big.NewInt(2e14), age: 230 * time.Minute, reason: gas.ReasonUnprofitable, eta: 10 * time.Minute},
+		{name: "past the maximum delay", amount: big.NewInt(2e14), age: 4 * time.Hour},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			worker := NewRewardFlowTaskWorker(logger, withConfig(t, cfg), WithGasPriceSource(prices), WithPreferenceStore(prefs))
+			task := newCLITask()
+			if tt.source != 0 {
+				// Gas is paid on the source chain: tasks from Polygon wait, while tasks
+				// from Optimism go ahead although they are routed to Polygon
+				task.ChainID = tt.source
+			}
+			task.Amount = tt.amount
+			task.TransactionHash = "0x" + string(rune('a'+i)) + "111111111111111111111111111111111111111111111111111111111111111"
+			task.Timestamp = time.Now().Add(-tt.age).Unix()
+			if tt.user != "" {
+				task.User = tt.user
+			}
+			response, err := worker.HandleTask(&performerV1.TaskRequest{TaskId: []byte(tt.name), Payload: []byte(marshalTask(t, task))})
+			if err != nil {
+				t.Fatalf("HandleTask failed: %v", err)
+			}
+			var result RewardDistributionResult
+			if err := json.Unmarshal(response.Result, &result); err != nil {
+				t.Fatalf("Failed to decode result: %v", err)
+			}
+
+			if tt.reason == "" {
+				if !result.Success || result.Deferred != nil {
+					t.Errorf("Expected a distribution, got %+v", result)
+				}
+				return
+			}
+			if result.Success || result.ErrorCode != ResultErrorDeferred || result.Deferred == nil || result.Deferred.Reason != tt.reason {
+				t.Fatalf("Expected a %s deferral, got %+v", tt.reason, result)
+			}
+			if eta := time.Until(time.Unix(result.Deferred.Until, 0)); eta < tt.eta-5*time.Second || eta > tt.eta+5*time.Second {
+				t.Errorf("Expected a retry in %s, got %s", tt.eta, eta)
+			}
+			if stats := worker.GetStats(); stats.TotalDeferred != 1 || stats.TotalTasksProcessed != 0 {
+				t.Errorf("Expected 1 deferred and no processed task, got %d and %d", stats.TotalDeferred, stats.TotalTasksProcessed)
+			}
+		})
+	}
+}
+
+func TestRewardFlowTaskWorker_DeferredTaskIsRetried(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	store, err := idempotency.Open(filepath.Join(t.TempDir(), "idempotency.db"), time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	prices := gas.NewStatic(big.NewInt(500e9))
+	worker := NewRewardFlowTaskWorker(logger, WithIdempotencyStore(store), WithGasPriceSource(prices))
+	request := &performerV1.TaskRequest{TaskId: []byte("deferred"), Payload: []byte(marshalTask(t, newCLITask()))}
+
+	response, err := worker.HandleTask(request)
+	if err != nil {
+		t.Fatalf("HandleTask failed: %v", err)
+	}
+	var result RewardDistributionResult
+	if err := json.Unmarshal(response.Result, &result); err != nil {
+		t.Fatalf("Failed to decode result: %v", err)
+	}
+	if result.ErrorCode != ResultErrorDeferred {
+		t.Fatalf("Expected a deferral at 500 gwei, got %+v", result)
+	}
+
+	// Deferrals are not stored, so the resubmitted task is distributed once gas is cheaper
+	prices.Set(1, big.NewInt(1e9))
+	response, err = worker.HandleTask(request)
+	if err != nil {
+		t.Fatalf("HandleTask failed: %v", err)
+	}
+	var retried RewardDistributionResult
+	if err := json.Unmarshal(response.Result, &retried); err != nil {
+		t.Fatalf("Failed to decode result: %v", err)
+	}
+	if !retried.Success || retried.Deferred != nil {
+		t.Errorf("Expected the resubmitted task to be distributed, got %+v", retried)
+	}
+}
+
+// seenStore is an idempotency store whose tasks were first seen at fixed times
+type seenStore struct {
+	idempotency.Store
+	seen map[string]time.Time
+}
+
+func (s *seenStore) FirstSeen(taskID string) (time.Time, error) {
+	if seen, ok := s.seen[taskID]; ok {
+		return seen, nil
+	}
+	return s.Store.FirstSeen(taskID)
+}
+
+func TestRewardFlowTaskWorker_DeferralCountsFromFirstDelivery(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	// Basic ABI payloads carry no timestamp; one delivered 5h ago is past the 4h maximum delay
+	store := &seenStore{Store: openTestStore(t), seen: map[string]time.Time{"first-seen-5h-ago": time.Now().Add(-5 * time.Hour)}}
+	worker := NewRewardFlowTaskWorker(logger, WithIdempotencyStore(store), WithGasPriceSource(gas.NewStatic(big.NewInt(500e9))))
+	task := newCLITask()
+	payload, err := EncodeTaskPayload(&task, PayloadFormatABI)
+	if err != nil {
+		t.Fatalf("EncodeTaskPayload failed: %v", err)
+	}
+
+	tests := []struct {
+		taskID   string
+		deferred bool
+	}{
+		{taskID: "first-seen-now", deferred: true},
+		{taskID: "first-seen-now", deferred: true},
+		{taskID: "first-seen-5h-ago", deferred: false},
+	}
+
+	for _, tt := range tests {
+		response, err := worker.HandleTask(&performerV1.TaskRequest{TaskId: []byte(tt.taskID), Payload: payload})
+		if err != nil {
+			t.Fatalf("HandleTask failed for %s: %v", tt.taskID, err)
+		}
+		var result RewardDistributionResult
+		if err := json.Unmarshal(response.Result, &result); err != nil {
+			t.Fatalf("Failed to decode result: %v", err)
+		}
+		if deferred := result.ErrorCode == ResultErrorDeferred; deferred != tt.deferred {
+			t.Errorf("Expected deferred to be %t for %s, got %+v", tt.deferred, tt.taskID, result)
+		}
+	}
+
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/idempotency"
+	"go.uber.org/zap"
+)
+
+// batchSourceKey identifies the on-chain batch a batch task was created from
+func batchSourceKey(task *BatchRewardDistributionTask) string {
+	if task.TaskHash == "" {
+		return ""
+	}
+	return "batch:" + task.TaskHash
+}
+
+// lookupProcessedTask returns the stored result of a task that was already processed.
+// A duplicate found by its source key under another task ID gets the stored result
+// re-encoded for its own task ID, as the result hash is bound to the task ID. It
+// fails when the store cannot be read, so that a task which may be a duplicate is
+// retried by the aggregator instead of distributed again. A batch task some of
+// whose recipients failed is not done: it returns settled, the stored outcome of
+// every recipient, so that only the failed ones are sent again.
+func (rf *RewardFlowTaskWorker) lookupProcessedTask(taskID, sourceKey string) (stored []byte, settled []RecipientDistributionResult, ok bool, err error) {
+	if rf.processed == nil {
+		return nil, nil, false, nil
+	}
+
+	record, ok, err := rf.processed.Lookup(taskID, sourceKey)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to look up processed task: %w", err)
+	}
+	if !ok {
+		return nil, nil, false, nil
+	}
+	if record.Retry != nil {
+		if err := json.Unmarshal(record.Retry, &settled); err != nil {
+			return nil, nil, false, fmt.Errorf("task %s has unreadable recipient outcomes: %w", record.TaskID, err)
+		}
+		rf.logger.Sugar().Infow("Retrying failed recipients of task",
+			zap.String("task_id", taskID),
+			zap.String("original_task_id", record.TaskID),
+			zap.String("source_key", sourceKey),
+		)
+		return nil, settled, false, nil
+	}
+
+	rf.metrics.DuplicateTask()
+	rf.logger.Sugar().Infow("Duplicate task, returning stored result",
+		zap.String("task_id", taskID),
+		zap.String("original_task_id", record.TaskID),
+		zap.String("source_key", sourceKey),
+		zap.Time("stored_at", record.StoredAt),
+	)
+	if record.TaskID == taskID {
+		return record.Result, nil, true, nil
+	}
+
+	result, err := decodeStoredResult(record.Result)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("task is a duplicate of task %s, whose stored result is unreadable: %w", record.TaskID, err)
+	}
+	result.TaskID = taskID
+	resultBytes, err := rf.encodeResult(result)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("task is a duplicate of task %s, whose result failed to encode: %w", record.TaskID, err)
+	}
+	return resultBytes, nil, true, nil
+}
+
+// firstSeen returns when a task ID was first delivered. Without a store, or
+// when it fails, every delivery counts as the first.
+func (rf *RewardFlowTaskWorker) firstSeen(taskID string) time.Time {
+	if rf.processed == nil {
+		return time.Now()
+	}
+	seen, err := rf.processed.FirstSeen(taskID)
+	if err != nil {
+		rf.logger.Error("Failed to record first delivery of task", zap.String("task_id", taskID), zap.Error(err))
+		return time.Now()
+	}
+	return seen
+}
+
+// recordProcessedTask stores a task result so later duplicates return it unchanged.
+// Distribution failures and deferrals are not recorded so that the task can be retried,
+// nor is anything outside live mode, which distributes nothing. A partly failed batch
+// is recorded with the outcome of every recipient, for a redelivery to retry the
+// failed ones without paying the others twice.
+func (rf *RewardFlowTaskWorker) recordProcessedTask(taskID, sourceKey string, result *RewardDistributionResult, resultBytes []byte) {
+	if rf.processed == nil || !rf.mode.Sends() || result.ErrorCode == ResultErrorDistributionFailed || result.ErrorCode == ResultErrorDeferred {
+		return
+	}
+
+	var retry []byte
+	if result.ErrorCode == ResultErrorPartialFailure {
+		var err error
+		if retry, err = json.Marshal(result.Recipients); err != nil {
+			rf.logger.Error("Failed to encode recipient outcomes", zap.String("task_id", taskID), zap.Error(err))
+			return
+		}
+	}
+	err := rf.processed.Put(&idempotency.Record{
+		TaskID:    taskID,
+		SourceKey: sourceKey,
+		Result:    resultBytes,
+		Retry:     retry,
+	})
+	if err != nil {
+		rf.logger.Error("Failed to record processed task", zap.String("task_id", taskID), zap.Error(err))
+	}
+}
+
+// pruneProcessedTasks removes expired records from the store until done is closed
+func pruneProcessedTasks(store idempotency.Store, interval time.Duration, logger *zap.Logger, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			removed, err := store.Prune()
+			if err != nil {
+				logger.Error("Failed to prune idempotency store", zap.Error(err))
+				continue
+			}
+			if removed > 0 {
+				logger.Sugar().Infow("Pruned idempotency store", zap.Int("removed", removed))
+			}
+		}
+	}
+}
+
+// keyedMutex serializes work on the same keys while letting unrelated keys proceed
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+type keyedLock struct {
+	sync.Mutex
+	refs int
+}
+
+func newKeyedMutex() *keyedMutex {
+	return &keyedMutex{locks: make(map[string]*keyedLock)}
+}
+
+// lock acquires every non-empty key in a fixed order and returns the matching unlock
+func (k *keyedMutex) lock(keys ...string) func() {
+	unique := make([]string, 0, len(keys))
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if key != "" && !seen[key] {
+			seen[key] = true
+			unique = append(unique, key)
+		}
+	}
+	// A fixed order prevents deadlocks between callers sharing some keys
+	sort.Strings(unique)
+
+	held := make([]*keyedLock, 0, len(unique))
+	for _, key := range unique {
+		k.mu.Lock()
+		l, ok := k.locks[key]
+		if !ok {
+			l = &keyedLock{}
+			k.locks[key] = l
+		}
+		l.refs++
+		k.mu.Unlock()
+
+		l.Lock()
+		held = append(held, l)
+	}
+
+	return func() {
+		for i := len(held) - 1; i >= 0; i-- {
+			held[i].Unlock()
+
+			k.mu.Lock()
+			held[i].refs--
+			if held[i].refs == 0 {
+				delete(k.locks, unique[i])
+			}
+			k.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/idempotency"
+	"go.uber.org/zap"
+)
+
+func TestRewardFlowTaskWorker_DuplicateTasks(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "idempotency.db")
+	store, err := idempotency.Open(path, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+
+	task := RewardDistributionTask{
+		User:            "0x1234567890123456789012345678901234567890",
+		Amount:          big.NewInt(1000000000000000000), // 1 ETH
+		ChainID:         1,
+		PoolID:          "0xabcdef1234567890abcdef1234567890abcdef12",
+		RewardType:      RewardTypeLiquidity,
+		Timestamp:       time.Now().Unix(),
+		HookAddress:     "0x9876543210987654321098765432109876543210",
+		TransactionHash: "0x1111111111111111111111111111111111111111111111111111111111111111",
+	}
+	taskData, err := json.Marshal(task)
+	if err != nil {
+		t.Fatalf("Failed to marshal task: %v", err)
+	}
+
+	worker := NewRewardFlowTaskWorker(logger, WithIdempotencyStore(store))
+	original, err := worker.HandleTask(&performerV1.TaskRequest{TaskId: []byte("task-1"), Payload: taskData})
+	if err != nil {
+		t.Fatalf("HandleTask failed: %v", err)
+	}
+	processed := worker.GetStats().TotalTasksProcessed
+
+	// The same task ID returns the stored result
+	response, err := worker.HandleTask(&performerV1.TaskRequest{TaskId: []byte("task-1"), Payload: taskData})
+	if err != nil {
+		t.Fatalf("HandleTask failed: %v", err)
+	}
+	if !bytes.Equal(response.Result, original.Result) {
+		t.Errorf("Expected stored result:\n%s\n%s", original.Result, response.Result)
+	}
+
+	// The same source event under a new task ID returns the stored result for the new task ID
+	response, err = worker.HandleTask(&performerV1.TaskRequest{TaskId: []byte("task-2"), Payload: taskData})
+	if err != nil {
+		t.Fatalf("HandleTask failed: %v", err)
+	}
+	var stored, duplicate RewardDistributionResult
+	if err := json.Unmarshal(original.Result, &stored); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+	if err := json.Unmarshal(response.Result, &duplicate); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+	if duplicate.TaskID != "task-2" || duplicate.ResultHash == stored.ResultHash || len(resultDiff(&duplicate, &stored)) > 0 {
+		t.Errorf("Expected the stored result under task-2, got %+v", duplicate)
+	}
+	if got := worker.GetStats().TotalTasksProcessed; got != processed {
+		t.Errorf("Expected duplicates not to be processed, processed count went from %d to %d", processed, got)
+	}
+
+	// Concurrent deliveries of a new task are processed once
+	task.TransactionHash = "0x2222222222222222222222222222222222222222222222222222222222222222"
+	taskData, err = json.Marshal(task)
+	if err != nil {
+		t.Fatalf("Failed to marshal task: %v", err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := worker.HandleTask(&performerV1.TaskRequest{TaskId: []byte("task-3"), Payload: taskData}); err != nil {
+				t.Errorf("HandleTask failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := worker.GetStats().TotalTasksProcessed; got != processed*2 {
+		t.Errorf("Expected one concurrent delivery to be processed, processed count went from %d to %d", processed, got)
+	}
+
+	// Records survive a restart of the performer
+	if err := store.Close(); err != nil {
+		t.Fatalf("Failed to close store: %v", err)
+	}
+	reopened, err := idempotency.Open(path, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	defer reopened.Close()
+
+	restarted := NewRewardFlowTaskWorker(logger, WithIdempotencyStore(reopened))
+	response, err = restarted.HandleTask(&performerV1.TaskRequest{TaskId: []byte("task-1"), Payload: taskData})
+	if err != nil {
+		t.Fatalf("HandleTask failed after restart: %v", err)
+	}
+	if !bytes.Equal(response.Result, original.Result) {
+		t.Errorf("Expected stored result after restart")
+	}
+	if got := restarted.GetStats().TotalTasksProcessed; got != 0 {
+		t.Errorf("Expected no tasks processed after restart, got %d", got)
+	}
+}
+
+func TestRewardFlowTaskWorker_DuplicateTaskHash(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	worker := NewRewardFlowTaskWorker(logger, WithIdempotencyStore(openTestStore(t)), WithResultEncoding(ResultEncodingABI))
+	payload := []byte(marshalTask(t, newCLITask()))
+	for _, taskID := range []string{"task-1", "task-2"} {
+		response, err := worker.HandleTask(&performerV1.TaskRequest{TaskId: []byte(taskID), Payload: payload})
+		if err != nil {
+			t.Fatalf("HandleTask failed for %s: %v", taskID, err)
+		}
+		result, err := DecodeTaskResult(response.Result)
+		if err != nil {
+			t.Fatalf("DecodeTaskResult failed for %s: %v", taskID, err)
+		}
+		if expected := TaskHashFromID([]byte(taskID)).Hex(); result.TaskID != expected {
+			t.Errorf("Expected task hash %s for %s, got %s", expected, taskID, result.TaskID)
+		}
+	}
+	if got := worker.GetStats().TotalTasksProcessed; got != 1 {
+		t.Errorf("Expected the duplicate not to be processed, got %d processed tasks", got)
+	}
+}
+
+// failingStore is an idempotency store whose lookups fail while it is down
+type failingStore struct {
+	idempotency.Store
+	down bool
+}
+
+func (s *failingStore) Lookup(taskID, sourceKey string) (*idempotency.Record, bool, error) {
+	if s.down {
+		return nil, false, errors.New("database not open")
+	}
+	return s.Store.Lookup(taskID, sourceKey)
+}
+
+func TestRewardFlowTaskWorker_StoreUnavailable(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	store := &failingStore{Store: openTestStore(t)}
+	worker := NewRewardFlowTaskWorker(logger, WithIdempotencyStore(store))
+	request := &performerV1.TaskRequest{TaskId: []byte("task-1"), Payload: []byte(marshalTask(t, newCLITask()))}
+	if _, err := worker.HandleTask(request); err != nil {
+		t.Fatalf("HandleTask failed: %v", err)
+	}
+
+	// A redelivery that cannot be checked against the store is rejected, not distributed again
+	store.down = true
+	_, err = worker.HandleTask(request)
+	if expected := "failed to look up processed task: database not open"; err == nil || err.Error() != expected {
+		t.Errorf("Expected error message '%s', got '%v'", expected, err)
+	}
+	if got := worker.GetStats().TotalTasksProcessed; got != 1 {
+		t.Errorf("Expected the redelivery not to be processed, got %d processed tasks", got)
+	}
+
+	// Once the store is back the redelivery gets the stored result
+	store.down = false
+	if _, err := worker.HandleTask(request); err != nil {
+		t.Fatalf("HandleTask failed: %v", err)
+	}
+	if got := worker.GetStats().TotalTasksProcessed; got != 1 {
+		t.Errorf("Expected the redelivery not to be processed, got %d processed tasks", got)
+	}
+}
@@ -1,66 +1,161 @@
 package main
 
 import (
-	"context"
-	"encoding/json"
 	"fmt"
 	"math/big"
+	"os"
 	"time"
 
-	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/performer/server"
 	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/bridge"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/chains"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/config"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/engagement"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/fees"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/gas"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/idempotency"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/metrics"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/policy"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/preferences"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/scheduler"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/stats"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/tier"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/transfers"
 	"go.uber.org/zap"
 )
 
 // RewardFlowTaskWorker implements the AVS performer interface for RewardFlow
 // This handles reward distribution tasks from Uniswap V4 hooks across multiple chains
 type RewardFlowTaskWorker struct {
-	logger *zap.Logger
-	stats  *TaskStats
+	logger         *zap.Logger
+	stats          *stats.Engine
+	resultEncoding ResultEncoding
+	processed      idempotency.Store
+	metrics        *metrics.Metrics
+	taskLocks      *keyedMutex
+	gate           *taskGate
+	scheduler      *scheduler.Scheduler
+	// distributions remembers the last distribution to each user and pool
+	distributions *scheduler.History
+
+	// Settings loaded from the operator configuration
+	rewards     config.RewardsConfig
+	policy      policy.ValidationPolicy
+	preferences preferences.PreferenceStore
+	// fallbackChain receives distributions without a usable preference, see determineTargetChain
+	fallbackChain uint64
+	chains        *chains.Registry
+	fees          fees.Engine
+	splitter      *fees.Splitter
+
+	engagement       *engagement.Tracker
+	engagementConfig config.EngagementConfig
+
+	gasPrices gas.PriceSource
+	gasPolicy gas.Policy
+
+	// bridges sends distributions, which are simulated when it is nil
+	bridges *bridge.Router
+	// transfers follows the deposits of bridges until they are delivered
+	transfers *transfers.Tracker
+
+	// mode selects whether distributions are sent, see ExecutionMode
+	mode ExecutionMode
+	// shadow compares results with the live operator in shadow mode
+	shadow *shadowComparer
 }
 
-// TaskStats tracks RewardFlow task processing statistics
-type TaskStats struct {
-	TotalTasksProcessed     int64    `json:"total_tasks_processed"`
-	TotalRewardsDistributed *big.Int `json:"total_rewards_distributed"`
-	TotalMEVCaptured        *big.Int `json:"total_mev_captured"`
-	AverageProcessingTime   int64    `json:"average_processing_time_ms"`
-	SuccessRate             float64  `json:"success_rate"`
+// WorkerOption configures optional RewardFlowTaskWorker behaviour
+type WorkerOption func(*RewardFlowTaskWorker)
+
+// WithResultEncoding sets how HandleTask serializes task results
+func WithResultEncoding(encoding ResultEncoding) WorkerOption {
+	return func(rf *RewardFlowTaskWorker) {
+		rf.resultEncoding = encoding
+	}
 }
 
 // RewardDistributionTask represents a reward distribution task from Uniswap V4 hooks
 type RewardDistributionTask struct {
-	User            string   `json:"user"`
-	Amount          *big.Int `json:"amount"`
-	ChainID         uint64   `json:"chain_id"`
-	PoolID          string   `json:"pool_id"`
-	RewardType      string   `json:"reward_type"` // "liquidity", "swap", "mev"
-	Timestamp       int64    `json:"timestamp"`
-	HookAddress     string   `json:"hook_address"`
-	TransactionHash string   `json:"transaction_hash"`
+	User            string     `json:"user"`
+	Amount          *big.Int   `json:"amount"`
+	ChainID         uint64     `json:"chain_id"`
+	PoolID          string     `json:"pool_id"`
+	RewardType      RewardType `json:"reward_type"` // "liquidity", "swap", "loyalty", "tier", "mev"
+	Timestamp       int64      `json:"timestamp"`
+	HookAddress     string     `json:"hook_address"`
+	TransactionHash string     `json:"transaction_hash"`
+
+	// Reward type specific parameters
+	LoyaltyScore uint64 `json:"loyalty_score,omitempty"` // Required for loyalty rewards
+	TierLevel    *uint8 `json:"tier_level,omitempty"`    // Required for tier rewards
+	// BaseAmount is the reward of a tier task before its multiplier. When set,
+	// Amount must be TierCalculations.calculateTierBonus of it.
+	BaseAmount *big.Int `json:"base_amount,omitempty"`
 }
 
 // RewardDistributionResult represents the result of processing a reward distribution task
 type RewardDistributionResult struct {
-	TaskID            string   `json:"task_id"`
-	Success           bool     `json:"success"`
-	DistributedAmount *big.Int `json:"distributed_amount"`
-	FeeAmount         *big.Int `json:"fee_amount"`
-	TargetChain       uint64   `json:"target_chain"`
-	TransactionHash   string   `json:"transaction_hash,omitempty"`
-	Error             string   `json:"error,omitempty"`
-	ProcessedAt       int64    `json:"processed_at"`
+	TaskID            string          `json:"task_id"`
+	Success           bool            `json:"success"`
+	DistributedAmount *big.Int        `json:"distributed_amount"`
+	FeeAmount         *big.Int        `json:"fee_amount"`
+	FeeBreakdown      *fees.Breakdown `json:"fee_breakdown,omitempty"`
+	FeeSplit          *fees.Split     `json:"fee_split,omitempty"`
+	MEVSplit          *fees.Split     `json:"mev_split,omitempty"`
+	TierMultiplier    uint64          `json:"tier_multiplier,omitempty"` // Applied or verified multiplier of tier tasks, in hundredths
+	TargetChain       uint64          `json:"target_chain"`
+	RoutingReason     RoutingReason   `json:"routing_reason,omitempty"`
+	TransactionHash   string          `json:"transaction_hash,omitempty"`
+	// Bridge is the bridge the distribution was sent through, unset when simulated
+	Bridge     *BridgeRoute    `json:"bridge,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	ErrorCode  ResultErrorCode `json:"error_code,omitempty"`
+	ResultHash string          `json:"result_hash,omitempty"` // keccak256 of the canonical ABI result
+	// Deferred is set when the distribution waits for cheaper gas, with ErrorCode ResultErrorDeferred
+	Deferred *Deferral `json:"deferred,omitempty"`
+	// Mode is the execution mode the task ran in. Dry runs and shadows build
+	// the same result as a live operator without sending the distribution.
+	Mode ExecutionMode `json:"mode"`
+
+	// ProcessedAt is wall-clock time and is kept out of the serialized result
+	// so that every operator produces identical bytes for the same task
+	ProcessedAt int64 `json:"-"`
+
+	// Recipients holds per-recipient outcomes for batch tasks
+	Recipients []RecipientDistributionResult `json:"recipients,omitempty"`
+
+	// resumed holds the batch recipients an earlier delivery settled, which
+	// its statistics already counted
+	resumed *resumedShares
+}
+
+// WithIdempotencyStore sets the store used to detect and answer duplicate tasks
+func WithIdempotencyStore(store idempotency.Store) WorkerOption {
+	return func(rf *RewardFlowTaskWorker) {
+		rf.processed = store
+	}
 }
 
 // NewRewardFlowTaskWorker creates a new RewardFlow task worker
-func NewRewardFlowTaskWorker(logger *zap.Logger) *RewardFlowTaskWorker {
-	return &RewardFlowTaskWorker{
-		logger: logger,
-		stats: &TaskStats{
-			TotalRewardsDistributed: big.NewInt(0),
-			TotalMEVCaptured:        big.NewInt(0),
-		},
+func NewRewardFlowTaskWorker(logger *zap.Logger, opts ...WorkerOption) *RewardFlowTaskWorker {
+	rf := &RewardFlowTaskWorker{
+		logger:        logger,
+		stats:         stats.NewEngine(stats.DefaultWindow),
+		taskLocks:     newKeyedMutex(),
+		gate:          newTaskGate(),
+		distributions: scheduler.NewHistory(),
+	}
+	// Start from the built-in configuration so options only override what they set
+	defaults, err := WithConfig(config.Default())
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in configuration: %v", err))
 	}
+	defaults(rf)
+	for _, opt := range opts {
+		opt(rf)
+	}
+	return rf
 }
 
 // ValidateTask validates incoming reward distribution task requests
@@ -70,16 +165,28 @@ func (rf *RewardFlowTaskWorker) ValidateTask(t *performerV1.TaskRequest) error {
 		zap.String("task_type", "reward_distribution"),
 	)
 
+	// Batch tasks carry their own validation rules
+	if DetectPayloadFormat(t.Payload).IsBatch() {
+		if err := rf.validateBatchTask(t); err != nil {
+			rf.recordValidationFailure(err)
+			return err
+		}
+		return nil
+	}
+
 	// Parse the task data
-	var task RewardDistributionTask
-	if err := json.Unmarshal(t.Payload, &task); err != nil {
-		rf.logger.Error("Failed to unmarshal task data", zap.Error(err))
-		return fmt.Errorf("invalid task data format: %w", err)
+	task, err := rf.decodeTask(t)
+	if err != nil {
+		rf.logger.Error("Failed to decode task data", zap.Error(err))
+		err = invalid(errInvalidPayload, "invalid task data format: %w", err)
+		rf.recordValidationFailure(err)
+		return err
 	}
 
 	// Validate task parameters
-	if err := rf.validateTaskParameters(&task); err != nil {
+	if err := rf.validateTaskParameters(task); err != nil {
 		rf.logger.Error("Task validation failed", zap.Error(err))
+		rf.recordValidationFailure(err)
 		return err
 	}
 
@@ -87,7 +194,7 @@ func (rf *RewardFlowTaskWorker) ValidateTask(t *performerV1.TaskRequest) error {
 		zap.String("user", task.User),
 		zap.String("amount", task.Amount.String()),
 		zap.Uint64("chain_id", task.ChainID),
-		zap.String("reward_type", task.RewardType),
+		zap.String("reward_type", string(task.RewardType)),
 	)
 
 	return nil
@@ -95,6 +202,12 @@ func (rf *RewardFlowTaskWorker) ValidateTask(t *performerV1.TaskRequest) error {
 
 // HandleTask processes reward distribution tasks and returns results
 func (rf *RewardFlowTaskWorker) HandleTask(t *performerV1.TaskRequest) (*performerV1.TaskResponse, error) {
+	// Refuse new work once draining so the in-flight tasks can finish
+	if !rf.gate.enter() {
+		return nil, errShuttingDown
+	}
+	defer rf.gate.leave()
+
 	startTime := time.Now()
 
 	rf.logger.Sugar().Infow("Processing RewardFlow task",
@@ -102,36 +215,112 @@ func (rf *RewardFlowTaskWorker) HandleTask(t *performerV1.TaskRequest) (*perform
 		zap.String("task_type", "reward_distribution"),
 	)
 
-	// Parse the task data
-	var task RewardDistributionTask
-	if err := json.Unmarshal(t.Payload, &task); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal task data: %w", err)
+	// Decode the task and derive its source event key for deduplication
+	var (
+		process     func() (*RewardDistributionResult, error)
+		sourceKey   string
+		observation stats.Observation
+		// recipient is the user or pool whose last distribution feeds the priority
+		recipient string
+		priority  *big.Int
+		// settled holds the recipient outcomes of an earlier delivery of a partly failed batch
+		settled []RecipientDistributionResult
+	)
+	if DetectPayloadFormat(t.Payload).IsBatch() {
+		task, _, err := DecodeBatchTaskPayload(t.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode batch task data: %w", err)
+		}
+		sourceKey = batchSourceKey(task)
+		recipient = task.PoolID
+		priority = rf.batchPriority(task, startTime)
+		observation = stats.Observation{RewardType: string(task.RewardType), SourceChain: task.ChainID}
+		if task.RewardType.CapturesMEV() {
+			observation.MEVCaptured = task.TotalAmount
+		}
+		process = func() (*RewardDistributionResult, error) {
+			return rf.processBatchRewardDistribution(string(t.TaskId), task, settled)
+		}
+	} else {
+		task, err := rf.decodeTask(t)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode task data: %w", err)
+		}
+		sourceKey = idempotency.SourceKey(task.ChainID, task.TransactionHash, task.User, string(task.RewardType))
+		recipient = task.User
+		priority = rf.taskPriority(task, startTime)
+		observation = stats.Observation{RewardType: string(task.RewardType), SourceChain: task.ChainID}
+		if task.RewardType.CapturesMEV() {
+			observation.MEVCaptured = task.Amount
+		}
+		process = func() (*RewardDistributionResult, error) {
+			return rf.processRewardDistribution(string(t.TaskId), task)
+		}
+	}
+
+	// Serialize concurrent deliveries of the same task and return stored results for duplicates
+	unlock := rf.taskLocks.lock(string(t.TaskId), sourceKey)
+	defer unlock()
+
+	stored, settled, ok, err := rf.lookupProcessedTask(string(t.TaskId), sourceKey)
+	if err != nil {
+		rf.logger.Error("Failed to check for a processed task", zap.String("task_id", string(t.TaskId)), zap.Error(err))
+		return nil, err
+	}
+	if ok {
+		return &performerV1.TaskResponse{
+			TaskId: t.TaskId,
+			Result: stored,
+		}, nil
 	}
 
+	// Wait for the scheduler to run the distribution, highest priority first
+	release, waited, err := rf.acquireSlot(string(t.TaskId), priority)
+	if err != nil {
+		rf.logger.Warn("Reward distribution rejected", zap.String("task_id", string(t.TaskId)), zap.Error(err))
+		return nil, err
+	}
+	observation.QueueWait = waited
+
 	// Process the reward distribution
-	result, err := rf.processRewardDistribution(string(t.TaskId), &task)
+	result, err := process()
+	release()
 	if err != nil {
 		rf.logger.Error("Failed to process reward distribution", zap.Error(err))
 		result = &RewardDistributionResult{
 			TaskID:      string(t.TaskId),
 			Success:     false,
 			Error:       err.Error(),
+			ErrorCode:   ResultErrorDistributionFailed,
 			ProcessedAt: time.Now().Unix(),
 		}
 	}
+	result.Mode = rf.mode
+
+	if result.Success {
+		rf.distributions.Mark(recipient, time.Now())
+	}
+
+	// Split the collected fee and captured MEV between their beneficiaries
+	rf.splitRevenue(result, observation.MEVCaptured)
 
 	// Update statistics
-	rf.updateStats(result, time.Since(startTime))
+	rf.updateStats(result, observation, time.Since(startTime))
 
-	// Marshal the result
-	resultBytes, err := json.Marshal(result)
+	// Encode the result
+	resultBytes, err := rf.encodeResult(result)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal result: %w", err)
+		return nil, fmt.Errorf("failed to encode result: %w", err)
 	}
 
+	rf.recordProcessedTask(string(t.TaskId), sourceKey, result, resultBytes)
+	rf.compareWithLive(result)
+
 	rf.logger.Sugar().Infow("Task processing completed",
 		zap.String("task_id", string(t.TaskId)),
 		zap.Bool("success", result.Success),
+		zap.String("result_hash", result.ResultHash),
+		zap.String("mode", string(result.Mode)),
 		zap.Duration("processing_time", time.Since(startTime)),
 	)
 
@@ -141,54 +330,102 @@ func (rf *RewardFlowTaskWorker) HandleTask(t *performerV1.TaskRequest) (*perform
 	}, nil
 }
 
+// validateBatchTask decodes and validates a multi-recipient batch task
+func (rf *RewardFlowTaskWorker) validateBatchTask(t *performerV1.TaskRequest) error {
+	task, format, err := DecodeBatchTaskPayload(t.Payload)
+	if err != nil {
+		rf.logger.Error("Failed to decode batch task data", zap.Error(err))
+		return invalid(errInvalidPayload, "invalid task data format: %w", err)
+	}
+
+	if err := rf.validateBatchTaskParameters(task); err != nil {
+		rf.logger.Error("Batch task validation failed", zap.Error(err))
+		return err
+	}
+
+	rf.logger.Sugar().Infow("Batch task validation successful",
+		zap.String("pool_id", task.PoolID),
+		zap.Int("recipients", len(task.Recipients)),
+		zap.String("total_amount", task.TotalAmount.String()),
+		zap.Uint64("target_chain", task.TargetChain),
+		zap.String("format", format.String()),
+	)
+
+	return nil
+}
+
+// decodeTask decodes the task payload in whichever format it was submitted.
+// The on-chain ABI layout carries no timestamp, so such tasks are stamped on receipt.
+func (rf *RewardFlowTaskWorker) decodeTask(t *performerV1.TaskRequest) (*RewardDistributionTask, error) {
+	task, format, err := DecodeTaskPayload(t.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	// The basic layout carries no timestamp, so the task counts its age, and any
+	// gas deferral, from the first delivery of its task ID
+	if format == PayloadFormatABI && task.Timestamp == 0 {
+		task.Timestamp = rf.firstSeen(string(t.TaskId)).Unix()
+	}
+
+	rf.logger.Debug("Decoded task payload",
+		zap.String("task_id", string(t.TaskId)),
+		zap.String("format", format.String()),
+	)
+
+	return task, nil
+}
+
 // validateTaskParameters validates the parameters of a reward distribution task
 func (rf *RewardFlowTaskWorker) validateTaskParameters(task *RewardDistributionTask) error {
 	// Validate user address
 	if task.User == "" {
-		return fmt.Errorf("user address is required")
+		return errMissingUser
 	}
 
 	// Validate amount
 	if task.Amount == nil || task.Amount.Cmp(big.NewInt(0)) <= 0 {
-		return fmt.Errorf("invalid reward amount")
+		return errInvalidAmount
 	}
 
-	// Validate minimum reward amount (0.001 ETH)
-	minReward := big.NewInt(1000000000000000) // 0.001 ETH in wei
-	if task.Amount.Cmp(minReward) < 0 {
-		return fmt.Errorf("reward amount below minimum threshold")
+	// Validate minimum reward amount
+	limits := rf.policy.Limits()
+	if task.Amount.Cmp(limits.MinRewardAmount) < 0 {
+		return errAmountBelowMinimum
 	}
 
-	// Validate maximum reward amount (100 ETH)
-	maxReward := new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18))
-	if task.Amount.Cmp(maxReward) > 0 {
-		return fmt.Errorf("reward amount exceeds maximum threshold")
+	// Validate maximum reward amount
+	if task.Amount.Cmp(limits.MaxRewardAmount) > 0 {
+		return errAmountAboveMaximum
 	}
 
 	// Validate chain ID
 	if task.ChainID == 0 {
-		return fmt.Errorf("chain ID is required")
+		return errMissingChain
+	}
+	if err := rf.chains.Check(task.ChainID); err != nil {
+		return invalid(errUnsupportedChain, "source %w", err)
 	}
 
-	// Validate reward type
-	validRewardTypes := map[string]bool{
-		"liquidity": true,
-		"swap":      true,
-		"mev":       true,
+	// Validate reward type and its type-specific parameters
+	if err := validateRewardType(task); err != nil {
+		return err
 	}
-	if !validRewardTypes[task.RewardType] {
-		return fmt.Errorf("invalid reward type: %s", task.RewardType)
+	if err := rf.verifyLoyaltyScore(task); err != nil {
+		return err
+	}
+	if _, _, err := rf.tierAmount(task); err != nil {
+		return err
 	}
 
 	// Validate timestamp
 	if task.Timestamp <= 0 {
-		return fmt.Errorf("invalid timestamp")
+		return errInvalidTimestamp
 	}
 
-	// Validate timestamp is not too old (max 24 hours)
-	maxAge := int64(24 * 60 * 60) // 24 hours in seconds
-	if time.Now().Unix()-task.Timestamp > maxAge {
-		return fmt.Errorf("task timestamp too old")
+	// Validate timestamp is not too old
+	if rf.taskTooOld(task.Timestamp) {
+		return errStaleTimestamp
 	}
 
 	return nil
@@ -200,28 +437,71 @@ func (rf *RewardFlowTaskWorker) processRewardDistribution(taskID string, task *R
 		zap.String("task_id", taskID),
 		zap.String("user", task.User),
 		zap.String("amount", task.Amount.String()),
-		zap.String("reward_type", task.RewardType),
+		zap.String("reward_type", string(task.RewardType)),
 	)
 
-	// Calculate fee (0.1% of reward amount)
-	feeRate := big.NewInt(1) // 0.1% = 1/1000
-	feeAmount := new(big.Int).Div(new(big.Int).Mul(task.Amount, feeRate), big.NewInt(1000))
-
-	// Calculate distributed amount (reward - fee)
-	distributedAmount := new(big.Int).Sub(task.Amount, feeAmount)
-
-	// Simulate cross-chain distribution
-	// In a real implementation, this would interact with the Across Protocol or other bridge
-	targetChain := rf.determineTargetChain(task.ChainID, task.User)
+	// Apply the tier multiplier of tier tasks
+	amount, tierMultiplier, err := rf.tierAmount(task)
+	if err != nil {
+		return nil, err
+	}
 
-	// Simulate processing delay
-	time.Sleep(100 * time.Millisecond)
+	targetChain, routingReason := rf.determineTargetChain(task.ChainID, task.User, amount)
 
-	// Update statistics
-	rf.stats.TotalRewardsDistributed.Add(rf.stats.TotalRewardsDistributed, distributedAmount)
+	// Calculate fee for the target chain
+	breakdown, err := rf.fees.Calculate(amount, targetChain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate fee: %w", err)
+	}
+	feeAmount := breakdown.Total()
+
+	// Wait for cheaper gas when it is expensive or would eat the reward
+	if deferral := rf.deferDistribution(task, amount, feeAmount); deferral != nil {
+		rf.logger.Sugar().Infow("Reward distribution deferred",
+			zap.String("task_id", taskID),
+			zap.String("reason", string(deferral.Reason)),
+			zap.Int64("until", deferral.Until),
+			zap.String("gas_price", deferral.GasPrice.String()),
+		)
+		return &RewardDistributionResult{
+			TaskID:        taskID,
+			Success:       false,
+			ErrorCode:     ResultErrorDeferred,
+			TargetChain:   targetChain,
+			RoutingReason: routingReason,
+			Deferred:      deferral,
+			ProcessedAt:   time.Now().Unix(),
+		}, nil
+	}
 
-	if task.RewardType == "mev" {
-		rf.stats.TotalMEVCaptured.Add(rf.stats.TotalMEVCaptured, task.Amount)
+	// Calculate distributed amount (reward - fee)
+	distributedAmount := new(big.Int).Sub(amount, feeAmount)
+
+	// Send the distribution through the cheapest healthy bridge, or simulate it
+	var transactionHash string
+	var route *BridgeRoute
+	if rf.bridges != nil {
+		deposit, err := rf.deposit(task.User, task.ChainID, targetChain, distributedAmount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to bridge distribution: %w", err)
+		}
+		transactionHash = deposit.TransactionHash.Hex()
+		route = &BridgeRoute{Name: deposit.Bridge, Fee: deposit.Fee}
+		message := "Bridge deposit sent"
+		if !rf.mode.Sends() {
+			message = "Bridge deposit built, not sent"
+		}
+		rf.logger.Sugar().Infow(message,
+			zap.String("task_id", taskID),
+			zap.String("bridge", deposit.Bridge),
+			zap.Uint64("source_chain", task.ChainID),
+			zap.Uint64("target_chain", targetChain),
+			zap.String("transaction_hash", transactionHash),
+		)
+		rf.trackTransfer(taskID, deposit)
+	} else {
+		// Simulate processing delay
+		time.Sleep(100 * time.Millisecond)
 	}
 
 	result := &RewardDistributionResult{
@@ -229,7 +509,12 @@ func (rf *RewardFlowTaskWorker) processRewardDistribution(taskID string, task *R
 		Success:           true,
 		DistributedAmount: distributedAmount,
 		FeeAmount:         feeAmount,
+		FeeBreakdown:      &breakdown,
 		TargetChain:       targetChain,
+		RoutingReason:     routingReason,
+		TierMultiplier:    tierMultiplier,
+		TransactionHash:   transactionHash,
+		Bridge:            route,
 		ProcessedAt:       time.Now().Unix(),
 	}
 
@@ -238,90 +523,105 @@ func (rf *RewardFlowTaskWorker) processRewardDistribution(taskID string, task *R
 		zap.String("distributed_amount", distributedAmount.String()),
 		zap.String("fee_amount", feeAmount.String()),
 		zap.Uint64("target_chain", targetChain),
+		zap.String("routing_reason", string(routingReason)),
 	)
 
+	rf.recordActivity(task)
+
 	return result, nil
 }
 
-// determineTargetChain determines the target chain for reward distribution
-func (rf *RewardFlowTaskWorker) determineTargetChain(sourceChainID uint64, user string) uint64 {
-	// Simple logic: distribute to a different chain based on user hash
-	// In a real implementation, this would use user preferences or other logic
-
-	// Map of supported chains
-	supportedChains := []uint64{
-		1,     // Ethereum Mainnet
-		10,    // Optimism
-		42161, // Arbitrum
-		137,   // Polygon
-		8453,  // Base
+// tierAmount returns the amount to distribute for a task and, for tier tasks,
+// their multiplier. Tier tasks with a base amount were verified on validation;
+// without one, the apply tier mode multiplies the task amount, which must stay
+// within the maximum reward amount once multiplied.
+func (rf *RewardFlowTaskWorker) tierAmount(task *RewardDistributionTask) (*big.Int, uint64, error) {
+	if task.RewardType != RewardTypeTier || task.TierLevel == nil {
+		return task.Amount, 0, nil
 	}
-
-	// Use user address to deterministically select target chain
-	userHash := len(user) % len(supportedChains)
-	targetChain := supportedChains[userHash]
-
-	// If target chain is the same as source, pick the next one
-	if targetChain == sourceChainID {
-		targetChain = supportedChains[(userHash+1)%len(supportedChains)]
+	level := tier.Level(*task.TierLevel)
+	multiplier := tier.GetTierMultiplier(level)
+	if task.BaseAmount != nil || rf.rewards.TierMode != config.TierModeApply {
+		return task.Amount, multiplier, nil
 	}
-
-	return targetChain
-}
-
-// updateStats updates task processing statistics
-func (rf *RewardFlowTaskWorker) updateStats(result *RewardDistributionResult, processingTime time.Duration) {
-	rf.stats.TotalTasksProcessed++
-	rf.stats.AverageProcessingTime = (rf.stats.AverageProcessingTime + processingTime.Milliseconds()) / 2
-
-	// Calculate success rate
-	if result.Success {
-		rf.stats.SuccessRate = float64(rf.stats.TotalTasksProcessed) / float64(rf.stats.TotalTasksProcessed) * 100
+	amount, err := tier.CalculateTierBonus(task.Amount, level)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to apply tier multiplier: %w", err)
+	}
+	if amount.Cmp(rf.policy.Limits().MaxRewardAmount) > 0 {
+		return nil, 0, invalid(errAmountAboveMaximum, "tier amount %s exceeds maximum threshold", amount)
 	}
+	return amount, multiplier, nil
 }
 
-// GetStats returns current task processing statistics
-func (rf *RewardFlowTaskWorker) GetStats() *TaskStats {
-	return rf.stats
+// updateStats records the outcome of a processed task. Outside live mode no
+// funds move, so only the task counts and latencies are recorded.
+func (rf *RewardFlowTaskWorker) updateStats(result *RewardDistributionResult, observation stats.Observation, processingTime time.Duration) {
+	observation.Success = result.Success
+	observation.Deferred = result.ErrorCode == ResultErrorDeferred
+	observation.Latency = processingTime
+	observation.TargetChain = result.TargetChain
+	observation.Distributed = result.DistributedAmount
+	fee := result.FeeAmount
+	if result.resumed != nil {
+		observation.Distributed = new(big.Int).Sub(observation.Distributed, result.resumed.distributed)
+		fee = new(big.Int).Sub(fee, result.resumed.fee)
+	}
+	if !result.Success {
+		// Nothing is captured from a failed distribution
+		observation.MEVCaptured = nil
+	}
+	if result.FeeSplit != nil {
+		observation.FeeSplit = result.FeeSplit.ByBeneficiary()
+	}
+	if result.MEVSplit != nil {
+		observation.MEVSplit = result.MEVSplit.ByBeneficiary()
+	}
+	if !rf.mode.Sends() {
+		observation.Distributed = nil
+		observation.MEVCaptured = nil
+		observation.FeeSplit = nil
+		observation.MEVSplit = nil
+		fee = nil
+	}
+	rf.stats.Record(observation)
+
+	rf.metrics.ObserveTask(metrics.TaskObservation{
+		RewardType:  observation.RewardType,
+		TargetChain: result.TargetChain,
+		Success:     result.Success,
+		Deferred:    observation.Deferred,
+		Duration:    processingTime,
+		Distributed: observation.Distributed,
+		Fee:         fee,
+		MEVCaptured: observation.MEVCaptured,
+	})
 }
 
-func main() {
-	ctx := context.Background()
-
-	// Initialize logger with RewardFlow-specific configuration
-	config := zap.NewProductionConfig()
-	config.EncoderConfig.TimeKey = "timestamp"
-	config.EncoderConfig.EncodeTime = zap.TimeEncoderOfLayout(time.RFC3339)
-
-	l, err := config.Build()
-	if err != nil {
-		panic(fmt.Errorf("failed to create logger: %w", err))
+// splitRevenue divides the fee collected by a successful task, and the MEV it
+// captured, between the fee split beneficiaries
+func (rf *RewardFlowTaskWorker) splitRevenue(result *RewardDistributionResult, mevCaptured *big.Int) {
+	if !result.Success || rf.splitter == nil {
+		return
 	}
-	defer l.Sync()
-
-	l.Info("Starting RewardFlow AVS Performer",
-		zap.String("version", "1.0.0"),
-		zap.String("description", "Uniswap V4 Hook Reward Distribution AVS"),
-	)
-
-	// Create RewardFlow task worker
-	w := NewRewardFlowTaskWorker(l)
-
-	// Start the performer server
-	pp, err := server.NewPonosPerformerWithRpcServer(&server.PonosPerformerConfig{
-		Port:    8080,
-		Timeout: 30 * time.Second, // Increased timeout for cross-chain operations
-	}, w, l)
-	if err != nil {
-		panic(fmt.Errorf("failed to create RewardFlow performer: %w", err))
+	feeSplit := rf.splitter.Split(result.FeeAmount)
+	result.FeeSplit = &feeSplit
+	if mevCaptured != nil {
+		mevSplit := rf.splitter.Split(mevCaptured)
+		result.MEVSplit = &mevSplit
 	}
+}
 
-	l.Info("RewardFlow AVS Performer started successfully",
-		zap.Int("port", 8080),
-		zap.Duration("timeout", 30*time.Second),
-	)
+// GetStats returns a snapshot of the task processing statistics
+func (rf *RewardFlowTaskWorker) GetStats() stats.Snapshot {
+	snapshot := rf.stats.Snapshot()
+	rf.queueStats(&snapshot.Queue)
+	return snapshot
+}
 
-	if err := pp.Start(ctx); err != nil {
-		panic(err)
+func main() {
+	if err := newApp().Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 }
@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/json"
 	"math/big"
+	"strings"
 	"testing"
 	"time"
 
@@ -177,7 +178,8 @@ func TestRewardFlowTaskWorker_ValidateTask(t *testing.T) {
 			if tt.expectError {
 				if err == nil {
 					t.Errorf("Expected error but got none")
-				} else if tt.errorMsg != "" && err.Error() != tt.errorMsg {
+				} else if tt.errorMsg != "" && err.Error() != tt.errorMsg && !strings.HasPrefix(err.Error(), tt.errorMsg+": ") {
+					// Errors may name the offending value after the message, as in "invalid reward type: invalid"
 					t.Errorf("Expected error message '%s', got '%s'", tt.errorMsg, err.Error())
 				}
 			} else {
@@ -314,6 +316,18 @@ func TestRewardFlowTaskWorker_Statistics(t *testing.T) {
 	if stats.TotalRewardsDistributed.Cmp(big.NewInt(0)) <= 0 {
 		t.Errorf("Expected positive rewards distributed, got %v", stats.TotalRewardsDistributed)
 	}
+
+	if stats.SuccessRate != 100 {
+		t.Errorf("Expected success rate 100, got %v", stats.SuccessRate)
+	}
+
+	if b := stats.ByRewardType[string(RewardTypeLiquidity)]; b.Tasks != 1 {
+		t.Errorf("Expected 1 liquidity task, got %d", b.Tasks)
+	}
+
+	if b := stats.BySourceChain[task.ChainID]; b.Tasks != 1 {
+		t.Errorf("Expected 1 task from chain %d, got %d", task.ChainID, b.Tasks)
+	}
 }
 
 func TestRewardFlowTaskWorker_MEVRewards(t *testing.T) {
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/metrics"
+)
+
+// Validation failure reasons exported as the reason label of
+// rewardflow_validation_failures_total. These values are stable.
+const (
+	reasonInvalidPayload     = "invalid_payload"
+	reasonMissingUser        = "missing_user"
+	reasonInvalidAmount      = "invalid_amount"
+	reasonAmountBelowMinimum = "amount_below_minimum"
+	reasonAmountAboveMaximum = "amount_above_maximum"
+	reasonMissingChain       = "missing_chain"
+	reasonUnsupportedChain   = "unsupported_chain"
+	reasonInvalidRewardType  = "invalid_reward_type"
+	reasonRewardParameters   = "invalid_reward_parameters"
+	reasonInvalidTimestamp   = "invalid_timestamp"
+	reasonStaleTimestamp     = "stale_timestamp"
+	reasonInvalidBatch       = "invalid_batch"
+	reasonOther              = "other"
+)
+
+// Validation errors, one for each reason label. Validation returns them, or
+// wraps them with the detail of the failure, so that failures are classified
+// with errors.Is rather than by their message.
+var (
+	errInvalidPayload     = errors.New("invalid task data format")
+	errMissingUser        = errors.New("user address is required")
+	errInvalidAmount      = errors.New("invalid reward amount")
+	errAmountBelowMinimum = errors.New("reward amount below minimum threshold")
+	errAmountAboveMaximum = errors.New("reward amount exceeds maximum threshold")
+	errMissingChain       = errors.New("chain ID is required")
+	errUnsupportedChain   = errors.New("unsupported chain")
+	errInvalidRewardType  = errors.New("invalid reward type")
+	errRewardParameters   = errors.New("invalid reward parameters")
+	errInvalidTimestamp   = errors.New("invalid timestamp")
+	errStaleTimestamp     = errors.New("task timestamp too old")
+	errInvalidBatch       = errors.New("invalid batch")
+)
+
+// validationError is a validation failure of one kind with its own message
+type validationError struct {
+	kind error
+	msg  string
+	err  error
+}
+
+func (e *validationError) Error() string { return e.msg }
+
+// Is reports whether target is the kind of the failure
+func (e *validationError) Is(target error) bool { return target == e.kind }
+
+// Unwrap returns the error the message wraps, if any
+func (e *validationError) Unwrap() error { return e.err }
+
+// invalid returns a validation failure of kind with a formatted message,
+// which wraps the error of a %w verb like fmt.Errorf
+func invalid(kind error, format string, args ...any) error {
+	err := fmt.Errorf(format, args...)
+	return &validationError{kind: kind, msg: err.Error(), err: errors.Unwrap(err)}
+}
+
+// validationFailureReasons maps validation errors onto reason labels
+var validationFailureReasons = []struct {
+	kind   error
+	reason string
+}{
+	{kind: errInvalidPayload, reason: reasonInvalidPayload},
+	{kind: errMissingUser, reason: reasonMissingUser},
+	{kind: errInvalidAmount, reason: reasonInvalidAmount},
+	{kind: errAmountBelowMinimum, reason: reasonAmountBelowMinimum},
+	{kind: errAmountAboveMaximum, reason: reasonAmountAboveMaximum},
+	{kind: errMissingChain, reason: reasonMissingChain},
+	{kind: errUnsupportedChain, reason: reasonUnsupportedChain},
+	{kind: errInvalidRewardType, reason: reasonInvalidRewardType},
+	{kind: errRewardParameters, reason: reasonRewardParameters},
+	{kind: errInvalidTimestamp, reason: reasonInvalidTimestamp},
+	{kind: errStaleTimestamp, reason: reasonStaleTimestamp},
+	{kind: errInvalidBatch, reason: reasonInvalidBatch},
+}
+
+// validationFailureReason classifies a validation error into a low-cardinality reason label
+func validationFailureReason(err error) string {
+	for _, r := range validationFailureReasons {
+		if errors.Is(err, r.kind) {
+			return r.reason
+		}
+	}
+	return reasonOther
+}
+
+// WithMetrics sets the Prometheus metrics the worker reports to
+func WithMetrics(m *metrics.Metrics) WorkerOption {
+	return func(rf *RewardFlowTaskWorker) {
+		rf.metrics = m
+	}
+}
+
+// recordValidationFailure reports a rejected task to the metrics
+func (rf *RewardFlowTaskWorker) recordValidationFailure(err error) {
+	rf.metrics.ValidationFailed(validationFailureReason(err))
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+func TestValidationFailureReason(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	worker := NewRewardFlowTaskWorker(logger)
+
+	validate := func(change func(task *RewardDistributionTask)) error {
+		task := newCLITask()
+		change(&task)
+		return worker.validateTaskParameters(&task)
+	}
+	validateBatch := func(change func(task *BatchRewardDistributionTask)) error {
+		task := newBatchTask()
+		change(&task)
+		return worker.validateBatchTaskParameters(&task)
+	}
+
+	tests := []struct {
+		name   string
+		err    error
+		reason string
+	}{
+		{name: "undecodable payload", err: worker.ValidateTask(&performerV1.TaskRequest{Payload: []byte("{")}), reason: reasonInvalidPayload},
+		{name: "missing user", err: validate(func(task *RewardDistributionTask) { task.User = "" }), reason: reasonMissingUser},
+		{name: "zero amount", err: validate(func(task *RewardDistributionTask) { task.Amount = new(big.Int) }), reason: reasonInvalidAmount},
+		{name: "zero batch amount", err: validateBatch(func(task *BatchRewardDistributionTask) { task.Amounts[2] = new(big.Int) }), reason: reasonInvalidAmount},
+		{name: "below minimum", err: validate(func(task *RewardDistributionTask) { task.Amount = big.NewInt(1) }), reason: reasonAmountBelowMinimum},
+		{name: "above maximum", err: validate(func(task *RewardDistributionTask) { task.Amount = new(big.Int).Lsh(big.NewInt(1), 128) }), reason: reasonAmountAboveMaximum},
+		{name: "missing chain", err: validate(func(task *RewardDistributionTask) { task.ChainID = 0 }), reason: reasonMissingChain},
+		{name: "unsupported source chain", err: validate(func(task *RewardDistributionTask) { task.ChainID = 56 }), reason: reasonUnsupportedChain},
+		{name: "unsupported target chain", err: validateBatch(func(task *BatchRewardDistributionTask) { task.TargetChain = 56 }), reason: reasonUnsupportedChain},
+		{name: "unknown reward type", err: validate(func(task *RewardDistributionTask) { task.RewardType = "invalid" }), reason: reasonInvalidRewardType},
+		{name: "unbatchable reward type", err: validateBatch(func(task *BatchRewardDistributionTask) { task.RewardType = RewardTypeLoyalty }), reason: reasonInvalidRewardType},
+		{name: "loyalty score too high", err: validate(func(task *RewardDistributionTask) { task.RewardType, task.LoyaltyScore = RewardTypeLoyalty, 101 }), reason: reasonRewardParameters},
+		{name: "missing pool ID", err: validate(func(task *RewardDistributionTask) { task.RewardType, task.PoolID = RewardTypeSwap, "" }), reason: reasonRewardParameters},
+		{name: "stale timestamp", err: validate(func(task *RewardDistributionTask) { task.Timestamp = time.Now().Add(-48 * time.Hour).Unix() }), reason: reasonStaleTimestamp},
+		{name: "duplicate recipient", err: validateBatch(func(task *BatchRewardDistributionTask) { task.Recipients[1] = task.Recipients[0] }), reason: reasonInvalidBatch},
+		{name: "wrapped", err: fmt.Errorf("batch rejected: %w", errStaleTimestamp), reason: reasonStaleTimestamp},
+		// Errors are classified by kind, not by what their message says
+		{name: "message only", err: errors.New("user address is required"), reason: reasonOther},
+		{name: "unexpected", err: errors.New("something unexpected"), reason: reasonOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err == nil {
+				t.Fatalf("Expected a validation error")
+			}
+			if got := validationFailureReason(tt.err); got != tt.reason {
+				t.Errorf("Expected reason %s for %q, got %s", tt.reason, tt.err, got)
+			}
+		})
+	}
+}
+
+func TestRewardFlowTaskWorker_Metrics(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	m := metrics.New()
+	worker := NewRewardFlowTaskWorker(logger, WithMetrics(m))
+
+	task := RewardDistributionTask{
+		User:            "0x1234567890123456789012345678901234567890",
+		Amount:          big.NewInt(1000000000000000000), // 1 ETH
+		ChainID:         1,
+		PoolID:          "0xabcdef1234567890abcdef1234567890abcdef12",
+		RewardType:      RewardTypeLiquidity,
+		Timestamp:       time.Now().Add(-48 * time.Hour).Unix(),
+		HookAddress:     "0x9876543210987654321098765432109876543210",
+		TransactionHash: "0x1111111111111111111111111111111111111111111111111111111111111111",
+	}
+	staleData, err := json.Marshal(task)
+	if err != nil {
+		t.Fatalf("Failed to marshal task: %v", err)
+	}
+	if err := worker.ValidateTask(&performerV1.TaskRequest{TaskId: []byte("stale"), Payload: staleData}); err == nil {
+		t.Fatalf("Expected stale task to be rejected")
+	}
+
+	task.Timestamp = time.Now().Unix()
+	taskData, err := json.Marshal(task)
+	if err != nil {
+		t.Fatalf("Failed to marshal task: %v", err)
+	}
+	response, err := worker.HandleTask(&performerV1.TaskRequest{TaskId: []byte("fresh"), Payload: taskData})
+	if err != nil {
+		t.Fatalf("HandleTask failed: %v", err)
+	}
+	var result RewardDistributionResult
+	if err := json.Unmarshal(response.Result, &result); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", metrics.Path, nil))
+	body, _ := io.ReadAll(rec.Body)
+
+	for _, series := range []string{
+		`rewardflow_validation_failures_total{reason="stale_timestamp"} 1`,
+		`rewardflow_tasks_total{reward_type="liquidity",status="success"} 1`,
+		`rewardflow_fees_wei_total{target_chain="` + strconv.FormatUint(result.TargetChain, 10) + `"}`,
+	} {
+		if !strings.Contains(string(body), series) {
+			t.Errorf("Expected metrics to contain %q", series)
+		}
+	}
+}
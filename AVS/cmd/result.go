@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ResultEncoding selects how HandleTask serializes a RewardDistributionResult
+type ResultEncoding int
+
+const (
+	// ResultEncodingJSON returns the JSON encoding of RewardDistributionResult
+	ResultEncodingJSON ResultEncoding = iota
+	// ResultEncodingABI returns the canonical ABI encoding submitted on-chain
+	ResultEncodingABI
+)
+
+// String returns the human readable name of the result encoding
+func (e ResultEncoding) String() string {
+	switch e {
+	case ResultEncodingJSON:
+		return "json"
+	case ResultEncodingABI:
+		return "abi"
+	default:
+		return "unknown"
+	}
+}
+
+// parseResultEncoding resolves a result encoding by name
+func parseResultEncoding(s string) (ResultEncoding, error) {
+	for _, e := range []ResultEncoding{ResultEncodingJSON, ResultEncodingABI} {
+		if strings.EqualFold(s, e.String()) {
+			return e, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported result encoding: %s", s)
+}
+
+// ResultErrorCode classifies why a task result was not successful
+type ResultErrorCode uint8
+
+const (
+	// ResultErrorNone indicates a successful distribution
+	ResultErrorNone ResultErrorCode = iota
+	// ResultErrorInvalidPayload indicates the task payload could not be decoded
+	ResultErrorInvalidPayload
+	// ResultErrorValidationFailed indicates the task failed parameter validation
+	ResultErrorValidationFailed
+	// ResultErrorDistributionFailed indicates the distribution itself failed
+	ResultErrorDistributionFailed
+	// ResultErrorPartialFailure indicates some recipients of a batch task failed
+	ResultErrorPartialFailure
+	// ResultErrorDeferred indicates the distribution waits for cheaper gas and
+	// the task should be resubmitted
+	ResultErrorDeferred
+)
+
+// String returns the human readable name of the error code
+func (c ResultErrorCode) String() string {
+	switch c {
+	case ResultErrorNone:
+		return "none"
+	case ResultErrorInvalidPayload:
+		return "invalid_payload"
+	case ResultErrorValidationFailed:
+		return "validation_failed"
+	case ResultErrorDistributionFailed:
+		return "distribution_failed"
+	case ResultErrorPartialFailure:
+		return "partial_failure"
+	case ResultErrorDeferred:
+		return "deferred"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(c))
+	}
+}
+
+// taskResultArgs is the canonical on-chain result layout: abi.encode(bytes32 taskHash,
+// bool success, uint256 distributedAmount, uint256 feeAmount, uint256 targetChain, uint8 errorCode)
+var taskResultArgs = abi.Arguments{
+	{Name: "taskHash", Type: abiBytes32Type},
+	{Name: "success", Type: mustNewABIType("bool")},
+	{Name: "distributedAmount", Type: abiUint256Type},
+	{Name: "feeAmount", Type: abiUint256Type},
+	{Name: "targetChain", Type: abiUint256Type},
+	{Name: "errorCode", Type: abiUint8Type},
+}
+
+// TaskHashFromID derives the bytes32 task hash from a performer task ID.
+// Raw 32-byte IDs and 0x-prefixed 32-byte hex IDs are used as-is; any other ID is hashed.
+func TaskHashFromID(taskID []byte) common.Hash {
+	if len(taskID) == common.HashLength {
+		return common.BytesToHash(taskID)
+	}
+	if s := string(taskID); len(s) == 2+2*common.HashLength && strings.HasPrefix(s, "0x") {
+		if b, err := hex.DecodeString(s[2:]); err == nil {
+			return common.BytesToHash(b)
+		}
+	}
+	return crypto.Keccak256Hash(taskID)
+}
+
+// EncodeTaskResult produces the canonical ABI encoding of a task result.
+// Only consensus-relevant fields are encoded, so operators processing the same
+// task produce byte-identical output.
+func EncodeTaskResult(result *RewardDistributionResult) ([]byte, error) {
+	if result == nil {
+		return nil, fmt.Errorf("result is nil")
+	}
+
+	return taskResultArgs.Pack(
+		TaskHashFromID([]byte(result.TaskID)),
+		result.Success,
+		bigOrZero(result.DistributedAmount),
+		bigOrZero(result.FeeAmount),
+		new(big.Int).SetUint64(result.TargetChain),
+		uint8(result.ErrorCode),
+	)
+}
+
+// DecodeTaskResult decodes a canonical ABI task result. The returned TaskID is
+// the hex task hash, since the original ID is not recoverable from its hash.
+func DecodeTaskResult(data []byte) (*RewardDistributionResult, error) {
+	values, err := taskResultArgs.Unpack(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode task result: %w", err)
+	}
+
+	targetChain, err := uint64FromBig("target chain", values[4].(*big.Int))
+	if err != nil {
+		return nil, err
+	}
+	taskHash := values[0].([32]byte)
+
+	return &RewardDistributionResult{
+		TaskID:            common.Hash(taskHash).Hex(),
+		Success:           values[1].(bool),
+		DistributedAmount: values[2].(*big.Int),
+		FeeAmount:         values[3].(*big.Int),
+		TargetChain:       targetChain,
+		ErrorCode:         ResultErrorCode(values[5].(uint8)),
+	}, nil
+}
+
+// TaskResultDigest returns the keccak256 digest of an encoded result, which is what operators sign
+func TaskResultDigest(encoded []byte) common.Hash {
+	return crypto.Keccak256Hash(encoded)
+}
+
+// encodeResult serializes a result using the worker's configured encoding.
+// The digest of the canonical ABI encoding is recorded in the result either way.
+func (rf *RewardFlowTaskWorker) encodeResult(result *RewardDistributionResult) ([]byte, error) {
+	encoded, err := EncodeTaskResult(result)
+	if err != nil {
+		return nil, err
+	}
+	result.ResultHash = TaskResultDigest(encoded).Hex()
+
+	switch rf.resultEncoding {
+	case ResultEncodingABI:
+		return encoded, nil
+	case ResultEncodingJSON:
+		return json.Marshal(result)
+	default:
+		return nil, fmt.Errorf("unsupported result encoding: %s", rf.resultEncoding)
+	}
+}
+
+func bigOrZero(v *big.Int) *big.Int {
+	if v == nil {
+		return new(big.Int)
+	}
+	return v
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"github.com/ethereum/go-ethereum/crypto"
+	"go.uber.org/zap"
+)
+
+func TestEncodeTaskResult_CanonicalLayout(t *testing.T) {
+	result := &RewardDistributionResult{
+		TaskID:            "0x" + strings.Repeat("aa", 32),
+		Success:           true,
+		DistributedAmount: big.NewInt(999000000000000000),
+		FeeAmount:         big.NewInt(1000000000000000),
+		TargetChain:       42161,
+		ErrorCode:         ResultErrorNone,
+		ProcessedAt:       time.Now().Unix(),
+	}
+
+	encoded, err := EncodeTaskResult(result)
+	if err != nil {
+		t.Fatalf("EncodeTaskResult failed: %v", err)
+	}
+
+	// abi.encode(bytes32(0xaa..aa), true, 0.999 ether, 0.001 ether, 42161, uint8(0))
+	expected := abiWords(t,
+		strings.Repeat("aa", 32),
+		"01",
+		"0ddd2935029d8000",
+		"038d7ea4c68000",
+		"a4b1",
+		"",
+	)
+	if !bytes.Equal(encoded, expected) {
+		t.Fatalf("Unexpected encoding:\nexpected %x\ngot      %x", expected, encoded)
+	}
+
+	if digest := TaskResultDigest(encoded); digest != crypto.Keccak256Hash(expected) {
+		t.Errorf("Digest mismatch: %s", digest.Hex())
+	}
+
+	decoded, err := DecodeTaskResult(encoded)
+	if err != nil {
+		t.Fatalf("DecodeTaskResult failed: %v", err)
+	}
+	if decoded.TaskID != result.TaskID || !decoded.Success || decoded.TargetChain != result.TargetChain {
+		t.Errorf("Unexpected decoded result: %+v", decoded)
+	}
+	if decoded.DistributedAmount.Cmp(result.DistributedAmount) != 0 || decoded.FeeAmount.Cmp(result.FeeAmount) != 0 {
+		t.Errorf("Unexpected decoded amounts: %v / %v", decoded.DistributedAmount, decoded.FeeAmount)
+	}
+}
+
+func TestTaskHashFromID(t *testing.T) {
+	raw := bytes.Repeat([]byte{0x42}, 32)
+	hexID := "0x" + strings.Repeat("42", 32)
+
+	if got := TaskHashFromID(raw); !bytes.Equal(got.Bytes(), raw) {
+		t.Errorf("Expected raw 32-byte ID to be used as-is, got %s", got.Hex())
+	}
+	if got := TaskHashFromID([]byte(hexID)); !bytes.Equal(got.Bytes(), raw) {
+		t.Errorf("Expected hex ID to be decoded, got %s", got.Hex())
+	}
+	if got := TaskHashFromID([]byte("task-1")); got != crypto.Keccak256Hash([]byte("task-1")) {
+		t.Errorf("Expected arbitrary ID to be hashed, got %s", got.Hex())
+	}
+}
+
+func TestRewardFlowTaskWorker_DeterministicResults(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	task := RewardDistributionTask{
+		User:            "0x1234567890123456789012345678901234567890",
+		Amount:          big.NewInt(1000000000000000000), // 1 ETH
+		ChainID:         1,
+		PoolID:          "0xabcdef1234567890abcdef1234567890abcdef12",
+		RewardType:      "liquidity",
+		Timestamp:       time.Now().Unix(),
+		HookAddress:     "0x9876543210987654321098765432109876543210",
+		TransactionHash: "0x1111111111111111111111111111111111111111111111111111111111111111",
+	}
+	taskData, err := json.Marshal(task)
+	if err != nil {
+		t.Fatalf("Failed to marshal task: %v", err)
+	}
+	taskRequest := &performerV1.TaskRequest{
+		TaskId:  []byte("0x" + strings.Repeat("ab", 32)),
+		Payload: taskData,
+	}
+
+	t.Run("abi", func(t *testing.T) {
+		operatorA := NewRewardFlowTaskWorker(logger, WithResultEncoding(ResultEncodingABI))
+		operatorB := NewRewardFlowTaskWorker(logger, WithResultEncoding(ResultEncodingABI))
+
+		responseA, err := operatorA.HandleTask(taskRequest)
+		if err != nil {
+			t.Fatalf("HandleTask failed: %v", err)
+		}
+		time.Sleep(time.Second) // Cross a wall-clock second boundary between operators
+		responseB, err := operatorB.HandleTask(taskRequest)
+		if err != nil {
+			t.Fatalf("HandleTask failed: %v", err)
+		}
+
+		if !bytes.Equal(responseA.Result, responseB.Result) {
+			t.Fatalf("Expected byte-identical results:\n%x\n%x", responseA.Result, responseB.Result)
+		}
+
+		result, err := DecodeTaskResult(responseA.Result)
+		if err != nil {
+			t.Fatalf("DecodeTaskResult failed: %v", err)
+		}
+		if result.TaskID != string(taskRequest.TaskId) {
+			t.Errorf("Expected task hash %s, got %s", string(taskRequest.TaskId), result.TaskID)
+		}
+		if !result.Success || result.ErrorCode != ResultErrorNone {
+			t.Errorf("Expected success, got %+v", result)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		worker := NewRewardFlowTaskWorker(logger)
+
+		response, err := worker.HandleTask(taskRequest)
+		if err != nil {
+			t.Fatalf("HandleTask failed: %v", err)
+		}
+
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(response.Result, &fields); err != nil {
+			t.Fatalf("Failed to unmarshal result: %v", err)
+		}
+		if _, ok := fields["processed_at"]; ok {
+			t.Errorf("Expected processed_at to be excluded from the serialized result")
+		}
+
+		var result RewardDistributionResult
+		if err := json.Unmarshal(response.Result, &result); err != nil {
+			t.Fatalf("Failed to unmarshal result: %v", err)
+		}
+		encoded, err := EncodeTaskResult(&result)
+		if err != nil {
+			t.Fatalf("EncodeTaskResult failed: %v", err)
+		}
+		if result.ResultHash != TaskResultDigest(encoded).Hex() {
+			t.Errorf("Expected result hash %s, got %s", TaskResultDigest(encoded).Hex(), result.ResultHash)
+		}
+	})
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/tier"
+)
+
+// RewardType identifies the kind of reward being distributed. Values are the
+// performer's canonical names; RewardFlowHook.RewardType ordinals map onto them.
+type RewardType string
+
+const (
+	RewardTypeLiquidity RewardType = "liquidity" // LIQUIDITY_PROVISION
+	RewardTypeSwap      RewardType = "swap"      // SWAP_VOLUME
+	RewardTypeLoyalty   RewardType = "loyalty"   // LOYALTY_BONUS
+	RewardTypeTier      RewardType = "tier"      // TIER_MULTIPLIER
+	RewardTypeMEV       RewardType = "mev"       // MEV_CAPTURE
+)
+
+// maxLoyaltyScore mirrors ActivityTracking.MAX_LOYALTY_SCORE
+const maxLoyaltyScore = 100
+
+// rewardTypeRule describes how a single reward type is decoded, validated and processed
+type rewardTypeRule struct {
+	// enum is the RewardFlowHook.RewardType ordinal
+	enum uint8
+	// solidityName is the RewardFlowHook.RewardType member name
+	solidityName string
+	// validate checks the task fields specific to this reward type
+	validate func(task *RewardDistributionTask) error
+	// batchable reports whether the type can be settled through a batch task,
+	// which carries no per-recipient reward parameters
+	batchable bool
+	// capturesMEV reports whether distributed amounts count towards MEV captured
+	capturesMEV bool
+}
+
+// rewardTypeRules holds the rules for every reward kind RewardFlowHook defines
+var rewardTypeRules = map[RewardType]rewardTypeRule{
+	RewardTypeLiquidity: {
+		enum:         0,
+		solidityName: "LIQUIDITY_PROVISION",
+		validate:     func(task *RewardDistributionTask) error { return nil },
+		batchable:    true,
+	},
+	RewardTypeSwap: {
+		enum:         1,
+		solidityName: "SWAP_VOLUME",
+		validate:     requirePoolID,
+		batchable:    true,
+	},
+	RewardTypeLoyalty: {
+		enum:         2,
+		solidityName: "LOYALTY_BONUS",
+		validate: func(task *RewardDistributionTask) error {
+			if task.LoyaltyScore == 0 {
+				return fmt.Errorf("loyalty score is required for loyalty rewards")
+			}
+			if task.LoyaltyScore > maxLoyaltyScore {
+				return fmt.Errorf("loyalty score %d exceeds maximum of %d", task.LoyaltyScore, maxLoyaltyScore)
+			}
+			return nil
+		},
+	},
+	RewardTypeTier: {
+		enum:         3,
+		solidityName: "TIER_MULTIPLIER",
+		validate: func(task *RewardDistributionTask) error {
+			if task.TierLevel == nil {
+				return fmt.Errorf("tier level is required for tier rewards")
+			}
+			if !tier.Level(*task.TierLevel).Valid() {
+				return fmt.Errorf("invalid tier level: %d", *task.TierLevel)
+			}
+			return verifyTierAmount(task)
+		},
+	},
+	RewardTypeMEV: {
+		enum:         4,
+		solidityName: "MEV_CAPTURE",
+		validate:     requirePoolID,
+		batchable:    true,
+		capturesMEV:  true,
+	},
+}
+
+// verifyTierAmount checks that a tier task carrying its base amount claims
+// TierCalculations.calculateTierBonus of it
+func verifyTierAmount(task *RewardDistributionTask) error {
+	if task.BaseAmount == nil {
+		return nil
+	}
+	level := tier.Level(*task.TierLevel)
+	expected, err := tier.CalculateTierBonus(task.BaseAmount, level)
+	if err != nil {
+		return err
+	}
+	if task.Amount == nil || task.Amount.Cmp(expected) != 0 {
+		return fmt.Errorf("tier amount %s does not match %s, base amount %s at %s (%d/%d)",
+			task.Amount, expected, task.BaseAmount, level, tier.GetTierMultiplier(level), tier.MultiplierDenominator)
+	}
+	return nil
+}
+
+func requirePoolID(task *RewardDistributionTask) error {
+	if task.PoolID == "" {
+		return fmt.Errorf("pool ID is required for %s rewards", task.RewardType)
+	}
+	return nil
+}
+
+// ParseRewardType resolves a reward type from its performer name, its Solidity
+// enum member name or its enum ordinal
+func ParseRewardType(s string) (RewardType, error) {
+	normalized := strings.TrimSpace(s)
+	if n, err := strconv.ParseUint(normalized, 10, 8); err == nil {
+		return RewardTypeFromEnum(uint8(n))
+	}
+
+	for rewardType, rule := range rewardTypeRules {
+		if strings.EqualFold(normalized, string(rewardType)) || strings.EqualFold(normalized, rule.solidityName) {
+			return rewardType, nil
+		}
+	}
+	return "", fmt.Errorf("invalid reward type: %s", s)
+}
+
+// RewardTypeFromEnum resolves a RewardFlowHook.RewardType ordinal
+func RewardTypeFromEnum(v uint8) (RewardType, error) {
+	for rewardType, rule := range rewardTypeRules {
+		if rule.enum == v {
+			return rewardType, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported reward type enum value: %d", v)
+}
+
+// Enum returns the RewardFlowHook.RewardType ordinal
+func (r RewardType) Enum() (uint8, error) {
+	rule, ok := rewardTypeRules[r]
+	if !ok {
+		return 0, fmt.Errorf("invalid reward type: %s", r)
+	}
+	return rule.enum, nil
+}
+
+// SolidityName returns the RewardFlowHook.RewardType member name
+func (r RewardType) SolidityName() string {
+	return rewardTypeRules[r].solidityName
+}
+
+// IsValid reports whether the reward type is known
+func (r RewardType) IsValid() bool {
+	_, ok := rewardTypeRules[r]
+	return ok
+}
+
+// CapturesMEV reports whether rewards of this type count towards MEV captured
+func (r RewardType) CapturesMEV() bool {
+	return rewardTypeRules[r].capturesMEV
+}
+
+// UnmarshalJSON accepts performer names, Solidity enum names and enum ordinals.
+// Unknown names are kept verbatim so that validation can reject them.
+func (r *RewardType) UnmarshalJSON(data []byte) error {
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	var s string
+	switch v := raw.(type) {
+	case string:
+		s = v
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	case nil:
+		*r = ""
+		return nil
+	default:
+		return fmt.Errorf("invalid reward type JSON: %s", string(data))
+	}
+
+	if parsed, err := ParseRewardType(s); err == nil {
+		*r = parsed
+	} else {
+		*r = RewardType(s)
+	}
+	return nil
+}
+
+// validateRewardType applies the validation rules of the task's reward type
+func validateRewardType(task *RewardDistributionTask) error {
+	rule, ok := rewardTypeRules[task.RewardType]
+	if !ok {
+		return invalid(errInvalidRewardType, "invalid reward type: %s", task.RewardType)
+	}
+	if err := rule.validate(task); err != nil {
+		return invalid(errRewardParameters, "%w", err)
+	}
+	return nil
+}
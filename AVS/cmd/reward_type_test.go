@@ -0,0 +1,274 @@
+package main
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"go.uber.org/zap"
+)
+
+func TestParseRewardType(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected RewardType
+		enum     uint8
+	}{
+		{input: "liquidity", expected: RewardTypeLiquidity, enum: 0},
+		{input: "LIQUIDITY_PROVISION", expected: RewardTypeLiquidity, enum: 0},
+		{input: "0", expected: RewardTypeLiquidity, enum: 0},
+		{input: "swap", expected: RewardTypeSwap, enum: 1},
+		{input: "SWAP_VOLUME", expected: RewardTypeSwap, enum: 1},
+		{input: "loyalty", expected: RewardTypeLoyalty, enum: 2},
+		{input: "loyalty_bonus", expected: RewardTypeLoyalty, enum: 2},
+		{input: "2", expected: RewardTypeLoyalty, enum: 2},
+		{input: "tier", expected: RewardTypeTier, enum: 3},
+		{input: "TIER_MULTIPLIER", expected: RewardTypeTier, enum: 3},
+		{input: "mev", expected: RewardTypeMEV, enum: 4},
+		{input: "MEV_CAPTURE", expected: RewardTypeMEV, enum: 4},
+		{input: "4", expected: RewardTypeMEV, enum: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			rewardType, err := ParseRewardType(tt.input)
+			if err != nil {
+				t.Fatalf("ParseRewardType failed: %v", err)
+			}
+			if rewardType != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, rewardType)
+			}
+			enum, err := rewardType.Enum()
+			if err != nil || enum != tt.enum {
+				t.Errorf("Expected enum %d, got %d (%v)", tt.enum, enum, err)
+			}
+			fromEnum, err := RewardTypeFromEnum(tt.enum)
+			if err != nil || fromEnum != tt.expected {
+				t.Errorf("Expected %s from enum %d, got %s (%v)", tt.expected, tt.enum, fromEnum, err)
+			}
+		})
+	}
+
+	for _, invalid := range []string{"", "invalid", "5", "256"} {
+		if _, err := ParseRewardType(invalid); err == nil {
+			t.Errorf("Expected error for %q", invalid)
+		}
+	}
+}
+
+func TestRewardType_UnmarshalJSON(t *testing.T) {
+	var task RewardDistributionTask
+	if err := json.Unmarshal([]byte(`{"reward_type": 3, "tier_level": 2}`), &task); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+	if task.RewardType != RewardTypeTier {
+		t.Errorf("Expected %s, got %s", RewardTypeTier, task.RewardType)
+	}
+	if task.TierLevel == nil || *task.TierLevel != 2 {
+		t.Errorf("Expected tier level 2, got %v", task.TierLevel)
+	}
+
+	if err := json.Unmarshal([]byte(`{"reward_type": "LOYALTY_BONUS"}`), &task); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+	if task.RewardType != RewardTypeLoyalty {
+		t.Errorf("Expected %s, got %s", RewardTypeLoyalty, task.RewardType)
+	}
+
+	// Unknown names survive decoding so validation can report them
+	if err := json.Unmarshal([]byte(`{"reward_type": "staking"}`), &task); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+	if task.RewardType.IsValid() {
+		t.Errorf("Expected %s to be invalid", task.RewardType)
+	}
+}
+
+func TestRewardFlowTaskWorker_RewardTypeRules(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	worker := NewRewardFlowTaskWorker(logger)
+	tierLevel := func(v uint8) *uint8 { return &v }
+
+	tests := []struct {
+		name     string
+		mutate   func(task *RewardDistributionTask)
+		errorMsg string
+	}{
+		{
+			name: "valid loyalty bonus",
+			mutate: func(task *RewardDistributionTask) {
+				task.RewardType = RewardTypeLoyalty
+				task.LoyaltyScore = 75
+			},
+		},
+		{
+			name: "loyalty bonus without score",
+			mutate: func(task *RewardDistributionTask) {
+				task.RewardType = RewardTypeLoyalty
+			},
+			errorMsg: "loyalty score is required for loyalty rewards",
+		},
+		{
+			name: "loyalty score above maximum",
+			mutate: func(task *RewardDistributionTask) {
+				task.RewardType = RewardTypeLoyalty
+				task.LoyaltyScore = 101
+			},
+			errorMsg: "loyalty score 101 exceeds maximum of 100",
+		},
+		{
+			name: "valid tier multiplier at bronze",
+			mutate: func(task *RewardDistributionTask) {
+				task.RewardType = RewardTypeTier
+				task.TierLevel = tierLevel(0)
+			},
+		},
+		{
+			name: "tier multiplier without tier level",
+			mutate: func(task *RewardDistributionTask) {
+				task.RewardType = RewardTypeTier
+			},
+			errorMsg: "tier level is required for tier rewards",
+		},
+		{
+			name: "tier level above diamond",
+			mutate: func(task *RewardDistributionTask) {
+				task.RewardType = RewardTypeTier
+				task.TierLevel = tierLevel(5)
+			},
+			errorMsg: "invalid tier level: 5",
+		},
+		{
+			name: "swap reward without pool",
+			mutate: func(task *RewardDistributionTask) {
+				task.RewardType = RewardTypeSwap
+				task.PoolID = ""
+			},
+			errorMsg: "pool ID is required for swap rewards",
+		},
+		{
+			name: "MEV capture without pool",
+			mutate: func(task *RewardDistributionTask) {
+				task.RewardType = RewardTypeMEV
+				task.PoolID = ""
+			},
+			errorMsg: "pool ID is required for mev rewards",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := RewardDistributionTask{
+				User:            "0x1234567890123456789012345678901234567890",
+				Amount:          big.NewInt(1000000000000000000),
+				ChainID:         1,
+				PoolID:          "0xabcdef1234567890abcdef1234567890abcdef12",
+				RewardType:      RewardTypeLiquidity,
+				Timestamp:       time.Now().Unix(),
+				HookAddress:     "0x9876543210987654321098765432109876543210",
+				TransactionHash: "0x1111111111111111111111111111111111111111111111111111111111111111",
+			}
+			tt.mutate(&task)
+
+			taskData, err := json.Marshal(task)
+			if err != nil {
+				t.Fatalf("Failed to marshal task: %v", err)
+			}
+
+			err = worker.ValidateTask(&performerV1.TaskRequest{
+				TaskId:  []byte("test-task-id-" + tt.name),
+				Payload: taskData,
+			})
+			if tt.errorMsg != "" {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				} else if err.Error() != tt.errorMsg {
+					t.Errorf("Expected error message '%s', got '%s'", tt.errorMsg, err.Error())
+				}
+			} else if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestDecodeTaskPayload_RewardParamsRoundTrip(t *testing.T) {
+	tier := uint8(3) // PLATINUM
+	tasks := []*RewardDistributionTask{
+		{
+			User:         solidityUser1,
+			Amount:       big.NewInt(1e18),
+			ChainID:      137,
+			RewardType:   RewardTypeLoyalty,
+			Timestamp:    1700000000,
+			HookAddress:  "0x9876543210987654321098765432109876543210",
+			LoyaltyScore: 42,
+		},
+		{
+			User:        solidityUser1,
+			Amount:      big.NewInt(1e18),
+			ChainID:     8453,
+			RewardType:  RewardTypeTier,
+			Timestamp:   1700000000,
+			HookAddress: "0x9876543210987654321098765432109876543210",
+			TierLevel:   &tier,
+		},
+	}
+
+	for _, task := range tasks {
+		t.Run(string(task.RewardType), func(t *testing.T) {
+			payload, err := EncodeTaskPayload(task, PayloadFormatABIRewardParams)
+			if err != nil {
+				t.Fatalf("EncodeTaskPayload failed: %v", err)
+			}
+			if len(payload) != 10*abiWordSize {
+				t.Fatalf("Expected 10 ABI words, got %d bytes", len(payload))
+			}
+
+			decoded, format, err := DecodeTaskPayload(payload)
+			if err != nil {
+				t.Fatalf("DecodeTaskPayload failed: %v", err)
+			}
+			if format != PayloadFormatABIRewardParams {
+				t.Errorf("Expected format %s, got %s", PayloadFormatABIRewardParams, format)
+			}
+			if decoded.RewardType != task.RewardType {
+				t.Errorf("Expected reward type %s, got %s", task.RewardType, decoded.RewardType)
+			}
+			if decoded.LoyaltyScore != task.LoyaltyScore {
+				t.Errorf("Expected loyalty score %d, got %d", task.LoyaltyScore, decoded.LoyaltyScore)
+			}
+			if (task.TierLevel == nil) != (decoded.TierLevel == nil) ||
+				(task.TierLevel != nil && *task.TierLevel != *decoded.TierLevel) {
+				t.Errorf("Expected tier level %v, got %v", task.TierLevel, decoded.TierLevel)
+			}
+		})
+	}
+}
+
+func TestRewardFlowTaskWorker_BatchRejectsParameterizedTypes(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	worker := NewRewardFlowTaskWorker(logger)
+	task := newBatchTask()
+	task.RewardType = RewardTypeLoyalty
+
+	taskData, err := json.Marshal(task)
+	if err != nil {
+		t.Fatalf("Failed to marshal task: %v", err)
+	}
+
+	err = worker.ValidateTask(&performerV1.TaskRequest{TaskId: []byte("test-batch-loyalty"), Payload: taskData})
+	if err == nil || err.Error() != "loyalty rewards cannot be distributed in a batch" {
+		t.Errorf("Expected batch rejection, got %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/config"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/preferences"
+)
+
+// RoutingReason records why a target chain was chosen for a distribution
+type RoutingReason string
+
+const (
+	// RoutingReasonPreferredChain means the user's preferred chain was used
+	RoutingReasonPreferredChain RoutingReason = "preferred_chain"
+	// RoutingReasonNoPreference means the user has no preferences and the fallback chain was used
+	RoutingReasonNoPreference RoutingReason = "no_preference"
+	// RoutingReasonPreferenceUnsupported means the preferred chain is not supported and the fallback chain was used
+	RoutingReasonPreferenceUnsupported RoutingReason = "preferred_chain_unsupported"
+	// RoutingReasonTaskTarget means the task itself named the target chain, as batch tasks do
+	RoutingReasonTaskTarget RoutingReason = "task_target_chain"
+)
+
+// WithPreferenceStore sets where per-user routing preferences are looked up
+func WithPreferenceStore(store preferences.PreferenceStore) WorkerOption {
+	return func(rf *RewardFlowTaskWorker) {
+		rf.preferences = store
+	}
+}
+
+// determineTargetChain routes a distribution to the user's preferred chain when
+// it is active. Otherwise it falls back to the configured fallback chain when it
+// is active and differs from the source chain, or else to the cheapest chain.
+func (rf *RewardFlowTaskWorker) determineTargetChain(sourceChainID uint64, user string, amount *big.Int) (uint64, RoutingReason) {
+	reason := RoutingReasonNoPreference
+	if rf.preferences != nil {
+		if prefs, ok := rf.preferences.Get(user); ok {
+			if rf.chains.IsActive(prefs.PreferredChain) {
+				return prefs.PreferredChain, RoutingReasonPreferredChain
+			}
+			reason = RoutingReasonPreferenceUnsupported
+		}
+	}
+
+	if rf.fallbackChain != 0 && rf.fallbackChain != sourceChainID && rf.chains.IsActive(rf.fallbackChain) {
+		return rf.fallbackChain, reason
+	}
+	return rf.cheapestChain(sourceChainID, amount), reason
+}
+
+// cheapestChain returns the active chain other than the source that is cheapest
+// to distribute amount to. Chains are ranked by their fee quote, then by their
+// base fee, which prices the distribution even when the fee model charges a flat
+// rate, then by configuration order. Chains whose fee exceeds the amount are
+// skipped unless every chain's does, so that the fee error is reported.
+func (rf *RewardFlowTaskWorker) cheapestChain(sourceChainID uint64, amount *big.Int) uint64 {
+	var (
+		best, first          uint64
+		bestFee, bestBaseFee *big.Int
+	)
+	for _, chain := range rf.chains.ActiveIDs() {
+		if chain == sourceChainID {
+			continue
+		}
+		if first == 0 {
+			first = chain
+		}
+		breakdown, err := rf.fees.Calculate(amount, chain)
+		if err != nil {
+			continue
+		}
+		fee := breakdown.Total()
+		baseFee := new(big.Int)
+		if params, ok := rf.chains.FeeParams(chain); ok && params.BaseFee != nil {
+			baseFee = params.BaseFee
+		}
+		if bestFee == nil || fee.Cmp(bestFee) < 0 || (fee.Cmp(bestFee) == 0 && baseFee.Cmp(bestBaseFee) < 0) {
+			best, bestFee, bestBaseFee = chain, fee, baseFee
+		}
+	}
+	switch {
+	case best != 0:
+		return best
+	case first != 0:
+		return first
+	default:
+		// The source chain is the only active chain
+		return sourceChainID
+	}
+}
+
+// preferenceSource is a PreferenceStore built from the configuration, with
+// the hooks start needs to keep it current and release it
+type preferenceSource struct {
+	store preferences.PreferenceStore
+	// events is set for the events source, which must be synced periodically
+	events *preferences.EventStore
+	close  func()
+}
+
+// newPreferenceSource opens the configured preference store. The events source
+// is synced once here so that an unreachable RPC fails startup.
+func newPreferenceSource(ctx context.Context, cfg config.PreferencesConfig) (*preferenceSource, error) {
+	switch cfg.Source {
+	case config.PreferencesSourceFile:
+		store, err := preferences.OpenFile(cfg.Path)
+		if err != nil {
+			return nil, err
+		}
+		return &preferenceSource{store: store, close: func() {}}, nil
+
+	case config.PreferencesSourceEvents:
+		client, err := ethclient.DialContext(ctx, cfg.RPC)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w", cfg.RPC, err)
+		}
+		store := preferences.NewEventStore(client, common.HexToAddress(cfg.DistributorAddress), cfg.FromBlock)
+		if _, err := store.Sync(ctx); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("failed to load user preferences: %w", err)
+		}
+		return &preferenceSource{store: store, events: store, close: client.Close}, nil
+
+	default:
+		return &preferenceSource{close: func() {}}, nil
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"go.uber.org/zap"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/config"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/preferences"
+)
+
+func TestRewardFlowTaskWorker_PreferenceRouting(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	store := preferences.NewMemoryStore()
+	setPreferredChain := func(user string, chain uint64) {
+		p := preferences.Default()
+		p.PreferredChain = chain
+		if err := store.Set(user, p); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+	setPreferredChain("0x00000000000000000000000000000000000000a1", 8453)
+	setPreferredChain("0x00000000000000000000000000000000000000a2", 1)
+	setPreferredChain("0x00000000000000000000000000000000000000a3", 56) // BSC is not supported
+
+	worker := NewRewardFlowTaskWorker(logger, WithPreferenceStore(store))
+
+	tests := []struct {
+		name          string
+		user          string
+		sourceChain   uint64
+		expectedChain uint64
+		expectedWhy   RoutingReason
+	}{
+		{
+			name:          "preferred chain",
+			user:          "0x00000000000000000000000000000000000000A1",
+			sourceChain:   1,
+			expectedChain: 8453,
+			expectedWhy:   RoutingReasonPreferredChain,
+		},
+		{
+			name:          "preferred chain is the source chain",
+			user:          "0x00000000000000000000000000000000000000a2",
+			sourceChain:   1,
+			expectedChain: 1,
+			expectedWhy:   RoutingReasonPreferredChain,
+		},
+		{
+			name:          "unsupported preferred chain",
+			user:          "0x00000000000000000000000000000000000000a3",
+			sourceChain:   1,
+			expectedChain: 137,
+			expectedWhy:   RoutingReasonPreferenceUnsupported,
+		},
+		{
+			name:          "no preferences",
+			user:          "0x00000000000000000000000000000000000000a4",
+			sourceChain:   10,
+			expectedChain: 137,
+			expectedWhy:   RoutingReasonNoPreference,
+		},
+		{
+			name:          "no preferences from the cheapest chain",
+			user:          "0x00000000000000000000000000000000000000a4",
+			sourceChain:   137,
+			expectedChain: 8453,
+			expectedWhy:   RoutingReasonNoPreference,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := newCLITask()
+			task.User = tt.user
+			task.ChainID = tt.sourceChain
+
+			response, err := worker.HandleTask(&performerV1.TaskRequest{
+				TaskId:  []byte("routing-" + tt.name),
+				Payload: []byte(marshalTask(t, task)),
+			})
+			if err != nil {
+				t.Fatalf("HandleTask failed: %v", err)
+			}
+
+			var result RewardDistributionResult
+			if err := json.Unmarshal(response.Result, &result); err != nil {
+				t.Fatalf("Failed to unmarshal result: %v", err)
+			}
+			if result.TargetChain != tt.expectedChain {
+				t.Errorf("Expected target chain %d, got %d", tt.expectedChain, result.TargetChain)
+			}
+			if result.RoutingReason != tt.expectedWhy {
+				t.Errorf("Expected routing reason %s, got %s", tt.expectedWhy, result.RoutingReason)
+			}
+		})
+	}
+}
+
+func TestRewardFlowTaskWorker_RoutingIgnoresAddressLength(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	worker := NewRewardFlowTaskWorker(logger)
+
+	// Without preferences every user on a chain is routed the same way
+	first, _ := worker.determineTargetChain(1, "0x1234567890123456789012345678901234567890", big.NewInt(1e18))
+	second, reason := worker.determineTargetChain(1, "0xabc", big.NewInt(1e18))
+	if first != second {
+		t.Errorf("Expected the same fallback chain for every user, got %d and %d", first, second)
+	}
+	if reason != RoutingReasonNoPreference {
+		t.Errorf("Expected routing reason %s, got %s", RoutingReasonNoPreference, reason)
+	}
+}
+
+func TestRewardFlowTaskWorker_FallbackRouting(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		mutate        func(cfg *config.Config)
+		sourceChain   uint64
+		expectedChain uint64
+	}{
+		{
+			// Flat fees tie, so the chain with the lowest base fee wins
+			name:          "flat fee from optimism",
+			mutate:        func(cfg *config.Config) {},
+			sourceChain:   10,
+			expectedChain: 137,
+		},
+		{
+			name:          "chain base fees from arbitrum",
+			mutate:        func(cfg *config.Config) { cfg.Rewards.FeeModel = config.FeeModelChainBase },
+			sourceChain:   42161,
+			expectedChain: 137,
+		},
+		{
+			name: "cheapest chain paused",
+			mutate: func(cfg *config.Config) {
+				cfg.Rewards.FeeModel = config.FeeModelChainBase
+				cfg.Chains[3].Paused = true
+			},
+			sourceChain:   10,
+			expectedChain: 8453,
+		},
+		{
+			name:          "configured fallback chain",
+			mutate:        func(cfg *config.Config) { cfg.Routing.FallbackChain = 42161 },
+			sourceChain:   10,
+			expectedChain: 42161,
+		},
+		{
+			name:          "configured fallback chain is the source chain",
+			mutate:        func(cfg *config.Config) { cfg.Routing.FallbackChain = 42161 },
+			sourceChain:   42161,
+			expectedChain: 137,
+		},
+		{
+			name: "configured fallback chain paused",
+			mutate: func(cfg *config.Config) {
+				cfg.Routing.FallbackChain = 42161
+				cfg.Chains[2].Paused = true
+			},
+			sourceChain:   10,
+			expectedChain: 137,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			tt.mutate(cfg)
+			if err := cfg.Validate(); err != nil {
+				t.Fatalf("Invalid test config: %v", err)
+			}
+			worker := NewRewardFlowTaskWorker(logger, withConfig(t, cfg))
+
+			chain, reason := worker.determineTargetChain(tt.sourceChain, "0x1234567890123456789012345678901234567890", big.NewInt(1e18))
+			if chain != tt.expectedChain || reason != RoutingReasonNoPreference {
+				t.Errorf("Expected target chain %d, got %d (%s)", tt.expectedChain, chain, reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/config"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/scheduler"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/stats"
+)
+
+// WithScheduler sets the scheduler distributions wait on for a slot
+func WithScheduler(s *scheduler.Scheduler) WorkerOption {
+	return func(rf *RewardFlowTaskWorker) {
+		rf.scheduler = s
+	}
+}
+
+// schedulerConfig converts the configured scheduler sizes
+func schedulerConfig(cfg config.SchedulerConfig) scheduler.Config {
+	return scheduler.Config{
+		Concurrency: cfg.Concurrency,
+		QueueSize:   cfg.QueueSize,
+		MaxWait:     cfg.MaxWait,
+	}
+}
+
+// taskPriority scores a task with DistributionUtils.calculateDistributionPriority.
+// The tier is the task's own tier level, else the user's tracked engagement
+// tier. Users who never got a distribution count from the task timestamp.
+func (rf *RewardFlowTaskWorker) taskPriority(task *RewardDistributionTask, now time.Time) *big.Int {
+	var userTier uint64
+	if task.TierLevel != nil {
+		userTier = uint64(*task.TierLevel)
+	} else if rf.engagement != nil {
+		if activity, ok := rf.engagement.Activity(task.User); ok {
+			userTier = uint64(activity.Tier())
+		}
+	}
+	return scheduler.Priority(task.Amount, userTier, rf.sinceLastDistribution(task.User, task.Timestamp, now))
+}
+
+// batchPriority scores a batch task by its total amount and the time since
+// the last distribution to its pool. Batches carry no user tier.
+func (rf *RewardFlowTaskWorker) batchPriority(task *BatchRewardDistributionTask, now time.Time) *big.Int {
+	return scheduler.Priority(task.TotalAmount, 0, rf.sinceLastDistribution(task.PoolID, task.Timestamp, now))
+}
+
+// sinceLastDistribution returns how long ago key last got a distribution, or
+// how old the task is if it never did
+func (rf *RewardFlowTaskWorker) sinceLastDistribution(key string, timestamp int64, now time.Time) time.Duration {
+	if since, ok := rf.distributions.Since(key, now); ok {
+		return since
+	}
+	if timestamp <= 0 {
+		return 0
+	}
+	return now.Sub(time.Unix(timestamp, 0))
+}
+
+// acquireSlot waits for the scheduler to run a distribution of the given priority
+func (rf *RewardFlowTaskWorker) acquireSlot(taskID string, priority *big.Int) (func(), time.Duration, error) {
+	release, waited, err := rf.scheduler.Acquire(context.Background(), priority)
+	if err != nil {
+		return nil, waited, fmt.Errorf("task %s rejected: %w", taskID, err)
+	}
+	return release, waited, nil
+}
+
+// queueStats describes the scheduler in stats
+func (rf *RewardFlowTaskWorker) queueStats(queue *stats.Queue) {
+	s := rf.scheduler.Stats()
+	queue.Depth = s.Depth
+	queue.Capacity = s.QueueSize
+	queue.Running = s.Running
+	queue.Concurrency = s.Concurrency
+	queue.Rejected = s.Rejected
+}
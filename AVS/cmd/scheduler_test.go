@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"go.uber.org/zap"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/engagement"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/scheduler"
+)
+
+func TestRewardFlowTaskWorker_TaskPriority(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	const (
+		user      = "0x1234567890123456789012345678901234567890"
+		silver    = "0x00000000000000000000000000000000000000aa"
+		returning = "0x00000000000000000000000000000000000000bb"
+	)
+	now := time.Unix(1700000000, 0)
+
+	// 10000 ETH of swaps scores 45, which is the Silver tier (level 1)
+	tracker := engagement.NewTracker()
+	if err := tracker.Record(silver, engagement.Event{Type: engagement.Swap, Amount: new(big.Int).Mul(big.NewInt(10000), big.NewInt(1e18)), Timestamp: 1}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	worker := NewRewardFlowTaskWorker(logger, WithEngagementTracker(tracker))
+	worker.distributions.Mark(returning, now.Add(-49*time.Hour))
+
+	tierLevel := uint8(3)
+	tests := []struct {
+		name     string
+		user     string
+		amount   *big.Int
+		tier     *uint8
+		age      time.Duration
+		expected int64
+	}{
+		{name: "fresh task", user: user, amount: big.NewInt(1e18), expected: 1},
+		{name: "task waiting for days", user: user, amount: big.NewInt(1e18), age: 72 * time.Hour, expected: 4},
+		{name: "task tier", user: user, amount: big.NewInt(1e18), tier: &tierLevel, expected: 31},
+		{name: "tracked tier", user: silver, amount: big.NewInt(1e18), expected: 11},
+		{name: "since the last distribution", user: returning, amount: big.NewInt(1e18), age: 30 * 24 * time.Hour, expected: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := newCLITask()
+			task.User = tt.user
+			task.Amount = tt.amount
+			task.TierLevel = tt.tier
+			task.Timestamp = now.Add(-tt.age).Unix()
+			if got := worker.taskPriority(&task, now); got.Int64() != tt.expected {
+				t.Errorf("Expected priority %d, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRewardFlowTaskWorker_QueueFull(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	s, err := scheduler.New(scheduler.Config{Concurrency: 1, QueueSize: 1, MaxWait: time.Minute})
+	if err != nil {
+		t.Fatalf("Failed to create scheduler: %v", err)
+	}
+	worker := NewRewardFlowTaskWorker(logger, WithScheduler(s))
+
+	// Hold the only slot so that tasks queue up
+	release, _, err := s.Acquire(context.Background(), big.NewInt(0))
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	request := func(i int) *performerV1.TaskRequest {
+		task := newCLITask()
+		task.TransactionHash = "0x" + strings.Repeat(string(rune('a'+i)), 64)
+		return &performerV1.TaskRequest{TaskId: []byte{byte(i)}, Payload: []byte(marshalTask(t, task))}
+	}
+
+	queued := make(chan error, 1)
+	go func() {
+		_, err := worker.HandleTask(request(0))
+		queued <- err
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for worker.GetStats().Queue.Depth != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the first task to queue")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	_, err = worker.HandleTask(request(1))
+	if expected := "task \x01 rejected: distribution queue is full: 1 distributions pending"; err == nil || err.Error() != expected {
+		t.Fatalf("Expected error message '%s', got '%v'", expected, err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	release()
+	if err := <-queued; err != nil {
+		t.Fatalf("Queued task failed: %v", err)
+	}
+
+	queue := worker.GetStats().Queue
+	if queue.Depth != 0 || queue.Capacity != 1 || queue.Concurrency != 1 || queue.Rejected != 1 {
+		t.Errorf("Unexpected queue stats %+v", queue)
+	}
+	if queue.Wait.P99 < 10 {
+		t.Errorf("Expected the queued task to wait at least 10ms, got %+v", queue.Wait)
+	}
+
+	// A rejected task was not processed, so it can be retried
+	if _, err := worker.HandleTask(request(1)); err != nil {
+		t.Errorf("Retry after rejection failed: %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/config"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/signer"
+)
+
+// The signing key is read from the environment only, never from the configuration file
+const (
+	// envPrivateKey holds a raw hex private key
+	envPrivateKey = "AVS_PRIVATE_KEY"
+	// envKeystorePassword unlocks the keystore at signer.keystore
+	envKeystorePassword = "AVS_KEYSTORE_PASSWORD"
+)
+
+// loadSigningKey loads the key in signer.keystore or AVS_PRIVATE_KEY
+func loadSigningKey(cfg config.SignerConfig) (*ecdsa.PrivateKey, error) {
+	key, err := signer.LoadKey(cfg.Keystore, os.Getenv(envKeystorePassword), os.Getenv(envPrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("signer: %w (set %s or signer.keystore with %s)", err, envPrivateKey, envKeystorePassword)
+	}
+	return key, nil
+}
+
+// newSigners loads the signing key and dials the RPC of every enabled chain
+// when a bridge sends distributions, and returns nil otherwise. The returned
+// function releases the clients.
+func newSigners(ctx context.Context, cfg *config.Config) (map[uint64]*signer.Signer, func(), error) {
+	if !cfg.Across.Enabled && !cfg.Direct.Enabled {
+		return nil, func() {}, nil
+	}
+	key, err := loadSigningKey(cfg.Signer)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	clients := make(map[uint64]signer.Client)
+	var dialed []*ethclient.Client
+	closeAll := func() {
+		for _, client := range dialed {
+			client.Close()
+		}
+	}
+	for _, chain := range cfg.Chains {
+		if !chain.IsEnabled() {
+			continue
+		}
+		client, err := ethclient.DialContext(ctx, chain.RPC)
+		if err != nil {
+			closeAll()
+			return nil, nil, fmt.Errorf("failed to connect to %s: %w", chain.RPC, err)
+		}
+		dialed = append(dialed, client)
+		clients[chain.ChainID] = client
+	}
+
+	signers, err := buildSigners(ctx, cfg, key, clients)
+	if err != nil {
+		closeAll()
+		return nil, nil, err
+	}
+	return signers, closeAll, nil
+}
+
+// buildSigners creates a signer on every enabled chain through its client,
+// checking that the client is connected to the configured chain. Outside live
+// mode the signers only build and sign transactions.
+func buildSigners(ctx context.Context, cfg *config.Config, key *ecdsa.PrivateKey, clients map[uint64]signer.Client) (map[uint64]*signer.Signer, error) {
+	caps := signer.FeeCaps{}
+	if cfg.Signer.MaxFeePerGas != nil {
+		caps.MaxFeePerGas = &cfg.Signer.MaxFeePerGas.Int
+	}
+	if cfg.Signer.MaxPriorityFeePerGas != nil {
+		caps.MaxPriorityFeePerGas = &cfg.Signer.MaxPriorityFeePerGas.Int
+	}
+
+	signers := make(map[uint64]*signer.Signer)
+	for _, chain := range cfg.Chains {
+		if !chain.IsEnabled() {
+			continue
+		}
+		client, ok := clients[chain.ChainID]
+		if !ok {
+			return nil, fmt.Errorf("no RPC for chain %d", chain.ChainID)
+		}
+		s, err := signer.New(ctx, client, key, caps, cfg.Signer.StuckAfter)
+		if err != nil {
+			return nil, fmt.Errorf("chain %s: %w", chain.Name, err)
+		}
+		if s.ChainID() != chain.ChainID {
+			return nil, fmt.Errorf("chain %s: RPC is connected to chain %d, expected %d", chain.Name, s.ChainID(), chain.ChainID)
+		}
+		s.SetDryRun(cfg.Execution.Mode != config.ExecutionModeLive)
+		signers[chain.ChainID] = s
+	}
+	return signers, nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/config"
+)
+
+func TestBuildSigners_WrongChain(t *testing.T) {
+	sim, _ := newSimulatedBridges(t)
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	sim.clients[10] = sim.backend.Client()
+	_, err = buildSigners(context.Background(), sim.cfg, key, sim.clients)
+	if expected := "chain optimism: RPC is connected to chain 1337, expected 10"; err == nil || err.Error() != expected {
+		t.Errorf("Expected error message '%s', got '%v'", expected, err)
+	}
+
+	delete(sim.clients, 10)
+	_, err = buildSigners(context.Background(), sim.cfg, key, sim.clients)
+	if expected := "no RPC for chain 10"; err == nil || err.Error() != expected {
+		t.Errorf("Expected error message '%s', got '%v'", expected, err)
+	}
+}
+
+func TestLoadSigningKey(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	t.Setenv(envPrivateKey, hexutil.Encode(crypto.FromECDSA(key)))
+	loaded, err := loadSigningKey(config.SignerConfig{})
+	if err != nil {
+		t.Fatalf("loadSigningKey failed: %v", err)
+	}
+	if crypto.PubkeyToAddress(loaded.PublicKey) != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Errorf("Expected the key in %s", envPrivateKey)
+	}
+
+	t.Setenv(envPrivateKey, "")
+	_, err = loadSigningKey(config.SignerConfig{})
+	if expected := "signer: no signing key (set AVS_PRIVATE_KEY or signer.keystore with AVS_KEYSTORE_PASSWORD)"; err == nil || err.Error() != expected {
+		t.Errorf("Expected error message '%s', got '%v'", expected, err)
+	}
+	_, err = loadSigningKey(config.SignerConfig{Keystore: "missing.json"})
+	if err == nil || !strings.HasPrefix(err.Error(), "signer: failed to read keystore") {
+		t.Errorf("Expected a missing keystore to fail, got %v", err)
+	}
+}
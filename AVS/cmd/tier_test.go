@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"go.uber.org/zap"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/config"
+)
+
+func newTierTask(level uint8, amount, baseAmount *big.Int) RewardDistributionTask {
+	task := newCLITask()
+	task.RewardType = RewardTypeTier
+	task.TierLevel = &level
+	task.Amount = amount
+	task.BaseAmount = baseAmount
+	return task
+}
+
+func TestRewardFlowTaskWorker_VerifyTierAmount(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	worker := NewRewardFlowTaskWorker(logger)
+
+	tests := []struct {
+		name     string
+		task     RewardDistributionTask
+		errorMsg string
+	}{
+		{
+			name: "gold amount matches base amount",
+			task: newTierTask(2, big.NewInt(1250000000000000000), big.NewInt(1e18)),
+		},
+		{
+			name: "no base amount to verify",
+			task: newTierTask(4, big.NewInt(1e18), nil),
+		},
+		{
+			name:     "diamond amount above the multiplier",
+			task:     newTierTask(4, big.NewInt(2000000000000000001), big.NewInt(1e18)),
+			errorMsg: "tier amount 2000000000000000001 does not match 2000000000000000000, base amount 1000000000000000000 at Diamond (200/100)",
+		},
+		{
+			name:     "bronze base amount with a silver amount",
+			task:     newTierTask(0, big.NewInt(1100000000000000000), big.NewInt(1e18)),
+			errorMsg: "tier amount 1100000000000000000 does not match 1000000000000000000, base amount 1000000000000000000 at Bronze (100/100)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := worker.ValidateTask(&performerV1.TaskRequest{
+				TaskId:  []byte("tier-" + tt.name),
+				Payload: []byte(marshalTask(t, tt.task)),
+			})
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("Expected task to be valid, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("Expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestRewardFlowTaskWorker_ApplyTierMultiplier(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		mode        string
+		task        RewardDistributionTask
+		distributed int64
+	}{
+		{
+			name:        "verify mode distributes the task amount",
+			mode:        config.TierModeVerify,
+			task:        newTierTask(3, big.NewInt(1e18), nil),
+			distributed: 999000000000000000,
+		},
+		{
+			name:        "apply mode multiplies the base amount",
+			mode:        config.TierModeApply,
+			task:        newTierTask(3, big.NewInt(1e18), nil),
+			distributed: 1498500000000000000, // 1.5 ETH less 0.1%
+		},
+		{
+			name:        "apply mode keeps verified amounts",
+			mode:        config.TierModeApply,
+			task:        newTierTask(3, big.NewInt(1500000000000000000), big.NewInt(1e18)),
+			distributed: 1498500000000000000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Rewards.TierMode = tt.mode
+			worker := NewRewardFlowTaskWorker(logger, withConfig(t, cfg))
+
+			response, err := worker.HandleTask(&performerV1.TaskRequest{
+				TaskId:  []byte("tier-" + tt.name),
+				Payload: []byte(marshalTask(t, tt.task)),
+			})
+			if err != nil {
+				t.Fatalf("HandleTask failed: %v", err)
+			}
+			var result RewardDistributionResult
+			if err := json.Unmarshal(response.Result, &result); err != nil {
+				t.Fatalf("Failed to unmarshal result: %v", err)
+			}
+			if result.DistributedAmount.Int64() != tt.distributed {
+				t.Errorf("Expected %d distributed, got %s", tt.distributed, result.DistributedAmount)
+			}
+			if result.TierMultiplier != 150 {
+				t.Errorf("Expected the platinum multiplier 150, got %d", result.TierMultiplier)
+			}
+		})
+	}
+}
+
+func TestRewardFlowTaskWorker_ApplyTierMultiplierLimit(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	// A platinum base amount of 1 ETH multiplies to the 1.5 ETH maximum
+	cfg := config.Default()
+	cfg.Rewards.TierMode = config.TierModeApply
+	cfg.Rewards.MaxAmount = config.NewAmount(big.NewInt(1500000000000000000))
+	worker := NewRewardFlowTaskWorker(logger, withConfig(t, cfg))
+
+	tests := []struct {
+		name     string
+		amount   *big.Int
+		errorMsg string
+	}{
+		{name: "at the maximum", amount: big.NewInt(1e18)},
+		{name: "above the maximum", amount: big.NewInt(1e18 + 1), errorMsg: "tier amount 1500000000000000001 exceeds maximum threshold"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := &performerV1.TaskRequest{
+				TaskId:  []byte("tier-limit-" + tt.name),
+				Payload: []byte(marshalTask(t, newTierTask(3, tt.amount, nil))),
+			}
+			err := worker.ValidateTask(request)
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			} else if err == nil || err.Error() != tt.errorMsg || !errors.Is(err, errAmountAboveMaximum) {
+				t.Fatalf("Expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+
+			// Tasks handled without validation are not distributed either
+			response, err := worker.HandleTask(request)
+			if err != nil {
+				t.Fatalf("HandleTask failed: %v", err)
+			}
+			var result RewardDistributionResult
+			if err := json.Unmarshal(response.Result, &result); err != nil {
+				t.Fatalf("Failed to unmarshal result: %v", err)
+			}
+			if result.Success != (tt.errorMsg == "") {
+				t.Errorf("Expected success to be %t, got %+v", tt.errorMsg == "", result)
+			}
+			if tt.errorMsg != "" && result.Error != tt.errorMsg {
+				t.Errorf("Expected error message '%s', got '%s'", tt.errorMsg, result.Error)
+			}
+		})
+	}
+}
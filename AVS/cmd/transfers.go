@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/bridge"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/config"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/transfers"
+)
+
+// transfersPath serves the transfer of a task on the metrics listener
+const transfersPath = "/transfers"
+
+// WithTransferTracker follows every bridge deposit with tracker until it is delivered
+func WithTransferTracker(tracker *transfers.Tracker) WorkerOption {
+	return func(rf *RewardFlowTaskWorker) {
+		rf.transfers = tracker
+	}
+}
+
+// newTransferTracker opens the transfer store and follows the deposits of
+// router, returning nil when distributions are not bridged or not sent. The
+// returned function closes the store.
+func newTransferTracker(cfg *config.Config, router *bridge.Router, logger *zap.Logger) (*transfers.Tracker, func(), error) {
+	if router == nil || cfg.Execution.Mode != config.ExecutionModeLive {
+		return nil, func() {}, nil
+	}
+	store, err := transfers.Open(cfg.Transfers.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+	closeStore := func() {
+		if err := store.Close(); err != nil {
+			logger.Error("Failed to close transfer store", zap.Error(err))
+		}
+	}
+	tracker := transfers.NewTracker(router, store, transfers.Config{
+		Timeout:    cfg.Transfers.Timeout,
+		MaxRetries: cfg.Transfers.MaxRetries,
+		FeeBumpBps: cfg.Transfers.FeeBumpBps,
+	})
+	return tracker, closeStore, nil
+}
+
+// trackTransfer starts following the deposit of a task. The deposit was sent
+// either way, so failing to track it does not fail the task. Deposits that
+// were only built are never tracked.
+func (rf *RewardFlowTaskWorker) trackTransfer(taskID string, deposit bridge.Deposit) {
+	if rf.transfers == nil || !rf.mode.Sends() {
+		return
+	}
+	if err := rf.transfers.Track(taskID, deposit); err != nil {
+		rf.logger.Error("Failed to track cross-chain transfer", zap.String("task_id", taskID), zap.Error(err))
+	}
+}
+
+// trackRecipientTransfer starts following the deposit to the recipient at
+// index of a batch task, like trackTransfer
+func (rf *RewardFlowTaskWorker) trackRecipientTransfer(taskID string, index int, deposit bridge.Deposit) {
+	if rf.transfers == nil || !rf.mode.Sends() {
+		return
+	}
+	if err := rf.transfers.TrackRecipient(taskID, index, deposit); err != nil {
+		rf.logger.Error("Failed to track cross-chain transfer", zap.String("task_id", taskID), zap.Int("recipient_index", index), zap.Error(err))
+	}
+}
+
+// batchTransfers is the transfer of every recipient of a batch task
+type batchTransfers struct {
+	TaskID     string                `json:"task_id"`
+	Recipients []*transfers.Transfer `json:"recipients"`
+}
+
+// transfersHandler serves the transfer of the task_id query parameter as JSON,
+// or the transfer of every recipient of a batch task
+func (rf *RewardFlowTaskWorker) transfersHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		taskID := r.URL.Query().Get("task_id")
+		if taskID == "" {
+			http.Error(w, "task_id is required", http.StatusBadRequest)
+			return
+		}
+		if rf.transfers == nil {
+			http.Error(w, "transfer tracking is disabled", http.StatusNotFound)
+			return
+		}
+		tracked, err := rf.transfers.Status(taskID)
+		if err != nil {
+			rf.logger.Error("Failed to look up transfer", zap.String("task_id", taskID), zap.Error(err))
+			http.Error(w, "failed to look up transfer", http.StatusInternalServerError)
+			return
+		}
+		if len(tracked) == 0 {
+			http.Error(w, fmt.Sprintf("no transfer for task %s", taskID), http.StatusNotFound)
+			return
+		}
+
+		var body any = tracked[0]
+		if tracked[0].Recipient != nil {
+			body = batchTransfers{TaskID: taskID, Recipients: tracked}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(body); err != nil {
+			rf.logger.Error("Failed to encode transfer", zap.Error(err))
+		}
+	})
+}
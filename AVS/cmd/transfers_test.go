@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"go.uber.org/zap"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/bridge"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/config"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/transfers"
+)
+
+func TestRewardFlowTaskWorker_TransfersHandler(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	router, err := bridge.NewRouter(bridge.DefaultCooldown, bridge.NewMock("mock", big.NewInt(1e15)))
+	if err != nil {
+		t.Fatalf("NewRouter failed: %v", err)
+	}
+	cfg := config.Default()
+	cfg.Transfers.Path = filepath.Join(t.TempDir(), "transfers.db")
+	tracker, closeTransfers, err := newTransferTracker(cfg, router, logger)
+	if err != nil {
+		t.Fatalf("newTransferTracker failed: %v", err)
+	}
+	defer closeTransfers()
+
+	// Every bridged distribution is tracked under its task ID
+	worker := NewRewardFlowTaskWorker(logger, WithBridgeRouter(router), WithTransferTracker(tracker))
+	result := handleTask(t, worker, "bridged", newCLITask())
+	if !result.Success || result.Bridge == nil {
+		t.Fatalf("Expected a bridged distribution, got %+v", result)
+	}
+	batch := newBatchTask()
+	payload, err := EncodeBatchTaskPayload(&batch, PayloadFormatBatchJSON)
+	if err != nil {
+		t.Fatalf("EncodeBatchTaskPayload failed: %v", err)
+	}
+	response, err := worker.HandleTask(&performerV1.TaskRequest{TaskId: []byte("batch"), Payload: payload})
+	if err != nil {
+		t.Fatalf("HandleTask failed: %v", err)
+	}
+	var batchResult RewardDistributionResult
+	if err := json.Unmarshal(response.Result, &batchResult); err != nil || !batchResult.Success {
+		t.Fatalf("Expected a bridged batch, got %+v (%v)", batchResult, err)
+	}
+	disabled := NewRewardFlowTaskWorker(logger)
+
+	tests := []struct {
+		name   string
+		worker *RewardFlowTaskWorker
+		query  string
+		status int
+	}{
+		{name: "tracked task", worker: worker, query: "?task_id=bridged", status: http.StatusOK},
+		{name: "tracked batch task", worker: worker, query: "?task_id=batch", status: http.StatusOK},
+		{name: "unknown task", worker: worker, query: "?task_id=other", status: http.StatusNotFound},
+		{name: "missing task", worker: worker, query: "", status: http.StatusBadRequest},
+		{name: "tracking disabled", worker: disabled, query: "?task_id=bridged", status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.worker.transfersHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, transfersPath+tt.query, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, rec.Code, rec.Body)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			// A batch task has the transfer of every recipient
+			if strings.Contains(tt.query, "batch") {
+				var tracked batchTransfers
+				if err := json.Unmarshal(rec.Body.Bytes(), &tracked); err != nil {
+					t.Fatalf("Failed to decode transfers: %v", err)
+				}
+				if tracked.TaskID != "batch" || len(tracked.Recipients) != len(batch.Recipients) {
+					t.Fatalf("Expected %d recipient transfers, got %+v", len(batch.Recipients), tracked)
+				}
+				for i, transfer := range tracked.Recipients {
+					if transfer.TaskID != "batch" || transfer.Recipient == nil || *transfer.Recipient != i {
+						t.Errorf("Expected the transfer of recipient %d, got %+v", i, transfer)
+					}
+					if hash := transfer.Deposit().TransactionHash.Hex(); hash != batchResult.Recipients[i].TransactionHash {
+						t.Errorf("Recipient %d: expected deposit %s, got %s", i, batchResult.Recipients[i].TransactionHash, hash)
+					}
+				}
+				return
+			}
+
+			var transfer transfers.Transfer
+			if err := json.Unmarshal(rec.Body.Bytes(), &transfer); err != nil {
+				t.Fatalf("Failed to decode transfer: %v", err)
+			}
+			if transfer.TaskID != "bridged" || transfer.State != transfers.StatePending || len(transfer.Deposits) != 1 {
+				t.Fatalf("Unexpected transfer %+v", transfer)
+			}
+			if hash := transfer.Deposits[0].TransactionHash.Hex(); hash != result.TransactionHash {
+				t.Errorf("Expected deposit %s, got %s", result.TransactionHash, hash)
+			}
+		})
+	}
+
+	if tracker, _, err := newTransferTracker(cfg, nil, logger); tracker != nil || err != nil {
+		t.Errorf("Expected no tracker without bridges, got %v", err)
+	}
+}
@@ -0,0 +1,482 @@
+// Package across builds and sends Across SpokePool deposits, the bridge
+// RewardDistributor._callAcrossSpokePool is meant to call for cross-chain
+// payouts.
+package across
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DefaultFillDeadline is how long relayers have to fill a deposit, the 30
+// minutes of _callAcrossSpokePool
+const DefaultFillDeadline = 30 * time.Minute
+
+// spokePoolABI is the part of the SpokePool interface used for deposits and
+// their fills
+const spokePoolABI = `[{
+	"type": "function",
+	"name": "depositV3",
+	"stateMutability": "payable",
+	"inputs": [
+		{"name": "depositor", "type": "address"},
+		{"name": "recipient", "type": "address"},
+		{"name": "inputToken", "type": "address"},
+		{"name": "outputToken", "type": "address"},
+		{"name": "inputAmount", "type": "uint256"},
+		{"name": "outputAmount", "type": "uint256"},
+		{"name": "destinationChainId", "type": "uint256"},
+		{"name": "exclusiveRelayer", "type": "address"},
+		{"name": "quoteTimestamp", "type": "uint32"},
+		{"name": "fillDeadline", "type": "uint32"},
+		{"name": "exclusivityDeadline", "type": "uint32"},
+		{"name": "message", "type": "bytes"}
+	],
+	"outputs": []
+}, {
+	"type": "event",
+	"name": "FilledV3Relay",
+	"anonymous": false,
+	"inputs": [
+		{"name": "inputToken", "type": "address", "indexed": false},
+		{"name": "outputToken", "type": "address", "indexed": false},
+		{"name": "inputAmount", "type": "uint256", "indexed": false},
+		{"name": "outputAmount", "type": "uint256", "indexed": false},
+		{"name": "repaymentChainId", "type": "uint256", "indexed": false},
+		{"name": "originChainId", "type": "uint256", "indexed": true},
+		{"name": "depositId", "type": "uint32", "indexed": true},
+		{"name": "fillDeadline", "type": "uint32", "indexed": false},
+		{"name": "exclusivityDeadline", "type": "uint32", "indexed": false},
+		{"name": "exclusiveRelayer", "type": "address", "indexed": false},
+		{"name": "relayer", "type": "address", "indexed": true},
+		{"name": "depositor", "type": "address", "indexed": false},
+		{"name": "recipient", "type": "address", "indexed": false},
+		{"name": "message", "type": "bytes", "indexed": false},
+		{"name": "relayExecutionInfo", "type": "tuple", "indexed": false, "components": [
+			{"name": "updatedRecipient", "type": "address"},
+			{"name": "updatedMessage", "type": "bytes"},
+			{"name": "updatedOutputAmount", "type": "uint256"},
+			{"name": "fillType", "type": "uint8"}
+		]}
+	]
+}]`
+
+// erc20ABI is the part of the ERC-20 interface token deposits need, as the
+// SpokePool pulls their input amount with transferFrom
+const erc20ABI = `[{
+	"type": "function",
+	"name": "allowance",
+	"stateMutability": "view",
+	"inputs": [
+		{"name": "owner", "type": "address"},
+		{"name": "spender", "type": "address"}
+	],
+	"outputs": [{"name": "", "type": "uint256"}]
+}, {
+	"type": "function",
+	"name": "approve",
+	"stateMutability": "nonpayable",
+	"inputs": [
+		{"name": "spender", "type": "address"},
+		{"name": "amount", "type": "uint256"}
+	],
+	"outputs": [{"name": "", "type": "bool"}]
+}]`
+
+const (
+	// depositMethod is the SpokePool method deposits call
+	depositMethod = "depositV3"
+	// fillEvent is the event the SpokePool of the destination chain logs when
+	// a relayer fills a deposit
+	fillEvent = "FilledV3Relay"
+)
+
+var parsedSpokePoolABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(spokePoolABI))
+	if err != nil {
+		panic(fmt.Sprintf("invalid SpokePool ABI: %v", err))
+	}
+	return parsed
+}()
+
+var parsedERC20ABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(erc20ABI))
+	if err != nil {
+		panic(fmt.Sprintf("invalid ERC-20 ABI: %v", err))
+	}
+	return parsed
+}()
+
+// Deposit describes an Across deposit. The relayer filling it on the
+// destination chain pays OutputAmount to the recipient and keeps RelayerFee.
+type Deposit struct {
+	Depositor   common.Address
+	Recipient   common.Address
+	InputToken  common.Address
+	OutputToken common.Address
+	// InputAmount is what the depositor pays on the origin chain
+	InputAmount *big.Int
+	// RelayerFee is the part of InputAmount the relayer keeps
+	RelayerFee         *big.Int
+	DestinationChainID uint64
+	// ExclusiveRelayer is the only relayer allowed to fill before
+	// ExclusivityDeadline, zero for any relayer
+	ExclusiveRelayer    common.Address
+	QuoteTimestamp      uint32
+	FillDeadline        uint32
+	ExclusivityDeadline uint32
+	Message             []byte
+	// Native sends InputAmount as value, for deposits of the wrapped native token
+	Native bool
+}
+
+// NewDeposit creates a deposit quoted at quoteTime that relayers have
+// fillWindow to fill
+func NewDeposit(quoteTime time.Time, fillWindow time.Duration) Deposit {
+	quote := uint32(quoteTime.Unix())
+	return Deposit{QuoteTimestamp: quote, FillDeadline: quote + uint32(fillWindow/time.Second)}
+}
+
+// OutputAmount returns what the recipient receives, InputAmount less RelayerFee
+func (d Deposit) OutputAmount() *big.Int {
+	output := new(big.Int).Set(d.InputAmount)
+	if d.RelayerFee != nil {
+		output.Sub(output, d.RelayerFee)
+	}
+	return output
+}
+
+// Validate checks that the SpokePool would accept the deposit
+func (d Deposit) Validate() error {
+	if d.InputAmount == nil || d.InputAmount.Sign() <= 0 {
+		return errors.New("input amount must be positive")
+	}
+	if d.RelayerFee != nil && (d.RelayerFee.Sign() < 0 || d.RelayerFee.Cmp(d.InputAmount) >= 0) {
+		return fmt.Errorf("relayer fee %s must be below the input amount %s", d.RelayerFee, d.InputAmount)
+	}
+	if d.Recipient == (common.Address{}) {
+		return errors.New("recipient is required")
+	}
+	if d.DestinationChainID == 0 {
+		return errors.New("destination chain is required")
+	}
+	if d.FillDeadline <= d.QuoteTimestamp {
+		return fmt.Errorf("fill deadline %d must be after the quote timestamp %d", d.FillDeadline, d.QuoteTimestamp)
+	}
+	return nil
+}
+
+// args returns the deposit as depositV3 arguments
+func (d Deposit) args() []interface{} {
+	message := d.Message
+	if message == nil {
+		message = []byte{}
+	}
+	return []interface{}{
+		d.Depositor,
+		d.Recipient,
+		d.InputToken,
+		d.OutputToken,
+		d.InputAmount,
+		d.OutputAmount(),
+		new(big.Int).SetUint64(d.DestinationChainID),
+		d.ExclusiveRelayer,
+		d.QuoteTimestamp,
+		d.FillDeadline,
+		d.ExclusivityDeadline,
+		message,
+	}
+}
+
+// Calldata returns the depositV3 call for the deposit
+func (d Deposit) Calldata() ([]byte, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	return parsedSpokePoolABI.Pack(depositMethod, d.args()...)
+}
+
+// ParseCalldata decodes a depositV3 call, as found in a deposit transaction.
+// Native is not part of the call and is left unset.
+func ParseCalldata(data []byte) (Deposit, error) {
+	method := parsedSpokePoolABI.Methods[depositMethod]
+	if len(data) < 4 || !bytes.Equal(data[:4], method.ID) {
+		return Deposit{}, errors.New("not a depositV3 call")
+	}
+	var call struct {
+		Depositor           common.Address
+		Recipient           common.Address
+		InputToken          common.Address
+		OutputToken         common.Address
+		InputAmount         *big.Int
+		OutputAmount        *big.Int
+		DestinationChainId  *big.Int
+		ExclusiveRelayer    common.Address
+		QuoteTimestamp      uint32
+		FillDeadline        uint32
+		ExclusivityDeadline uint32
+		Message             []byte
+	}
+	values, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return Deposit{}, fmt.Errorf("failed to decode deposit: %w", err)
+	}
+	if err := method.Inputs.Copy(&call, values); err != nil {
+		return Deposit{}, fmt.Errorf("failed to decode deposit: %w", err)
+	}
+	return Deposit{
+		Depositor:           call.Depositor,
+		Recipient:           call.Recipient,
+		InputToken:          call.InputToken,
+		OutputToken:         call.OutputToken,
+		InputAmount:         call.InputAmount,
+		RelayerFee:          new(big.Int).Sub(call.InputAmount, call.OutputAmount),
+		DestinationChainID:  call.DestinationChainId.Uint64(),
+		ExclusiveRelayer:    call.ExclusiveRelayer,
+		QuoteTimestamp:      call.QuoteTimestamp,
+		FillDeadline:        call.FillDeadline,
+		ExclusivityDeadline: call.ExclusivityDeadline,
+		Message:             call.Message,
+	}, nil
+}
+
+// SpokePool sends deposits to the SpokePool of one chain
+type SpokePool struct {
+	address  common.Address
+	backend  bind.ContractBackend
+	contract *bind.BoundContract
+}
+
+// NewSpokePool binds the SpokePool at address
+func NewSpokePool(address common.Address, backend bind.ContractBackend) *SpokePool {
+	return &SpokePool{
+		address:  address,
+		backend:  backend,
+		contract: bind.NewBoundContract(address, parsedSpokePoolABI, backend, backend, backend),
+	}
+}
+
+// Address returns the SpokePool address
+func (p *SpokePool) Address() common.Address {
+	return p.address
+}
+
+// QuoteTime returns the time of the latest block, which deposits quote from
+func (p *SpokePool) QuoteTime(ctx context.Context) (time.Time, error) {
+	header, err := p.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get latest block: %w", err)
+	}
+	return time.Unix(int64(header.Time), 0), nil
+}
+
+// Deposit sends the deposit from opts.From, which must be its depositor. Token
+// deposits need an Allowance covering their input amount, see Approve.
+func (p *SpokePool) Deposit(opts *bind.TransactOpts, d Deposit) (*types.Transaction, error) {
+	if err := d.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid deposit: %w", err)
+	}
+	if d.Depositor != opts.From {
+		return nil, fmt.Errorf("depositor %s is not the sender %s", d.Depositor, opts.From)
+	}
+	send := *opts
+	send.Value = nil
+	if d.Native {
+		send.Value = d.InputAmount
+	}
+	tx, err := p.contract.Transact(&send, depositMethod, d.args()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deposit to SpokePool %s: %w", p.address, err)
+	}
+	return tx, nil
+}
+
+// Allowance returns how much of token the SpokePool may pull from owner, which
+// token deposits need to cover their input amount
+func (p *SpokePool) Allowance(ctx context.Context, token, owner common.Address) (*big.Int, error) {
+	var out []interface{}
+	erc20 := bind.NewBoundContract(token, parsedERC20ABI, p.backend, p.backend, p.backend)
+	if err := erc20.Call(&bind.CallOpts{Context: ctx}, &out, "allowance", owner, p.address); err != nil {
+		return nil, fmt.Errorf("failed to get allowance of SpokePool %s for token %s: %w", p.address, token, err)
+	}
+	return out[0].(*big.Int), nil
+}
+
+// Approve lets the SpokePool pull amount of token from opts.From
+func (p *SpokePool) Approve(opts *bind.TransactOpts, token common.Address, amount *big.Int) (*types.Transaction, error) {
+	erc20 := bind.NewBoundContract(token, parsedERC20ABI, p.backend, p.backend, p.backend)
+	tx, err := erc20.Transact(opts, "approve", p.address, amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to approve SpokePool %s for token %s: %w", p.address, token, err)
+	}
+	return tx, nil
+}
+
+// LatestBlock returns the number of the latest block, which fills of later
+// deposits are looked for from
+func (p *SpokePool) LatestBlock(ctx context.Context) (uint64, error) {
+	header, err := p.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest block: %w", err)
+	}
+	return header.Number.Uint64(), nil
+}
+
+// Fills returns the fills of deposits from originChain logged by the SpokePool
+// since fromBlock
+func (p *SpokePool) Fills(ctx context.Context, originChain uint64, fromBlock uint64) ([]Fill, error) {
+	logs, err := p.backend.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(fromBlock),
+		Addresses: []common.Address{p.address},
+		Topics: [][]common.Hash{
+			{parsedSpokePoolABI.Events[fillEvent].ID},
+			{common.BigToHash(new(big.Int).SetUint64(originChain))},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fills from SpokePool %s: %w", p.address, err)
+	}
+	fills := make([]Fill, 0, len(logs))
+	for _, log := range logs {
+		fill, err := ParseFill(log)
+		if err != nil {
+			return nil, err
+		}
+		fills = append(fills, fill)
+	}
+	return fills, nil
+}
+
+// Fill is a deposit a relayer delivered on its destination chain, as logged
+// by FilledV3Relay
+type Fill struct {
+	OriginChainID uint64
+	DepositID     uint32
+	Relayer       common.Address
+	Depositor     common.Address
+	Recipient     common.Address
+	InputToken    common.Address
+	OutputToken   common.Address
+	InputAmount   *big.Int
+	OutputAmount  *big.Int
+	FillDeadline  uint32
+	// TransactionHash is the fill transaction on the destination chain
+	TransactionHash common.Hash
+}
+
+// Matches reports whether the fill delivered d. Fills name deposits by an ID
+// the SpokePool of the origin chain assigns, so they are matched on the
+// deposit arguments instead.
+func (f Fill) Matches(d Deposit) bool {
+	return f.Depositor == d.Depositor &&
+		f.Recipient == d.Recipient &&
+		f.InputToken == d.InputToken &&
+		f.OutputToken == d.OutputToken &&
+		f.InputAmount != nil && d.InputAmount != nil && f.InputAmount.Cmp(d.InputAmount) == 0 &&
+		f.OutputAmount != nil && f.OutputAmount.Cmp(d.OutputAmount()) == 0 &&
+		f.FillDeadline == d.FillDeadline
+}
+
+// filledV3Relay holds the arguments of a FilledV3Relay event
+type filledV3Relay struct {
+	InputToken          common.Address
+	OutputToken         common.Address
+	InputAmount         *big.Int
+	OutputAmount        *big.Int
+	RepaymentChainId    *big.Int
+	OriginChainId       *big.Int
+	DepositId           uint32
+	FillDeadline        uint32
+	ExclusivityDeadline uint32
+	ExclusiveRelayer    common.Address
+	Relayer             common.Address
+	Depositor           common.Address
+	Recipient           common.Address
+	Message             []byte
+	RelayExecutionInfo  struct {
+		UpdatedRecipient    common.Address
+		UpdatedMessage      []byte
+		UpdatedOutputAmount *big.Int
+		FillType            uint8
+	}
+}
+
+// Log returns the topics and data the SpokePool logs the fill with, as
+// ParseFill reads them back
+func (f Fill) Log() ([]common.Hash, []byte, error) {
+	event := parsedSpokePoolABI.Events[fillEvent]
+	e := filledV3Relay{
+		InputToken:       f.InputToken,
+		OutputToken:      f.OutputToken,
+		InputAmount:      f.InputAmount,
+		OutputAmount:     f.OutputAmount,
+		RepaymentChainId: new(big.Int),
+		FillDeadline:     f.FillDeadline,
+		Depositor:        f.Depositor,
+		Recipient:        f.Recipient,
+		Message:          []byte{},
+	}
+	e.RelayExecutionInfo.UpdatedRecipient = f.Recipient
+	e.RelayExecutionInfo.UpdatedMessage = []byte{}
+	e.RelayExecutionInfo.UpdatedOutputAmount = f.OutputAmount
+	data, err := event.Inputs.NonIndexed().Pack(
+		e.InputToken, e.OutputToken, e.InputAmount, e.OutputAmount, e.RepaymentChainId,
+		e.FillDeadline, e.ExclusivityDeadline, e.ExclusiveRelayer, e.Depositor, e.Recipient,
+		e.Message, e.RelayExecutionInfo,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode fill: %w", err)
+	}
+	topics := []common.Hash{
+		event.ID,
+		common.BigToHash(new(big.Int).SetUint64(f.OriginChainID)),
+		common.BigToHash(new(big.Int).SetUint64(uint64(f.DepositID))),
+		common.BytesToHash(f.Relayer.Bytes()),
+	}
+	return topics, data, nil
+}
+
+// ParseFill decodes a FilledV3Relay log
+func ParseFill(log types.Log) (Fill, error) {
+	event := parsedSpokePoolABI.Events[fillEvent]
+	if len(log.Topics) == 0 || log.Topics[0] != event.ID {
+		return Fill{}, errors.New("not a FilledV3Relay event")
+	}
+	var e filledV3Relay
+	if err := parsedSpokePoolABI.UnpackIntoInterface(&e, fillEvent, log.Data); err != nil {
+		return Fill{}, fmt.Errorf("failed to decode fill %s: %w", log.TxHash, err)
+	}
+	var indexed abi.Arguments
+	for _, arg := range event.Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	if err := abi.ParseTopics(&e, indexed, log.Topics[1:]); err != nil {
+		return Fill{}, fmt.Errorf("failed to decode fill %s: %w", log.TxHash, err)
+	}
+	return Fill{
+		OriginChainID:   e.OriginChainId.Uint64(),
+		DepositID:       e.DepositId,
+		Relayer:         e.Relayer,
+		Depositor:       e.Depositor,
+		Recipient:       e.Recipient,
+		InputToken:      e.InputToken,
+		OutputToken:     e.OutputToken,
+		InputAmount:     e.InputAmount,
+		OutputAmount:    e.OutputAmount,
+		FillDeadline:    e.FillDeadline,
+		TransactionHash: log.TxHash,
+	}, nil
+}
@@ -0,0 +1,375 @@
+package across
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	spokePoolAddress = common.HexToAddress("0x00000000000000000000000000000000000000a0")
+	recipient        = common.HexToAddress("0x00000000000000000000000000000000000000b0")
+	weth             = common.HexToAddress("0x00000000000000000000000000000000000000c0")
+	outputWeth       = common.HexToAddress("0x00000000000000000000000000000000000000c1")
+)
+
+// mockSpokePoolCode accepts any call and value and logs the call arguments,
+// so that a test can read back the deposit the SpokePool received.
+//
+//	PUSH1 0x04 CALLDATASIZE SUB PUSH1 0x04 PUSH1 0x00 CALLDATACOPY
+//	PUSH1 0x04 CALLDATASIZE SUB PUSH1 0x00 LOG0 STOP
+func mockSpokePoolCode() []byte {
+	return common.FromHex("0x600436036004600037600436036000a000")
+}
+
+// mockLoggerCode logs the rest of the call under the four topics the call
+// starts with, so that a test can log the events of a real SpokePool.
+//
+//	PUSH1 0x80 CALLDATASIZE SUB PUSH1 0x80 PUSH1 0x00 CALLDATACOPY
+//	PUSH1 0x60 CALLDATALOAD PUSH1 0x40 CALLDATALOAD PUSH1 0x20 CALLDATALOAD PUSH1 0x00 CALLDATALOAD
+//	PUSH1 0x80 CALLDATASIZE SUB PUSH1 0x00 LOG4 STOP
+func mockLoggerCode() []byte {
+	return common.FromHex("0x608036036080600037606035604035602035600035608036036000a400")
+}
+
+// mockTokenCode keeps a single allowance: approve stores its amount and
+// returns true, allowance returns it whatever the owner and spender.
+//
+//	PUSH1 0x00 CALLDATALOAD PUSH1 0xe0 SHR DUP1 PUSH4 0x095ea7b3 EQ PUSH1 0x1a JUMPI
+//	PUSH4 0xdd62ed3e EQ PUSH1 0x2b JUMPI STOP
+//	JUMPDEST PUSH1 0x24 CALLDATALOAD PUSH1 0x00 SSTORE PUSH1 0x01 PUSH1 0x00 MSTORE PUSH1 0x20 PUSH1 0x00 RETURN
+//	JUMPDEST PUSH1 0x00 SLOAD PUSH1 0x00 MSTORE PUSH1 0x20 PUSH1 0x00 RETURN
+func mockTokenCode() []byte {
+	return common.FromHex("0x60003560e01c8063095ea7b314601a5763dd62ed3e14602b57005b602435600055600160005260206000f35b60005460005260206000f3")
+}
+
+func testDeposit(depositor common.Address) Deposit {
+	d := NewDeposit(time.Unix(1700000000, 0), DefaultFillDeadline)
+	d.Depositor = depositor
+	d.Recipient = recipient
+	d.InputToken = weth
+	d.OutputToken = outputWeth
+	d.InputAmount = big.NewInt(1e17)
+	d.RelayerFee = big.NewInt(1e15)
+	d.DestinationChainID = 10
+	return d
+}
+
+func TestDeposit_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(d *Deposit)
+		errorMsg string
+	}{
+		{name: "valid", modify: func(d *Deposit) {}},
+		{name: "no relayer fee", modify: func(d *Deposit) { d.RelayerFee = nil }},
+		{name: "zero input", modify: func(d *Deposit) { d.InputAmount = new(big.Int) }, errorMsg: "input amount must be positive"},
+		{name: "fee equals input", modify: func(d *Deposit) { d.RelayerFee = big.NewInt(1e17) }, errorMsg: "relayer fee 100000000000000000 must be below the input amount 100000000000000000"},
+		{name: "negative fee", modify: func(d *Deposit) { d.RelayerFee = big.NewInt(-1) }, errorMsg: "relayer fee -1 must be below the input amount 100000000000000000"},
+		{name: "no recipient", modify: func(d *Deposit) { d.Recipient = common.Address{} }, errorMsg: "recipient is required"},
+		{name: "no destination", modify: func(d *Deposit) { d.DestinationChainID = 0 }, errorMsg: "destination chain is required"},
+		{name: "deadline at quote", modify: func(d *Deposit) { d.FillDeadline = d.QuoteTimestamp }, errorMsg: "fill deadline 1700000000 must be after the quote timestamp 1700000000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := testDeposit(common.Address{})
+			tt.modify(&d)
+			err := d.Validate()
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("Expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+// unpackDeposit decodes depositV3 arguments
+func unpackDeposit(t *testing.T, data []byte) []interface{} {
+	t.Helper()
+	args, err := parsedSpokePoolABI.Methods[depositMethod].Inputs.Unpack(data)
+	if err != nil {
+		t.Fatalf("Failed to unpack deposit: %v", err)
+	}
+	return args
+}
+
+// checkDeposit compares decoded depositV3 arguments with a deposit
+func checkDeposit(t *testing.T, args []interface{}, d Deposit) {
+	t.Helper()
+	expected := d.args()
+	if len(args) != len(expected) {
+		t.Fatalf("Expected %d arguments, got %d", len(expected), len(args))
+	}
+	for i, arg := range args {
+		name := parsedSpokePoolABI.Methods[depositMethod].Inputs[i].Name
+		switch want := expected[i].(type) {
+		case *big.Int:
+			if arg.(*big.Int).Cmp(want) != 0 {
+				t.Errorf("Expected %s %s, got %s", name, want, arg)
+			}
+		case []byte:
+			if !bytes.Equal(arg.([]byte), want) {
+				t.Errorf("Expected %s %x, got %x", name, want, arg)
+			}
+		default:
+			if arg != want {
+				t.Errorf("Expected %s %v, got %v", name, want, arg)
+			}
+		}
+	}
+}
+
+func TestDeposit_Calldata(t *testing.T) {
+	d := testDeposit(common.HexToAddress("0x00000000000000000000000000000000000000d0"))
+	data, err := d.Calldata()
+	if err != nil {
+		t.Fatalf("Calldata failed: %v", err)
+	}
+
+	selector := crypto.Keccak256([]byte("depositV3(address,address,address,address,uint256,uint256,uint256,address,uint32,uint32,uint32,bytes)"))[:4]
+	if !bytes.Equal(data[:4], selector) {
+		t.Fatalf("Expected selector %x, got %x", selector, data[:4])
+	}
+	args := unpackDeposit(t, data[4:])
+	checkDeposit(t, args, d)
+	if output := args[5].(*big.Int); output.Cmp(big.NewInt(99e15)) != 0 {
+		t.Errorf("Expected output amount 0.099 ETH, got %s", output)
+	}
+	if deadline := args[9].(uint32); deadline != 1700000000+1800 {
+		t.Errorf("Expected fill deadline 30 minutes after the quote, got %d", deadline)
+	}
+
+	parsed, err := ParseCalldata(data)
+	if err != nil {
+		t.Fatalf("ParseCalldata failed: %v", err)
+	}
+	checkDeposit(t, parsed.args(), d)
+	if _, err := ParseCalldata(data[1:]); err == nil || err.Error() != "not a depositV3 call" {
+		t.Errorf("Expected error message 'not a depositV3 call', got '%v'", err)
+	}
+
+	d.InputAmount = nil
+	if _, err := d.Calldata(); err == nil {
+		t.Errorf("Expected an invalid deposit to be rejected")
+	}
+}
+
+func TestSpokePool_Deposit(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	depositor := crypto.PubkeyToAddress(key.PublicKey)
+	backend := simulated.NewBackend(types.GenesisAlloc{
+		depositor:        {Balance: big.NewInt(params.Ether)},
+		spokePoolAddress: {Code: mockSpokePoolCode()},
+	})
+	t.Cleanup(func() { backend.Close() })
+	client := backend.Client()
+	ctx := context.Background()
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get chain ID: %v", err)
+	}
+	opts, err := bind.NewKeyedTransactorWithChainID(key, chainID)
+	if err != nil {
+		t.Fatalf("Failed to create transactor: %v", err)
+	}
+	pool := NewSpokePool(spokePoolAddress, client)
+
+	quoteTime, err := pool.QuoteTime(ctx)
+	if err != nil {
+		t.Fatalf("QuoteTime failed: %v", err)
+	}
+	d := testDeposit(depositor)
+	fill := NewDeposit(quoteTime, time.Hour)
+	d.QuoteTimestamp, d.FillDeadline = fill.QuoteTimestamp, fill.FillDeadline
+	d.Native = true
+
+	tx, err := pool.Deposit(opts, d)
+	if err != nil {
+		t.Fatalf("Deposit failed: %v", err)
+	}
+	backend.Commit()
+
+	receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+	if err != nil {
+		t.Fatalf("Failed to get receipt: %v", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("Expected a successful deposit, got status %d", receipt.Status)
+	}
+	if tx.To() == nil || *tx.To() != spokePoolAddress || tx.Value().Cmp(d.InputAmount) != 0 {
+		t.Errorf("Expected %s sent to the SpokePool, got %s to %v", d.InputAmount, tx.Value(), tx.To())
+	}
+	if len(receipt.Logs) != 1 {
+		t.Fatalf("Expected the SpokePool to log the deposit, got %d logs", len(receipt.Logs))
+	}
+	checkDeposit(t, unpackDeposit(t, receipt.Logs[0].Data), d)
+
+	balance, err := client.BalanceAt(ctx, spokePoolAddress, nil)
+	if err != nil {
+		t.Fatalf("Failed to get balance: %v", err)
+	}
+	if balance.Cmp(d.InputAmount) != 0 {
+		t.Errorf("Expected the SpokePool to hold %s, got %s", d.InputAmount, balance)
+	}
+
+	// Token deposits send no value, and only the depositor can send a deposit
+	d.Native = false
+	tx, err = pool.Deposit(opts, d)
+	if err != nil {
+		t.Fatalf("Deposit failed: %v", err)
+	}
+	if tx.Value().Sign() != 0 {
+		t.Errorf("Expected no value for a token deposit, got %s", tx.Value())
+	}
+	d.Depositor = recipient
+	expected := "depositor " + recipient.Hex() + " is not the sender " + depositor.Hex()
+	if _, err := pool.Deposit(opts, d); err == nil || err.Error() != expected {
+		t.Errorf("Expected error message '%s', got '%v'", expected, err)
+	}
+}
+
+func TestSpokePool_Approve(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	owner := crypto.PubkeyToAddress(key.PublicKey)
+	backend := simulated.NewBackend(types.GenesisAlloc{
+		owner: {Balance: big.NewInt(params.Ether)},
+		weth:  {Code: mockTokenCode()},
+	})
+	t.Cleanup(func() { backend.Close() })
+	client := backend.Client()
+	ctx := context.Background()
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get chain ID: %v", err)
+	}
+	opts, err := bind.NewKeyedTransactorWithChainID(key, chainID)
+	if err != nil {
+		t.Fatalf("Failed to create transactor: %v", err)
+	}
+	pool := NewSpokePool(spokePoolAddress, client)
+
+	if allowance, err := pool.Allowance(ctx, weth, owner); err != nil || allowance.Sign() != 0 {
+		t.Fatalf("Expected no allowance, got %v (%v)", allowance, err)
+	}
+	tx, err := pool.Approve(opts, weth, big.NewInt(1e17))
+	if err != nil {
+		t.Fatalf("Approve failed: %v", err)
+	}
+	backend.Commit()
+
+	if receipt, err := client.TransactionReceipt(ctx, tx.Hash()); err != nil || receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("Expected a successful approval, got %+v (%v)", receipt, err)
+	}
+	if allowance, err := pool.Allowance(ctx, weth, owner); err != nil || allowance.Cmp(big.NewInt(1e17)) != 0 {
+		t.Errorf("Expected an allowance of 1e17, got %v (%v)", allowance, err)
+	}
+
+	// Accounts without code have no allowance to read
+	if _, err := pool.Allowance(ctx, outputWeth, owner); err == nil {
+		t.Errorf("Expected an error reading the allowance of a missing token")
+	}
+}
+
+func TestSpokePool_Fills(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	relayer := crypto.PubkeyToAddress(key.PublicKey)
+	backend := simulated.NewBackend(types.GenesisAlloc{
+		relayer:          {Balance: big.NewInt(params.Ether)},
+		spokePoolAddress: {Code: mockLoggerCode()},
+	})
+	t.Cleanup(func() { backend.Close() })
+	client := backend.Client()
+	ctx := context.Background()
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get chain ID: %v", err)
+	}
+	opts, err := bind.NewKeyedTransactorWithChainID(key, chainID)
+	if err != nil {
+		t.Fatalf("Failed to create transactor: %v", err)
+	}
+	pool := NewSpokePool(spokePoolAddress, client)
+	logger := bind.NewBoundContract(spokePoolAddress, parsedSpokePoolABI, client, client, client)
+
+	d := testDeposit(common.HexToAddress("0x00000000000000000000000000000000000000d0"))
+	from, err := pool.LatestBlock(ctx)
+	if err != nil {
+		t.Fatalf("LatestBlock failed: %v", err)
+	}
+	for _, origin := range []uint64{1, 8453} {
+		topics, data, err := Fill{
+			OriginChainID: origin,
+			DepositID:     7,
+			Relayer:       relayer,
+			Depositor:     d.Depositor,
+			Recipient:     d.Recipient,
+			InputToken:    d.InputToken,
+			OutputToken:   d.OutputToken,
+			InputAmount:   d.InputAmount,
+			OutputAmount:  d.OutputAmount(),
+			FillDeadline:  d.FillDeadline,
+		}.Log()
+		if err != nil {
+			t.Fatalf("Log failed: %v", err)
+		}
+		var call []byte
+		for _, topic := range topics {
+			call = append(call, topic.Bytes()...)
+		}
+		if _, err := logger.RawTransact(opts, append(call, data...)); err != nil {
+			t.Fatalf("Failed to log fill: %v", err)
+		}
+		backend.Commit()
+	}
+
+	// Only fills of deposits from the origin chain are returned
+	fills, err := pool.Fills(ctx, 1, from)
+	if err != nil {
+		t.Fatalf("Fills failed: %v", err)
+	}
+	if len(fills) != 1 {
+		t.Fatalf("Expected 1 fill, got %d", len(fills))
+	}
+	fill := fills[0]
+	if fill.OriginChainID != 1 || fill.DepositID != 7 || fill.Relayer != relayer || fill.TransactionHash == (common.Hash{}) {
+		t.Errorf("Unexpected fill %+v", fill)
+	}
+	if !fill.Matches(d) {
+		t.Errorf("Expected the fill to match the deposit")
+	}
+	d.RelayerFee = big.NewInt(2e15)
+	if fill.Matches(d) {
+		t.Errorf("Expected the fill not to match a deposit with another output amount")
+	}
+
+	if _, err := ParseFill(types.Log{Topics: []common.Hash{{}}}); err == nil || err.Error() != "not a FilledV3Relay event" {
+		t.Errorf("Expected error message 'not a FilledV3Relay event', got '%v'", err)
+	}
+}
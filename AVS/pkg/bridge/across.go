@@ -0,0 +1,268 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/across"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/fees"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/signer"
+)
+
+// NameAcross is the name of the Across bridge
+const NameAcross = "across"
+
+// approvalPollInterval is how often a token deposit checks whether its approval was mined
+const approvalPollInterval = time.Second
+
+// dryRunDepositGas is the gas limit of dry-run token deposits whose approval is
+// signed but not sent, as the node cannot estimate a deposit it would revert
+const dryRunDepositGas = 300000
+
+// AcrossChain is a chain Across deposits can be sent from or to
+type AcrossChain struct {
+	// Signer sends deposits from the chain and reads the fills of deposits to
+	// it. Chains only deposited to may have none, their fills going unobserved.
+	Signer    *signer.Signer
+	SpokePool common.Address
+	// Token is the reward token deposits send and deliver on the chain
+	Token common.Address
+}
+
+// Across sends transfers between chains as SpokePool deposits. The relayer
+// fee of a transfer is the base fee of its target chain, as the chain_base fee
+// model charges it. Fills are read from the SpokePool of the target chain, and
+// expired deposits are refunded by Across itself.
+type Across struct {
+	chains       map[uint64]AcrossChain
+	pools        map[uint64]*across.SpokePool
+	fees         fees.ChainFees
+	fillWindow   time.Duration
+	native       bool
+	now          func() time.Time
+	pollInterval time.Duration
+}
+
+// NewAcross creates an Across bridge between chains. With native set, deposits
+// send their input amount as value, the token being the wrapped native token.
+// Otherwise the SpokePool pulls the token, and the first deposit from a chain
+// approves it to.
+func NewAcross(chains map[uint64]AcrossChain, chainFees fees.ChainFees, fillWindow time.Duration, native bool) *Across {
+	a := &Across{
+		chains:       chains,
+		pools:        make(map[uint64]*across.SpokePool),
+		fees:         chainFees,
+		fillWindow:   fillWindow,
+		native:       native,
+		now:          time.Now,
+		pollInterval: approvalPollInterval,
+	}
+	for id, chain := range chains {
+		if chain.Signer != nil {
+			a.pools[id] = across.NewSpokePool(chain.SpokePool, chain.Signer.Client())
+		}
+	}
+	return a
+}
+
+// Name returns "across"
+func (a *Across) Name() string {
+	return NameAcross
+}
+
+// Quote charges the base fee of the target chain for transfers between two Across chains
+func (a *Across) Quote(_ context.Context, t Transfer) (Quote, error) {
+	if t.SourceChain == t.TargetChain {
+		return Quote{}, ErrUnsupportedRoute
+	}
+	if _, ok := a.pools[t.SourceChain]; !ok {
+		return Quote{}, ErrUnsupportedRoute
+	}
+	if _, ok := a.chains[t.TargetChain]; !ok {
+		return Quote{}, ErrUnsupportedRoute
+	}
+	fee := new(big.Int).Set(fees.DefaultBaseFee)
+	if params, ok := a.fees.FeeParams(t.TargetChain); ok && params.BaseFee != nil {
+		fee.Set(params.BaseFee)
+	}
+	return Quote{Bridge: NameAcross, Fee: fee}, nil
+}
+
+// Deposit sends a depositV3 to the SpokePool of the source chain, quoted at
+// the latest block, for the amount plus the quoted relayer fee
+func (a *Across) Deposit(ctx context.Context, t Transfer, q Quote) (Deposit, error) {
+	pool, ok := a.pools[t.SourceChain]
+	if !ok {
+		return Deposit{}, fmt.Errorf("no SpokePool for chain %d", t.SourceChain)
+	}
+	if _, ok := a.chains[t.TargetChain]; !ok {
+		return Deposit{}, fmt.Errorf("no reward token for chain %d", t.TargetChain)
+	}
+	var targetBlock uint64
+	if target, ok := a.pools[t.TargetChain]; ok {
+		block, err := target.LatestBlock(ctx)
+		if err != nil {
+			return Deposit{}, fmt.Errorf("chain %d: %w", t.TargetChain, err)
+		}
+		targetBlock = block
+	}
+
+	quoteTime, err := pool.QuoteTime(ctx)
+	if err != nil {
+		return Deposit{}, err
+	}
+	deposit := a.deposit(across.NewDeposit(quoteTime, a.fillWindow), t, q.Fee)
+	deposit.Native = a.native
+
+	chain := a.chains[t.SourceChain]
+	approved := true
+	if !a.native {
+		if approved, err = a.approve(ctx, chain, pool, deposit.InputAmount); err != nil {
+			return Deposit{}, err
+		}
+	}
+	tx, err := chain.Signer.Transact(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		if !approved {
+			opts.GasLimit = dryRunDepositGas
+		}
+		return pool.Deposit(opts, deposit)
+	})
+	if err != nil {
+		return Deposit{}, err
+	}
+	return Deposit{
+		Bridge:          NameAcross,
+		Transfer:        t,
+		Fee:             q.Fee,
+		TransactionHash: tx.Hash(),
+		FillDeadline:    time.Unix(int64(deposit.FillDeadline), 0),
+		TargetBlock:     targetBlock,
+	}, nil
+}
+
+// approve makes sure the SpokePool may pull amount of the reward token from the
+// signer of chain. When it may not, the SpokePool is approved for any amount,
+// so that only the first deposit pays for an approval, and the deposit waits
+// for the approval to be mined. It reports false in a dry run, whose approval
+// is signed but not sent.
+func (a *Across) approve(ctx context.Context, chain AcrossChain, pool *across.SpokePool, amount *big.Int) (bool, error) {
+	allowance, err := pool.Allowance(ctx, chain.Token, chain.Signer.Address())
+	if err != nil {
+		return false, err
+	}
+	if allowance.Cmp(amount) >= 0 {
+		return true, nil
+	}
+	tx, err := chain.Signer.Transact(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return pool.Approve(opts, chain.Token, abi.MaxUint256)
+	})
+	if err != nil {
+		return false, err
+	}
+	if chain.Signer.DryRun() {
+		return false, nil
+	}
+
+	ticker := time.NewTicker(a.pollInterval)
+	defer ticker.Stop()
+	for {
+		r, err := receipt(ctx, chain.Signer, tx.Hash())
+		if err != nil {
+			return false, err
+		}
+		if r != nil {
+			if r.Status != types.ReceiptStatusSuccessful {
+				return false, fmt.Errorf("approval %s of SpokePool %s reverted", tx.Hash(), pool.Address())
+			}
+			return true, nil
+		}
+		select {
+		case <-ctx.Done():
+			return false, fmt.Errorf("approval %s of SpokePool %s not mined: %w", tx.Hash(), pool.Address(), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// deposit fills in the SpokePool deposit d sending t at fee from the signer of
+// the source chain
+func (a *Across) deposit(d across.Deposit, t Transfer, fee *big.Int) across.Deposit {
+	d.Depositor = a.chains[t.SourceChain].Signer.Address()
+	d.Recipient = t.Recipient
+	d.InputToken = a.chains[t.SourceChain].Token
+	d.OutputToken = a.chains[t.TargetChain].Token
+	d.InputAmount = new(big.Int).Add(t.Amount, fee)
+	d.RelayerFee = fee
+	d.DestinationChainID = t.TargetChain
+	return d
+}
+
+// Status reports a deposit as failed when it reverted, as filled once a
+// relayer filled it on the target chain and as expired when its fill deadline
+// passed unfilled. Deposits to chains without a signer are pending until then.
+func (a *Across) Status(ctx context.Context, d Deposit) (Status, error) {
+	chain, ok := a.chains[d.Transfer.SourceChain]
+	if !ok || chain.Signer == nil {
+		return "", fmt.Errorf("no SpokePool for chain %d", d.Transfer.SourceChain)
+	}
+	r, err := receipt(ctx, chain.Signer, d.TransactionHash)
+	if err != nil {
+		return "", err
+	}
+	if r == nil {
+		return StatusPending, nil
+	}
+	if r.Status != types.ReceiptStatusSuccessful {
+		return StatusFailed, nil
+	}
+	filled, err := a.filled(ctx, d)
+	if err != nil {
+		return "", err
+	}
+	if filled {
+		return StatusFilled, nil
+	}
+	if !a.now().Before(d.FillDeadline) {
+		return StatusExpired, nil
+	}
+	return StatusPending, nil
+}
+
+// filled looks for the fill of d on the SpokePool of its target chain
+func (a *Across) filled(ctx context.Context, d Deposit) (bool, error) {
+	pool, ok := a.pools[d.Transfer.TargetChain]
+	if !ok {
+		return false, nil
+	}
+	fills, err := pool.Fills(ctx, d.Transfer.SourceChain, d.TargetBlock)
+	if err != nil {
+		return false, err
+	}
+	expected := a.deposit(across.Deposit{FillDeadline: uint32(d.FillDeadline.Unix())}, d.Transfer, d.Fee)
+	for _, fill := range fills {
+		if fill.Matches(expected) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Refund accepts expired deposits, which Across returns to the depositor on
+// the source chain without a transaction
+func (a *Across) Refund(ctx context.Context, d Deposit) error {
+	status, err := a.Status(ctx, d)
+	if err != nil {
+		return err
+	}
+	if status != StatusExpired {
+		return fmt.Errorf("%w: deposit %s is %s", ErrNotRefundable, d.TransactionHash, status)
+	}
+	return nil
+}
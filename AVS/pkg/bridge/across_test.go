@@ -0,0 +1,325 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/across"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/chains"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/fees"
+	"github.com/RewardFlow/RewardFlowAVS/pkg/signer"
+)
+
+var (
+	spokePoolAddress = common.HexToAddress("0x00000000000000000000000000000000000000a0")
+	fillPoolAddress  = common.HexToAddress("0x00000000000000000000000000000000000000a1")
+	weth             = common.HexToAddress("0x00000000000000000000000000000000000000c0")
+	outputWeth       = common.HexToAddress("0x00000000000000000000000000000000000000c1")
+)
+
+// mockSpokePoolCode accepts any call and value and logs the call arguments.
+// See pkg/across for the disassembly.
+func mockSpokePoolCode() []byte {
+	return common.FromHex("0x600436036004600037600436036000a000")
+}
+
+// mockFillPoolCode logs the rest of the call under the four topics the call
+// starts with, standing in for the SpokePool fills are logged by. See
+// pkg/across for the disassembly.
+func mockFillPoolCode() []byte {
+	return common.FromHex("0x608036036080600037606035604035602035600035608036036000a400")
+}
+
+// mockAllowanceTokenCode keeps a single allowance for approve and allowance calls.
+// See pkg/across for the disassembly.
+func mockAllowanceTokenCode() []byte {
+	return common.FromHex("0x60003560e01c8063095ea7b314601a5763dd62ed3e14602b57005b602435600055600160005260206000f35b60005460005260206000f3")
+}
+
+// newSimulatedSigner starts a simulated chain with a funded signer and the
+// given contracts deployed
+func newSimulatedSigner(t *testing.T, code map[common.Address][]byte) (*simulated.Backend, *signer.Signer) {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	alloc := types.GenesisAlloc{from: {Balance: big.NewInt(5 * params.Ether)}}
+	for addr, c := range code {
+		alloc[addr] = types.Account{Code: c}
+	}
+	backend := simulated.NewBackend(alloc)
+	t.Cleanup(func() { backend.Close() })
+
+	s, err := signer.New(context.Background(), backend.Client(), key, signer.FeeCaps{}, signer.DefaultStuckAfter)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	return backend, s
+}
+
+func TestAcross(t *testing.T) {
+	backend, s := newSimulatedSigner(t, map[common.Address][]byte{spokePoolAddress: mockSpokePoolCode()})
+	a := NewAcross(map[uint64]AcrossChain{
+		1337: {Signer: s, SpokePool: spokePoolAddress, Token: weth},
+		10:   {Token: outputWeth},
+		8453: {Token: outputWeth},
+	}, fees.StaticChainFees{10: {BaseFee: big.NewInt(1e15)}}, time.Hour, true)
+	ctx := context.Background()
+
+	// Routes need a SpokePool to deposit from and a token to deliver
+	for _, tt := range []struct {
+		transfer Transfer
+		fee      *big.Int
+	}{
+		{transfer: testTransfer(1337, 10), fee: big.NewInt(1e15)},
+		{transfer: testTransfer(1337, 8453), fee: fees.DefaultBaseFee},
+		{transfer: testTransfer(1337, 1337)},
+		{transfer: testTransfer(1337, 42161)},
+		{transfer: testTransfer(10, 1337)},
+	} {
+		quote, err := a.Quote(ctx, tt.transfer)
+		if tt.fee == nil {
+			if !errors.Is(err, ErrUnsupportedRoute) {
+				t.Errorf("Expected chain %d to %d to be unsupported, got %v", tt.transfer.SourceChain, tt.transfer.TargetChain, err)
+			}
+			continue
+		}
+		if err != nil || quote.Fee.Cmp(tt.fee) != 0 {
+			t.Errorf("Expected a fee of %s from chain %d to %d, got %v (%v)", tt.fee, tt.transfer.SourceChain, tt.transfer.TargetChain, quote.Fee, err)
+		}
+	}
+
+	transfer := testTransfer(1337, 10)
+	transfer.Amount = big.NewInt(1e17)
+	quote, err := a.Quote(ctx, transfer)
+	if err != nil {
+		t.Fatalf("Quote failed: %v", err)
+	}
+	d, err := a.Deposit(ctx, transfer, quote)
+	if err != nil {
+		t.Fatalf("Deposit failed: %v", err)
+	}
+	backend.Commit()
+
+	tx, _, err := backend.Client().TransactionByHash(ctx, d.TransactionHash)
+	if err != nil {
+		t.Fatalf("Failed to get deposit: %v", err)
+	}
+	sent, err := across.ParseCalldata(tx.Data())
+	if err != nil {
+		t.Fatalf("ParseCalldata failed: %v", err)
+	}
+	if sent.InputAmount.Cmp(big.NewInt(101e15)) != 0 || sent.OutputAmount().Cmp(transfer.Amount) != 0 || tx.Value().Cmp(sent.InputAmount) != 0 {
+		t.Errorf("Expected 101e15 in and 1e17 out, got %s in, %s out, %s value", sent.InputAmount, sent.OutputAmount(), tx.Value())
+	}
+	if sent.InputToken != weth || sent.OutputToken != outputWeth || sent.Recipient != testRecipient || sent.DestinationChainID != 10 {
+		t.Errorf("Unexpected deposit %+v", sent)
+	}
+	if !d.FillDeadline.Equal(time.Unix(int64(sent.FillDeadline), 0)) {
+		t.Errorf("Expected fill deadline %d, got %s", sent.FillDeadline, d.FillDeadline)
+	}
+
+	// A mined deposit stays pending until its fill deadline, then Across refunds it
+	a.now = func() time.Time { return d.FillDeadline.Add(-time.Second) }
+	if status, err := a.Status(ctx, d); err != nil || status != StatusPending {
+		t.Errorf("Expected a pending deposit, got %s (%v)", status, err)
+	}
+	if err := a.Refund(ctx, d); !errors.Is(err, ErrNotRefundable) {
+		t.Errorf("Expected a pending deposit not to be refundable, got %v", err)
+	}
+	a.now = func() time.Time { return d.FillDeadline }
+	if status, err := a.Status(ctx, d); err != nil || status != StatusExpired {
+		t.Errorf("Expected an expired deposit, got %s (%v)", status, err)
+	}
+	if err := a.Refund(ctx, d); err != nil {
+		t.Errorf("Refund failed: %v", err)
+	}
+}
+
+func TestAcross_TokenDeposit(t *testing.T) {
+	backend, s := newSimulatedSigner(t, map[common.Address][]byte{
+		spokePoolAddress: mockSpokePoolCode(),
+		weth:             mockAllowanceTokenCode(),
+	})
+	a := NewAcross(map[uint64]AcrossChain{
+		1337: {Signer: s, SpokePool: spokePoolAddress, Token: weth},
+		10:   {Token: outputWeth},
+	}, fees.StaticChainFees{10: {BaseFee: big.NewInt(1e15)}}, time.Hour, false)
+	a.pollInterval = 10 * time.Millisecond
+	ctx := context.Background()
+	pool := across.NewSpokePool(spokePoolAddress, s.Client())
+
+	// Mine blocks while deposits wait for their approval
+	done := make(chan struct{})
+	t.Cleanup(func() { close(done) })
+	go func() {
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				backend.Commit()
+			}
+		}
+	}()
+
+	deposit := func() *types.Transaction {
+		t.Helper()
+		transfer := testTransfer(1337, 10)
+		quote, err := a.Quote(ctx, transfer)
+		if err != nil {
+			t.Fatalf("Quote failed: %v", err)
+		}
+		d, err := a.Deposit(ctx, transfer, quote)
+		if err != nil {
+			t.Fatalf("Deposit failed: %v", err)
+		}
+		tx, _, err := backend.Client().TransactionByHash(ctx, d.TransactionHash)
+		if err != nil {
+			t.Fatalf("Failed to get deposit: %v", err)
+		}
+		return tx
+	}
+
+	// The first deposit approves the SpokePool, later ones reuse the allowance
+	first := deposit()
+	if allowance, err := pool.Allowance(ctx, weth, s.Address()); err != nil || allowance.Cmp(abi.MaxUint256) != 0 {
+		t.Errorf("Expected an unlimited allowance, got %v (%v)", allowance, err)
+	}
+	second := deposit()
+	if first.Nonce() != 1 || second.Nonce() != 2 {
+		t.Errorf("Expected one approval before the deposits, got deposit nonces %d and %d", first.Nonce(), second.Nonce())
+	}
+	if first.Value().Sign() != 0 || second.Value().Sign() != 0 {
+		t.Errorf("Expected token deposits to send no value, got %s and %s", first.Value(), second.Value())
+	}
+}
+
+func TestAcross_TokenDepositDryRun(t *testing.T) {
+	_, s := newSimulatedSigner(t, map[common.Address][]byte{
+		spokePoolAddress: mockSpokePoolCode(),
+		weth:             mockAllowanceTokenCode(),
+	})
+	s.SetDryRun(true)
+	a := NewAcross(map[uint64]AcrossChain{
+		1337: {Signer: s, SpokePool: spokePoolAddress, Token: weth},
+		10:   {Token: outputWeth},
+	}, fees.StaticChainFees{}, time.Hour, false)
+	ctx := context.Background()
+
+	// The approval is signed but not sent, so the deposit cannot be estimated
+	transfer := testTransfer(1337, 10)
+	quote, err := a.Quote(ctx, transfer)
+	if err != nil {
+		t.Fatalf("Quote failed: %v", err)
+	}
+	if _, err := a.Deposit(ctx, transfer, quote); err != nil {
+		t.Fatalf("Deposit failed: %v", err)
+	}
+	allowance, err := across.NewSpokePool(spokePoolAddress, s.Client()).Allowance(ctx, weth, s.Address())
+	if err != nil || allowance.Sign() != 0 {
+		t.Errorf("Expected no allowance after a dry run, got %v (%v)", allowance, err)
+	}
+	if nonce, err := s.Client().PendingNonceAt(ctx, s.Address()); err != nil || nonce != 0 {
+		t.Errorf("Expected nothing sent in a dry run, got pending nonce %d (%v)", nonce, err)
+	}
+}
+
+func TestAcross_ChainFees(t *testing.T) {
+	a := NewAcross(map[uint64]AcrossChain{1: {Signer: &signer.Signer{}}, 10: {}}, fees.StaticChainFees{10: chains.FeeParams{Bps: 5}}, time.Hour, false)
+	quote, err := a.Quote(context.Background(), testTransfer(1, 10))
+	if err != nil {
+		t.Fatalf("Quote failed: %v", err)
+	}
+	if quote.Fee.Cmp(fees.DefaultBaseFee) != 0 {
+		t.Errorf("Expected the default base fee without one for the chain, got %s", quote.Fee)
+	}
+}
+
+func TestAcross_Fill(t *testing.T) {
+	backend, s := newSimulatedSigner(t, map[common.Address][]byte{
+		spokePoolAddress: mockSpokePoolCode(),
+		fillPoolAddress:  mockFillPoolCode(),
+	})
+	// Both chains live on the simulated backend, the target one logging fills
+	a := NewAcross(map[uint64]AcrossChain{
+		1337: {Signer: s, SpokePool: spokePoolAddress, Token: weth},
+		10:   {Signer: s, SpokePool: fillPoolAddress, Token: outputWeth},
+	}, fees.StaticChainFees{}, time.Hour, true)
+	a.now = func() time.Time { return time.Unix(0, 0) }
+	ctx := context.Background()
+	backend.Commit()
+
+	transfer := testTransfer(1337, 10)
+	transfer.Amount = big.NewInt(1e17)
+	quote, err := a.Quote(ctx, transfer)
+	if err != nil {
+		t.Fatalf("Quote failed: %v", err)
+	}
+	d, err := a.Deposit(ctx, transfer, quote)
+	if err != nil {
+		t.Fatalf("Deposit failed: %v", err)
+	}
+	backend.Commit()
+	if d.TargetBlock != 1 {
+		t.Errorf("Expected fills to be looked for from block 1, got %d", d.TargetBlock)
+	}
+
+	// Only a fill of the deposit arguments fills it
+	fill := func(amount *big.Int) {
+		topics, data, err := across.Fill{
+			OriginChainID: 1337,
+			Relayer:       s.Address(),
+			Depositor:     s.Address(),
+			Recipient:     testRecipient,
+			InputToken:    weth,
+			OutputToken:   outputWeth,
+			InputAmount:   new(big.Int).Add(transfer.Amount, quote.Fee),
+			OutputAmount:  amount,
+			FillDeadline:  uint32(d.FillDeadline.Unix()),
+		}.Log()
+		if err != nil {
+			t.Fatalf("Log failed: %v", err)
+		}
+		var call []byte
+		for _, topic := range topics {
+			call = append(call, topic.Bytes()...)
+		}
+		_, err = s.Transact(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return bind.NewBoundContract(fillPoolAddress, abi.ABI{}, s.Client(), s.Client(), s.Client()).RawTransact(opts, append(call, data...))
+		})
+		if err != nil {
+			t.Fatalf("Failed to log fill: %v", err)
+		}
+		backend.Commit()
+	}
+	fill(big.NewInt(1))
+	if status, err := a.Status(ctx, d); err != nil || status != StatusPending {
+		t.Errorf("Expected a pending deposit, got %s (%v)", status, err)
+	}
+	fill(transfer.Amount)
+	if status, err := a.Status(ctx, d); err != nil || status != StatusFilled {
+		t.Errorf("Expected a filled deposit, got %s (%v)", status, err)
+	}
+
+	// A filled deposit stays filled past its deadline and is not refunded
+	a.now = func() time.Time { return d.FillDeadline }
+	if err := a.Refund(ctx, d); !errors.Is(err, ErrNotRefundable) {
+		t.Errorf("Expected a filled deposit not to be refundable, got %v", err)
+	}
+}
@@ -0,0 +1,262 @@
+// Package bridge delivers distributions to their target chain. A Bridge
+// quotes, sends, tracks and refunds transfers; the Router picks the cheapest
+// healthy bridge for each transfer, so the performer is not tied to one.
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/signer"
+)
+
+// DefaultCooldown is how long a bridge whose deposit failed is skipped on the route
+const DefaultCooldown = 5 * time.Minute
+
+var (
+	// ErrUnsupportedRoute is returned by Quote for routes a bridge cannot serve
+	ErrUnsupportedRoute = errors.New("route not supported")
+	// ErrNoRoute is returned when no healthy bridge serves a route
+	ErrNoRoute = errors.New("no healthy bridge")
+	// ErrNotRefundable is returned by Refund for deposits that cannot be refunded
+	ErrNotRefundable = errors.New("deposit cannot be refunded")
+)
+
+// Status is the state of a deposit
+type Status string
+
+const (
+	// StatusPending deposits are sent but not yet delivered
+	StatusPending Status = "pending"
+	// StatusFilled deposits were delivered to the recipient
+	StatusFilled Status = "filled"
+	// StatusFailed deposits were rejected on the source chain and moved no funds
+	StatusFailed Status = "failed"
+	// StatusExpired deposits were not delivered before their fill deadline
+	StatusExpired Status = "expired"
+	// StatusRefunded deposits were returned to the depositor
+	StatusRefunded Status = "refunded"
+)
+
+// Transfer is a distribution to deliver
+type Transfer struct {
+	Recipient   common.Address `json:"recipient"`
+	SourceChain uint64         `json:"source_chain"`
+	TargetChain uint64         `json:"target_chain"`
+	// Amount is what the recipient receives
+	Amount *big.Int `json:"amount"`
+}
+
+// Quote is what a bridge charges for a transfer
+type Quote struct {
+	Bridge string
+	// Fee is paid on top of the transfer amount
+	Fee *big.Int
+}
+
+// Deposit is a transfer sent through a bridge
+type Deposit struct {
+	Bridge   string   `json:"bridge"`
+	Transfer Transfer `json:"transfer"`
+	Fee      *big.Int `json:"fee"`
+	// TransactionHash is the deposit transaction on the source chain, as first
+	// sent; the signer may resend it under another hash
+	TransactionHash common.Hash `json:"transaction_hash"`
+	// FillDeadline is when an undelivered deposit expires, zero if it cannot
+	FillDeadline time.Time `json:"fill_deadline"`
+	// TargetBlock is the latest block of the target chain when the deposit was
+	// sent, where its fill is looked for from
+	TargetBlock uint64 `json:"target_block,omitempty"`
+}
+
+// Bridge delivers transfers to their target chain
+type Bridge interface {
+	// Name identifies the bridge in results and metrics
+	Name() string
+	// Quote returns the fee of a transfer, ErrUnsupportedRoute if the bridge cannot send it
+	Quote(ctx context.Context, t Transfer) (Quote, error)
+	// Deposit sends a transfer at a quote from Quote
+	Deposit(ctx context.Context, t Transfer, q Quote) (Deposit, error)
+	// Status reports the state of a deposit
+	Status(ctx context.Context, d Deposit) (Status, error)
+	// Refund returns an undelivered deposit to the depositor, ErrNotRefundable if it cannot be
+	Refund(ctx context.Context, d Deposit) error
+}
+
+// routeKey identifies a bridge on a route to a target chain
+type routeKey struct {
+	bridge string
+	target uint64
+}
+
+// Router sends every transfer through the cheapest healthy bridge. A bridge
+// is healthy on a route while it quotes it and no deposit on it failed within
+// the cooldown. It is safe for concurrent use.
+type Router struct {
+	bridges  []Bridge
+	byName   map[string]Bridge
+	cooldown time.Duration
+	now      func() time.Time
+
+	mu        sync.Mutex
+	unhealthy map[routeKey]time.Time
+}
+
+// NewRouter creates a router over bridges. On equal fees the earlier bridge wins.
+func NewRouter(cooldown time.Duration, bridges ...Bridge) (*Router, error) {
+	if len(bridges) == 0 {
+		return nil, errors.New("at least one bridge is required")
+	}
+	r := &Router{
+		byName:    make(map[string]Bridge, len(bridges)),
+		cooldown:  cooldown,
+		now:       time.Now,
+		unhealthy: make(map[routeKey]time.Time),
+	}
+	for _, b := range bridges {
+		if _, ok := r.byName[b.Name()]; ok {
+			return nil, fmt.Errorf("bridge %s is listed twice", b.Name())
+		}
+		r.bridges = append(r.bridges, b)
+		r.byName[b.Name()] = b
+	}
+	return r, nil
+}
+
+// Bridge returns the bridge with the given name
+func (r *Router) Bridge(name string) (Bridge, bool) {
+	b, ok := r.byName[name]
+	return b, ok
+}
+
+// candidate is a bridge quoting a transfer
+type candidate struct {
+	bridge Bridge
+	quote  Quote
+	order  int
+}
+
+// quotes returns the healthy bridges quoting t, cheapest first
+func (r *Router) quotes(ctx context.Context, t Transfer) []candidate {
+	var candidates []candidate
+	for i, b := range r.bridges {
+		if !r.healthy(b.Name(), t.TargetChain) {
+			continue
+		}
+		q, err := b.Quote(ctx, t)
+		if err != nil {
+			continue
+		}
+		candidates = append(candidates, candidate{bridge: b, quote: q, order: i})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if c := candidates[i].quote.Fee.Cmp(candidates[j].quote.Fee); c != 0 {
+			return c < 0
+		}
+		return candidates[i].order < candidates[j].order
+	})
+	return candidates
+}
+
+// Select returns the cheapest healthy bridge for t and its quote
+func (r *Router) Select(ctx context.Context, t Transfer) (Bridge, Quote, error) {
+	candidates := r.quotes(ctx, t)
+	if len(candidates) == 0 {
+		return nil, Quote{}, fmt.Errorf("%w from chain %d to chain %d", ErrNoRoute, t.SourceChain, t.TargetChain)
+	}
+	return candidates[0].bridge, candidates[0].quote, nil
+}
+
+// Deposit sends t through the cheapest healthy bridge. A bridge whose deposit
+// fails is skipped on the route for the cooldown and the next cheapest is tried.
+func (r *Router) Deposit(ctx context.Context, t Transfer) (Deposit, error) {
+	return r.deposit(ctx, t, nil)
+}
+
+// Redeposit sends the transfer of an undelivered deposit again, through the
+// cheapest healthy bridge, paying at least minFee to make it more attractive
+// to relayers
+func (r *Router) Redeposit(ctx context.Context, d Deposit, minFee *big.Int) (Deposit, error) {
+	return r.deposit(ctx, d.Transfer, minFee)
+}
+
+// deposit sends t as Deposit does, raising quotes below minFee to it
+func (r *Router) deposit(ctx context.Context, t Transfer, minFee *big.Int) (Deposit, error) {
+	candidates := r.quotes(ctx, t)
+	if len(candidates) == 0 {
+		return Deposit{}, fmt.Errorf("%w from chain %d to chain %d", ErrNoRoute, t.SourceChain, t.TargetChain)
+	}
+	var errs []error
+	for _, c := range candidates {
+		if minFee != nil && c.quote.Fee.Cmp(minFee) < 0 {
+			c.quote.Fee = new(big.Int).Set(minFee)
+		}
+		d, err := c.bridge.Deposit(ctx, t, c.quote)
+		if err == nil {
+			return d, nil
+		}
+		r.markUnhealthy(c.bridge.Name(), t.TargetChain)
+		errs = append(errs, fmt.Errorf("%s: %w", c.bridge.Name(), err))
+	}
+	return Deposit{}, errors.Join(errs...)
+}
+
+// Status reports the state of a deposit through the bridge that sent it
+func (r *Router) Status(ctx context.Context, d Deposit) (Status, error) {
+	b, ok := r.byName[d.Bridge]
+	if !ok {
+		return "", fmt.Errorf("unknown bridge %q", d.Bridge)
+	}
+	return b.Status(ctx, d)
+}
+
+// Refund refunds a deposit through the bridge that sent it
+func (r *Router) Refund(ctx context.Context, d Deposit) error {
+	b, ok := r.byName[d.Bridge]
+	if !ok {
+		return fmt.Errorf("unknown bridge %q", d.Bridge)
+	}
+	return b.Refund(ctx, d)
+}
+
+func (r *Router) healthy(name string, target uint64) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	until, ok := r.unhealthy[routeKey{name, target}]
+	if !ok {
+		return true
+	}
+	if !r.now().Before(until) {
+		delete(r.unhealthy, routeKey{name, target})
+		return true
+	}
+	return false
+}
+
+func (r *Router) markUnhealthy(name string, target uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.unhealthy[routeKey{name, target}] = r.now().Add(r.cooldown)
+}
+
+// receipt returns the receipt of a transaction s sent, or of the one it
+// resent in its place, nil while it is not mined
+func receipt(ctx context.Context, s *signer.Signer, hash common.Hash) (*types.Receipt, error) {
+	hash = s.Resolve(hash)
+	r, err := s.Client().TransactionReceipt(ctx, hash)
+	if signer.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get receipt of %s: %w", hash, err)
+	}
+	return r, nil
+}
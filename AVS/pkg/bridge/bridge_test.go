@@ -0,0 +1,201 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var testRecipient = common.HexToAddress("0x00000000000000000000000000000000000000b0")
+
+func testTransfer(source, target uint64) Transfer {
+	return Transfer{Recipient: testRecipient, SourceChain: source, TargetChain: target, Amount: big.NewInt(1e18)}
+}
+
+func TestRouter_Select(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(cheap, dear *Mock)
+		target   uint64
+		expected string
+	}{
+		{name: "cheapest bridge", target: 10, expected: "cheap"},
+		{name: "route fee", setup: func(cheap, dear *Mock) { cheap.SetFee(42161, big.NewInt(5e15)) }, target: 42161, expected: "dear"},
+		{name: "equal fees keep the bridge order", setup: func(cheap, dear *Mock) { cheap.SetFee(10, big.NewInt(2e15)) }, target: 10, expected: "dear"},
+		{name: "unhealthy bridge", setup: func(cheap, dear *Mock) { cheap.SetHealthy(false) }, target: 10, expected: "dear"},
+		{name: "unsupported route", setup: func(cheap, dear *Mock) { cheap.SetFee(10, nil) }, target: 10, expected: "dear"},
+		{
+			name:   "no bridge",
+			setup:  func(cheap, dear *Mock) { cheap.SetHealthy(false); dear.SetFee(10, nil) },
+			target: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dear := NewMock("dear", big.NewInt(2e15))
+			cheap := NewMock("cheap", big.NewInt(1e15))
+			if tt.setup != nil {
+				tt.setup(cheap, dear)
+			}
+			router, err := NewRouter(time.Minute, dear, cheap)
+			if err != nil {
+				t.Fatalf("NewRouter failed: %v", err)
+			}
+
+			b, quote, err := router.Select(context.Background(), testTransfer(1, tt.target))
+			if tt.expected == "" {
+				if expected := "no healthy bridge from chain 1 to chain 10"; err == nil || err.Error() != expected || !errors.Is(err, ErrNoRoute) {
+					t.Errorf("Expected error message '%s', got '%v'", expected, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Select failed: %v", err)
+			}
+			if b.Name() != tt.expected || quote.Bridge != tt.expected {
+				t.Errorf("Expected %s, got %s quoting %s", tt.expected, b.Name(), quote.Bridge)
+			}
+		})
+	}
+}
+
+func TestRouter_DepositFailover(t *testing.T) {
+	cheap := NewMock("cheap", big.NewInt(1e15))
+	dear := NewMock("dear", big.NewInt(2e15))
+	router, err := NewRouter(time.Minute, cheap, dear)
+	if err != nil {
+		t.Fatalf("NewRouter failed: %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	router.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// A failed deposit falls over to the next cheapest bridge
+	cheap.FailDeposits(errors.New("rpc unavailable"))
+	d, err := router.Deposit(ctx, testTransfer(1, 10))
+	if err != nil {
+		t.Fatalf("Deposit failed: %v", err)
+	}
+	if d.Bridge != "dear" || d.Fee.Cmp(big.NewInt(2e15)) != 0 {
+		t.Errorf("Expected a deposit through dear, got %+v", d)
+	}
+
+	// The failed bridge is skipped on that route for the cooldown only
+	cheap.FailDeposits(nil)
+	if b, _, _ := router.Select(ctx, testTransfer(1, 10)); b.Name() != "dear" {
+		t.Errorf("Expected cheap to cool down on chain 10, got %s", b.Name())
+	}
+	if b, _, _ := router.Select(ctx, testTransfer(1, 42161)); b.Name() != "cheap" {
+		t.Errorf("Expected cheap to stay healthy on chain 42161, got %s", b.Name())
+	}
+	now = now.Add(time.Minute)
+	if b, _, _ := router.Select(ctx, testTransfer(1, 10)); b.Name() != "cheap" {
+		t.Errorf("Expected cheap after the cooldown, got %s", b.Name())
+	}
+
+	// Every bridge failing reports each failure
+	cheap.FailDeposits(errors.New("rpc unavailable"))
+	dear.FailDeposits(errors.New("out of funds"))
+	_, err = router.Deposit(ctx, testTransfer(1, 10))
+	if expected := "cheap: rpc unavailable\ndear: out of funds"; err == nil || err.Error() != expected {
+		t.Errorf("Expected error message '%s', got '%v'", expected, err)
+	}
+}
+
+func TestRouter_StatusAndRefund(t *testing.T) {
+	mock := NewMock("mock", big.NewInt(1e15))
+	router, err := NewRouter(time.Minute, mock)
+	if err != nil {
+		t.Fatalf("NewRouter failed: %v", err)
+	}
+	ctx := context.Background()
+
+	d, err := router.Deposit(ctx, testTransfer(1, 10))
+	if err != nil {
+		t.Fatalf("Deposit failed: %v", err)
+	}
+	if again := NewMock("mock", big.NewInt(1e15)); d.TransactionHash != mustDeposit(t, again).TransactionHash {
+		t.Errorf("Expected deterministic deposit hashes")
+	}
+	if status, err := router.Status(ctx, d); err != nil || status != StatusPending {
+		t.Errorf("Expected a pending deposit, got %s (%v)", status, err)
+	}
+	if err := router.Refund(ctx, d); !errors.Is(err, ErrNotRefundable) {
+		t.Errorf("Expected a pending deposit not to be refundable, got %v", err)
+	}
+
+	mock.SetStatus(d.TransactionHash, StatusExpired)
+	if err := router.Refund(ctx, d); err != nil {
+		t.Fatalf("Refund failed: %v", err)
+	}
+	if status, _ := router.Status(ctx, d); status != StatusRefunded {
+		t.Errorf("Expected a refunded deposit, got %s", status)
+	}
+	if len(mock.Deposits()) != 1 {
+		t.Errorf("Expected 1 deposit, got %d", len(mock.Deposits()))
+	}
+
+	d.Bridge = "other"
+	if _, err := router.Status(ctx, d); err == nil || err.Error() != `unknown bridge "other"` {
+		t.Errorf("Expected error message 'unknown bridge \"other\"', got '%v'", err)
+	}
+}
+
+func TestRouter_Redeposit(t *testing.T) {
+	mock := NewMock("mock", big.NewInt(1e15))
+	router, err := NewRouter(time.Minute, mock)
+	if err != nil {
+		t.Fatalf("NewRouter failed: %v", err)
+	}
+	ctx := context.Background()
+
+	d, err := router.Deposit(ctx, testTransfer(1, 10))
+	if err != nil {
+		t.Fatalf("Deposit failed: %v", err)
+	}
+	// Quotes below the minimum fee are raised to it, higher ones kept
+	for _, tt := range []struct {
+		minFee *big.Int
+		fee    *big.Int
+	}{
+		{minFee: big.NewInt(2e15), fee: big.NewInt(2e15)},
+		{minFee: big.NewInt(1e14), fee: big.NewInt(1e15)},
+	} {
+		again, err := router.Redeposit(ctx, d, tt.minFee)
+		if err != nil {
+			t.Fatalf("Redeposit failed: %v", err)
+		}
+		if again.Fee.Cmp(tt.fee) != 0 || again.Transfer != d.Transfer || again.TransactionHash == d.TransactionHash {
+			t.Errorf("Expected a new deposit of the transfer at %s, got %+v", tt.fee, again)
+		}
+	}
+}
+
+func mustDeposit(t *testing.T, b Bridge) Deposit {
+	t.Helper()
+	transfer := testTransfer(1, 10)
+	quote, err := b.Quote(context.Background(), transfer)
+	if err != nil {
+		t.Fatalf("Quote failed: %v", err)
+	}
+	d, err := b.Deposit(context.Background(), transfer, quote)
+	if err != nil {
+		t.Fatalf("Deposit failed: %v", err)
+	}
+	return d
+}
+
+func TestNewRouter_Errors(t *testing.T) {
+	if _, err := NewRouter(time.Minute); err == nil || err.Error() != "at least one bridge is required" {
+		t.Errorf("Expected error message 'at least one bridge is required', got '%v'", err)
+	}
+	_, err := NewRouter(time.Minute, NewMock("mock", new(big.Int)), NewMock("mock", new(big.Int)))
+	if err == nil || err.Error() != "bridge mock is listed twice" {
+		t.Errorf("Expected error message 'bridge mock is listed twice', got '%v'", err)
+	}
+}
@@ -0,0 +1,132 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/signer"
+)
+
+// NameDirect is the name of the same-chain transfer bridge
+const NameDirect = "direct"
+
+// erc20ABI is the ERC-20 transfer function
+const erc20ABI = `[{
+	"type": "function",
+	"name": "transfer",
+	"stateMutability": "nonpayable",
+	"inputs": [
+		{"name": "to", "type": "address"},
+		{"name": "amount", "type": "uint256"}
+	],
+	"outputs": [{"name": "", "type": "bool"}]
+}]`
+
+var parsedERC20ABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(erc20ABI))
+	if err != nil {
+		panic(fmt.Sprintf("invalid ERC-20 ABI: %v", err))
+	}
+	return parsed
+}()
+
+// DirectChain is a chain direct transfers can be sent on
+type DirectChain struct {
+	Signer *signer.Signer
+	// Token is the reward token transferred, unused for native transfers
+	Token common.Address
+}
+
+// Direct pays transfers whose source chain is their target chain straight
+// to the recipient, with no bridge and no fee
+type Direct struct {
+	chains map[uint64]DirectChain
+	native bool
+}
+
+// NewDirect creates a same-chain transfer bridge. With native set, transfers
+// send the native currency rather than the reward token, with only the gas of
+// a plain transfer, so recipients must be accounts rather than contracts.
+func NewDirect(chains map[uint64]DirectChain, native bool) *Direct {
+	return &Direct{chains: chains, native: native}
+}
+
+// Name returns "direct"
+func (d *Direct) Name() string {
+	return NameDirect
+}
+
+// Quote is free for transfers within a chain and unsupported otherwise
+func (d *Direct) Quote(_ context.Context, t Transfer) (Quote, error) {
+	if t.SourceChain != t.TargetChain {
+		return Quote{}, ErrUnsupportedRoute
+	}
+	if _, ok := d.chains[t.SourceChain]; !ok {
+		return Quote{}, ErrUnsupportedRoute
+	}
+	return Quote{Bridge: NameDirect, Fee: new(big.Int)}, nil
+}
+
+// Deposit sends the amount to the recipient
+func (d *Direct) Deposit(ctx context.Context, t Transfer, q Quote) (Deposit, error) {
+	if t.SourceChain != t.TargetChain {
+		return Deposit{}, ErrUnsupportedRoute
+	}
+	chain, ok := d.chains[t.SourceChain]
+	if !ok {
+		return Deposit{}, fmt.Errorf("no signer for chain %d", t.SourceChain)
+	}
+
+	client := chain.Signer.Client()
+	tx, err := chain.Signer.Transact(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		if d.native {
+			// Plain value transfers skip gas estimation, which needs contract code
+			opts.Value = t.Amount
+			opts.GasLimit = params.TxGas
+			return bind.NewBoundContract(t.Recipient, abi.ABI{}, client, client, client).Transfer(opts)
+		}
+		token := bind.NewBoundContract(chain.Token, parsedERC20ABI, client, client, client)
+		return token.Transact(opts, "transfer", t.Recipient, t.Amount)
+	})
+	if err != nil {
+		return Deposit{}, fmt.Errorf("failed to transfer on chain %d: %w", t.SourceChain, err)
+	}
+	return Deposit{
+		Bridge:          NameDirect,
+		Transfer:        t,
+		Fee:             q.Fee,
+		TransactionHash: tx.Hash(),
+	}, nil
+}
+
+// Status reports a transfer as filled once it is mined, and failed if it reverted
+func (d *Direct) Status(ctx context.Context, dep Deposit) (Status, error) {
+	chain, ok := d.chains[dep.Transfer.SourceChain]
+	if !ok {
+		return "", fmt.Errorf("no signer for chain %d", dep.Transfer.SourceChain)
+	}
+	r, err := receipt(ctx, chain.Signer, dep.TransactionHash)
+	switch {
+	case err != nil:
+		return "", err
+	case r == nil:
+		return StatusPending, nil
+	case r.Status != types.ReceiptStatusSuccessful:
+		return StatusFailed, nil
+	default:
+		return StatusFilled, nil
+	}
+}
+
+// Refund always fails, as transfers are final once mined
+func (d *Direct) Refund(_ context.Context, dep Deposit) error {
+	return fmt.Errorf("%w: transfer %s is final", ErrNotRefundable, dep.TransactionHash)
+}
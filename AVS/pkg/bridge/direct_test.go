@@ -0,0 +1,84 @@
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var tokenAddress = common.HexToAddress("0x00000000000000000000000000000000000000d0")
+
+// mockTokenCode accepts any call and returns true
+//
+//	PUSH1 0x01 PUSH1 0x00 MSTORE PUSH1 0x20 PUSH1 0x00 RETURN
+func mockTokenCode() []byte {
+	return common.FromHex("0x600160005260206000f3")
+}
+
+func TestDirect(t *testing.T) {
+	backend, s := newSimulatedSigner(t, map[common.Address][]byte{tokenAddress: mockTokenCode()})
+	client := backend.Client()
+	ctx := context.Background()
+	chains := map[uint64]DirectChain{1337: {Signer: s, Token: tokenAddress}}
+
+	direct := NewDirect(chains, true)
+	if _, err := direct.Quote(ctx, testTransfer(1337, 10)); !errors.Is(err, ErrUnsupportedRoute) {
+		t.Errorf("Expected transfers between chains to be unsupported, got %v", err)
+	}
+	if _, err := direct.Quote(ctx, testTransfer(10, 10)); !errors.Is(err, ErrUnsupportedRoute) {
+		t.Errorf("Expected chains without a sender to be unsupported, got %v", err)
+	}
+	transfer := testTransfer(1337, 1337)
+	quote, err := direct.Quote(ctx, transfer)
+	if err != nil {
+		t.Fatalf("Quote failed: %v", err)
+	}
+	if quote.Fee.Sign() != 0 {
+		t.Errorf("Expected a free transfer, got %s", quote.Fee)
+	}
+
+	// Native transfers pay the recipient once mined
+	d, err := direct.Deposit(ctx, transfer, quote)
+	if err != nil {
+		t.Fatalf("Deposit failed: %v", err)
+	}
+	backend.Commit()
+	if status, err := direct.Status(ctx, d); err != nil || status != StatusFilled {
+		t.Errorf("Expected a mined transfer to be filled, got %s (%v)", status, err)
+	}
+	balance, err := client.BalanceAt(ctx, testRecipient, nil)
+	if err != nil {
+		t.Fatalf("Failed to get balance: %v", err)
+	}
+	if balance.Cmp(transfer.Amount) != 0 {
+		t.Errorf("Expected the recipient to hold %s, got %s", transfer.Amount, balance)
+	}
+	if err := direct.Refund(ctx, d); !errors.Is(err, ErrNotRefundable) {
+		t.Errorf("Expected a transfer not to be refundable, got %v", err)
+	}
+
+	// Token transfers call transfer on the reward token
+	direct = NewDirect(chains, false)
+	d, err = direct.Deposit(ctx, transfer, quote)
+	if err != nil {
+		t.Fatalf("Deposit failed: %v", err)
+	}
+	backend.Commit()
+	tx, _, err := client.TransactionByHash(ctx, d.TransactionHash)
+	if err != nil {
+		t.Fatalf("Failed to get transfer: %v", err)
+	}
+	expected, err := parsedERC20ABI.Pack("transfer", testRecipient, transfer.Amount)
+	if err != nil {
+		t.Fatalf("Failed to pack transfer: %v", err)
+	}
+	if tx.To() == nil || *tx.To() != tokenAddress || tx.Value().Sign() != 0 || !bytes.Equal(tx.Data(), expected) {
+		t.Errorf("Expected a token transfer to %s, got %x to %v", tokenAddress, tx.Data(), tx.To())
+	}
+	if status, err := direct.Status(ctx, d); err != nil || status != StatusFilled {
+		t.Errorf("Expected a mined transfer to be filled, got %s (%v)", status, err)
+	}
+}
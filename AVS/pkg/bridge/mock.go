@@ -0,0 +1,142 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Mock is a deterministic in-memory bridge for tests. It quotes every route
+// at a fixed fee unless told otherwise, and names its deposits by hashing the
+// bridge name and a counter. It is safe for concurrent use.
+type Mock struct {
+	name string
+
+	mu         sync.Mutex
+	fee        *big.Int
+	routeFees  map[uint64]*big.Int
+	unhealthy  bool
+	depositErr error
+	deposits   []Deposit
+	statuses   map[common.Hash]Status
+}
+
+// NewMock creates a mock bridge charging fee on every route
+func NewMock(name string, fee *big.Int) *Mock {
+	return &Mock{
+		name:      name,
+		fee:       new(big.Int).Set(fee),
+		routeFees: make(map[uint64]*big.Int),
+		statuses:  make(map[common.Hash]Status),
+	}
+}
+
+// Name returns the name the mock was created with
+func (m *Mock) Name() string {
+	return m.name
+}
+
+// SetFee sets the fee of transfers to a target chain. A nil fee makes the
+// route unsupported.
+func (m *Mock) SetFee(targetChain uint64, fee *big.Int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if fee == nil {
+		m.routeFees[targetChain] = nil
+		return
+	}
+	m.routeFees[targetChain] = new(big.Int).Set(fee)
+}
+
+// SetHealthy makes Quote fail while the mock is unhealthy
+func (m *Mock) SetHealthy(healthy bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.unhealthy = !healthy
+}
+
+// FailDeposits makes Deposit return err, or succeed again when err is nil
+func (m *Mock) FailDeposits(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.depositErr = err
+}
+
+// SetStatus sets the status of a deposit
+func (m *Mock) SetStatus(hash common.Hash, status Status) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.statuses[hash] = status
+}
+
+// Deposits returns the deposits sent so far, in order
+func (m *Mock) Deposits() []Deposit {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Deposit(nil), m.deposits...)
+}
+
+// Quote returns the fee of the target chain
+func (m *Mock) Quote(_ context.Context, t Transfer) (Quote, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.unhealthy {
+		return Quote{}, fmt.Errorf("%s is unhealthy", m.name)
+	}
+	fee := m.fee
+	if routeFee, ok := m.routeFees[t.TargetChain]; ok {
+		if routeFee == nil {
+			return Quote{}, ErrUnsupportedRoute
+		}
+		fee = routeFee
+	}
+	return Quote{Bridge: m.name, Fee: new(big.Int).Set(fee)}, nil
+}
+
+// Deposit records the transfer as a pending deposit
+func (m *Mock) Deposit(_ context.Context, t Transfer, q Quote) (Deposit, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.depositErr != nil {
+		return Deposit{}, m.depositErr
+	}
+	d := Deposit{
+		Bridge:          m.name,
+		Transfer:        t,
+		Fee:             new(big.Int).Set(q.Fee),
+		TransactionHash: crypto.Keccak256Hash([]byte(fmt.Sprintf("%s/%d", m.name, len(m.deposits)))),
+	}
+	m.deposits = append(m.deposits, d)
+	m.statuses[d.TransactionHash] = StatusPending
+	return d, nil
+}
+
+// Status returns the status of a deposit the mock sent
+func (m *Mock) Status(_ context.Context, d Deposit) (Status, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	status, ok := m.statuses[d.TransactionHash]
+	if !ok {
+		return "", fmt.Errorf("unknown deposit %s", d.TransactionHash)
+	}
+	return status, nil
+}
+
+// Refund marks an expired deposit as refunded
+func (m *Mock) Refund(_ context.Context, d Deposit) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	status, ok := m.statuses[d.TransactionHash]
+	if !ok {
+		return fmt.Errorf("unknown deposit %s", d.TransactionHash)
+	}
+	if status != StatusExpired {
+		return fmt.Errorf("%w: deposit %s is %s", ErrNotRefundable, d.TransactionHash, status)
+	}
+	m.statuses[d.TransactionHash] = StatusRefunded
+	return nil
+}
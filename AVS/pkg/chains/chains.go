@@ -0,0 +1,159 @@
+// Package chains is the registry of chains rewards can be distributed on. It
+// reconciles the operator configuration with the chains the RewardDistributor
+// supports.
+package chains
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// FeeParams are the per-chain distribution fee parameters
+type FeeParams struct {
+	// BaseFee is the flat fee charged on the chain, as in DistributionUtils.calculateFees.
+	// Nil leaves it to the fee model.
+	BaseFee *big.Int
+	// Bps is the proportional fee in basis points
+	Bps uint64
+}
+
+// Chain describes a chain rewards can be distributed on
+type Chain struct {
+	ID             uint64
+	Name           string
+	NativeCurrency string
+	SpokePool      common.Address
+	// Confirmations is the number of blocks a deposit needs before it is final
+	Confirmations uint64
+	// Enabled mirrors RewardDistributor.supportedChains
+	Enabled bool
+	// Paused stops routing to and from the chain without changing its support
+	Paused bool
+	Fee    FeeParams
+}
+
+// Active reports whether rewards can currently be routed to or from the chain
+func (c Chain) Active() bool {
+	return c.Enabled && !c.Paused
+}
+
+// Change is an update of a chain's support
+type Change struct {
+	ChainID uint64
+	Name    string
+	Enabled bool
+}
+
+// Registry holds the known chains in routing order, safe for concurrent use
+type Registry struct {
+	mu     sync.RWMutex
+	order  []uint64
+	chains map[uint64]Chain
+}
+
+// NewRegistry creates a registry of chains, keeping their order as the routing order
+func NewRegistry(chains []Chain) (*Registry, error) {
+	r := &Registry{chains: make(map[uint64]Chain, len(chains))}
+	for _, chain := range chains {
+		if chain.ID == 0 {
+			return nil, fmt.Errorf("chain %q has no chain ID", chain.Name)
+		}
+		if _, ok := r.chains[chain.ID]; ok {
+			return nil, fmt.Errorf("chain %d is listed twice", chain.ID)
+		}
+		r.order = append(r.order, chain.ID)
+		r.chains[chain.ID] = chain
+	}
+	return r, nil
+}
+
+// Get returns the chain with the given ID
+func (r *Registry) Get(id uint64) (Chain, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	chain, ok := r.chains[id]
+	return chain, ok
+}
+
+// All returns every chain in routing order
+func (r *Registry) All() []Chain {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	chains := make([]Chain, 0, len(r.order))
+	for _, id := range r.order {
+		chains = append(chains, r.chains[id])
+	}
+	return chains
+}
+
+// ActiveIDs returns the IDs of the active chains in routing order
+func (r *Registry) ActiveIDs() []uint64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var ids []uint64
+	for _, id := range r.order {
+		if r.chains[id].Active() {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// IsActive reports whether the chain is known and active
+func (r *Registry) IsActive(id uint64) bool {
+	chain, ok := r.Get(id)
+	return ok && chain.Active()
+}
+
+// Check returns an error describing why rewards cannot be routed to or from a chain
+func (r *Registry) Check(id uint64) error {
+	chain, ok := r.Get(id)
+	switch {
+	case !ok:
+		return fmt.Errorf("chain %d is not supported", id)
+	case !chain.Enabled:
+		return fmt.Errorf("chain %d (%s) is disabled", id, chain.Name)
+	case chain.Paused:
+		return fmt.Errorf("chain %d (%s) is paused", id, chain.Name)
+	}
+	return nil
+}
+
+// SetEnabled updates the support of a known chain, returning whether it changed
+func (r *Registry) SetEnabled(id uint64, enabled bool) (bool, error) {
+	return r.update(id, func(chain *Chain) bool {
+		changed := chain.Enabled != enabled
+		chain.Enabled = enabled
+		return changed
+	})
+}
+
+// SetPaused pauses or resumes a known chain, returning whether it changed
+func (r *Registry) SetPaused(id uint64, paused bool) (bool, error) {
+	return r.update(id, func(chain *Chain) bool {
+		changed := chain.Paused != paused
+		chain.Paused = paused
+		return changed
+	})
+}
+
+func (r *Registry) update(id uint64, apply func(chain *Chain) bool) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	chain, ok := r.chains[id]
+	if !ok {
+		return false, fmt.Errorf("chain %d is not supported", id)
+	}
+	changed := apply(&chain)
+	r.chains[id] = chain
+	return changed, nil
+}
+
+// FeeParams returns the fee parameters of a known chain
+func (r *Registry) FeeParams(id uint64) (FeeParams, bool) {
+	chain, ok := r.Get(id)
+	return chain.Fee, ok
+}
@@ -0,0 +1,99 @@
+package chains
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newTestRegistry(t *testing.T) *Registry {
+	t.Helper()
+	registry, err := NewRegistry([]Chain{
+		{ID: 1, Name: "ethereum", Enabled: true},
+		{ID: 10, Name: "optimism"},
+		{ID: 42161, Name: "arbitrum", Enabled: true},
+		{ID: 8453, Name: "base", Enabled: true, Paused: true},
+	})
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
+	return registry
+}
+
+func TestRegistry_Check(t *testing.T) {
+	registry := newTestRegistry(t)
+
+	tests := []struct {
+		name     string
+		chainID  uint64
+		errorMsg string
+	}{
+		{name: "active", chainID: 42161},
+		{name: "unknown", chainID: 56, errorMsg: "chain 56 is not supported"},
+		{name: "disabled", chainID: 10, errorMsg: "chain 10 (optimism) is disabled"},
+		{name: "paused", chainID: 8453, errorMsg: "chain 8453 (base) is paused"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := registry.Check(tt.chainID)
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("Expected chain %d to be active, got %v", tt.chainID, err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("Expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestRegistry_Toggle(t *testing.T) {
+	registry := newTestRegistry(t)
+	if got, want := registry.ActiveIDs(), []uint64{1, 42161}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Expected active chains %v, got %v", want, got)
+	}
+
+	if changed, err := registry.SetEnabled(10, true); err != nil || !changed {
+		t.Fatalf("Expected enabling optimism to change it, got %v, %v", changed, err)
+	}
+	if changed, _ := registry.SetEnabled(10, true); changed {
+		t.Errorf("Expected enabling an enabled chain to be a no-op")
+	}
+	if _, err := registry.SetPaused(8453, false); err != nil {
+		t.Fatalf("SetPaused failed: %v", err)
+	}
+	if _, err := registry.SetEnabled(1, false); err != nil {
+		t.Fatalf("SetEnabled failed: %v", err)
+	}
+
+	// Routing order is the registration order
+	if got, want := registry.ActiveIDs(), []uint64{10, 42161, 8453}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected active chains %v, got %v", want, got)
+	}
+	if _, err := registry.SetEnabled(56, true); err == nil {
+		t.Errorf("Expected an error enabling an unknown chain")
+	}
+}
+
+func TestNewRegistry_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		chains   []Chain
+		errorMsg string
+	}{
+		{name: "missing ID", chains: []Chain{{Name: "ethereum"}}, errorMsg: `chain "ethereum" has no chain ID`},
+		{name: "duplicate ID", chains: []Chain{{ID: 1, Name: "ethereum"}, {ID: 1, Name: "mainnet"}}, errorMsg: "chain 1 is listed twice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRegistry(tt.chains)
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("Expected error containing '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
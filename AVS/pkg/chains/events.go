@@ -0,0 +1,200 @@
+package chains
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"go.uber.org/zap"
+)
+
+// distributorABI is the subset of RewardDistributor used to follow chain support
+const distributorABI = `[{
+	"type": "function",
+	"name": "isChainSupported",
+	"stateMutability": "view",
+	"inputs": [{"name": "chainId", "type": "uint256"}],
+	"outputs": [{"name": "", "type": "bool"}]
+}, {
+	"type": "event",
+	"name": "ChainSupportUpdated",
+	"inputs": [
+		{"name": "chainId", "type": "uint256", "indexed": false},
+		{"name": "supported", "type": "bool", "indexed": false}
+	]
+}]`
+
+var parsedDistributorABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(distributorABI))
+	if err != nil {
+		panic(fmt.Sprintf("invalid distributor ABI: %v", err))
+	}
+	return parsed
+}()
+
+// ChainSupportUpdatedTopic is the topic of RewardDistributor.ChainSupportUpdated(uint256 chainId, bool supported)
+var ChainSupportUpdatedTopic = parsedDistributorABI.Events["ChainSupportUpdated"].ID
+
+// maxBlockRange bounds a single eth_getLogs request
+const maxBlockRange = 10000
+
+// Client is the subset of an Ethereum client used to follow a RewardDistributor
+type Client interface {
+	ethereum.ContractCaller
+	ethereum.LogFilterer
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// Follower keeps the Enabled flag of a registry in line with a RewardDistributor.
+// The first Sync reads isChainSupported for every registered chain; later syncs
+// apply ChainSupportUpdated events. Events for chains missing from the registry
+// are ignored, as the performer has no configuration to route to them.
+type Follower struct {
+	registry    *Registry
+	client      Client
+	distributor common.Address
+
+	syncMu sync.Mutex
+	next   uint64 // first block not yet applied, 0 before the first sync
+}
+
+// NewFollower creates a follower of distributor's chain support. Call Sync to load it.
+func NewFollower(registry *Registry, client Client, distributor common.Address) *Follower {
+	return &Follower{registry: registry, client: client, distributor: distributor}
+}
+
+// Sync brings the registry up to date with the distributor and returns the chains that changed
+func (f *Follower) Sync(ctx context.Context) ([]Change, error) {
+	f.syncMu.Lock()
+	defer f.syncMu.Unlock()
+
+	head, err := f.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get head block: %w", err)
+	}
+
+	if f.next == 0 {
+		changes, err := f.reconcile(ctx, head)
+		if err != nil {
+			return nil, err
+		}
+		f.next = head + 1
+		return changes, nil
+	}
+
+	var changes []Change
+	for f.next <= head {
+		to := f.next + maxBlockRange - 1
+		if to > head {
+			to = head
+		}
+
+		logs, err := f.client.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(f.next),
+			ToBlock:   new(big.Int).SetUint64(to),
+			Addresses: []common.Address{f.distributor},
+			Topics:    [][]common.Hash{{ChainSupportUpdatedTopic}},
+		})
+		if err != nil {
+			return changes, fmt.Errorf("failed to get ChainSupportUpdated logs for blocks %d-%d: %w", f.next, to, err)
+		}
+		for _, log := range logs {
+			change, ok, err := f.apply(log)
+			if err != nil {
+				return changes, err
+			}
+			if ok {
+				changes = append(changes, change)
+			}
+		}
+		f.next = to + 1
+	}
+	return changes, nil
+}
+
+// Run syncs every interval until ctx is done
+func (f *Follower) Run(ctx context.Context, interval time.Duration, logger *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changes, err := f.Sync(ctx)
+			if err != nil {
+				logger.Warn("Failed to sync chain support", zap.Error(err))
+			}
+			LogChanges(logger, changes)
+		}
+	}
+}
+
+// LogChanges logs each change of chain support
+func LogChanges(logger *zap.Logger, changes []Change) {
+	for _, change := range changes {
+		logger.Info("Chain support updated",
+			zap.Uint64("chain_id", change.ChainID),
+			zap.String("chain", change.Name),
+			zap.Bool("enabled", change.Enabled),
+		)
+	}
+}
+
+// reconcile reads isChainSupported at block for every registered chain
+func (f *Follower) reconcile(ctx context.Context, block uint64) ([]Change, error) {
+	var changes []Change
+	for _, chain := range f.registry.All() {
+		data, err := parsedDistributorABI.Pack("isChainSupported", new(big.Int).SetUint64(chain.ID))
+		if err != nil {
+			return nil, err
+		}
+		out, err := f.client.CallContract(ctx, ethereum.CallMsg{To: &f.distributor, Data: data}, new(big.Int).SetUint64(block))
+		if err != nil {
+			return nil, fmt.Errorf("failed to call isChainSupported(%d): %w", chain.ID, err)
+		}
+		values, err := parsedDistributorABI.Unpack("isChainSupported", out)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode isChainSupported(%d): %w", chain.ID, err)
+		}
+		supported := values[0].(bool)
+		if changed, _ := f.registry.SetEnabled(chain.ID, supported); changed {
+			changes = append(changes, Change{ChainID: chain.ID, Name: chain.Name, Enabled: supported})
+		}
+	}
+	return changes, nil
+}
+
+// apply decodes a ChainSupportUpdated log into the registry and reports whether it changed a chain
+func (f *Follower) apply(log types.Log) (Change, bool, error) {
+	if len(log.Topics) != 1 || log.Topics[0] != ChainSupportUpdatedTopic {
+		return Change{}, false, fmt.Errorf("log %s:%d is not a ChainSupportUpdated event", log.TxHash.Hex(), log.Index)
+	}
+	values, err := parsedDistributorABI.Unpack("ChainSupportUpdated", log.Data)
+	if err != nil {
+		return Change{}, false, fmt.Errorf("failed to decode ChainSupportUpdated log %s:%d: %w", log.TxHash.Hex(), log.Index, err)
+	}
+
+	chainID := values[0].(*big.Int)
+	supported := values[1].(bool)
+	if !chainID.IsUint64() {
+		return Change{}, false, nil
+	}
+	chain, ok := f.registry.Get(chainID.Uint64())
+	if !ok {
+		return Change{}, false, nil
+	}
+	changed, err := f.registry.SetEnabled(chain.ID, supported)
+	if err != nil || !changed {
+		return Change{}, false, err
+	}
+	return Change{ChainID: chain.ID, Name: chain.Name, Enabled: supported}, true, nil
+}
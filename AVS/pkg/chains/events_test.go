@@ -0,0 +1,159 @@
+package chains
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// mockDistributorCode stands in for the chain support of RewardDistributor,
+// keeping supportedChains[chainId] in storage slot chainId. isChainSupported
+// returns the slot; any other call is updateChainSupport(chainId, supported),
+// which stores the flag and emits ChainSupportUpdated(chainId, supported).
+//
+//	00 PUSH1 0x04 CALLDATALOAD PUSH1 0x00 CALLDATALOAD PUSH1 0xe0 SHR
+//	09 PUSH4 isChainSupported EQ PUSH1 0x45 JUMPI
+//	18 PUSH1 0x24 CALLDATALOAD DUP2 SSTORE
+//	23 PUSH1 0x40 PUSH1 0x04 PUSH1 0x00 CALLDATACOPY
+//	30 PUSH32 topic PUSH1 0x40 PUSH1 0x00 LOG1 STOP
+//	69 JUMPDEST SLOAD PUSH1 0x00 MSTORE PUSH1 0x20 PUSH1 0x00 RETURN
+func mockDistributorCode() []byte {
+	code := common.FromHex("0x60043560003560e01c63")
+	code = append(code, parsedDistributorABI.Methods["isChainSupported"].ID...)
+	code = append(code, common.FromHex("0x146045576024358155604060046000377f")...)
+	code = append(code, ChainSupportUpdatedTopic.Bytes()...)
+	code = append(code, common.FromHex("0x60406000a100")...)
+	return append(code, common.FromHex("0x5b5460005260206000f3")...)
+}
+
+var distributorAddress = common.HexToAddress("0x00000000000000000000000000000000000d1570")
+
+type simulatedDistributor struct {
+	backend *simulated.Backend
+	owner   *ecdsa.PrivateKey
+}
+
+// newSimulatedDistributor deploys the mock with the chains of the RewardDistributor constructor
+func newSimulatedDistributor(t *testing.T) *simulatedDistributor {
+	t.Helper()
+	owner, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	supported := common.BigToHash(big.NewInt(1))
+	storage := map[common.Hash]common.Hash{}
+	for _, id := range []int64{1, 42161, 137, 8453} {
+		storage[common.BigToHash(big.NewInt(id))] = supported
+	}
+	backend := simulated.NewBackend(types.GenesisAlloc{
+		distributorAddress:                      {Code: mockDistributorCode(), Storage: storage},
+		crypto.PubkeyToAddress(owner.PublicKey): {Balance: big.NewInt(params.Ether)},
+	})
+	t.Cleanup(func() { backend.Close() })
+	return &simulatedDistributor{backend: backend, owner: owner}
+}
+
+// updateChainSupport sends updateChainSupport from the owner and mines it
+func (s *simulatedDistributor) updateChainSupport(t *testing.T, chainID uint64, supported bool) {
+	t.Helper()
+	ctx := context.Background()
+	client := s.backend.Client()
+
+	id, err := client.ChainID(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get chain ID: %v", err)
+	}
+	nonce, err := client.PendingNonceAt(ctx, crypto.PubkeyToAddress(s.owner.PublicKey))
+	if err != nil {
+		t.Fatalf("Failed to get nonce: %v", err)
+	}
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to get head: %v", err)
+	}
+
+	flag := int64(0)
+	if supported {
+		flag = 1
+	}
+	data := crypto.Keccak256([]byte("updateChainSupport(uint256,bool)"))[:4]
+	data = append(data, common.BigToHash(new(big.Int).SetUint64(chainID)).Bytes()...)
+	data = append(data, common.BigToHash(big.NewInt(flag)).Bytes()...)
+
+	tip := big.NewInt(params.GWei)
+	tx, err := types.SignNewTx(s.owner, types.LatestSignerForChainID(id), &types.DynamicFeeTx{
+		ChainID:   id,
+		Nonce:     nonce,
+		GasTipCap: tip,
+		GasFeeCap: new(big.Int).Add(tip, new(big.Int).Mul(head.BaseFee, big.NewInt(2))),
+		Gas:       100000,
+		To:        &distributorAddress,
+		Data:      data,
+	})
+	if err != nil {
+		t.Fatalf("Failed to sign transaction: %v", err)
+	}
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		t.Fatalf("Failed to send transaction: %v", err)
+	}
+	s.backend.Commit()
+}
+
+func TestFollower_Sync(t *testing.T) {
+	sim := newSimulatedDistributor(t)
+	// Configured as in Constants.sol, with every chain enabled
+	registry, err := NewRegistry([]Chain{
+		{ID: 1, Name: "ethereum", Enabled: true},
+		{ID: 10, Name: "optimism", Enabled: true},
+		{ID: 42161, Name: "arbitrum", Enabled: true},
+		{ID: 137, Name: "polygon", Enabled: true},
+		{ID: 8453, Name: "base", Enabled: true},
+	})
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
+	follower := NewFollower(registry, sim.backend.Client(), distributorAddress)
+
+	// The first sync disables Optimism, which the distributor does not support
+	changes, err := follower.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if want := []Change{{ChainID: 10, Name: "optimism", Enabled: false}}; !reflect.DeepEqual(changes, want) {
+		t.Fatalf("Expected changes %+v, got %+v", want, changes)
+	}
+
+	// Later syncs apply ChainSupportUpdated events, ignoring unconfigured chains
+	sim.updateChainSupport(t, 10, true)
+	sim.updateChainSupport(t, 137, false)
+	sim.updateChainSupport(t, 56, true)
+	sim.updateChainSupport(t, 1, true)
+	changes, err = follower.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	want := []Change{
+		{ChainID: 10, Name: "optimism", Enabled: true},
+		{ChainID: 137, Name: "polygon", Enabled: false},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("Expected changes %+v, got %+v", want, changes)
+	}
+	if got, want := registry.ActiveIDs(), []uint64{1, 10, 42161, 8453}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected active chains %v, got %v", want, got)
+	}
+
+	// Nothing new to apply
+	changes, err = follower.Sync(context.Background())
+	if err != nil || len(changes) != 0 {
+		t.Errorf("Expected no changes, got %+v, %v", changes, err)
+	}
+}
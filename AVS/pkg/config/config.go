@@ -0,0 +1,952 @@
+// Package config loads the RewardFlow operator configuration from a YAML file,
+// a per-environment profile and environment variable overrides.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/yaml.v3"
+)
+
+// Validation policy sources
+const (
+	PolicySourceStatic    = "static"
+	PolicySourceRegistrar = "registrar"
+)
+
+// User preference sources
+const (
+	PreferencesSourceNone   = "none"
+	PreferencesSourceFile   = "file"
+	PreferencesSourceEvents = "events"
+)
+
+// Fee models, see pkg/fees
+const (
+	FeeModelFlatBps     = "flat_bps"
+	FeeModelChainBase   = "chain_base"
+	FeeModelBasePlusBps = "base_plus_bps"
+)
+
+// Fee split beneficiaries, see pkg/fees
+const (
+	BeneficiaryLP              = "lp"
+	BeneficiaryOperator        = "operator"
+	BeneficiaryProtocol        = "protocol"
+	BeneficiaryGasCompensation = "gas_compensation"
+)
+
+// Tier modes: verify only checks the amounts of tier tasks that carry their
+// base amount, apply also multiplies the amounts of those that do not
+const (
+	TierModeVerify = "verify"
+	TierModeApply  = "apply"
+)
+
+// User activity sources, see pkg/engagement
+const (
+	EngagementSourceNone   = "none"
+	EngagementSourceTasks  = "tasks"
+	EngagementSourceEvents = "events"
+)
+
+// Chain support sources
+const (
+	ChainSupportSourceStatic = "static"
+	ChainSupportSourceEvents = "events"
+)
+
+// Gas price sources, see pkg/gas
+const (
+	GasSourceNone   = "none"
+	GasSourceStatic = "static"
+	GasSourceRPC    = "rpc"
+)
+
+// Execution modes: live sends distributions, dry-run builds and signs their
+// transactions without sending them, and shadow does the same while comparing
+// its results with those of a live operator
+const (
+	ExecutionModeLive   = "live"
+	ExecutionModeDryRun = "dry-run"
+	ExecutionModeShadow = "shadow"
+)
+
+// defaultNativeCurrency is the native currency of chains that do not set one
+const defaultNativeCurrency = "ETH"
+
+// Supported environments, matching specs/runtime
+const (
+	EnvironmentDevnet  = "devnet"
+	EnvironmentTestnet = "testnet"
+	EnvironmentMainnet = "mainnet"
+)
+
+// Environments lists every supported environment
+var Environments = []string{EnvironmentDevnet, EnvironmentTestnet, EnvironmentMainnet}
+
+// Config is the complete performer configuration
+type Config struct {
+	// Environment selects the profile applied on top of the base configuration
+	Environment string            `yaml:"environment"`
+	Server      ServerConfig      `yaml:"server"`
+	Metrics     MetricsConfig     `yaml:"metrics"`
+	Logging     LoggingConfig     `yaml:"logging"`
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+	Scheduler   SchedulerConfig   `yaml:"scheduler"`
+	// Gas defers distributions while gas is expensive or would eat the reward
+	Gas GasConfig `yaml:"gas"`
+	// Signer prices and tracks the transactions distributions are sent with
+	Signer SignerConfig `yaml:"signer"`
+	// Across sends cross-chain distributions as SpokePool deposits
+	Across AcrossConfig `yaml:"across"`
+	// Direct pays same-chain distributions straight to the user
+	Direct DirectConfig `yaml:"direct"`
+	// Transfers follows bridge deposits until they are delivered
+	Transfers TransfersConfig `yaml:"transfers"`
+	// Execution selects whether distributions are sent or only rehearsed
+	Execution ExecutionConfig `yaml:"execution"`
+	Rewards   RewardsConfig   `yaml:"rewards"`
+	// ValidationPolicy selects where the reward limits come from
+	ValidationPolicy ValidationPolicyConfig `yaml:"validation_policy"`
+	// Preferences selects where per-user routing preferences come from
+	Preferences PreferencesConfig `yaml:"preferences"`
+	// Routing selects where distributions without a usable preference go
+	Routing RoutingConfig `yaml:"routing"`
+	// Engagement selects where the user activity behind engagement scores comes from
+	Engagement EngagementConfig `yaml:"engagement"`
+	// ChainSupport selects whether chains[].enabled is kept in line with the RewardDistributor
+	ChainSupport ChainSupportConfig `yaml:"chain_support"`
+	EigenLayer   EigenLayerConfig   `yaml:"eigenlayer"`
+	// Chains lists the supported chains. Their order breaks ties between
+	// equally cheap fallback chains.
+	Chains []ChainConfig `yaml:"chains"`
+}
+
+// ServerConfig configures the ponos performer server
+type ServerConfig struct {
+	Port    int           `yaml:"port"`
+	Timeout time.Duration `yaml:"timeout"`
+	// DrainTimeout bounds how long shutdown waits for in-flight tasks
+	DrainTimeout time.Duration `yaml:"drain_timeout"`
+	// ResultEncoding is "json" or "abi"
+	ResultEncoding string `yaml:"result_encoding"`
+}
+
+// MetricsConfig configures the Prometheus listener
+type MetricsConfig struct {
+	Port int `yaml:"port"`
+}
+
+// LoggingConfig configures the performer logger
+type LoggingConfig struct {
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"` // "json" or "text"
+}
+
+// IdempotencyConfig configures the processed task store
+type IdempotencyConfig struct {
+	Path          string        `yaml:"path"`
+	Retention     time.Duration `yaml:"retention"`
+	PruneInterval time.Duration `yaml:"prune_interval"`
+}
+
+// SchedulerConfig sizes the distribution scheduler, which runs pending
+// distributions by DistributionUtils.calculateDistributionPriority
+type SchedulerConfig struct {
+	// Concurrency is how many distributions run at once
+	Concurrency int `yaml:"concurrency"`
+	// QueueSize is how many distributions may wait; more are rejected
+	QueueSize int `yaml:"queue_size"`
+	// MaxWait is how long a distribution waits before it goes ahead of higher priorities
+	MaxWait time.Duration `yaml:"max_wait"`
+}
+
+// GasConfig selects the gas price source and when distributions wait for
+// cheaper gas. The static source reports static_price on every chain; the rpc
+// source asks chains[].rpc of the target chain. Nothing is deferred with none.
+type GasConfig struct {
+	Source      string  `yaml:"source"`
+	StaticPrice *Amount `yaml:"static_price"`
+	// MaxPrice is the gas price above which distributions are deferred
+	MaxPrice *Amount `yaml:"max_price"`
+	// GasLimit is the gas a distribution is expected to cost
+	GasLimit uint64 `yaml:"gas_limit"`
+	// MinProfit is the least a distribution must deliver after fees and gas
+	MinProfit *Amount `yaml:"min_profit"`
+	// MaxDelay is how long after its timestamp a task may be deferred
+	MaxDelay time.Duration `yaml:"max_delay"`
+}
+
+// SignerConfig sets the key distributions are sent from and how their
+// transactions are priced. The key is the encrypted JSON keystore, unlocked
+// with AVS_KEYSTORE_PASSWORD, or the raw hex key in AVS_PRIVATE_KEY; both are
+// read from the environment only.
+type SignerConfig struct {
+	Keystore string `yaml:"keystore"`
+	// MaxFeePerGas caps the EIP-1559 fee cap of every transaction
+	MaxFeePerGas *Amount `yaml:"max_fee_per_gas"`
+	// MaxPriorityFeePerGas caps the priority fee of every transaction
+	MaxPriorityFeePerGas *Amount `yaml:"max_priority_fee_per_gas"`
+	// StuckAfter is how long a transaction may stay pending before it is resent with higher fees
+	StuckAfter time.Duration `yaml:"stuck_after"`
+	// RecoverInterval is how often pending transactions are checked
+	RecoverInterval time.Duration `yaml:"recover_interval"`
+}
+
+// AcrossConfig sends cross-chain distributions as Across SpokePool deposits
+// from the signer key, each enabled chain needing rpc, spoke_pool and
+// reward_token. Distributions are simulated while neither across nor direct
+// is enabled.
+type AcrossConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// FillDeadline is how long relayers have to fill a deposit
+	FillDeadline time.Duration `yaml:"fill_deadline"`
+	// Native sends rewards as the native currency, reward_token being its wrapped token
+	Native bool `yaml:"native"`
+}
+
+// DirectConfig pays distributions whose target chain is the task's chain
+// straight to the user, with no bridge, from the signer key.
+// Each enabled chain needs rpc, and reward_token unless native.
+type DirectConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Native pays the native currency rather than reward_token
+	Native bool `yaml:"native"`
+}
+
+// TransfersConfig sets how the deposits of bridged distributions are followed
+// until they are delivered, after the cross-chain limits of the contracts.
+// It applies while across or direct is enabled.
+type TransfersConfig struct {
+	// Path is the file transfers are kept in across restarts
+	Path string `yaml:"path"`
+	// Timeout is how long a transfer has to be delivered before it fails and is refunded
+	Timeout time.Duration `yaml:"timeout"`
+	// MaxRetries is how many times an undelivered deposit is sent again
+	MaxRetries int `yaml:"max_retries"`
+	// FeeBumpBps raises the relayer fee of each retry over the last deposit
+	FeeBumpBps uint64 `yaml:"fee_bump_bps"`
+	// PollInterval is how often deposits are checked
+	PollInterval time.Duration `yaml:"poll_interval"`
+}
+
+// ExecutionConfig selects whether distributions move funds, so that new
+// performer versions can be staged on mainnet. Outside live mode nothing is
+// sent, no transfer is tracked and no result is kept for duplicate tasks.
+type ExecutionConfig struct {
+	// Mode is live, dry-run or shadow
+	Mode string `yaml:"mode"`
+	// LiveURL is the metrics listener of the live operator a shadow compares its results with
+	LiveURL string `yaml:"live_url"`
+	// CompareTimeout is how long a shadow waits for the live result of a task
+	CompareTimeout time.Duration `yaml:"compare_timeout"`
+	// CompareInterval is how often a shadow asks for a live result not yet available
+	CompareInterval time.Duration `yaml:"compare_interval"`
+}
+
+// RewardsConfig holds the task validation and fee parameters
+type RewardsConfig struct {
+	MinAmount *Amount `yaml:"min_amount"`
+	MaxAmount *Amount `yaml:"max_amount"`
+	// TaskFee is the flat fee the hook charges per task
+	TaskFee *Amount `yaml:"task_fee"`
+	// FeeBps is the processing fee in basis points
+	FeeBps uint64 `yaml:"fee_bps"`
+	// FeeModel is flat_bps, chain_base or base_plus_bps
+	FeeModel string `yaml:"fee_model"`
+	// ProtocolFeeShareBps is the protocol's share of the proportional fee; operators get the rest
+	ProtocolFeeShareBps uint64 `yaml:"protocol_fee_share_bps"`
+	// MaxTaskAge is how old a task timestamp may be
+	MaxTaskAge time.Duration `yaml:"max_task_age"`
+	// Split divides collected fees and MEV between their beneficiaries
+	Split SplitConfig `yaml:"split"`
+	// TierMode is verify or apply, see pkg/tier
+	TierMode string `yaml:"tier_mode"`
+}
+
+// SplitConfig holds the basis-point shares of collected fees and MEV, as in
+// Constants.sol. Whatever the shares leave goes to the remainder beneficiary.
+type SplitConfig struct {
+	LPBps              uint64 `yaml:"lp_bps"`
+	OperatorBps        uint64 `yaml:"operator_bps"`
+	ProtocolBps        uint64 `yaml:"protocol_bps"`
+	GasCompensationBps uint64 `yaml:"gas_compensation_bps"`
+	Remainder          string `yaml:"remainder"`
+}
+
+// ValidationPolicyConfig selects the source of the reward limits. The static
+// source uses the rewards section; the registrar source reads rewardFlowConfig
+// from RewardFlowAVSRegistrar over eigenlayer.l1_rpc.
+type ValidationPolicyConfig struct {
+	Source           string        `yaml:"source"`
+	RegistrarAddress string        `yaml:"registrar_address"`
+	RefreshInterval  time.Duration `yaml:"refresh_interval"`
+}
+
+// PreferencesConfig selects the source of user preferences. The file source
+// reads a JSON object keyed by user address; the events source follows the
+// PreferencesUpdated events of a RewardDistributor.
+type PreferencesConfig struct {
+	Source             string        `yaml:"source"`
+	Path               string        `yaml:"path"`
+	RPC                string        `yaml:"rpc"`
+	DistributorAddress string        `yaml:"distributor_address"`
+	FromBlock          uint64        `yaml:"from_block"`
+	PollInterval       time.Duration `yaml:"poll_interval"`
+}
+
+// RoutingConfig sets where distributions go when the user has no preference or
+// prefers an inactive chain
+type RoutingConfig struct {
+	// FallbackChain receives them when it is active and is not the source chain.
+	// Zero, or a chain that cannot be used, routes them to the active chain
+	// cheapest to distribute to.
+	FallbackChain uint64 `yaml:"fallback_chain"`
+}
+
+// EngagementConfig selects the source of user activity. The tasks source
+// records the liquidity and swap tasks the performer processes; the events
+// source follows the ActivityRecorded events of a contract.
+type EngagementConfig struct {
+	Source          string        `yaml:"source"`
+	RPC             string        `yaml:"rpc"`
+	ContractAddress string        `yaml:"contract_address"`
+	FromBlock       uint64        `yaml:"from_block"`
+	PollInterval    time.Duration `yaml:"poll_interval"`
+	// InactiveThreshold is how long after their last activity users count as active
+	InactiveThreshold time.Duration `yaml:"inactive_threshold"`
+}
+
+// ChainSupportConfig selects the source of chain support. The static source
+// uses chains[].enabled; the events source reads isChainSupported from a
+// RewardDistributor at startup and then follows its ChainSupportUpdated events.
+type ChainSupportConfig struct {
+	Source             string        `yaml:"source"`
+	RPC                string        `yaml:"rpc"`
+	DistributorAddress string        `yaml:"distributor_address"`
+	PollInterval       time.Duration `yaml:"poll_interval"`
+}
+
+// EigenLayerConfig locates the AVS contracts
+type EigenLayerConfig struct {
+	L1RPC      string `yaml:"l1_rpc"`
+	L2RPC      string `yaml:"l2_rpc"`
+	AVSAddress string `yaml:"avs_address"`
+}
+
+// ChainConfig describes a chain rewards can be distributed on
+type ChainConfig struct {
+	ChainID        uint64 `yaml:"chain_id"`
+	Name           string `yaml:"name"`
+	NativeCurrency string `yaml:"native_currency"`
+	RPC            string `yaml:"rpc"`
+	SpokePool      string `yaml:"spoke_pool"`
+	// RewardToken is the token Across deposits and direct transfers of rewards send on the chain
+	RewardToken string `yaml:"reward_token"`
+	// Confirmations is how many blocks a deposit needs before it is final
+	Confirmations uint64 `yaml:"confirmations"`
+	// Enabled defaults to true. The events chain support source overrides it.
+	Enabled *bool `yaml:"enabled"`
+	// Paused stops routing to and from the chain, whatever its support
+	Paused bool `yaml:"paused"`
+	// BaseFee is the flat distribution fee on the chain
+	BaseFee *Amount `yaml:"base_fee"`
+	// FeeBps is the proportional distribution fee on the chain
+	FeeBps uint64 `yaml:"fee_bps"`
+}
+
+// IsEnabled reports whether the chain is enabled, which it is unless set to false
+func (c ChainConfig) IsEnabled() bool {
+	return c.Enabled == nil || *c.Enabled
+}
+
+// Currency returns the native currency of the chain, ETH if it is not set
+func (c ChainConfig) Currency() string {
+	if c.NativeCurrency == "" {
+		return defaultNativeCurrency
+	}
+	return c.NativeCurrency
+}
+
+// Amount is a wei amount written in YAML as a decimal integer
+type Amount struct {
+	big.Int
+}
+
+// NewAmount creates an Amount from a *big.Int
+func NewAmount(v *big.Int) *Amount {
+	a := &Amount{}
+	a.Set(v)
+	return a
+}
+
+// UnmarshalYAML parses a decimal wei amount
+func (a *Amount) UnmarshalYAML(value *yaml.Node) error {
+	if _, ok := a.SetString(strings.TrimSpace(value.Value), 10); !ok {
+		return fmt.Errorf("line %d: invalid wei amount %q", value.Line, value.Value)
+	}
+	return nil
+}
+
+// MarshalYAML writes the amount as a decimal string
+func (a Amount) MarshalYAML() (interface{}, error) {
+	return a.String(), nil
+}
+
+// Default returns the built-in configuration, which matches the performer's historical constants
+func Default() *Config {
+	return &Config{
+		Environment: EnvironmentDevnet,
+		Server: ServerConfig{
+			Port:           8080,
+			Timeout:        30 * time.Second, // Increased timeout for cross-chain operations
+			DrainTimeout:   30 * time.Second,
+			ResultEncoding: "json",
+		},
+		Metrics: MetricsConfig{Port: 9090},
+		Logging: LoggingConfig{Level: "info", Format: "json"},
+		Idempotency: IdempotencyConfig{
+			Path:          "./data/idempotency.db",
+			Retention:     7 * 24 * time.Hour,
+			PruneInterval: time.Hour,
+		},
+		Scheduler: SchedulerConfig{
+			Concurrency: 8,
+			QueueSize:   1000,
+			MaxWait:     30 * time.Second,
+		},
+		Gas: GasConfig{
+			Source:    GasSourceNone,
+			MaxPrice:  NewAmount(big.NewInt(100e9)), // 100 gwei
+			GasLimit:  150000,
+			MinProfit: NewAmount(new(big.Int)), // isDistributionProfitable with no threshold
+			MaxDelay:  4 * time.Hour,
+		},
+		Signer: SignerConfig{
+			MaxFeePerGas:         NewAmount(big.NewInt(200e9)), // 200 gwei
+			MaxPriorityFeePerGas: NewAmount(big.NewInt(2e9)),   // 2 gwei
+			StuckAfter:           3 * time.Minute,
+			RecoverInterval:      30 * time.Second,
+		},
+		Across: AcrossConfig{
+			FillDeadline: 30 * time.Minute, // _callAcrossSpokePool
+			Native:       true,
+		},
+		Direct: DirectConfig{
+			Native: true,
+		},
+		Transfers: TransfersConfig{
+			Path:         "./data/transfers.db",
+			Timeout:      time.Hour, // CROSS_CHAIN_TIMEOUT
+			MaxRetries:   3,         // MAX_CROSS_CHAIN_RETRIES
+			FeeBumpBps:   2500,
+			PollInterval: 30 * time.Second,
+		},
+		Execution: ExecutionConfig{
+			Mode:            ExecutionModeLive,
+			CompareTimeout:  5 * time.Minute,
+			CompareInterval: 10 * time.Second,
+		},
+		Rewards: RewardsConfig{
+			MinAmount:  NewAmount(big.NewInt(1e15)),                                    // 0.001 ETH
+			MaxAmount:  NewAmount(new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18))), // 100 ETH
+			TaskFee:    NewAmount(big.NewInt(1e14)),                                    // 0.0001 ETH
+			FeeBps:     10,                                                             // 0.1%
+			FeeModel:   FeeModelFlatBps,
+			MaxTaskAge: 24 * time.Hour,
+			Split: SplitConfig{
+				LPBps:              5000, // LP_FEE_SHARE
+				OperatorBps:        1000, // OPERATOR_FEE_SHARE
+				ProtocolBps:        300,  // PROTOCOL_FEE_SHARE
+				GasCompensationBps: 200,  // GAS_COMPENSATION_SHARE
+				Remainder:          BeneficiaryLP,
+			},
+			TierMode: TierModeVerify,
+		},
+		ValidationPolicy: ValidationPolicyConfig{
+			Source:          PolicySourceStatic,
+			RefreshInterval: 5 * time.Minute,
+		},
+		Preferences: PreferencesConfig{
+			Source:       PreferencesSourceNone,
+			Path:         "./data/preferences.json",
+			PollInterval: 30 * time.Second,
+		},
+		Engagement: EngagementConfig{
+			Source:            EngagementSourceNone,
+			PollInterval:      30 * time.Second,
+			InactiveThreshold: 7 * 24 * time.Hour, // ActivityTracking.CONSISTENCY_WINDOW
+		},
+		ChainSupport: ChainSupportConfig{
+			Source:       ChainSupportSourceStatic,
+			PollInterval: 30 * time.Second,
+		},
+		// Base fees follow DistributionUtils.calculateFees
+		Chains: []ChainConfig{
+			{ChainID: 1, Name: "ethereum", NativeCurrency: "ETH", Confirmations: 12, BaseFee: NewAmount(big.NewInt(1e15))},
+			{ChainID: 10, Name: "optimism", NativeCurrency: "ETH", Confirmations: 10, BaseFee: NewAmount(big.NewInt(1e15))},
+			{ChainID: 42161, Name: "arbitrum", NativeCurrency: "ETH", Confirmations: 10, BaseFee: NewAmount(big.NewInt(1e14))},
+			{ChainID: 137, Name: "polygon", NativeCurrency: "POL", Confirmations: 128, BaseFee: NewAmount(big.NewInt(5e13))},
+			{ChainID: 8453, Name: "base", NativeCurrency: "ETH", Confirmations: 10, BaseFee: NewAmount(big.NewInt(66666666666666))}, // 0.001 ETH / 15,
+		},
+	}
+}
+
+// file is the on-disk layout: a base configuration plus per-environment profiles
+type file struct {
+	Config       `yaml:",inline"`
+	Environments map[string]yaml.Node `yaml:"environments"`
+}
+
+// Load builds the configuration from the defaults, the YAML file at path (if
+// any), the profile of the selected environment and environment variables, in
+// that order, and validates the result. A non-empty environment argument takes
+// precedence over the file and the ENVIRONMENT variable.
+func Load(path, environment string) (*Config, error) {
+	return load(path, environment, os.LookupEnv)
+}
+
+func load(path, environment string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	cfg := Default()
+
+	var profiles map[string]yaml.Node
+	if path != "" {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+
+		f := file{Config: *cfg}
+		if err := decodeStrict(raw, &f); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+		*cfg = f.Config
+		profiles = f.Environments
+	}
+
+	// The environment must be known before its profile is applied
+	if v, ok := lookupEnv(EnvEnvironment); ok && v != "" {
+		cfg.Environment = v
+	}
+	if environment != "" {
+		cfg.Environment = environment
+	}
+	cfg.Environment = strings.ToLower(cfg.Environment)
+
+	for name := range profiles {
+		if !isEnvironment(name) {
+			return nil, fmt.Errorf("config file %s: unknown environment profile %q", path, name)
+		}
+	}
+	if profile, ok := profiles[cfg.Environment]; ok {
+		selected := cfg.Environment
+		raw, err := yaml.Marshal(&profile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s profile: %w", selected, err)
+		}
+		if err := decodeStrict(raw, cfg); err != nil {
+			return nil, fmt.Errorf("failed to apply %s profile: %w", selected, err)
+		}
+		// A profile cannot switch to another environment
+		cfg.Environment = selected
+	}
+
+	if err := applyEnv(cfg, lookupEnv); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// decodeStrict decodes YAML onto out, rejecting unknown fields. An empty
+// document leaves out unchanged.
+func decodeStrict(raw []byte, out interface{}) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(raw))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(out); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// Validate checks the whole configuration and reports every problem at once
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if !isEnvironment(c.Environment) {
+		fail("environment: must be one of %s, got %q", strings.Join(Environments, ", "), c.Environment)
+	}
+
+	if !validPort(c.Server.Port) {
+		fail("server.port: invalid port %d", c.Server.Port)
+	}
+	if c.Server.Timeout <= 0 {
+		fail("server.timeout: must be positive")
+	}
+	if c.Server.DrainTimeout <= 0 {
+		fail("server.drain_timeout: must be positive")
+	}
+	if c.Server.ResultEncoding != "json" && c.Server.ResultEncoding != "abi" {
+		fail("server.result_encoding: must be json or abi, got %q", c.Server.ResultEncoding)
+	}
+	if c.Scheduler.Concurrency <= 0 {
+		fail("scheduler.concurrency: must be positive")
+	}
+	if c.Scheduler.QueueSize <= 0 {
+		fail("scheduler.queue_size: must be positive")
+	}
+	if c.Scheduler.MaxWait <= 0 {
+		fail("scheduler.max_wait: must be positive")
+	}
+	if !validPort(c.Metrics.Port) {
+		fail("metrics.port: invalid port %d", c.Metrics.Port)
+	} else if c.Metrics.Port == c.Server.Port {
+		fail("metrics.port: must differ from server.port (%d)", c.Server.Port)
+	}
+
+	if _, err := zapcore.ParseLevel(c.Logging.Level); err != nil {
+		fail("logging.level: invalid level %q", c.Logging.Level)
+	}
+	if c.Logging.Format != "json" && c.Logging.Format != "text" {
+		fail("logging.format: must be json or text, got %q", c.Logging.Format)
+	}
+
+	if c.Idempotency.Path == "" {
+		fail("idempotency.path: is required")
+	}
+	if c.Idempotency.Retention < 0 {
+		fail("idempotency.retention: must not be negative")
+	}
+	if c.Idempotency.PruneInterval <= 0 {
+		fail("idempotency.prune_interval: must be positive")
+	}
+
+	if c.Rewards.MinAmount == nil || c.Rewards.MinAmount.Sign() <= 0 {
+		fail("rewards.min_amount: must be positive")
+	}
+	if c.Rewards.MaxAmount == nil || c.Rewards.MaxAmount.Sign() <= 0 {
+		fail("rewards.max_amount: must be positive")
+	} else if c.Rewards.MinAmount != nil && c.Rewards.MaxAmount.Cmp(&c.Rewards.MinAmount.Int) < 0 {
+		fail("rewards.max_amount: %s is below min_amount %s", c.Rewards.MaxAmount, c.Rewards.MinAmount)
+	}
+	if c.Rewards.TaskFee == nil || c.Rewards.TaskFee.Sign() < 0 {
+		fail("rewards.task_fee: must not be negative")
+	}
+	if c.Rewards.FeeBps >= 10000 {
+		fail("rewards.fee_bps: must be below 10000, got %d", c.Rewards.FeeBps)
+	}
+	switch c.Rewards.FeeModel {
+	case FeeModelFlatBps, FeeModelChainBase, FeeModelBasePlusBps:
+	default:
+		fail("rewards.fee_model: must be %s, %s or %s, got %q", FeeModelFlatBps, FeeModelChainBase, FeeModelBasePlusBps, c.Rewards.FeeModel)
+	}
+	if c.Rewards.ProtocolFeeShareBps > 10000 {
+		fail("rewards.protocol_fee_share_bps: must be at most 10000, got %d", c.Rewards.ProtocolFeeShareBps)
+	}
+	if c.Rewards.MaxTaskAge <= 0 {
+		fail("rewards.max_task_age: must be positive")
+	}
+	if c.Rewards.TierMode != TierModeVerify && c.Rewards.TierMode != TierModeApply {
+		fail("rewards.tier_mode: must be %s or %s, got %q", TierModeVerify, TierModeApply, c.Rewards.TierMode)
+	}
+	split := c.Rewards.Split
+	if total := split.LPBps + split.OperatorBps + split.ProtocolBps + split.GasCompensationBps; total > 10000 {
+		fail("rewards.split: shares add up to %d bps, more than 10000", total)
+	}
+	switch split.Remainder {
+	case BeneficiaryLP, BeneficiaryOperator, BeneficiaryProtocol, BeneficiaryGasCompensation:
+	default:
+		fail("rewards.split.remainder: must be %s, %s, %s or %s, got %q", BeneficiaryLP, BeneficiaryOperator, BeneficiaryProtocol, BeneficiaryGasCompensation, split.Remainder)
+	}
+
+	switch c.ValidationPolicy.Source {
+	case PolicySourceStatic:
+	case PolicySourceRegistrar:
+		if !common.IsHexAddress(c.ValidationPolicy.RegistrarAddress) {
+			fail("validation_policy.registrar_address: invalid address %q", c.ValidationPolicy.RegistrarAddress)
+		}
+		if c.EigenLayer.L1RPC == "" {
+			fail("eigenlayer.l1_rpc: is required by the registrar validation policy")
+		}
+	default:
+		fail("validation_policy.source: must be %s or %s, got %q", PolicySourceStatic, PolicySourceRegistrar, c.ValidationPolicy.Source)
+	}
+	if c.ValidationPolicy.RefreshInterval <= 0 {
+		fail("validation_policy.refresh_interval: must be positive")
+	}
+
+	switch c.Preferences.Source {
+	case PreferencesSourceNone:
+	case PreferencesSourceFile:
+		if c.Preferences.Path == "" {
+			fail("preferences.path: is required by the file source")
+		}
+	case PreferencesSourceEvents:
+		if !validURL(c.Preferences.RPC) {
+			fail("preferences.rpc: invalid URL %q", c.Preferences.RPC)
+		}
+		if !common.IsHexAddress(c.Preferences.DistributorAddress) {
+			fail("preferences.distributor_address: invalid address %q", c.Preferences.DistributorAddress)
+		}
+		if c.Preferences.PollInterval <= 0 {
+			fail("preferences.poll_interval: must be positive")
+		}
+	default:
+		fail("preferences.source: must be %s, %s or %s, got %q", PreferencesSourceNone, PreferencesSourceFile, PreferencesSourceEvents, c.Preferences.Source)
+	}
+
+	if c.Routing.FallbackChain != 0 && !c.hasChain(c.Routing.FallbackChain) {
+		fail("routing.fallback_chain: chain %d is not configured", c.Routing.FallbackChain)
+	}
+
+	switch c.Engagement.Source {
+	case EngagementSourceNone, EngagementSourceTasks:
+	case EngagementSourceEvents:
+		if !validURL(c.Engagement.RPC) {
+			fail("engagement.rpc: invalid URL %q", c.Engagement.RPC)
+		}
+		if !common.IsHexAddress(c.Engagement.ContractAddress) {
+			fail("engagement.contract_address: invalid address %q", c.Engagement.ContractAddress)
+		}
+		if c.Engagement.PollInterval <= 0 {
+			fail("engagement.poll_interval: must be positive")
+		}
+	default:
+		fail("engagement.source: must be %s, %s or %s, got %q", EngagementSourceNone, EngagementSourceTasks, EngagementSourceEvents, c.Engagement.Source)
+	}
+	if c.Engagement.InactiveThreshold < 0 {
+		fail("engagement.inactive_threshold: must not be negative")
+	}
+
+	switch c.ChainSupport.Source {
+	case ChainSupportSourceStatic:
+	case ChainSupportSourceEvents:
+		if !validURL(c.ChainSupport.RPC) {
+			fail("chain_support.rpc: invalid URL %q", c.ChainSupport.RPC)
+		}
+		if !common.IsHexAddress(c.ChainSupport.DistributorAddress) {
+			fail("chain_support.distributor_address: invalid address %q", c.ChainSupport.DistributorAddress)
+		}
+		if c.ChainSupport.PollInterval <= 0 {
+			fail("chain_support.poll_interval: must be positive")
+		}
+	default:
+		fail("chain_support.source: must be %s or %s, got %q", ChainSupportSourceStatic, ChainSupportSourceEvents, c.ChainSupport.Source)
+	}
+
+	switch c.Gas.Source {
+	case GasSourceNone:
+	case GasSourceStatic, GasSourceRPC:
+		if c.Gas.Source == GasSourceStatic && (c.Gas.StaticPrice == nil || c.Gas.StaticPrice.Sign() <= 0) {
+			fail("gas.static_price: must be positive for the static source")
+		}
+		if c.Gas.Source == GasSourceRPC {
+			for i, chain := range c.Chains {
+				if chain.IsEnabled() && chain.RPC == "" {
+					fail("chains[%d].rpc: is required by the rpc gas source", i)
+				}
+			}
+		}
+		if c.Gas.MaxPrice != nil && c.Gas.MaxPrice.Sign() <= 0 {
+			fail("gas.max_price: must be positive")
+		}
+		if c.Gas.MinProfit != nil && c.Gas.MinProfit.Sign() < 0 {
+			fail("gas.min_profit: must not be negative")
+		}
+		if c.Gas.MaxDelay <= 0 {
+			fail("gas.max_delay: must be positive")
+		}
+		// Deferred tasks older than the maximum age would be rejected on retry
+		if c.Gas.MaxDelay > c.Rewards.MaxTaskAge {
+			fail("gas.max_delay: must not exceed rewards.max_task_age (%s), got %s", c.Rewards.MaxTaskAge, c.Gas.MaxDelay)
+		}
+	default:
+		fail("gas.source: must be %s, %s or %s, got %q", GasSourceNone, GasSourceStatic, GasSourceRPC, c.Gas.Source)
+	}
+
+	if c.Signer.MaxFeePerGas == nil || c.Signer.MaxFeePerGas.Sign() <= 0 {
+		fail("signer.max_fee_per_gas: must be positive")
+	}
+	if c.Signer.MaxPriorityFeePerGas == nil || c.Signer.MaxPriorityFeePerGas.Sign() < 0 {
+		fail("signer.max_priority_fee_per_gas: must not be negative")
+	} else if c.Signer.MaxFeePerGas != nil && c.Signer.MaxPriorityFeePerGas.Cmp(&c.Signer.MaxFeePerGas.Int) > 0 {
+		fail("signer.max_priority_fee_per_gas: must not exceed max_fee_per_gas")
+	}
+	if c.Signer.StuckAfter <= 0 {
+		fail("signer.stuck_after: must be positive")
+	}
+	if c.Signer.RecoverInterval <= 0 {
+		fail("signer.recover_interval: must be positive")
+	}
+
+	if c.Across.Enabled {
+		for i, chain := range c.Chains {
+			if !chain.IsEnabled() {
+				continue
+			}
+			if chain.RPC == "" {
+				fail("chains[%d].rpc: is required by across", i)
+			}
+			if chain.SpokePool == "" {
+				fail("chains[%d].spoke_pool: is required by across", i)
+			}
+			if chain.RewardToken == "" {
+				fail("chains[%d].reward_token: is required by across", i)
+			}
+		}
+		if c.Across.FillDeadline <= 0 {
+			fail("across.fill_deadline: must be positive")
+		}
+	}
+
+	if c.Direct.Enabled {
+		for i, chain := range c.Chains {
+			if !chain.IsEnabled() {
+				continue
+			}
+			if chain.RPC == "" {
+				fail("chains[%d].rpc: is required by direct", i)
+			}
+			if !c.Direct.Native && chain.RewardToken == "" {
+				fail("chains[%d].reward_token: is required by direct", i)
+			}
+		}
+	}
+
+	if c.Transfers.Path == "" {
+		fail("transfers.path: is required")
+	}
+	if c.Transfers.Timeout <= 0 {
+		fail("transfers.timeout: must be positive")
+	}
+	if c.Transfers.MaxRetries < 0 {
+		fail("transfers.max_retries: must not be negative")
+	}
+	if c.Transfers.PollInterval <= 0 {
+		fail("transfers.poll_interval: must be positive")
+	}
+
+	switch c.Execution.Mode {
+	case ExecutionModeLive, ExecutionModeDryRun:
+	case ExecutionModeShadow:
+		if c.Execution.LiveURL == "" {
+			fail("execution.live_url: is required in shadow mode")
+		} else if !validURL(c.Execution.LiveURL) {
+			fail("execution.live_url: invalid URL %q", c.Execution.LiveURL)
+		}
+		if c.Execution.CompareTimeout <= 0 {
+			fail("execution.compare_timeout: must be positive")
+		}
+		if c.Execution.CompareInterval <= 0 {
+			fail("execution.compare_interval: must be positive")
+		}
+	default:
+		fail("execution.mode: must be %s, %s or %s, got %q", ExecutionModeLive, ExecutionModeDryRun, ExecutionModeShadow, c.Execution.Mode)
+	}
+
+	if c.EigenLayer.L1RPC != "" && !validURL(c.EigenLayer.L1RPC) {
+		fail("eigenlayer.l1_rpc: invalid URL %q", c.EigenLayer.L1RPC)
+	}
+	if c.EigenLayer.L2RPC != "" && !validURL(c.EigenLayer.L2RPC) {
+		fail("eigenlayer.l2_rpc: invalid URL %q", c.EigenLayer.L2RPC)
+	}
+	if c.EigenLayer.AVSAddress != "" && !common.IsHexAddress(c.EigenLayer.AVSAddress) {
+		fail("eigenlayer.avs_address: invalid address %q", c.EigenLayer.AVSAddress)
+	}
+
+	if len(c.Chains) == 0 {
+		fail("chains: at least one chain is required")
+	}
+	seenIDs := make(map[uint64]int)
+	seenNames := make(map[string]int)
+	for i, chain := range c.Chains {
+		if chain.ChainID == 0 {
+			fail("chains[%d].chain_id: is required", i)
+		} else if prev, ok := seenIDs[chain.ChainID]; ok {
+			fail("chains[%d].chain_id: %d duplicates chains[%d]", i, chain.ChainID, prev)
+		} else {
+			seenIDs[chain.ChainID] = i
+		}
+		name := strings.ToLower(chain.Name)
+		if name == "" {
+			fail("chains[%d].name: is required", i)
+		} else if prev, ok := seenNames[name]; ok {
+			fail("chains[%d].name: %q duplicates chains[%d]", i, chain.Name, prev)
+		} else {
+			seenNames[name] = i
+		}
+		if chain.RPC != "" && !validURL(chain.RPC) {
+			fail("chains[%d].rpc: invalid URL %q", i, chain.RPC)
+		}
+		if chain.SpokePool != "" && !common.IsHexAddress(chain.SpokePool) {
+			fail("chains[%d].spoke_pool: invalid address %q", i, chain.SpokePool)
+		}
+		if chain.RewardToken != "" && !common.IsHexAddress(chain.RewardToken) {
+			fail("chains[%d].reward_token: invalid address %q", i, chain.RewardToken)
+		}
+		if chain.BaseFee != nil && chain.BaseFee.Sign() < 0 {
+			fail("chains[%d].base_fee: must not be negative", i)
+		}
+		if chain.FeeBps >= 10000 {
+			fail("chains[%d].fee_bps: must be below 10000, got %d", i, chain.FeeBps)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// ChainIDs returns the configured chain IDs in routing order
+func (c *Config) ChainIDs() []uint64 {
+	ids := make([]uint64, 0, len(c.Chains))
+	for _, chain := range c.Chains {
+		ids = append(ids, chain.ChainID)
+	}
+	return ids
+}
+
+// hasChain reports whether a chain ID is configured
+func (c *Config) hasChain(id uint64) bool {
+	for _, chain := range c.Chains {
+		if chain.ChainID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func isEnvironment(s string) bool {
+	for _, env := range Environments {
+		if s == env {
+			return true
+		}
+	}
+	return false
+}
+
+func validPort(port int) bool {
+	return port > 0 && port <= 65535
+}
+
+func validURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
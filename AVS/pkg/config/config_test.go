@@ -0,0 +1,463 @@
+package config
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func envFrom(vars map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		v, ok := vars[name]
+		return v, ok
+	}
+}
+
+func writeConfig(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "operator.yaml")
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestDefault_IsValid(t *testing.T) {
+	cfg := Default()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Default config is invalid: %v", err)
+	}
+	if got := cfg.ChainIDs(); len(got) != 5 || got[0] != 1 || got[4] != 8453 {
+		t.Errorf("Unexpected default chains: %v", got)
+	}
+}
+
+func TestLoad_ExampleProfiles(t *testing.T) {
+	example := filepath.Join("..", "..", "operator.example.yaml")
+
+	tests := []struct {
+		environment string
+		check       func(t *testing.T, cfg *Config)
+	}{
+		{
+			environment: EnvironmentDevnet,
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Logging.Level != "debug" || cfg.Logging.Format != "text" {
+					t.Errorf("Expected devnet debug text logging, got %+v", cfg.Logging)
+				}
+				if len(cfg.Chains) != 5 {
+					t.Errorf("Expected base chains, got %v", cfg.ChainIDs())
+				}
+			},
+		},
+		{
+			environment: EnvironmentTestnet,
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Server.ResultEncoding != "abi" {
+					t.Errorf("Expected abi results, got %s", cfg.Server.ResultEncoding)
+				}
+				if ids := cfg.ChainIDs(); len(ids) != 4 || ids[0] != 11155111 {
+					t.Errorf("Expected testnet chains to replace the base list, got %v", ids)
+				}
+				if cfg.Logging.Level != "info" {
+					t.Errorf("Expected base log level, got %s", cfg.Logging.Level)
+				}
+			},
+		},
+		{
+			environment: EnvironmentMainnet,
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Rewards.MaxTaskAge != 6*time.Hour {
+					t.Errorf("Expected 6h max task age, got %s", cfg.Rewards.MaxTaskAge)
+				}
+				// Fields a profile does not mention keep their base values
+				if cfg.Rewards.FeeBps != 10 || cfg.Rewards.MinAmount.Cmp(big.NewInt(1e15)) != 0 {
+					t.Errorf("Expected base reward settings, got %+v", cfg.Rewards)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.environment, func(t *testing.T) {
+			cfg, err := load(example, tt.environment, envFrom(nil))
+			if err != nil {
+				t.Fatalf("Failed to load example config: %v", err)
+			}
+			if cfg.Environment != tt.environment {
+				t.Errorf("Expected environment %s, got %s", tt.environment, cfg.Environment)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestLoad_EnvironmentOverrides(t *testing.T) {
+	path := writeConfig(t, `
+environments:
+  testnet:
+    metrics:
+      port: 9100
+`)
+
+	cfg, err := load(path, "", envFrom(map[string]string{
+		"ENVIRONMENT":                "TESTNET",
+		"METRICS_PORT":               "9200",
+		"LOG_LEVEL":                  "warn",
+		"MIN_REWARD_AMOUNT":          "5",
+		"FEE_BPS":                    "25",
+		"FEE_MODEL":                  "base_plus_bps",
+		"SPLIT_OPERATOR_BPS":         "1500",
+		"SPLIT_REMAINDER":            "protocol",
+		"TIER_MODE":                  "apply",
+		"MAX_TASK_AGE":               "1h",
+		"ARBITRUM_RPC":               "https://arb.example.org",
+		"ACROSS_SPOKE_POOL_ARBITRUM": "0xe35e9842fceaCA96570B734083f4a58e8F7C5f2A",
+		"ARBITRUM_PAUSED":            "true",
+		"ARBITRUM_REWARD_TOKEN":      "0x82aF49447D8a07e3bd95BD0d56f35241523fBab1",
+		"ACROSS_FILL_DEADLINE":       "1h",
+		"SIGNER_MAX_FEE_PER_GAS":     "50000000000",
+		"SIGNER_STUCK_AFTER":         "5m",
+		"TRANSFERS_MAX_RETRIES":      "1",
+		"TRANSFERS_FEE_BUMP_BPS":     "5000",
+		"EXECUTION_MODE":             "shadow",
+		"SHADOW_LIVE_URL":            "http://live.example.org:9090",
+		"SHADOW_COMPARE_TIMEOUT":     "1m",
+		"ROUTING_FALLBACK_CHAIN":     "8453",
+		"ETHEREUM_CHAIN_ID":          "11155111",
+		"AVS_ADDRESS":                "0x9876543210987654321098765432109876543210",
+		"EIGENLAYER_L1_RPC":          "",
+	}))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.Environment != EnvironmentTestnet {
+		t.Errorf("Expected testnet, got %s", cfg.Environment)
+	}
+	// Environment variables win over the profile
+	if cfg.Metrics.Port != 9200 {
+		t.Errorf("Expected metrics port 9200, got %d", cfg.Metrics.Port)
+	}
+	if cfg.Logging.Level != "warn" || cfg.Rewards.FeeBps != 25 || cfg.Rewards.FeeModel != FeeModelBasePlusBps || cfg.Rewards.MaxTaskAge != time.Hour {
+		t.Errorf("Unexpected overrides: %+v / %+v", cfg.Logging, cfg.Rewards)
+	}
+	if cfg.Rewards.TierMode != TierModeApply {
+		t.Errorf("Expected tier mode apply, got %q", cfg.Rewards.TierMode)
+	}
+	if split := cfg.Rewards.Split; split.OperatorBps != 1500 || split.LPBps != 5000 || split.Remainder != BeneficiaryProtocol {
+		t.Errorf("Unexpected split overrides: %+v", split)
+	}
+	if cfg.Rewards.MinAmount.Cmp(big.NewInt(5)) != 0 {
+		t.Errorf("Expected min amount 5, got %s", cfg.Rewards.MinAmount)
+	}
+	if cfg.Chains[0].ChainID != 11155111 {
+		t.Errorf("Expected ethereum chain ID override, got %d", cfg.Chains[0].ChainID)
+	}
+	arbitrum := cfg.Chains[2]
+	if arbitrum.RPC != "https://arb.example.org" || arbitrum.SpokePool != "0xe35e9842fceaCA96570B734083f4a58e8F7C5f2A" || arbitrum.RewardToken != "0x82aF49447D8a07e3bd95BD0d56f35241523fBab1" || !arbitrum.Paused {
+		t.Errorf("Unexpected arbitrum overrides: %+v", arbitrum)
+	}
+	if cfg.Across.FillDeadline != time.Hour || cfg.Across.Enabled {
+		t.Errorf("Unexpected across overrides: %+v", cfg.Across)
+	}
+	if cfg.Signer.MaxFeePerGas.Cmp(big.NewInt(50e9)) != 0 || cfg.Signer.StuckAfter != 5*time.Minute {
+		t.Errorf("Unexpected signer overrides: %+v", cfg.Signer)
+	}
+	if cfg.Routing.FallbackChain != 8453 {
+		t.Errorf("Expected fallback chain 8453, got %d", cfg.Routing.FallbackChain)
+	}
+	if cfg.Transfers.MaxRetries != 1 || cfg.Transfers.FeeBumpBps != 5000 || cfg.Transfers.Timeout != time.Hour {
+		t.Errorf("Unexpected transfers overrides: %+v", cfg.Transfers)
+	}
+	if cfg.Execution.Mode != ExecutionModeShadow || cfg.Execution.LiveURL != "http://live.example.org:9090" || cfg.Execution.CompareTimeout != time.Minute {
+		t.Errorf("Unexpected execution overrides: %+v", cfg.Execution)
+	}
+	if cfg.EigenLayer.L1RPC != "" {
+		t.Errorf("Expected empty variables to be ignored, got %q", cfg.EigenLayer.L1RPC)
+	}
+
+	// An explicit environment argument wins over ENVIRONMENT
+	cfg, err = load(path, EnvironmentMainnet, envFrom(map[string]string{"ENVIRONMENT": "testnet"}))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Environment != EnvironmentMainnet || cfg.Metrics.Port != 9090 {
+		t.Errorf("Expected mainnet without the testnet profile, got %s on port %d", cfg.Environment, cfg.Metrics.Port)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		env      map[string]string
+		errors   []string
+	}{
+		{
+			name:     "unknown field",
+			contents: "server:\n  prot: 8080\n",
+			errors:   []string{"field prot not found"},
+		},
+		{
+			name:     "unknown field in profile",
+			contents: "environments:\n  devnet:\n    rewards:\n      fee: 5\n",
+			errors:   []string{"failed to apply devnet profile", "field fee not found"},
+		},
+		{
+			name:     "unknown profile",
+			contents: "environments:\n  staging: {}\n",
+			errors:   []string{`unknown environment profile "staging"`},
+		},
+		{
+			name:     "invalid amount",
+			contents: "rewards:\n  min_amount: 1e15\n",
+			errors:   []string{`invalid wei amount "1e15"`},
+		},
+		{
+			name:   "invalid environment variable",
+			env:    map[string]string{"METRICS_PORT": "ninety"},
+			errors: []string{"METRICS_PORT: strconv.Atoi"},
+		},
+		{
+			name: "every problem is reported",
+			contents: `
+environment: staging
+server:
+  port: 0
+  result_encoding: xml
+metrics:
+  port: 8080
+logging:
+  level: loud
+rewards:
+  min_amount: "10"
+  max_amount: "5"
+  fee_bps: 10000
+  fee_model: tiered
+  protocol_fee_share_bps: 10001
+  split:
+    lp_bps: 9000
+    operator_bps: 1001
+    remainder: treasury
+  tier_mode: multiply
+chains:
+  - chain_id: 1
+    name: ethereum
+    spoke_pool: not-an-address
+  - chain_id: 1
+    name: Ethereum
+    rpc: localhost
+    fee_bps: 10000
+`,
+			errors: []string{
+				`environment: must be one of devnet, testnet, mainnet, got "staging"`,
+				"server.port: invalid port 0",
+				`server.result_encoding: must be json or abi, got "xml"`,
+				`logging.level: invalid level "loud"`,
+				"rewards.max_amount: 5 is below min_amount 10",
+				"rewards.fee_bps: must be below 10000, got 10000",
+				`rewards.fee_model: must be flat_bps, chain_base or base_plus_bps, got "tiered"`,
+				"rewards.protocol_fee_share_bps: must be at most 10000, got 10001",
+				`rewards.tier_mode: must be verify or apply, got "multiply"`,
+				"rewards.split: shares add up to 10501 bps, more than 10000",
+				`rewards.split.remainder: must be lp, operator, protocol or gas_compensation, got "treasury"`,
+				`chains[0].spoke_pool: invalid address "not-an-address"`,
+				"chains[1].chain_id: 1 duplicates chains[0]",
+				`chains[1].name: "Ethereum" duplicates chains[0]`,
+				`chains[1].rpc: invalid URL "localhost"`,
+				"chains[1].fee_bps: must be below 10000, got 10000",
+			},
+		},
+		{
+			name:     "ports must differ",
+			contents: "metrics:\n  port: 8080\n",
+			errors:   []string{"metrics.port: must differ from server.port (8080)"},
+		},
+		{
+			name:     "registrar policy without address or RPC",
+			contents: "validation_policy:\n  source: registrar\n  refresh_interval: 0s\n",
+			errors: []string{
+				`validation_policy.registrar_address: invalid address ""`,
+				"eigenlayer.l1_rpc: is required by the registrar validation policy",
+				"validation_policy.refresh_interval: must be positive",
+			},
+		},
+		{
+			name:     "events preferences without RPC or distributor",
+			contents: "preferences:\n  source: events\n",
+			errors: []string{
+				`preferences.rpc: invalid URL ""`,
+				`preferences.distributor_address: invalid address ""`,
+			},
+		},
+		{
+			name:     "events engagement without RPC or contract",
+			contents: "engagement:\n  source: events\n  inactive_threshold: -1s\n",
+			errors: []string{
+				`engagement.rpc: invalid URL ""`,
+				`engagement.contract_address: invalid address ""`,
+				"engagement.inactive_threshold: must not be negative",
+			},
+		},
+		{
+			name:     "empty scheduler",
+			contents: "scheduler:\n  concurrency: 0\n  max_wait: 0s\n",
+			env:      map[string]string{"SCHEDULER_QUEUE_SIZE": "-1"},
+			errors: []string{
+				"scheduler.concurrency: must be positive",
+				"scheduler.queue_size: must be positive",
+				"scheduler.max_wait: must be positive",
+			},
+		},
+		{
+			name:     "static gas without a price",
+			contents: "gas:\n  source: static\n  max_delay: 0s\n",
+			env:      map[string]string{"GAS_MIN_PROFIT": "-1"},
+			errors: []string{
+				"gas.static_price: must be positive for the static source",
+				"gas.min_profit: must not be negative",
+				"gas.max_delay: must be positive",
+			},
+		},
+		{
+			name:     "gas delay beyond the task age",
+			contents: "gas:\n  source: static\n  static_price: \"1000000000\"\n  max_delay: 25h\n",
+			errors:   []string{"gas.max_delay: must not exceed rewards.max_task_age (24h0m0s), got 25h0m0s"},
+		},
+		{
+			name:     "rpc gas without chain RPCs",
+			contents: "gas:\n  source: rpc\nchains:\n  - chain_id: 1\n    name: ethereum\n    rpc: https://eth.example.com\n  - chain_id: 10\n    name: optimism\n  - chain_id: 137\n    name: polygon\n    enabled: false\n",
+			errors:   []string{"chains[1].rpc: is required by the rpc gas source"},
+		},
+		{
+			name:   "unknown gas source",
+			env:    map[string]string{"GAS_SOURCE": "oracle"},
+			errors: []string{`gas.source: must be none, static or rpc, got "oracle"`},
+		},
+		{
+			name:     "signer fee caps and intervals",
+			contents: "signer:\n  max_fee_per_gas: \"1000000000\"\n  stuck_after: 0s\n",
+			env:      map[string]string{"SIGNER_MAX_PRIORITY_FEE_PER_GAS": "2000000000", "SIGNER_RECOVER_INTERVAL": "-1s"},
+			errors: []string{
+				"signer.max_priority_fee_per_gas: must not exceed max_fee_per_gas",
+				"signer.stuck_after: must be positive",
+				"signer.recover_interval: must be positive",
+			},
+		},
+		{
+			name:   "zero signer fee cap",
+			env:    map[string]string{"SIGNER_MAX_FEE_PER_GAS": "0", "SIGNER_MAX_PRIORITY_FEE_PER_GAS": "-1"},
+			errors: []string{"signer.max_fee_per_gas: must be positive", "signer.max_priority_fee_per_gas: must not be negative"},
+		},
+		{
+			name:     "transfer limits",
+			contents: "transfers:\n  path: \"\"\n  timeout: 0s\n  max_retries: -1\n",
+			env:      map[string]string{"TRANSFERS_POLL_INTERVAL": "-1s"},
+			errors: []string{
+				"transfers.path: is required",
+				"transfers.timeout: must be positive",
+				"transfers.max_retries: must not be negative",
+				"transfers.poll_interval: must be positive",
+			},
+		},
+		{
+			name:     "unconfigured fallback chain",
+			contents: "routing:\n  fallback_chain: 56\n",
+			errors:   []string{"routing.fallback_chain: chain 56 is not configured"},
+		},
+		{
+			name:   "unknown execution mode",
+			env:    map[string]string{"EXECUTION_MODE": "paper"},
+			errors: []string{`execution.mode: must be live, dry-run or shadow, got "paper"`},
+		},
+		{
+			name:     "shadow without live operator",
+			contents: "execution:\n  mode: shadow\n  compare_timeout: 0s\n",
+			env:      map[string]string{"SHADOW_COMPARE_INTERVAL": "-1s"},
+			errors: []string{
+				"execution.live_url: is required in shadow mode",
+				"execution.compare_timeout: must be positive",
+				"execution.compare_interval: must be positive",
+			},
+		},
+		{
+			name:     "across without chain endpoints",
+			contents: "across:\n  enabled: true\nchains:\n  - chain_id: 1\n    name: ethereum\n    rpc: https://eth.example.com\n    spoke_pool: \"0x5c7BCd6E7De5423a257D81B442095A1a6ced35C5\"\n    reward_token: \"0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2\"\n  - chain_id: 10\n    name: optimism\n    reward_token: weth\n  - chain_id: 137\n    name: polygon\n    enabled: false\n",
+			env:      map[string]string{"ACROSS_FILL_DEADLINE": "0s"},
+			errors: []string{
+				"chains[1].rpc: is required by across",
+				"chains[1].spoke_pool: is required by across",
+				`chains[1].reward_token: invalid address "weth"`,
+				"across.fill_deadline: must be positive",
+			},
+		},
+		{
+			name:     "direct token transfers without chain endpoints",
+			contents: "direct:\n  enabled: true\n  native: false\nchains:\n  - chain_id: 1\n    name: ethereum\n    rpc: https://eth.example.com\n    reward_token: \"0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2\"\n  - chain_id: 10\n    name: optimism\n  - chain_id: 137\n    name: polygon\n    enabled: false\n",
+			errors: []string{
+				"chains[1].rpc: is required by direct",
+				"chains[1].reward_token: is required by direct",
+			},
+		},
+		{
+			name:   "invalid across flag",
+			env:    map[string]string{"ACROSS_ENABLED": "maybe"},
+			errors: []string{"ACROSS_ENABLED: strconv.ParseBool"},
+		},
+		{
+			name:     "events chain support without RPC or distributor",
+			contents: "chain_support:\n  source: events\n",
+			errors: []string{
+				`chain_support.rpc: invalid URL ""`,
+				`chain_support.distributor_address: invalid address ""`,
+			},
+		},
+		{
+			name:   "unknown policy source",
+			env:    map[string]string{"VALIDATION_POLICY": "oracle"},
+			errors: []string{`validation_policy.source: must be static or registrar, got "oracle"`},
+		},
+		{
+			name:     "no chains",
+			contents: "chains: []\n",
+			errors:   []string{"chains: at least one chain is required"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := ""
+			if tt.contents != "" {
+				path = writeConfig(t, tt.contents)
+			}
+			_, err := load(path, "", envFrom(tt.env))
+			if err == nil {
+				t.Fatalf("Expected error but got none")
+			}
+			for _, want := range tt.errors {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Expected error to contain '%s', got '%s'", want, err.Error())
+				}
+			}
+		})
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	if _, err := load(filepath.Join(t.TempDir(), "missing.yaml"), "", envFrom(nil)); err == nil {
+		t.Errorf("Expected error for missing config file")
+	}
+
+	// An empty file keeps the defaults
+	cfg, err := load(writeConfig(t, ""), "", envFrom(nil))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Server.Port != 8080 || cfg.Environment != EnvironmentDevnet {
+		t.Errorf("Expected defaults, got %+v", cfg.Server)
+	}
+}
@@ -0,0 +1,296 @@
+package config
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables overriding the configuration. Names follow
+// docs/OPERATOR_GUIDE.md; per-chain variables use the upper-cased chain name,
+// e.g. ARBITRUM_RPC, ACROSS_SPOKE_POOL_ARBITRUM, ARBITRUM_REWARD_TOKEN and
+// ARBITRUM_PAUSED.
+const (
+	EnvEnvironment          = "ENVIRONMENT"
+	EnvPerformerPort        = "PERFORMER_PORT"
+	EnvPerformerTimeout     = "PERFORMER_TIMEOUT"
+	EnvDrainTimeout         = "DRAIN_TIMEOUT"
+	EnvResultEncoding       = "RESULT_ENCODING"
+	EnvMetricsPort          = "METRICS_PORT"
+	EnvLogLevel             = "LOG_LEVEL"
+	EnvLogFormat            = "LOG_FORMAT"
+	EnvIdempotencyPath      = "IDEMPOTENCY_DB"
+	EnvIdempotencyRetention = "IDEMPOTENCY_RETENTION"
+	EnvSchedulerConcurrency = "SCHEDULER_CONCURRENCY"
+	EnvSchedulerQueueSize   = "SCHEDULER_QUEUE_SIZE"
+	EnvSchedulerMaxWait     = "SCHEDULER_MAX_WAIT"
+	EnvGasSource            = "GAS_SOURCE"
+	EnvGasStaticPrice       = "GAS_STATIC_PRICE"
+	EnvGasMaxPrice          = "GAS_MAX_PRICE"
+	EnvGasMinProfit         = "GAS_MIN_PROFIT"
+	EnvGasMaxDelay          = "GAS_MAX_DELAY"
+	EnvKeystore             = "AVS_KEYSTORE"
+	EnvSignerMaxFee         = "SIGNER_MAX_FEE_PER_GAS"
+	EnvSignerMaxPriorityFee = "SIGNER_MAX_PRIORITY_FEE_PER_GAS"
+	EnvSignerStuckAfter     = "SIGNER_STUCK_AFTER"
+	EnvSignerRecover        = "SIGNER_RECOVER_INTERVAL"
+	EnvAcrossEnabled        = "ACROSS_ENABLED"
+	EnvAcrossFillDeadline   = "ACROSS_FILL_DEADLINE"
+	EnvAcrossNative         = "ACROSS_NATIVE"
+	EnvDirectEnabled        = "DIRECT_ENABLED"
+	EnvDirectNative         = "DIRECT_NATIVE"
+	EnvTransfersPath        = "TRANSFERS_DB"
+	EnvTransfersTimeout     = "TRANSFERS_TIMEOUT"
+	EnvTransfersMaxRetries  = "TRANSFERS_MAX_RETRIES"
+	EnvTransfersFeeBump     = "TRANSFERS_FEE_BUMP_BPS"
+	EnvTransfersPoll        = "TRANSFERS_POLL_INTERVAL"
+	EnvExecutionMode        = "EXECUTION_MODE"
+	EnvShadowLiveURL        = "SHADOW_LIVE_URL"
+	EnvShadowTimeout        = "SHADOW_COMPARE_TIMEOUT"
+	EnvShadowInterval       = "SHADOW_COMPARE_INTERVAL"
+	EnvMinRewardAmount      = "MIN_REWARD_AMOUNT"
+	EnvMaxRewardAmount      = "MAX_REWARD_AMOUNT"
+	EnvTaskFee              = "TASK_FEE"
+	EnvFeeBps               = "FEE_BPS"
+	EnvFeeModel             = "FEE_MODEL"
+	EnvProtocolFeeShare     = "PROTOCOL_FEE_SHARE_BPS"
+	EnvSplitLP              = "SPLIT_LP_BPS"
+	EnvSplitOperator        = "SPLIT_OPERATOR_BPS"
+	EnvSplitProtocol        = "SPLIT_PROTOCOL_BPS"
+	EnvSplitGasCompensation = "SPLIT_GAS_COMPENSATION_BPS"
+	EnvSplitRemainder       = "SPLIT_REMAINDER"
+	EnvTierMode             = "TIER_MODE"
+	EnvMaxTaskAge           = "MAX_TASK_AGE"
+	EnvEigenLayerL1RPC      = "EIGENLAYER_L1_RPC"
+	EnvEigenLayerL2RPC      = "EIGENLAYER_L2_RPC"
+	EnvAVSAddress           = "AVS_ADDRESS"
+	EnvEthereumChainID      = "ETHEREUM_CHAIN_ID"
+	EnvPolicySource         = "VALIDATION_POLICY"
+	EnvRegistrarAddress     = "REGISTRAR_ADDRESS"
+	EnvPolicyRefresh        = "VALIDATION_POLICY_REFRESH"
+	EnvPreferencesSource    = "PREFERENCES_SOURCE"
+	EnvPreferencesFile      = "PREFERENCES_FILE"
+	EnvPreferencesRPC       = "PREFERENCES_RPC"
+	EnvDistributorAddress   = "REWARD_DISTRIBUTOR_ADDRESS"
+	EnvFallbackChain        = "ROUTING_FALLBACK_CHAIN"
+	EnvEngagementSource     = "ENGAGEMENT_SOURCE"
+	EnvEngagementRPC        = "ENGAGEMENT_RPC"
+	EnvEngagementContract   = "ENGAGEMENT_CONTRACT_ADDRESS"
+	EnvInactiveThreshold    = "ENGAGEMENT_INACTIVE_THRESHOLD"
+	EnvChainSupportSource   = "CHAIN_SUPPORT_SOURCE"
+	EnvChainSupportRPC      = "CHAIN_SUPPORT_RPC"
+
+	envChainRPCSuffix    = "_RPC"
+	envSpokePoolPrefix   = "ACROSS_SPOKE_POOL_"
+	envChainPausedSuffix = "_PAUSED"
+	envRewardTokenSuffix = "_REWARD_TOKEN"
+	ethereumChainName    = "ethereum"
+)
+
+// applyEnv overrides configuration fields from environment variables
+func applyEnv(cfg *Config, lookupEnv func(string) (string, bool)) error {
+	get := func(name string) (string, bool) {
+		v, ok := lookupEnv(name)
+		v = strings.TrimSpace(v)
+		return v, ok && v != ""
+	}
+
+	stringVars := []struct {
+		name   string
+		target *string
+	}{
+		{EnvResultEncoding, &cfg.Server.ResultEncoding},
+		{EnvFeeModel, &cfg.Rewards.FeeModel},
+		{EnvSplitRemainder, &cfg.Rewards.Split.Remainder},
+		{EnvTierMode, &cfg.Rewards.TierMode},
+		{EnvLogLevel, &cfg.Logging.Level},
+		{EnvLogFormat, &cfg.Logging.Format},
+		{EnvIdempotencyPath, &cfg.Idempotency.Path},
+		{EnvKeystore, &cfg.Signer.Keystore},
+		{EnvTransfersPath, &cfg.Transfers.Path},
+		{EnvExecutionMode, &cfg.Execution.Mode},
+		{EnvShadowLiveURL, &cfg.Execution.LiveURL},
+		{EnvEigenLayerL1RPC, &cfg.EigenLayer.L1RPC},
+		{EnvEigenLayerL2RPC, &cfg.EigenLayer.L2RPC},
+		{EnvAVSAddress, &cfg.EigenLayer.AVSAddress},
+		{EnvPolicySource, &cfg.ValidationPolicy.Source},
+		{EnvRegistrarAddress, &cfg.ValidationPolicy.RegistrarAddress},
+		{EnvPreferencesSource, &cfg.Preferences.Source},
+		{EnvPreferencesFile, &cfg.Preferences.Path},
+		{EnvPreferencesRPC, &cfg.Preferences.RPC},
+		{EnvDistributorAddress, &cfg.Preferences.DistributorAddress},
+		{EnvDistributorAddress, &cfg.ChainSupport.DistributorAddress},
+		{EnvEngagementSource, &cfg.Engagement.Source},
+		{EnvEngagementRPC, &cfg.Engagement.RPC},
+		{EnvEngagementContract, &cfg.Engagement.ContractAddress},
+		{EnvChainSupportSource, &cfg.ChainSupport.Source},
+		{EnvGasSource, &cfg.Gas.Source},
+		{EnvChainSupportRPC, &cfg.ChainSupport.RPC},
+	}
+	for _, s := range stringVars {
+		if v, ok := get(s.name); ok {
+			*s.target = v
+		}
+	}
+
+	ints := []struct {
+		name   string
+		target *int
+	}{
+		{EnvPerformerPort, &cfg.Server.Port},
+		{EnvMetricsPort, &cfg.Metrics.Port},
+		{EnvSchedulerConcurrency, &cfg.Scheduler.Concurrency},
+		{EnvSchedulerQueueSize, &cfg.Scheduler.QueueSize},
+		{EnvTransfersMaxRetries, &cfg.Transfers.MaxRetries},
+	}
+	for _, i := range ints {
+		if v, ok := get(i.name); ok {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", i.name, err)
+			}
+			*i.target = n
+		}
+	}
+
+	durations := []struct {
+		name   string
+		target *time.Duration
+	}{
+		{EnvPerformerTimeout, &cfg.Server.Timeout},
+		{EnvDrainTimeout, &cfg.Server.DrainTimeout},
+		{EnvIdempotencyRetention, &cfg.Idempotency.Retention},
+		{EnvSchedulerMaxWait, &cfg.Scheduler.MaxWait},
+		{EnvGasMaxDelay, &cfg.Gas.MaxDelay},
+		{EnvSignerStuckAfter, &cfg.Signer.StuckAfter},
+		{EnvSignerRecover, &cfg.Signer.RecoverInterval},
+		{EnvAcrossFillDeadline, &cfg.Across.FillDeadline},
+		{EnvTransfersTimeout, &cfg.Transfers.Timeout},
+		{EnvTransfersPoll, &cfg.Transfers.PollInterval},
+		{EnvShadowTimeout, &cfg.Execution.CompareTimeout},
+		{EnvShadowInterval, &cfg.Execution.CompareInterval},
+		{EnvMaxTaskAge, &cfg.Rewards.MaxTaskAge},
+		{EnvPolicyRefresh, &cfg.ValidationPolicy.RefreshInterval},
+		{EnvInactiveThreshold, &cfg.Engagement.InactiveThreshold},
+	}
+	for _, d := range durations {
+		if v, ok := get(d.name); ok {
+			parsed, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", d.name, err)
+			}
+			*d.target = parsed
+		}
+	}
+
+	amounts := []struct {
+		name   string
+		target **Amount
+	}{
+		{EnvMinRewardAmount, &cfg.Rewards.MinAmount},
+		{EnvMaxRewardAmount, &cfg.Rewards.MaxAmount},
+		{EnvTaskFee, &cfg.Rewards.TaskFee},
+		{EnvGasStaticPrice, &cfg.Gas.StaticPrice},
+		{EnvGasMaxPrice, &cfg.Gas.MaxPrice},
+		{EnvGasMinProfit, &cfg.Gas.MinProfit},
+		{EnvSignerMaxFee, &cfg.Signer.MaxFeePerGas},
+		{EnvSignerMaxPriorityFee, &cfg.Signer.MaxPriorityFeePerGas},
+	}
+	for _, a := range amounts {
+		if v, ok := get(a.name); ok {
+			amount, ok := new(big.Int).SetString(v, 10)
+			if !ok {
+				return fmt.Errorf("%s: invalid wei amount %q", a.name, v)
+			}
+			*a.target = NewAmount(amount)
+		}
+	}
+
+	bools := []struct {
+		name   string
+		target *bool
+	}{
+		{EnvAcrossEnabled, &cfg.Across.Enabled},
+		{EnvAcrossNative, &cfg.Across.Native},
+		{EnvDirectEnabled, &cfg.Direct.Enabled},
+		{EnvDirectNative, &cfg.Direct.Native},
+	}
+	for _, b := range bools {
+		if v, ok := get(b.name); ok {
+			parsed, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", b.name, err)
+			}
+			*b.target = parsed
+		}
+	}
+
+	bpsVars := []struct {
+		name   string
+		target *uint64
+	}{
+		{EnvFeeBps, &cfg.Rewards.FeeBps},
+		{EnvProtocolFeeShare, &cfg.Rewards.ProtocolFeeShareBps},
+		{EnvSplitLP, &cfg.Rewards.Split.LPBps},
+		{EnvSplitOperator, &cfg.Rewards.Split.OperatorBps},
+		{EnvSplitProtocol, &cfg.Rewards.Split.ProtocolBps},
+		{EnvSplitGasCompensation, &cfg.Rewards.Split.GasCompensationBps},
+		{EnvTransfersFeeBump, &cfg.Transfers.FeeBumpBps},
+	}
+	for _, b := range bpsVars {
+		if v, ok := get(b.name); ok {
+			bps, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				return fmt.Errorf("%s: %w", b.name, err)
+			}
+			*b.target = bps
+		}
+	}
+
+	if v, ok := get(EnvFallbackChain); ok {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s: %w", EnvFallbackChain, err)
+		}
+		cfg.Routing.FallbackChain = id
+	}
+
+	for i := range cfg.Chains {
+		chain := &cfg.Chains[i]
+		name := envChainName(chain.Name)
+		if v, ok := get(name + envChainRPCSuffix); ok {
+			chain.RPC = v
+		}
+		if v, ok := get(envSpokePoolPrefix + name); ok {
+			chain.SpokePool = v
+		}
+		if v, ok := get(name + envRewardTokenSuffix); ok {
+			chain.RewardToken = v
+		}
+		if v, ok := get(name + envChainPausedSuffix); ok {
+			paused, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name+envChainPausedSuffix, err)
+			}
+			chain.Paused = paused
+		}
+		if strings.EqualFold(chain.Name, ethereumChainName) {
+			if v, ok := get(EnvEthereumChainID); ok {
+				id, err := strconv.ParseUint(v, 10, 64)
+				if err != nil {
+					return fmt.Errorf("%s: %w", EnvEthereumChainID, err)
+				}
+				chain.ChainID = id
+			}
+		}
+	}
+
+	return nil
+}
+
+// envChainName converts a chain name into its environment variable form
+func envChainName(name string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", " ", "_").Replace(name))
+}
@@ -0,0 +1,223 @@
+// Package engagement mirrors the activity scoring of ActivityTracking and
+// EngagementMetrics: loyalty and consistency scores, the normalized engagement
+// score and the tier derived from it.
+package engagement
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/tier"
+)
+
+// ActivityType mirrors ActivityTracking.ActivityType
+type ActivityType uint8
+
+const (
+	LiquidityProvision ActivityType = iota
+	LiquidityRemoval
+	Swap
+	ClaimRewards
+)
+
+var activityTypeNames = [...]string{"liquidity_provision", "liquidity_removal", "swap", "claim_rewards"}
+
+// Valid reports whether t is one of the four activity types
+func (t ActivityType) Valid() bool {
+	return int(t) < len(activityTypeNames)
+}
+
+func (t ActivityType) String() string {
+	if !t.Valid() {
+		return fmt.Sprintf("ActivityType(%d)", uint8(t))
+	}
+	return activityTypeNames[t]
+}
+
+const (
+	// LoyaltyDecayRate is LOYALTY_DECAY_RATE, the loyalty lost per day of inactivity
+	LoyaltyDecayRate = 1e15
+	// ConsistencyWindow is CONSISTENCY_WINDOW in seconds
+	ConsistencyWindow = 7 * secondsPerDay
+	// MaxLoyaltyScore is MAX_LOYALTY_SCORE
+	MaxLoyaltyScore = 100
+
+	secondsPerDay = 24 * 60 * 60
+)
+
+var (
+	// liquidityNormalizer is the liquidity scoring 100, 1000 ETH
+	liquidityNormalizer = new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))
+	// volumeNormalizer is the swap volume scoring 100, 10000 ETH
+	volumeNormalizer = new(big.Int).Mul(big.NewInt(10000), big.NewInt(1e18))
+)
+
+// LoyaltyPoints is the base increase _updateLoyaltyScore grants an activity type
+func LoyaltyPoints(t ActivityType) uint64 {
+	switch t {
+	case LiquidityProvision:
+		return 2
+	case Swap:
+		return 1
+	case ClaimRewards:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// UpdateLoyaltyScore applies _updateLoyaltyScore: the score decays by
+// LoyaltyDecayRate per day of elapsed seconds, floored at zero, then gains the
+// points of t and is capped at MaxLoyaltyScore
+func UpdateLoyaltyScore(score uint64, t ActivityType, elapsed uint64) uint64 {
+	decay := new(big.Int).SetUint64(elapsed)
+	decay.Mul(decay, big.NewInt(LoyaltyDecayRate))
+	decay.Quo(decay, big.NewInt(secondsPerDay))
+	if decay.Cmp(new(big.Int).SetUint64(score)) > 0 {
+		score = 0
+	} else {
+		score -= decay.Uint64()
+	}
+
+	score += LoyaltyPoints(t)
+	if score > MaxLoyaltyScore {
+		score = MaxLoyaltyScore
+	}
+	return score
+}
+
+// ConsistencyScore applies _updateConsistencyScore: the transactions made per
+// week elapsed, as a percentage capped at 100, or 100 within the first week
+func ConsistencyScore(transactionCount, elapsed uint64) uint64 {
+	expected := elapsed / secondsPerDay / 7
+	if expected == 0 {
+		return 100
+	}
+	score := new(big.Int).SetUint64(transactionCount)
+	score.Mul(score, big.NewInt(100))
+	score.Quo(score, new(big.Int).SetUint64(expected))
+	if !score.IsUint64() || score.Uint64() > 100 {
+		return 100
+	}
+	return score.Uint64()
+}
+
+// Activity mirrors ActivityTracking.UserActivity without its per-pool mappings
+type Activity struct {
+	TotalLiquidity      *big.Int `json:"total_liquidity"`
+	SwapVolume          *big.Int `json:"swap_volume"`
+	PositionDuration    uint64   `json:"position_duration"`
+	LastActivity        int64    `json:"last_activity"` // unix seconds
+	LoyaltyScore        uint64   `json:"loyalty_score"`
+	TransactionCount    uint64   `json:"transaction_count"`
+	AveragePositionSize *big.Int `json:"average_position_size"`
+	ConsistencyScore    uint64   `json:"consistency_score"`
+}
+
+// NewActivity returns the activity of a user who has done nothing yet
+func NewActivity() *Activity {
+	return &Activity{
+		TotalLiquidity:      new(big.Int),
+		SwapVolume:          new(big.Int),
+		AveragePositionSize: new(big.Int),
+	}
+}
+
+// Clone returns a deep copy of a
+func (a *Activity) Clone() *Activity {
+	c := *a
+	c.TotalLiquidity = new(big.Int).Set(a.TotalLiquidity)
+	c.SwapVolume = new(big.Int).Set(a.SwapVolume)
+	c.AveragePositionSize = new(big.Int).Set(a.AveragePositionSize)
+	return &c
+}
+
+// Event is one activity of a user
+type Event struct {
+	Type      ActivityType `json:"type"`
+	Amount    *big.Int     `json:"amount"`    // liquidity provided or swap volume
+	Timestamp int64        `json:"timestamp"` // unix seconds
+}
+
+// Apply updates a with e as updateLiquidityProvision or updateSwapVolume does.
+// Like the libraries, lastActivity is moved to the event before the loyalty and
+// consistency scores are updated, so neither sees any elapsed time: loyalty
+// never decays on update and consistency is 100 after every activity.
+func (a *Activity) Apply(e Event) error {
+	amount := e.Amount
+	if amount == nil {
+		amount = new(big.Int)
+	}
+	if amount.Sign() < 0 {
+		return fmt.Errorf("negative %s amount %s", e.Type, amount)
+	}
+
+	switch e.Type {
+	case LiquidityProvision:
+		a.TotalLiquidity.Add(a.TotalLiquidity, amount)
+		// Averaged before the transaction count includes this provision
+		if a.TransactionCount == 0 {
+			a.AveragePositionSize.SetInt64(0)
+		} else {
+			a.AveragePositionSize.Quo(a.TotalLiquidity, new(big.Int).SetUint64(a.TransactionCount))
+		}
+	case Swap:
+		a.SwapVolume.Add(a.SwapVolume, amount)
+	default:
+		return fmt.Errorf("ActivityTracking has no %s update", e.Type)
+	}
+
+	a.LastActivity = e.Timestamp
+	a.TransactionCount++
+	a.LoyaltyScore = UpdateLoyaltyScore(a.LoyaltyScore, e.Type, a.elapsed(e.Timestamp))
+	a.ConsistencyScore = ConsistencyScore(a.TransactionCount, a.elapsed(e.Timestamp))
+	return nil
+}
+
+// elapsed is block.timestamp - lastActivity, at least zero
+func (a *Activity) elapsed(now int64) uint64 {
+	if now <= a.LastActivity {
+		return 0
+	}
+	return uint64(now - a.LastActivity)
+}
+
+// EngagementScore applies getEngagementScore: 30% liquidity, 25% swap volume,
+// 25% loyalty and 20% consistency
+func (a *Activity) EngagementScore() uint64 {
+	liquidity := normalizeScore(a.TotalLiquidity, liquidityNormalizer)
+	volume := normalizeScore(a.SwapVolume, volumeNormalizer)
+	return (liquidity*30 + volume*25 + a.LoyaltyScore*25 + a.ConsistencyScore*20) / 100
+}
+
+// Tier applies getUserTier to the engagement score
+func (a *Activity) Tier() tier.Level {
+	score := a.EngagementScore()
+	switch {
+	case score >= 90:
+		return tier.Diamond
+	case score >= 75:
+		return tier.Platinum
+	case score >= 50:
+		return tier.Gold
+	case score >= 25:
+		return tier.Silver
+	default:
+		return tier.Bronze
+	}
+}
+
+// IsActive applies isUserActive: whether the last activity is at most
+// inactiveThreshold seconds before now
+func (a *Activity) IsActive(inactiveThreshold uint64, now int64) bool {
+	return a.elapsed(now) <= inactiveThreshold
+}
+
+// normalizeScore applies _normalizeScore, scaling value to 0-100 of maxValue
+func normalizeScore(value, maxValue *big.Int) uint64 {
+	if value.Cmp(maxValue) >= 0 {
+		return 100
+	}
+	score := new(big.Int).Mul(value, big.NewInt(100))
+	return score.Quo(score, maxValue).Uint64()
+}
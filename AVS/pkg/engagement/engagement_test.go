@@ -0,0 +1,227 @@
+package engagement
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/tier"
+)
+
+// vectorsPath is shared with test/unit/EngagementVectors.t.sol, which checks
+// the same vectors against ActivityTracking and EngagementMetrics
+var vectorsPath = filepath.Join("..", "..", "..", "test", "vectors", "engagement.json")
+
+type engagementVectors struct {
+	Sequences []struct {
+		Name   string `json:"name"`
+		Events []struct {
+			Type      ActivityType `json:"type"`
+			Amount    string       `json:"amount"`
+			Timestamp string       `json:"timestamp"`
+		} `json:"events"`
+		Expect struct {
+			TotalLiquidity      string     `json:"totalLiquidity"`
+			SwapVolume          string     `json:"swapVolume"`
+			LastActivity        string     `json:"lastActivity"`
+			LoyaltyScore        string     `json:"loyaltyScore"`
+			TransactionCount    string     `json:"transactionCount"`
+			AveragePositionSize string     `json:"averagePositionSize"`
+			ConsistencyScore    string     `json:"consistencyScore"`
+			EngagementScore     string     `json:"engagementScore"`
+			Tier                tier.Level `json:"tier"`
+		} `json:"expect"`
+		Active []struct {
+			Now               string `json:"now"`
+			InactiveThreshold string `json:"inactiveThreshold"`
+			Expect            bool   `json:"expect"`
+		} `json:"active"`
+	} `json:"sequences"`
+	Loyalty []struct {
+		Name         string       `json:"name"`
+		Score        string       `json:"score"`
+		ActivityType ActivityType `json:"activityType"`
+		Elapsed      string       `json:"elapsed"`
+		Expect       string       `json:"expect"`
+	} `json:"loyalty"`
+	Consistency []struct {
+		Name             string `json:"name"`
+		TransactionCount string `json:"transactionCount"`
+		Elapsed          string `json:"elapsed"`
+		Expect           string `json:"expect"`
+	} `json:"consistency"`
+}
+
+func loadVectors(t *testing.T) engagementVectors {
+	t.Helper()
+	data, err := os.ReadFile(vectorsPath)
+	if err != nil {
+		t.Fatalf("Failed to read engagement vectors: %v", err)
+	}
+	var vectors engagementVectors
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		t.Fatalf("Failed to parse engagement vectors: %v", err)
+	}
+	if len(vectors.Sequences) == 0 || len(vectors.Loyalty) == 0 || len(vectors.Consistency) == 0 {
+		t.Fatalf("Engagement vectors are missing a section")
+	}
+	return vectors
+}
+
+func wei(t *testing.T, s string) *big.Int {
+	t.Helper()
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		t.Fatalf("Invalid amount %q", s)
+	}
+	return v
+}
+
+func number(t *testing.T, s string) uint64 {
+	t.Helper()
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		t.Fatalf("Invalid number %q: %v", s, err)
+	}
+	return v
+}
+
+func TestActivity_Vectors(t *testing.T) {
+	for _, v := range loadVectors(t).Sequences {
+		t.Run(v.Name, func(t *testing.T) {
+			activity := NewActivity()
+			for _, e := range v.Events {
+				if err := activity.Apply(Event{Type: e.Type, Amount: wei(t, e.Amount), Timestamp: int64(number(t, e.Timestamp))}); err != nil {
+					t.Fatalf("Apply failed: %v", err)
+				}
+			}
+
+			want := v.Expect
+			if activity.TotalLiquidity.Cmp(wei(t, want.TotalLiquidity)) != 0 || activity.SwapVolume.Cmp(wei(t, want.SwapVolume)) != 0 {
+				t.Errorf("Expected liquidity %s and volume %s, got %s and %s", want.TotalLiquidity, want.SwapVolume, activity.TotalLiquidity, activity.SwapVolume)
+			}
+			if activity.AveragePositionSize.Cmp(wei(t, want.AveragePositionSize)) != 0 {
+				t.Errorf("Expected average position size %s, got %s", want.AveragePositionSize, activity.AveragePositionSize)
+			}
+			if uint64(activity.LastActivity) != number(t, want.LastActivity) || activity.TransactionCount != number(t, want.TransactionCount) {
+				t.Errorf("Expected %s transactions until %s, got %d until %d", want.TransactionCount, want.LastActivity, activity.TransactionCount, activity.LastActivity)
+			}
+			if activity.LoyaltyScore != number(t, want.LoyaltyScore) || activity.ConsistencyScore != number(t, want.ConsistencyScore) {
+				t.Errorf("Expected loyalty %s and consistency %s, got %d and %d", want.LoyaltyScore, want.ConsistencyScore, activity.LoyaltyScore, activity.ConsistencyScore)
+			}
+			if got := activity.EngagementScore(); got != number(t, want.EngagementScore) {
+				t.Errorf("Expected engagement score %s, got %d", want.EngagementScore, got)
+			}
+			if got := activity.Tier(); got != want.Tier {
+				t.Errorf("Expected %s, got %s", want.Tier, got)
+			}
+			for _, check := range v.Active {
+				if got := activity.IsActive(number(t, check.InactiveThreshold), int64(number(t, check.Now))); got != check.Expect {
+					t.Errorf("Expected active %v at %s within %s, got %v", check.Expect, check.Now, check.InactiveThreshold, got)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateLoyaltyScore_Vectors(t *testing.T) {
+	for _, v := range loadVectors(t).Loyalty {
+		t.Run(v.Name, func(t *testing.T) {
+			if got := UpdateLoyaltyScore(number(t, v.Score), v.ActivityType, number(t, v.Elapsed)); got != number(t, v.Expect) {
+				t.Errorf("Expected %s, got %d", v.Expect, got)
+			}
+		})
+	}
+}
+
+func TestConsistencyScore_Vectors(t *testing.T) {
+	for _, v := range loadVectors(t).Consistency {
+		t.Run(v.Name, func(t *testing.T) {
+			if got := ConsistencyScore(number(t, v.TransactionCount), number(t, v.Elapsed)); got != number(t, v.Expect) {
+				t.Errorf("Expected %s, got %d", v.Expect, got)
+			}
+		})
+	}
+}
+
+func TestActivity_ApplyErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    Event
+		errorMsg string
+	}{
+		{
+			name:     "claim",
+			event:    Event{Type: ClaimRewards, Amount: big.NewInt(1), Timestamp: 1},
+			errorMsg: "ActivityTracking has no claim_rewards update",
+		},
+		{
+			name:     "removal",
+			event:    Event{Type: LiquidityRemoval, Amount: big.NewInt(1), Timestamp: 1},
+			errorMsg: "ActivityTracking has no liquidity_removal update",
+		},
+		{
+			name:     "negative amount",
+			event:    Event{Type: Swap, Amount: big.NewInt(-1), Timestamp: 1},
+			errorMsg: "negative swap amount -1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			activity := NewActivity()
+			err := activity.Apply(tt.event)
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("Expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+			if activity.TransactionCount != 0 || activity.LastActivity != 0 {
+				t.Errorf("Expected a rejected event to leave the activity unchanged, got %+v", activity)
+			}
+		})
+	}
+}
+
+func TestTracker(t *testing.T) {
+	tracker := NewTracker()
+	const user = "0x00000000000000000000000000000000000000AB"
+
+	if _, ok := tracker.Summary(user, 0, 0); ok {
+		t.Fatal("Expected no summary for an unknown user")
+	}
+	if err := tracker.Record(user, Event{Type: LiquidityProvision, Amount: big.NewInt(1e18), Timestamp: 1000}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if err := tracker.Record(user, Event{Type: ClaimRewards, Timestamp: 2000}); err == nil {
+		t.Error("Expected a claim to be rejected")
+	}
+
+	score, ok := tracker.LoyaltyScore("0x00000000000000000000000000000000000000ab")
+	if !ok || score != 2 {
+		t.Errorf("Expected loyalty 2 for the lower-case address, got %d (%v)", score, ok)
+	}
+
+	summary, ok := tracker.Summary(user, 60, 1060)
+	if !ok {
+		t.Fatal("Expected a summary")
+	}
+	// 0 liquidity + 0 volume + 2 loyalty * 25 + 100 consistency * 20, over 100
+	if summary.EngagementScore != 20 || summary.Tier != tier.Bronze || summary.TierName != "Bronze" || !summary.Active {
+		t.Errorf("Unexpected summary %+v", summary)
+	}
+	if summary, _ := tracker.Summary(user, 60, 1061); summary.Active {
+		t.Error("Expected the user to be inactive after the threshold")
+	}
+
+	// Copies do not alias the tracked activity
+	activity, _ := tracker.Activity(user)
+	activity.TotalLiquidity.SetInt64(0)
+	if again, _ := tracker.Activity(user); again.TotalLiquidity.Int64() != 1e18 {
+		t.Errorf("Expected the tracked liquidity to be unchanged, got %s", again.TotalLiquidity)
+	}
+	if tracker.Len() != 1 {
+		t.Errorf("Expected 1 user, got %d", tracker.Len())
+	}
+}
@@ -0,0 +1,140 @@
+package engagement
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"go.uber.org/zap"
+)
+
+// ActivityRecordedTopic is the topic of
+// Events.ActivityRecorded(address indexed user, uint8 activityType, uint256 amount, uint256 timestamp)
+var ActivityRecordedTopic = crypto.Keccak256Hash([]byte("ActivityRecorded(address,uint8,uint256,uint256)"))
+
+// maxBlockRange bounds a single eth_getLogs request
+const maxBlockRange = 10000
+
+// LogReader is the subset of an Ethereum client used to follow events
+type LogReader interface {
+	ethereum.LogFilterer
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// EventStore is a Tracker populated from the ActivityRecorded events of a
+// contract. Events are applied in log order, at the timestamp they carry.
+type EventStore struct {
+	*Tracker
+	client   LogReader
+	contract common.Address
+
+	syncMu sync.Mutex
+	next   uint64 // first block not yet applied
+}
+
+// NewEventStore creates a store following contract from fromBlock on. Call
+// Sync to load the events.
+func NewEventStore(client LogReader, contract common.Address, fromBlock uint64) *EventStore {
+	return &EventStore{
+		Tracker:  NewTracker(),
+		client:   client,
+		contract: contract,
+		next:     fromBlock,
+	}
+}
+
+// Sync applies every ActivityRecorded event up to the current head and
+// returns how many were applied
+func (s *EventStore) Sync(ctx context.Context) (int, error) {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	head, err := s.client.BlockNumber(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get head block: %w", err)
+	}
+
+	applied := 0
+	for s.next <= head {
+		to := s.next + maxBlockRange - 1
+		if to > head {
+			to = head
+		}
+
+		logs, err := s.client.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(s.next),
+			ToBlock:   new(big.Int).SetUint64(to),
+			Addresses: []common.Address{s.contract},
+			Topics:    [][]common.Hash{{ActivityRecordedTopic}},
+		})
+		if err != nil {
+			return applied, fmt.Errorf("failed to get ActivityRecorded logs for blocks %d-%d: %w", s.next, to, err)
+		}
+		for _, log := range logs {
+			if err := s.apply(log); err != nil {
+				return applied, err
+			}
+			applied++
+		}
+		s.next = to + 1
+	}
+	return applied, nil
+}
+
+// Run syncs every interval until ctx is done
+func (s *EventStore) Run(ctx context.Context, interval time.Duration, logger *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			applied, err := s.Sync(ctx)
+			if err != nil {
+				logger.Warn("Failed to sync user activity", zap.Error(err))
+			}
+			if applied > 0 {
+				logger.Info("User activity updated", zap.Int("events", applied), zap.Int("users", s.Len()))
+			}
+		}
+	}
+}
+
+// apply decodes an ActivityRecorded log into the tracker
+func (s *EventStore) apply(log types.Log) error {
+	if len(log.Topics) != 2 || log.Topics[0] != ActivityRecordedTopic {
+		return fmt.Errorf("log %s:%d is not an ActivityRecorded event", log.TxHash.Hex(), log.Index)
+	}
+	if len(log.Data) != 96 {
+		return fmt.Errorf("ActivityRecorded log %s:%d: expected 96 data bytes, got %d", log.TxHash.Hex(), log.Index, len(log.Data))
+	}
+
+	activityType := new(big.Int).SetBytes(log.Data[:32])
+	if !activityType.IsUint64() || activityType.Uint64() > uint64(ClaimRewards) {
+		return fmt.Errorf("ActivityRecorded log %s:%d: invalid activity type %s", log.TxHash.Hex(), log.Index, activityType)
+	}
+	timestamp := new(big.Int).SetBytes(log.Data[64:])
+	if !timestamp.IsInt64() {
+		return fmt.Errorf("ActivityRecorded log %s:%d: invalid timestamp %s", log.TxHash.Hex(), log.Index, timestamp)
+	}
+
+	e := Event{
+		Type:      ActivityType(activityType.Uint64()),
+		Amount:    new(big.Int).SetBytes(log.Data[32:64]),
+		Timestamp: timestamp.Int64(),
+	}
+	// The libraries only update on liquidity provision and swaps
+	if e.Type != LiquidityProvision && e.Type != Swap {
+		return nil
+	}
+	user := common.BytesToAddress(log.Topics[1].Bytes()).Hex()
+	return s.Record(user, e)
+}
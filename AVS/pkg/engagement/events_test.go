@@ -0,0 +1,183 @@
+package engagement
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// mockTrackerCode emits ActivityRecorded(msg.sender, activityType, amount,
+// timestamp) for a call with abi.encode(activityType, amount, timestamp).
+//
+//	PUSH1 0x60 PUSH1 0x00 PUSH1 0x00 CALLDATACOPY
+//	CALLER PUSH32 topic PUSH1 0x60 PUSH1 0x00 LOG2 STOP
+func mockTrackerCode() []byte {
+	code := common.FromHex("0x60606000600037337f")
+	code = append(code, ActivityRecordedTopic.Bytes()...)
+	return append(code, common.FromHex("0x60606000a200")...)
+}
+
+var trackerAddress = common.HexToAddress("0x00000000000000000000000000000000000ac717")
+
+type simulatedTracker struct {
+	backend *simulated.Backend
+	users   []*ecdsa.PrivateKey
+}
+
+func newSimulatedTracker(t *testing.T, users int) *simulatedTracker {
+	t.Helper()
+	alloc := types.GenesisAlloc{trackerAddress: {Code: mockTrackerCode()}}
+	sim := &simulatedTracker{}
+	for i := 0; i < users; i++ {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatalf("Failed to generate key: %v", err)
+		}
+		alloc[crypto.PubkeyToAddress(key.PublicKey)] = types.Account{Balance: big.NewInt(params.Ether)}
+		sim.users = append(sim.users, key)
+	}
+	sim.backend = simulated.NewBackend(alloc)
+	t.Cleanup(func() { sim.backend.Close() })
+	return sim
+}
+
+// record emits an activity of a user and mines it
+func (s *simulatedTracker) record(t *testing.T, user int, activityType ActivityType, amount *big.Int, timestamp int64) {
+	t.Helper()
+	ctx := context.Background()
+	client := s.backend.Client()
+	key := s.users[user]
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get chain ID: %v", err)
+	}
+	nonce, err := client.PendingNonceAt(ctx, crypto.PubkeyToAddress(key.PublicKey))
+	if err != nil {
+		t.Fatalf("Failed to get nonce: %v", err)
+	}
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to get head: %v", err)
+	}
+
+	tip := big.NewInt(params.GWei)
+	data := common.BigToHash(big.NewInt(int64(activityType))).Bytes()
+	data = append(data, common.BigToHash(amount).Bytes()...)
+	data = append(data, common.BigToHash(big.NewInt(timestamp)).Bytes()...)
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(chainID), &types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasTipCap: tip,
+		GasFeeCap: new(big.Int).Add(tip, new(big.Int).Mul(head.BaseFee, big.NewInt(2))),
+		Gas:       100000,
+		To:        &trackerAddress,
+		Data:      data,
+	})
+	if err != nil {
+		t.Fatalf("Failed to sign transaction: %v", err)
+	}
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		t.Fatalf("Failed to send transaction: %v", err)
+	}
+	s.backend.Commit()
+}
+
+func (s *simulatedTracker) address(user int) string {
+	return crypto.PubkeyToAddress(s.users[user].PublicKey).Hex()
+}
+
+func TestEventStore_Sync(t *testing.T) {
+	sim := newSimulatedTracker(t, 2)
+	sim.record(t, 0, LiquidityProvision, big.NewInt(1e18), 1000)
+	sim.record(t, 0, Swap, big.NewInt(5e18), 1100)
+	sim.record(t, 1, ClaimRewards, big.NewInt(1), 1200)
+
+	store := NewEventStore(sim.backend.Client(), trackerAddress, 0)
+	applied, err := store.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	// Claims are read but the libraries do not track them
+	if applied != 3 || store.Len() != 1 {
+		t.Fatalf("Expected 3 events for 1 user, got %d events for %d users", applied, store.Len())
+	}
+
+	activity, ok := store.Activity(sim.address(0))
+	if !ok || activity.TotalLiquidity.Int64() != 1e18 || activity.SwapVolume.Int64() != 5e18 {
+		t.Fatalf("Unexpected activity for first user: %+v", activity)
+	}
+	if activity.LoyaltyScore != 3 || activity.TransactionCount != 2 || activity.LastActivity != 1100 {
+		t.Errorf("Expected loyalty 3 after 2 transactions until 1100, got %+v", activity)
+	}
+
+	// Only new events are applied on the next sync
+	sim.record(t, 1, Swap, big.NewInt(1e18), 1300)
+	applied, err = store.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if applied != 1 {
+		t.Errorf("Expected 1 new event, got %d", applied)
+	}
+	if score, ok := store.LoyaltyScore(strings.ToLower(sim.address(1))); !ok || score != 1 {
+		t.Errorf("Expected loyalty 1 for the second user, got %d (%v)", score, ok)
+	}
+}
+
+func TestEventStore_ApplyErrors(t *testing.T) {
+	user := common.BytesToHash(common.HexToAddress("0x00000000000000000000000000000000000000ab").Bytes())
+	word := func(v int64) []byte { return common.BigToHash(big.NewInt(v)).Bytes() }
+	data := func(words ...[]byte) []byte {
+		var out []byte
+		for _, w := range words {
+			out = append(out, w...)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name     string
+		log      types.Log
+		errorMsg string
+	}{
+		{
+			name:     "other event",
+			log:      types.Log{Topics: []common.Hash{crypto.Keccak256Hash([]byte("PreferencesUpdated(address,uint256,uint256)")), user}},
+			errorMsg: "is not an ActivityRecorded event",
+		},
+		{
+			name:     "short data",
+			log:      types.Log{Topics: []common.Hash{ActivityRecordedTopic, user}, Data: data(word(0), word(1))},
+			errorMsg: "expected 96 data bytes, got 64",
+		},
+		{
+			name:     "unknown activity type",
+			log:      types.Log{Topics: []common.Hash{ActivityRecordedTopic, user}, Data: data(word(4), word(1), word(1))},
+			errorMsg: "invalid activity type 4",
+		},
+		{
+			name:     "activity type beyond uint8",
+			log:      types.Log{Topics: []common.Hash{ActivityRecordedTopic, user}, Data: data(word(256), word(1), word(1))},
+			errorMsg: "invalid activity type 256",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewEventStore(nil, trackerAddress, 0)
+			err := store.apply(tt.log)
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("Expected error containing '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package engagement
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/tier"
+)
+
+// Summary mirrors getActivitySummary, plus whether the user is active
+type Summary struct {
+	User string `json:"user"`
+	Activity
+	EngagementScore uint64     `json:"engagement_score"`
+	Tier            tier.Level `json:"tier"`
+	TierName        string     `json:"tier_name"`
+	Active          bool       `json:"active"`
+}
+
+// Tracker keeps the activity of every user, safe for concurrent use
+type Tracker struct {
+	mu    sync.RWMutex
+	users map[string]*Activity
+}
+
+// NewTracker creates a tracker without any activity
+func NewTracker() *Tracker {
+	return &Tracker{users: make(map[string]*Activity)}
+}
+
+// Record applies an activity of user
+func (t *Tracker) Record(user string, e Event) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := normalizeUser(user)
+	activity, ok := t.users[key]
+	if !ok {
+		activity = NewActivity()
+	}
+	updated := activity.Clone()
+	if err := updated.Apply(e); err != nil {
+		return err
+	}
+	t.users[key] = updated
+	return nil
+}
+
+// Activity returns a copy of the activity of user, and false if the user has none
+func (t *Tracker) Activity(user string) (*Activity, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	activity, ok := t.users[normalizeUser(user)]
+	if !ok {
+		return nil, false
+	}
+	return activity.Clone(), true
+}
+
+// LoyaltyScore returns the loyalty score of user, and false if the user has no activity
+func (t *Tracker) LoyaltyScore(user string) (uint64, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	activity, ok := t.users[normalizeUser(user)]
+	if !ok {
+		return 0, false
+	}
+	return activity.LoyaltyScore, true
+}
+
+// Summary scores the activity of user at now, counting the user as active
+// when the last activity is at most inactiveThreshold seconds old
+func (t *Tracker) Summary(user string, inactiveThreshold uint64, now int64) (Summary, bool) {
+	activity, ok := t.Activity(user)
+	if !ok {
+		return Summary{}, false
+	}
+	level := activity.Tier()
+	return Summary{
+		User:            normalizeUser(user),
+		Activity:        *activity,
+		EngagementScore: activity.EngagementScore(),
+		Tier:            level,
+		TierName:        level.String(),
+		Active:          activity.IsActive(inactiveThreshold, now),
+	}, true
+}
+
+// Len returns the number of users with activity
+func (t *Tracker) Len() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.users)
+}
+
+// normalizeUser makes checksummed and lower-case addresses the same key
+func normalizeUser(user string) string {
+	return strings.ToLower(strings.TrimSpace(user))
+}
@@ -0,0 +1,238 @@
+// Package fees calculates distribution fees. The per-chain base fee mirrors
+// DistributionUtils.calculateFees and the proportional fee mirrors
+// RewardMath.calculateFee, so every operator charges what the contracts would.
+package fees
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/chains"
+)
+
+// Fee models
+const (
+	ModelFlatBps     = "flat_bps"
+	ModelChainBase   = "chain_base"
+	ModelBasePlusBps = "base_plus_bps"
+)
+
+// BpsDenominator is Constants.FEE_DENOMINATOR
+const BpsDenominator = 10000
+
+// DefaultBaseFee is the DistributionUtils.calculateFees base fee, charged on
+// Ethereum and on chains without a base fee of their own
+var DefaultBaseFee = big.NewInt(1e15) // 0.001 ETH
+
+// Breakdown splits a distribution fee by what it pays for
+type Breakdown struct {
+	// BridgeFee pays for delivery on the target chain
+	BridgeFee *big.Int `json:"bridge_fee"`
+	// ProtocolFee is the protocol's share of the proportional fee
+	ProtocolFee *big.Int `json:"protocol_fee"`
+	// OperatorFee is the operators' share of the proportional fee
+	OperatorFee *big.Int `json:"operator_fee"`
+}
+
+// Zero returns an empty breakdown
+func Zero() Breakdown {
+	return Breakdown{BridgeFee: new(big.Int), ProtocolFee: new(big.Int), OperatorFee: new(big.Int)}
+}
+
+// Total returns the sum of the fee components
+func (b Breakdown) Total() *big.Int {
+	total := new(big.Int)
+	for _, part := range []*big.Int{b.BridgeFee, b.ProtocolFee, b.OperatorFee} {
+		if part != nil {
+			total.Add(total, part)
+		}
+	}
+	return total
+}
+
+// Add accumulates another breakdown into b
+func (b *Breakdown) Add(other Breakdown) {
+	add := func(dst **big.Int, v *big.Int) {
+		if *dst == nil {
+			*dst = new(big.Int)
+		}
+		if v != nil {
+			(*dst).Add(*dst, v)
+		}
+	}
+	add(&b.BridgeFee, other.BridgeFee)
+	add(&b.ProtocolFee, other.ProtocolFee)
+	add(&b.OperatorFee, other.OperatorFee)
+}
+
+// Engine calculates the fee of a distribution
+type Engine interface {
+	// Model returns the fee model name
+	Model() string
+	// Calculate returns the fee of distributing amount to targetChain. Like
+	// DistributionUtils.calculateNetAmount, it fails when the fee exceeds the amount.
+	Calculate(amount *big.Int, targetChain uint64) (Breakdown, error)
+}
+
+// ChainFees looks up the fee parameters of a target chain
+type ChainFees interface {
+	FeeParams(chainID uint64) (chains.FeeParams, bool)
+}
+
+// StaticChainFees is a fixed table of chain fee parameters
+type StaticChainFees map[uint64]chains.FeeParams
+
+// FeeParams returns the fee parameters of a chain in the table
+func (s StaticChainFees) FeeParams(chainID uint64) (chains.FeeParams, bool) {
+	params, ok := s[chainID]
+	return params, ok
+}
+
+// DistributionUtilsFees returns the base fees hard-coded in DistributionUtils.calculateFees
+func DistributionUtilsFees() StaticChainFees {
+	base := func(divisor int64) chains.FeeParams {
+		return chains.FeeParams{BaseFee: new(big.Int).Div(DefaultBaseFee, big.NewInt(divisor))}
+	}
+	return StaticChainFees{
+		1:     base(1),  // Ethereum
+		42161: base(10), // Arbitrum
+		137:   base(20), // Polygon
+		8453:  base(15), // Base
+	}
+}
+
+// FlatBps charges a proportional fee, split between the protocol and the operators
+type FlatBps struct {
+	bps           uint64
+	protocolShare uint64
+}
+
+// NewFlatBps creates a proportional fee engine. protocolShareBps is the
+// protocol's share of the fee; the operators get the rest.
+func NewFlatBps(bps, protocolShareBps uint64) (*FlatBps, error) {
+	if err := validateBps(bps, protocolShareBps); err != nil {
+		return nil, err
+	}
+	return &FlatBps{bps: bps, protocolShare: protocolShareBps}, nil
+}
+
+// Model returns "flat_bps"
+func (e *FlatBps) Model() string {
+	return ModelFlatBps
+}
+
+// Calculate charges amount * bps / 10000, whatever the target chain
+func (e *FlatBps) Calculate(amount *big.Int, _ uint64) (Breakdown, error) {
+	breakdown := Zero()
+	breakdown.ProtocolFee, breakdown.OperatorFee = splitProportional(amount, e.bps, e.protocolShare)
+	return breakdown, checkAmount(amount, breakdown)
+}
+
+// ChainBase charges the base fee of the target chain, as DistributionUtils.calculateFees
+type ChainBase struct {
+	chains ChainFees
+}
+
+// NewChainBase creates a per-chain base fee engine. Chains missing from
+// chainFees, or without a base fee, are charged DefaultBaseFee.
+func NewChainBase(chainFees ChainFees) *ChainBase {
+	return &ChainBase{chains: chainFees}
+}
+
+// Model returns "chain_base"
+func (e *ChainBase) Model() string {
+	return ModelChainBase
+}
+
+// Calculate charges the base fee of targetChain as the bridge fee
+func (e *ChainBase) Calculate(amount *big.Int, targetChain uint64) (Breakdown, error) {
+	breakdown := Zero()
+	breakdown.BridgeFee = baseFee(e.chains, targetChain)
+	return breakdown, checkAmount(amount, breakdown)
+}
+
+// BasePlusBps charges the base fee of the target chain plus a proportional fee
+type BasePlusBps struct {
+	chains        ChainFees
+	bps           uint64
+	protocolShare uint64
+}
+
+// NewBasePlusBps creates a base plus proportional fee engine. A chain's own
+// fee_bps, when set, replaces bps for distributions to that chain.
+func NewBasePlusBps(chainFees ChainFees, bps, protocolShareBps uint64) (*BasePlusBps, error) {
+	if err := validateBps(bps, protocolShareBps); err != nil {
+		return nil, err
+	}
+	return &BasePlusBps{chains: chainFees, bps: bps, protocolShare: protocolShareBps}, nil
+}
+
+// Model returns "base_plus_bps"
+func (e *BasePlusBps) Model() string {
+	return ModelBasePlusBps
+}
+
+// Calculate charges the base fee of targetChain plus amount * bps / 10000
+func (e *BasePlusBps) Calculate(amount *big.Int, targetChain uint64) (Breakdown, error) {
+	bps := e.bps
+	if params, ok := e.chains.FeeParams(targetChain); ok && params.Bps != 0 {
+		bps = params.Bps
+	}
+
+	breakdown := Zero()
+	breakdown.BridgeFee = baseFee(e.chains, targetChain)
+	breakdown.ProtocolFee, breakdown.OperatorFee = splitProportional(amount, bps, e.protocolShare)
+	return breakdown, checkAmount(amount, breakdown)
+}
+
+// baseFee returns the base fee of a chain, DefaultBaseFee if it has none
+func baseFee(chainFees ChainFees, chainID uint64) *big.Int {
+	if params, ok := chainFees.FeeParams(chainID); ok && params.BaseFee != nil {
+		return new(big.Int).Set(params.BaseFee)
+	}
+	return new(big.Int).Set(DefaultBaseFee)
+}
+
+// splitProportional computes amount * bps / 10000 as RewardMath.calculateFee
+// does with a rate of bps * 1e14, then gives the protocol its share, rounded
+// down, and the operators the exact remainder
+func splitProportional(amount *big.Int, bps, protocolShareBps uint64) (protocol, operator *big.Int) {
+	fee := new(big.Int).Mul(amount, new(big.Int).SetUint64(bps))
+	fee.Div(fee, big.NewInt(BpsDenominator))
+
+	protocol = new(big.Int).Mul(fee, new(big.Int).SetUint64(protocolShareBps))
+	protocol.Div(protocol, big.NewInt(BpsDenominator))
+	operator = fee.Sub(fee, protocol)
+	return protocol, operator
+}
+
+func checkAmount(amount *big.Int, breakdown Breakdown) error {
+	if total := breakdown.Total(); total.Cmp(amount) > 0 {
+		return fmt.Errorf("fee %s exceeds amount %s", total, amount)
+	}
+	return nil
+}
+
+func validateBps(bps, protocolShareBps uint64) error {
+	if bps >= BpsDenominator {
+		return fmt.Errorf("fee bps must be below %d, got %d", BpsDenominator, bps)
+	}
+	if protocolShareBps > BpsDenominator {
+		return fmt.Errorf("protocol share must be at most %d bps, got %d", BpsDenominator, protocolShareBps)
+	}
+	return nil
+}
+
+// New creates the engine of a fee model
+func New(model string, chainFees ChainFees, bps, protocolShareBps uint64) (Engine, error) {
+	switch model {
+	case ModelFlatBps:
+		return NewFlatBps(bps, protocolShareBps)
+	case ModelChainBase:
+		return NewChainBase(chainFees), nil
+	case ModelBasePlusBps:
+		return NewBasePlusBps(chainFees, bps, protocolShareBps)
+	default:
+		return nil, fmt.Errorf("unknown fee model %q", model)
+	}
+}
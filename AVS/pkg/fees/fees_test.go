@@ -0,0 +1,211 @@
+package fees
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/chains"
+)
+
+// vectorsPath is shared with test/unit/FeeVectors.t.sol, which checks the
+// same vectors against DistributionUtils and RewardMath
+var vectorsPath = filepath.Join("..", "..", "..", "test", "vectors", "fees.json")
+
+type feeVector struct {
+	Name             string `json:"name"`
+	Amount           string `json:"amount"`
+	TargetChain      uint64 `json:"targetChain"`
+	Bps              uint64 `json:"bps"`
+	ProtocolShareBps uint64 `json:"protocolShareBps"`
+	Fee              string `json:"fee"`
+	BridgeFee        string `json:"bridgeFee"`
+	ProtocolFee      string `json:"protocolFee"`
+	OperatorFee      string `json:"operatorFee"`
+	NetAmount        string `json:"netAmount"`
+}
+
+type feeVectors struct {
+	CalculateFees []feeVector `json:"calculateFees"`
+	Proportional  []feeVector `json:"proportional"`
+	BasePlusBps   []feeVector `json:"basePlusBps"`
+}
+
+func loadVectors(t *testing.T) feeVectors {
+	t.Helper()
+	data, err := os.ReadFile(vectorsPath)
+	if err != nil {
+		t.Fatalf("Failed to read fee vectors: %v", err)
+	}
+	var vectors feeVectors
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		t.Fatalf("Failed to parse fee vectors: %v", err)
+	}
+	if len(vectors.CalculateFees) == 0 || len(vectors.Proportional) == 0 || len(vectors.BasePlusBps) == 0 {
+		t.Fatalf("Fee vectors are missing a section")
+	}
+	return vectors
+}
+
+func wei(t *testing.T, s string) *big.Int {
+	t.Helper()
+	if s == "" {
+		return new(big.Int)
+	}
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		t.Fatalf("Invalid wei amount %q", s)
+	}
+	return v
+}
+
+// checkVector compares a calculated breakdown with a vector. Vectors without a
+// net amount are the ones where DistributionUtils.calculateNetAmount reverts.
+func checkVector(t *testing.T, v feeVector, breakdown Breakdown, err error) {
+	t.Helper()
+	if v.NetAmount == "" {
+		if err == nil || !strings.Contains(err.Error(), "exceeds amount") {
+			t.Errorf("Expected the fee to exceed the amount, got %v", err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	if got := breakdown.Total(); got.Cmp(wei(t, v.Fee)) != 0 {
+		t.Errorf("Expected fee %s, got %s", v.Fee, got)
+	}
+	if got := new(big.Int).Sub(wei(t, v.Amount), breakdown.Total()); got.Cmp(wei(t, v.NetAmount)) != 0 {
+		t.Errorf("Expected net amount %s, got %s", v.NetAmount, got)
+	}
+}
+
+func TestChainBase_Vectors(t *testing.T) {
+	engine := NewChainBase(DistributionUtilsFees())
+	for _, v := range loadVectors(t).CalculateFees {
+		t.Run(v.Name, func(t *testing.T) {
+			breakdown, err := engine.Calculate(wei(t, v.Amount), v.TargetChain)
+			checkVector(t, v, breakdown, err)
+			if err == nil && breakdown.BridgeFee.Cmp(wei(t, v.Fee)) != 0 {
+				t.Errorf("Expected the whole fee %s to be the bridge fee, got %s", v.Fee, breakdown.BridgeFee)
+			}
+		})
+	}
+}
+
+func TestFlatBps_Vectors(t *testing.T) {
+	for _, v := range loadVectors(t).Proportional {
+		t.Run(v.Name, func(t *testing.T) {
+			engine, err := NewFlatBps(v.Bps, v.ProtocolShareBps)
+			if err != nil {
+				t.Fatalf("NewFlatBps failed: %v", err)
+			}
+			breakdown, err := engine.Calculate(wei(t, v.Amount), 1)
+			if err != nil {
+				t.Fatalf("Calculate failed: %v", err)
+			}
+			if breakdown.BridgeFee.Sign() != 0 {
+				t.Errorf("Expected no bridge fee, got %s", breakdown.BridgeFee)
+			}
+			if breakdown.ProtocolFee.Cmp(wei(t, v.ProtocolFee)) != 0 || breakdown.OperatorFee.Cmp(wei(t, v.OperatorFee)) != 0 {
+				t.Errorf("Expected protocol/operator fees %s/%s, got %s/%s", v.ProtocolFee, v.OperatorFee, breakdown.ProtocolFee, breakdown.OperatorFee)
+			}
+			if got := breakdown.Total(); got.Cmp(wei(t, v.Fee)) != 0 {
+				t.Errorf("Expected fee %s, got %s", v.Fee, got)
+			}
+		})
+	}
+}
+
+func TestBasePlusBps_Vectors(t *testing.T) {
+	for _, v := range loadVectors(t).BasePlusBps {
+		t.Run(v.Name, func(t *testing.T) {
+			engine, err := NewBasePlusBps(DistributionUtilsFees(), v.Bps, v.ProtocolShareBps)
+			if err != nil {
+				t.Fatalf("NewBasePlusBps failed: %v", err)
+			}
+			breakdown, err := engine.Calculate(wei(t, v.Amount), v.TargetChain)
+			checkVector(t, v, breakdown, err)
+			if err != nil {
+				return
+			}
+			if breakdown.BridgeFee.Cmp(wei(t, v.BridgeFee)) != 0 || breakdown.ProtocolFee.Cmp(wei(t, v.ProtocolFee)) != 0 || breakdown.OperatorFee.Cmp(wei(t, v.OperatorFee)) != 0 {
+				t.Errorf("Expected bridge/protocol/operator fees %s/%s/%s, got %s/%s/%s",
+					v.BridgeFee, v.ProtocolFee, v.OperatorFee, breakdown.BridgeFee, breakdown.ProtocolFee, breakdown.OperatorFee)
+			}
+		})
+	}
+}
+
+func TestBasePlusBps_ChainParams(t *testing.T) {
+	registry, err := chains.NewRegistry([]chains.Chain{
+		{ID: 1, Name: "ethereum", Enabled: true, Fee: chains.FeeParams{BaseFee: big.NewInt(1e15)}},
+		{ID: 8453, Name: "base", Enabled: true, Fee: chains.FeeParams{BaseFee: big.NewInt(0), Bps: 50}},
+		{ID: 10, Name: "optimism", Enabled: true},
+	})
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
+	engine, err := NewBasePlusBps(registry, 10, 0)
+	if err != nil {
+		t.Fatalf("NewBasePlusBps failed: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		chain       uint64
+		bridgeFee   int64
+		operatorFee int64
+	}{
+		{name: "configured base fee", chain: 1, bridgeFee: 1e15, operatorFee: 1e15},
+		{name: "zero base fee and chain bps", chain: 8453, bridgeFee: 0, operatorFee: 5e15},
+		{name: "no base fee uses the default", chain: 10, bridgeFee: 1e15, operatorFee: 1e15},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breakdown, err := engine.Calculate(big.NewInt(1e18), tt.chain)
+			if err != nil {
+				t.Fatalf("Calculate failed: %v", err)
+			}
+			if breakdown.BridgeFee.Cmp(big.NewInt(tt.bridgeFee)) != 0 || breakdown.OperatorFee.Cmp(big.NewInt(tt.operatorFee)) != 0 {
+				t.Errorf("Expected bridge/operator fees %d/%d, got %s/%s", tt.bridgeFee, tt.operatorFee, breakdown.BridgeFee, breakdown.OperatorFee)
+			}
+		})
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		model         string
+		bps           uint64
+		protocolShare uint64
+		errorMsg      string
+	}{
+		{name: "unknown model", model: "tiered", errorMsg: `unknown fee model "tiered"`},
+		{name: "bps too high", model: ModelFlatBps, bps: 10000, errorMsg: "fee bps must be below 10000, got 10000"},
+		{name: "share too high", model: ModelBasePlusBps, bps: 10, protocolShare: 10001, errorMsg: "protocol share must be at most 10000 bps, got 10001"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.model, DistributionUtilsFees(), tt.bps, tt.protocolShare)
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("Expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestBreakdown_Add(t *testing.T) {
+	var total Breakdown
+	total.Add(Breakdown{BridgeFee: big.NewInt(3), OperatorFee: big.NewInt(5)})
+	total.Add(Breakdown{BridgeFee: big.NewInt(4), ProtocolFee: big.NewInt(1), OperatorFee: big.NewInt(2)})
+	if total.BridgeFee.Int64() != 7 || total.ProtocolFee.Int64() != 1 || total.OperatorFee.Int64() != 7 || total.Total().Int64() != 15 {
+		t.Errorf("Unexpected sum %+v", total)
+	}
+}
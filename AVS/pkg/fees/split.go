@@ -0,0 +1,124 @@
+package fees
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Beneficiaries of collected fees and MEV, as in Constants.sol
+const (
+	BeneficiaryLP              = "lp"
+	BeneficiaryOperator        = "operator"
+	BeneficiaryProtocol        = "protocol"
+	BeneficiaryGasCompensation = "gas_compensation"
+)
+
+// Beneficiaries lists every beneficiary in reporting order
+var Beneficiaries = []string{BeneficiaryLP, BeneficiaryOperator, BeneficiaryProtocol, BeneficiaryGasCompensation}
+
+// Shares are the basis-point shares of a split. They may add up to less than
+// 10000: what they leave, and the rounding dust of every part, goes to Remainder.
+type Shares struct {
+	LP              uint64
+	Operator        uint64
+	Protocol        uint64
+	GasCompensation uint64
+	// Remainder is the beneficiary of the unallocated share
+	Remainder string
+}
+
+// DefaultShares returns the Constants.sol shares: LP_FEE_SHARE, OPERATOR_FEE_SHARE,
+// PROTOCOL_FEE_SHARE and GAS_COMPENSATION_SHARE, with the remaining 35% to LPs
+func DefaultShares() Shares {
+	return Shares{LP: 5000, Operator: 1000, Protocol: 300, GasCompensation: 200, Remainder: BeneficiaryLP}
+}
+
+// Split is an amount divided between the beneficiaries
+type Split struct {
+	LP              *big.Int `json:"lp"`
+	Operator        *big.Int `json:"operator"`
+	Protocol        *big.Int `json:"protocol"`
+	GasCompensation *big.Int `json:"gas_compensation"`
+}
+
+// Total returns the sum of the parts, which is always the split amount
+func (s Split) Total() *big.Int {
+	total := new(big.Int)
+	for _, part := range s.ByBeneficiary() {
+		if part != nil {
+			total.Add(total, part)
+		}
+	}
+	return total
+}
+
+// ByBeneficiary returns the parts keyed by beneficiary
+func (s Split) ByBeneficiary() map[string]*big.Int {
+	return map[string]*big.Int{
+		BeneficiaryLP:              s.LP,
+		BeneficiaryOperator:        s.Operator,
+		BeneficiaryProtocol:        s.Protocol,
+		BeneficiaryGasCompensation: s.GasCompensation,
+	}
+}
+
+// part returns the field holding a beneficiary's part
+func (s *Split) part(beneficiary string) **big.Int {
+	switch beneficiary {
+	case BeneficiaryLP:
+		return &s.LP
+	case BeneficiaryOperator:
+		return &s.Operator
+	case BeneficiaryProtocol:
+		return &s.Protocol
+	case BeneficiaryGasCompensation:
+		return &s.GasCompensation
+	}
+	return nil
+}
+
+// Splitter divides amounts by fixed shares
+type Splitter struct {
+	shares Shares
+}
+
+// NewSplitter creates a splitter, checking that the shares fit in 10000 bps
+// and that the remainder goes to a known beneficiary
+func NewSplitter(shares Shares) (*Splitter, error) {
+	total := shares.LP + shares.Operator + shares.Protocol + shares.GasCompensation
+	if total > BpsDenominator {
+		return nil, fmt.Errorf("split shares add up to %d bps, more than %d", total, BpsDenominator)
+	}
+	var split Split
+	if split.part(shares.Remainder) == nil {
+		return nil, fmt.Errorf("unknown remainder beneficiary %q", shares.Remainder)
+	}
+	return &Splitter{shares: shares}, nil
+}
+
+// Shares returns the shares of the splitter
+func (s *Splitter) Shares() Shares {
+	return s.shares
+}
+
+// Split divides amount, rounding every part down as the contracts do and
+// giving the remainder beneficiary the difference, so the parts add up to amount
+func (s *Splitter) Split(amount *big.Int) Split {
+	if amount == nil {
+		amount = new(big.Int)
+	}
+	share := func(bps uint64) *big.Int {
+		part := new(big.Int).Mul(amount, new(big.Int).SetUint64(bps))
+		return part.Div(part, big.NewInt(BpsDenominator))
+	}
+	split := Split{
+		LP:              share(s.shares.LP),
+		Operator:        share(s.shares.Operator),
+		Protocol:        share(s.shares.Protocol),
+		GasCompensation: share(s.shares.GasCompensation),
+	}
+	remainder := new(big.Int).Sub(amount, split.Total())
+	part := split.part(s.shares.Remainder)
+	*part = remainder.Add(remainder, *part)
+	return split
+}
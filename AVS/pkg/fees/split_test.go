@@ -0,0 +1,99 @@
+package fees
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestSplitter_Split(t *testing.T) {
+	tests := []struct {
+		name   string
+		shares Shares
+		amount int64
+		want   [4]int64 // lp, operator, protocol, gas compensation
+	}{
+		{
+			name:   "default shares",
+			shares: DefaultShares(),
+			amount: 1e18,
+			want:   [4]int64{85e16, 1e17, 3e16, 2e16},
+		},
+		{
+			name:   "rounding dust goes to the remainder",
+			shares: Shares{LP: 3333, Operator: 3333, Protocol: 3334, Remainder: BeneficiaryProtocol},
+			amount: 7,
+			// 7*3333/10000 = 2 each, 7*3334/10000 = 2, dust 1
+			want: [4]int64{2, 2, 3, 0},
+		},
+		{
+			name:   "unallocated share to operators",
+			shares: Shares{LP: 5000, Protocol: 1000, Remainder: BeneficiaryOperator},
+			amount: 1001,
+			want:   [4]int64{500, 401, 100, 0},
+		},
+		{
+			name:   "zero amount",
+			shares: DefaultShares(),
+			want:   [4]int64{0, 0, 0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splitter, err := NewSplitter(tt.shares)
+			if err != nil {
+				t.Fatalf("NewSplitter failed: %v", err)
+			}
+			split := splitter.Split(big.NewInt(tt.amount))
+			got := [4]int64{split.LP.Int64(), split.Operator.Int64(), split.Protocol.Int64(), split.GasCompensation.Int64()}
+			if got != tt.want {
+				t.Errorf("Expected lp/operator/protocol/gas parts %v, got %v", tt.want, got)
+			}
+			if split.Total().Int64() != tt.amount {
+				t.Errorf("Expected parts to add up to %d, got %s", tt.amount, split.Total())
+			}
+		})
+	}
+}
+
+func TestSplitter_SumsToWhole(t *testing.T) {
+	splitter, err := NewSplitter(Shares{LP: 1, Operator: 7, Protocol: 13, GasCompensation: 9979, Remainder: BeneficiaryLP})
+	if err != nil {
+		t.Fatalf("NewSplitter failed: %v", err)
+	}
+	amount := new(big.Int)
+	for i := 0; i < 1000; i++ {
+		amount.Mul(amount, big.NewInt(3)).Add(amount, big.NewInt(int64(i)))
+		if got := splitter.Split(amount).Total(); got.Cmp(amount) != 0 {
+			t.Fatalf("Expected parts to add up to %s, got %s", amount, got)
+		}
+	}
+}
+
+func TestNewSplitter_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		shares   Shares
+		errorMsg string
+	}{
+		{
+			name:     "shares above 100%",
+			shares:   Shares{LP: 9000, Operator: 1001, Remainder: BeneficiaryLP},
+			errorMsg: "split shares add up to 10001 bps, more than 10000",
+		},
+		{
+			name:     "unknown remainder",
+			shares:   Shares{LP: 5000, Remainder: "treasury"},
+			errorMsg: `unknown remainder beneficiary "treasury"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSplitter(tt.shares)
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("Expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+// Package gas reads gas prices and decides when a distribution is worth
+// sending, following DistributionUtils.calculateOptimalTiming and
+// DistributionUtils.isDistributionProfitable.
+package gas
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// HighGasPrice is the price above which calculateOptimalTiming waits an hour
+// rather than half an hour
+var HighGasPrice = big.NewInt(20e9)
+
+const (
+	// highGasDelay and lowGasDelay are the gas factors of calculateOptimalTiming
+	highGasDelay = time.Hour
+	lowGasDelay  = 30 * time.Minute
+	// defaultUserDelay is the user factor of users without a preference
+	defaultUserDelay = time.Hour
+)
+
+// PriceSource reports the current gas price of a chain, in wei
+type PriceSource interface {
+	GasPrice(ctx context.Context, chainID uint64) (*big.Int, error)
+}
+
+// Static is a PriceSource with fixed prices, for tests and chains without an
+// RPC. Prices can be changed at any time. It is safe for concurrent use.
+type Static struct {
+	mu       sync.RWMutex
+	fallback *big.Int
+	prices   map[uint64]*big.Int
+}
+
+// NewStatic creates a source reporting price on every chain
+func NewStatic(price *big.Int) *Static {
+	return &Static{fallback: new(big.Int).Set(price), prices: make(map[uint64]*big.Int)}
+}
+
+// Set sets the price of one chain
+func (s *Static) Set(chainID uint64, price *big.Int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prices[chainID] = new(big.Int).Set(price)
+}
+
+// GasPrice returns the price of the chain, or the price of every chain if it has none
+func (s *Static) GasPrice(_ context.Context, chainID uint64) (*big.Int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if price, ok := s.prices[chainID]; ok {
+		return new(big.Int).Set(price), nil
+	}
+	return new(big.Int).Set(s.fallback), nil
+}
+
+// GasPricer is the part of ethclient.Client that suggests gas prices
+type GasPricer interface {
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+}
+
+// Clients is a PriceSource asking each chain's RPC for its suggested gas price
+type Clients map[uint64]GasPricer
+
+// GasPrice returns the price suggested by the chain's RPC
+func (c Clients) GasPrice(ctx context.Context, chainID uint64) (*big.Int, error) {
+	client, ok := c[chainID]
+	if !ok {
+		return nil, fmt.Errorf("no RPC for chain %d", chainID)
+	}
+	price, err := client.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gas price of chain %d: %w", chainID, err)
+	}
+	return price, nil
+}
+
+// OptimalTiming applies DistributionUtils.calculateOptimalTiming: half way
+// between the gas delay, an hour above HighGasPrice and half an hour otherwise,
+// and the user's claim frequency, an hour if unset
+func OptimalTiming(now time.Time, userDelay time.Duration, gasPrice *big.Int) time.Time {
+	gasDelay := lowGasDelay
+	if gasPrice.Cmp(HighGasPrice) > 0 {
+		gasDelay = highGasDelay
+	}
+	if userDelay <= 0 {
+		userDelay = defaultUserDelay
+	}
+	return now.Add((gasDelay + userDelay) / 2)
+}
+
+// Reason says why a distribution is deferred
+type Reason string
+
+const (
+	// ReasonHighGas defers distributions while gas costs more than the limit
+	ReasonHighGas Reason = "high_gas"
+	// ReasonUnprofitable defers distributions whose fees and gas leave less than the minimum
+	ReasonUnprofitable Reason = "unprofitable"
+)
+
+// Policy decides which distributions wait for cheaper gas
+type Policy struct {
+	// MaxGasPrice is the price above which distributions are deferred
+	MaxGasPrice *big.Int
+	// GasLimit is the gas a distribution costs
+	GasLimit uint64
+	// MinProfit is the least a distribution must deliver after fees and gas
+	MinProfit *big.Int
+	// MaxDelay is how long after its timestamp a task may be deferred
+	MaxDelay time.Duration
+}
+
+// Decision is the outcome of Policy.Decide
+type Decision struct {
+	Defer    bool
+	Reason   Reason
+	GasPrice *big.Int
+	// GasCost is GasLimit at GasPrice
+	GasCost *big.Int
+	// Until is when the distribution should be retried
+	Until time.Time
+}
+
+// Quote describes a distribution to decide on
+type Quote struct {
+	Amount *big.Int
+	Fee    *big.Int
+	// Created is the task timestamp deferrals count from
+	Created time.Time
+	// UserDelay is the user's claim frequency, zero if unknown
+	UserDelay time.Duration
+}
+
+// Decide defers a distribution when gas is above MaxGasPrice, or when the
+// amount less the fee and gas cost is below MinProfit, as
+// isDistributionProfitable does with fees alone. Nothing is deferred past
+// MaxDelay after the task was created, and the retry time is the optimal
+// timing capped at that deadline.
+func (p Policy) Decide(q Quote, gasPrice *big.Int, now time.Time) Decision {
+	gasCost := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(p.GasLimit))
+	decision := Decision{GasPrice: gasPrice, GasCost: gasCost}
+
+	deadline := q.Created.Add(p.MaxDelay)
+	if !now.Before(deadline) {
+		return decision
+	}
+
+	net := new(big.Int).Sub(q.Amount, gasCost)
+	if q.Fee != nil {
+		net.Sub(net, q.Fee)
+	}
+	switch {
+	case p.MaxGasPrice != nil && gasPrice.Cmp(p.MaxGasPrice) > 0:
+		decision.Reason = ReasonHighGas
+	case p.MinProfit != nil && net.Cmp(p.MinProfit) < 0:
+		decision.Reason = ReasonUnprofitable
+	default:
+		return decision
+	}
+
+	decision.Defer = true
+	decision.Until = OptimalTiming(now, q.UserDelay, gasPrice)
+	if decision.Until.After(deadline) {
+		decision.Until = deadline
+	}
+	return decision
+}
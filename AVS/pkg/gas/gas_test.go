@@ -0,0 +1,152 @@
+package gas
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+)
+
+func gwei(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e9))
+}
+
+func TestOptimalTiming(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name      string
+		userDelay time.Duration
+		gasPrice  *big.Int
+		expected  time.Duration
+	}{
+		{name: "low gas, no preference", gasPrice: gwei(10), expected: 45 * time.Minute},
+		{name: "exactly 20 gwei is low", gasPrice: gwei(20), expected: 45 * time.Minute},
+		{name: "high gas, no preference", gasPrice: gwei(21), expected: time.Hour},
+		{name: "low gas, daily claims", userDelay: 24 * time.Hour, gasPrice: gwei(1), expected: 12*time.Hour + 15*time.Minute},
+		{name: "high gas, frequent claims", userDelay: 10 * time.Minute, gasPrice: gwei(100), expected: 35 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := OptimalTiming(now, tt.userDelay, tt.gasPrice); got.Sub(now) != tt.expected {
+				t.Errorf("Expected %s from now, got %s", tt.expected, got.Sub(now))
+			}
+		})
+	}
+}
+
+func TestPolicy_Decide(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	policy := Policy{
+		MaxGasPrice: gwei(50),
+		GasLimit:    100000,
+		MinProfit:   big.NewInt(1e15),
+		MaxDelay:    2 * time.Hour,
+	}
+
+	tests := []struct {
+		name     string
+		quote    Quote
+		gasPrice *big.Int
+		reason   Reason
+		until    time.Duration
+	}{
+		{
+			name:     "cheap and profitable",
+			quote:    Quote{Amount: big.NewInt(1e18), Fee: big.NewInt(1e15), Created: now},
+			gasPrice: gwei(10),
+		},
+		{
+			name:     "high gas",
+			quote:    Quote{Amount: big.NewInt(1e18), Fee: big.NewInt(1e15), Created: now},
+			gasPrice: gwei(51),
+			reason:   ReasonHighGas,
+			until:    time.Hour,
+		},
+		{
+			// 0.003 ETH less a 0.001 ETH fee and 100000 gas at 10 gwei leaves 0.001 ETH
+			name:     "just profitable",
+			quote:    Quote{Amount: big.NewInt(3e15), Fee: big.NewInt(1e15), Created: now},
+			gasPrice: gwei(10),
+		},
+		{
+			name:     "gas makes it unprofitable",
+			quote:    Quote{Amount: big.NewInt(3e15), Fee: big.NewInt(1e15), Created: now},
+			gasPrice: gwei(11),
+			reason:   ReasonUnprofitable,
+			until:    45 * time.Minute,
+		},
+		{
+			name:     "fees exceed the amount",
+			quote:    Quote{Amount: big.NewInt(1e14), Fee: big.NewInt(1e15), Created: now, UserDelay: 30 * time.Minute},
+			gasPrice: gwei(1),
+			reason:   ReasonUnprofitable,
+			until:    30 * time.Minute,
+		},
+		{
+			name:     "retry capped at the deadline",
+			quote:    Quote{Amount: big.NewInt(1e18), Created: now.Add(-110 * time.Minute)},
+			gasPrice: gwei(100),
+			reason:   ReasonHighGas,
+			until:    10 * time.Minute,
+		},
+		{
+			name:     "past the maximum delay",
+			quote:    Quote{Amount: big.NewInt(1e18), Created: now.Add(-2 * time.Hour)},
+			gasPrice: gwei(100),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := policy.Decide(tt.quote, tt.gasPrice, now)
+			if decision.Defer != (tt.reason != "") || decision.Reason != tt.reason {
+				t.Fatalf("Expected reason %q, got %+v", tt.reason, decision)
+			}
+			if expected := new(big.Int).Mul(tt.gasPrice, big.NewInt(100000)); decision.GasCost.Cmp(expected) != 0 {
+				t.Errorf("Expected gas cost %s, got %s", expected, decision.GasCost)
+			}
+			if decision.Defer && decision.Until.Sub(now) != tt.until {
+				t.Errorf("Expected retry in %s, got %s", tt.until, decision.Until.Sub(now))
+			}
+		})
+	}
+}
+
+func TestStatic(t *testing.T) {
+	source := NewStatic(gwei(5))
+	source.Set(1, gwei(30))
+
+	for chainID, expected := range map[uint64]*big.Int{1: gwei(30), 10: gwei(5)} {
+		price, err := source.GasPrice(context.Background(), chainID)
+		if err != nil {
+			t.Fatalf("GasPrice failed: %v", err)
+		}
+		if price.Cmp(expected) != 0 {
+			t.Errorf("Expected %s on chain %d, got %s", expected, chainID, price)
+		}
+	}
+}
+
+func TestClients(t *testing.T) {
+	backend := simulated.NewBackend(types.GenesisAlloc{})
+	t.Cleanup(func() { backend.Close() })
+	source := Clients{1337: backend.Client()}
+
+	price, err := source.GasPrice(context.Background(), 1337)
+	if err != nil {
+		t.Fatalf("GasPrice failed: %v", err)
+	}
+	if price.Sign() <= 0 {
+		t.Errorf("Expected a positive gas price, got %s", price)
+	}
+
+	_, err = source.GasPrice(context.Background(), 1)
+	if expected := "no RPC for chain 1"; err == nil || err.Error() != expected {
+		t.Errorf("Expected error message '%s', got '%v'", expected, err)
+	}
+}
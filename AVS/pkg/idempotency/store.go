@@ -0,0 +1,262 @@
+// Package idempotency provides a durable record of processed RewardFlow tasks so
+// that retried or duplicated tasks return their original result instead of
+// distributing rewards twice.
+package idempotency
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	tasksBucket   = []byte("tasks")
+	sourcesBucket = []byte("sources")
+	seenBucket    = []byte("seen")
+)
+
+// ErrInvalidRecord is returned when a record cannot be stored
+var ErrInvalidRecord = errors.New("invalid idempotency record")
+
+// Record is the stored outcome of a processed task
+type Record struct {
+	TaskID    string `json:"task_id"`
+	SourceKey string `json:"source_key,omitempty"`
+	Result    []byte `json:"result"`
+	// Retry, when set, is what a redelivery needs to retry the parts of the
+	// task that failed, Result being the outcome so far. Such a task is not
+	// done, so a redelivery is processed rather than answered with Result.
+	Retry    []byte    `json:"retry,omitempty"`
+	StoredAt time.Time `json:"stored_at"`
+}
+
+// Store records processed tasks keyed by task ID and by source event
+type Store interface {
+	// Lookup returns the record stored under the task ID or, failing that, the source key
+	Lookup(taskID, sourceKey string) (*Record, bool, error)
+	// Put stores a record under its task ID and source key
+	Put(record *Record) error
+	// FirstSeen returns when a task ID was first seen, recording the current
+	// time when it is new. Tasks whose payload carries no timestamp count their
+	// age from it, however often they are delivered.
+	FirstSeen(taskID string) (time.Time, error)
+	// Prune removes records older than the retention window and returns how many were removed
+	Prune() (int, error)
+	// Close releases the underlying resources
+	Close() error
+}
+
+// SourceKey identifies the on-chain event a task was created from, so the same
+// event submitted under a different task ID is still recognised as a duplicate.
+// It returns an empty key when the task carries no transaction hash.
+func SourceKey(chainID uint64, txHash, user, rewardType string) string {
+	if txHash == "" {
+		return ""
+	}
+	return fmt.Sprintf("%d:%s:%s:%s",
+		chainID,
+		strings.ToLower(txHash),
+		strings.ToLower(user),
+		strings.ToLower(rewardType),
+	)
+}
+
+// BoltStore is a file-backed Store built on bbolt
+type BoltStore struct {
+	db        *bolt.DB
+	retention time.Duration
+	now       func() time.Time
+}
+
+// Open opens or creates a BoltStore at path. Records older than retention are
+// ignored on lookup and removed by Prune; a zero retention keeps records forever.
+func Open(path string, retention time.Duration) (*BoltStore, error) {
+	if retention < 0 {
+		return nil, fmt.Errorf("retention must not be negative: %s", retention)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create store directory: %w", err)
+	}
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open idempotency store %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{tasksBucket, sourcesBucket, seenBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize idempotency store: %w", err)
+	}
+
+	return &BoltStore{db: db, retention: retention, now: time.Now}, nil
+}
+
+// Lookup implements Store
+func (s *BoltStore) Lookup(taskID, sourceKey string) (*Record, bool, error) {
+	var record *Record
+	err := s.db.View(func(tx *bolt.Tx) error {
+		tasks := tx.Bucket(tasksBucket)
+
+		raw := tasks.Get([]byte(taskID))
+		if raw == nil && sourceKey != "" {
+			if originalID := tx.Bucket(sourcesBucket).Get([]byte(sourceKey)); originalID != nil {
+				raw = tasks.Get(originalID)
+			}
+		}
+		if raw == nil {
+			return nil
+		}
+
+		record = &Record{}
+		return json.Unmarshal(raw, record)
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to look up task %s: %w", taskID, err)
+	}
+	if record == nil || s.expired(record) {
+		return nil, false, nil
+	}
+	return record, true, nil
+}
+
+// Put implements Store
+func (s *BoltStore) Put(record *Record) error {
+	if record == nil || record.TaskID == "" {
+		return ErrInvalidRecord
+	}
+	if record.StoredAt.IsZero() {
+		record.StoredAt = s.now()
+	}
+
+	raw, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode record: %w", err)
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(tasksBucket).Put([]byte(record.TaskID), raw); err != nil {
+			return err
+		}
+		if record.SourceKey != "" {
+			return tx.Bucket(sourcesBucket).Put([]byte(record.SourceKey), []byte(record.TaskID))
+		}
+		return nil
+	})
+}
+
+// FirstSeen implements Store
+func (s *BoltStore) FirstSeen(taskID string) (time.Time, error) {
+	if taskID == "" {
+		return time.Time{}, ErrInvalidRecord
+	}
+	var seen time.Time
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(seenBucket)
+		if raw := bucket.Get([]byte(taskID)); len(raw) == 8 {
+			seen = time.Unix(int64(binary.BigEndian.Uint64(raw)), 0)
+			if !s.expiredAt(seen) {
+				return nil
+			}
+		}
+		seen = time.Unix(s.now().Unix(), 0)
+		var raw [8]byte
+		binary.BigEndian.PutUint64(raw[:], uint64(seen.Unix()))
+		return bucket.Put([]byte(taskID), raw[:])
+	})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to record task %s: %w", taskID, err)
+	}
+	return seen, nil
+}
+
+// Prune implements Store
+func (s *BoltStore) Prune() (int, error) {
+	if s.retention == 0 {
+		return 0, nil
+	}
+
+	removed := 0
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		tasks := tx.Bucket(tasksBucket)
+		sources := tx.Bucket(sourcesBucket)
+
+		var expired []*Record
+		err := tasks.ForEach(func(k, v []byte) error {
+			var record Record
+			if err := json.Unmarshal(v, &record); err != nil {
+				return fmt.Errorf("corrupt record %s: %w", string(k), err)
+			}
+			if s.expired(&record) {
+				expired = append(expired, &record)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		var unseen [][]byte
+		err = tx.Bucket(seenBucket).ForEach(func(k, v []byte) error {
+			if len(v) != 8 || s.expiredAt(time.Unix(int64(binary.BigEndian.Uint64(v)), 0)) {
+				unseen = append(unseen, k)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range unseen {
+			if err := tx.Bucket(seenBucket).Delete(k); err != nil {
+				return err
+			}
+		}
+
+		for _, record := range expired {
+			if err := tasks.Delete([]byte(record.TaskID)); err != nil {
+				return err
+			}
+			if record.SourceKey != "" {
+				// Only drop the source index if it still points at this task
+				if owner := sources.Get([]byte(record.SourceKey)); string(owner) == record.TaskID {
+					if err := sources.Delete([]byte(record.SourceKey)); err != nil {
+						return err
+					}
+				}
+			}
+			removed++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune idempotency store: %w", err)
+	}
+	return removed, nil
+}
+
+// Close implements Store
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *BoltStore) expired(record *Record) bool {
+	return s.expiredAt(record.StoredAt)
+}
+
+func (s *BoltStore) expiredAt(t time.Time) bool {
+	return s.retention > 0 && s.now().Sub(t) > s.retention
+}
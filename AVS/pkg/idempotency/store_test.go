@@ -0,0 +1,161 @@
+package idempotency
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openTestStore(t *testing.T, path string, retention time.Duration) *BoltStore {
+	t.Helper()
+	store, err := Open(path, retention)
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	return store
+}
+
+func TestBoltStore_LookupByTaskIDAndSource(t *testing.T) {
+	store := openTestStore(t, filepath.Join(t.TempDir(), "idempotency.db"), time.Hour)
+	defer store.Close()
+
+	source := SourceKey(1, "0xABCD", "0xUser", "liquidity")
+	if err := store.Put(&Record{TaskID: "task-1", SourceKey: source, Result: []byte("result-1")}); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		taskID    string
+		sourceKey string
+		found     bool
+	}{
+		{name: "same task ID", taskID: "task-1", found: true},
+		{name: "same source under new task ID", taskID: "task-2", sourceKey: SourceKey(1, "0xabcd", "0xuser", "LIQUIDITY"), found: true},
+		{name: "different reward type", taskID: "task-2", sourceKey: SourceKey(1, "0xabcd", "0xuser", "swap")},
+		{name: "different chain", taskID: "task-2", sourceKey: SourceKey(10, "0xabcd", "0xuser", "liquidity")},
+		{name: "unknown task without source", taskID: "task-3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record, ok, err := store.Lookup(tt.taskID, tt.sourceKey)
+			if err != nil {
+				t.Fatalf("Lookup failed: %v", err)
+			}
+			if ok != tt.found {
+				t.Fatalf("Expected found=%v, got %v", tt.found, ok)
+			}
+			if ok && (record.TaskID != "task-1" || !bytes.Equal(record.Result, []byte("result-1"))) {
+				t.Errorf("Unexpected record: %+v", record)
+			}
+		})
+	}
+
+	if err := store.Put(&Record{}); err != ErrInvalidRecord {
+		t.Errorf("Expected ErrInvalidRecord, got %v", err)
+	}
+	if SourceKey(1, "", "0xuser", "liquidity") != "" {
+		t.Errorf("Expected empty source key without a transaction hash")
+	}
+}
+
+func TestBoltStore_SurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "idempotency.db")
+
+	store := openTestStore(t, path, time.Hour)
+	if err := store.Put(&Record{TaskID: "task-1", SourceKey: "1:0xabcd:0xuser:liquidity", Result: []byte("result-1"), Retry: []byte("retry-1")}); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	reopened := openTestStore(t, path, time.Hour)
+	defer reopened.Close()
+
+	record, ok, err := reopened.Lookup("task-9", "1:0xabcd:0xuser:liquidity")
+	if err != nil || !ok {
+		t.Fatalf("Expected record after reopen, got ok=%v err=%v", ok, err)
+	}
+	if !bytes.Equal(record.Result, []byte("result-1")) || !bytes.Equal(record.Retry, []byte("retry-1")) {
+		t.Errorf("Expected stored result and retry, got %q and %q", record.Result, record.Retry)
+	}
+}
+
+func TestBoltStore_Prune(t *testing.T) {
+	store := openTestStore(t, filepath.Join(t.TempDir(), "idempotency.db"), time.Hour)
+	defer store.Close()
+
+	now := time.Unix(1700000000, 0)
+	store.now = func() time.Time { return now }
+
+	records := []*Record{
+		{TaskID: "old", SourceKey: "source-old", Result: []byte("old"), StoredAt: now.Add(-2 * time.Hour)},
+		{TaskID: "fresh", SourceKey: "source-fresh", Result: []byte("fresh"), StoredAt: now.Add(-30 * time.Minute)},
+	}
+	for _, record := range records {
+		if err := store.Put(record); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+	}
+
+	// Expired records are hidden even before they are pruned
+	if _, ok, _ := store.Lookup("old", ""); ok {
+		t.Errorf("Expected expired record to be ignored")
+	}
+
+	removed, err := store.Prune()
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("Expected 1 record removed, got %d", removed)
+	}
+	if _, ok, _ := store.Lookup("other", "source-fresh"); !ok {
+		t.Errorf("Expected fresh record to survive pruning")
+	}
+
+	forever := openTestStore(t, filepath.Join(t.TempDir(), "forever.db"), 0)
+	defer forever.Close()
+	if removed, err := forever.Prune(); err != nil || removed != 0 {
+		t.Errorf("Expected no pruning with zero retention, got %d (%v)", removed, err)
+	}
+}
+
+func TestBoltStore_FirstSeen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "idempotency.db")
+	store := openTestStore(t, path, time.Hour)
+
+	now := time.Unix(1700000000, 0)
+	store.now = func() time.Time { return now }
+	first, err := store.FirstSeen("task-1")
+	if err != nil || !first.Equal(now) {
+		t.Fatalf("Expected task-1 first seen at %s, got %s (%v)", now, first, err)
+	}
+
+	// Later deliveries keep the first time, across restarts
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	store = openTestStore(t, path, time.Hour)
+	defer store.Close()
+	store.now = func() time.Time { return now.Add(30 * time.Minute) }
+	if seen, err := store.FirstSeen("task-1"); err != nil || !seen.Equal(now) {
+		t.Errorf("Expected task-1 first seen at %s, got %s (%v)", now, seen, err)
+	}
+
+	// Entries older than the retention window are pruned and start over
+	later := now.Add(2 * time.Hour)
+	store.now = func() time.Time { return later }
+	if _, err := store.Prune(); err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if seen, err := store.FirstSeen("task-1"); err != nil || !seen.Equal(later) {
+		t.Errorf("Expected task-1 first seen at %s after pruning, got %s (%v)", later, seen, err)
+	}
+	if _, err := store.FirstSeen(""); err != ErrInvalidRecord {
+		t.Errorf("Expected ErrInvalidRecord for an empty task ID, got %v", err)
+	}
+}
@@ -0,0 +1,246 @@
+// Package metrics exports RewardFlow performer metrics in the Prometheus format.
+//
+// Metric names and labels are part of the operator interface: dashboards and
+// alerts depend on them, so they must not be renamed once released.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	// DefaultPort is the port the metrics listener binds to when none is configured
+	DefaultPort = 9090
+	// Path is the HTTP path metrics are served on
+	Path = "/metrics"
+
+	namespace = "rewardflow"
+)
+
+// Task outcome label values
+const (
+	StatusSuccess  = "success"
+	StatusFailure  = "failure"
+	StatusDeferred = "deferred"
+)
+
+// Shadow comparison outcome label values
+const (
+	ShadowMatch       = "match"
+	ShadowMismatch    = "mismatch"
+	ShadowUnavailable = "unavailable"
+)
+
+// Metrics holds the performer's Prometheus collectors. A nil *Metrics is valid
+// and records nothing, so instrumentation can be disabled without nil checks.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	tasks              *prometheus.CounterVec
+	taskDuration       *prometheus.HistogramVec
+	validationFailures *prometheus.CounterVec
+	duplicateTasks     prometheus.Counter
+	distributed        *prometheus.CounterVec
+	fees               *prometheus.CounterVec
+	mevCaptured        prometheus.Counter
+	executionMode      *prometheus.GaugeVec
+	shadowComparisons  *prometheus.CounterVec
+}
+
+// New creates the performer metrics on a dedicated registry
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		tasks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tasks_total",
+			Help:      "Tasks processed, by reward type and outcome.",
+		}, []string{"reward_type", "status"}),
+		taskDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "task_duration_seconds",
+			Help:      "Time taken to process a task, by reward type.",
+			Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+		}, []string{"reward_type"}),
+		validationFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "validation_failures_total",
+			Help:      "Tasks rejected during validation, by reason.",
+		}, []string{"reason"}),
+		duplicateTasks: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "duplicate_tasks_total",
+			Help:      "Tasks answered from the idempotency store instead of being processed again.",
+		}),
+		distributed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "distributed_wei_total",
+			Help:      "Reward amount distributed to recipients in wei, by target chain.",
+		}, []string{"target_chain"}),
+		fees: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "fees_wei_total",
+			Help:      "Fees charged on distributions in wei, by target chain.",
+		}, []string{"target_chain"}),
+		mevCaptured: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "mev_captured_wei_total",
+			Help:      "MEV captured and redistributed in wei.",
+		}),
+		executionMode: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "execution_mode",
+			Help:      "Set to 1 for the mode distributions run in: live, dry-run or shadow.",
+		}, []string{"mode"}),
+		shadowComparisons: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "shadow_comparisons_total",
+			Help:      "Shadow results compared with those of the live operator, by outcome.",
+		}, []string{"outcome"}),
+	}
+
+	m.registry.MustRegister(
+		m.tasks,
+		m.taskDuration,
+		m.validationFailures,
+		m.duplicateTasks,
+		m.distributed,
+		m.fees,
+		m.mevCaptured,
+		m.executionMode,
+		m.shadowComparisons,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// TaskObservation is the outcome of a processed task
+type TaskObservation struct {
+	RewardType  string
+	TargetChain uint64
+	Success     bool
+	// Deferred tasks are counted apart from failures
+	Deferred    bool
+	Duration    time.Duration
+	Distributed *big.Int
+	Fee         *big.Int
+	MEVCaptured *big.Int
+}
+
+// ObserveTask records a processed task
+func (m *Metrics) ObserveTask(o TaskObservation) {
+	if m == nil {
+		return
+	}
+
+	status := StatusSuccess
+	if o.Deferred {
+		status = StatusDeferred
+	} else if !o.Success {
+		status = StatusFailure
+	}
+	m.tasks.WithLabelValues(o.RewardType, status).Inc()
+	m.taskDuration.WithLabelValues(o.RewardType).Observe(o.Duration.Seconds())
+
+	chain := strconv.FormatUint(o.TargetChain, 10)
+	addWei(m.distributed.WithLabelValues(chain), o.Distributed)
+	addWei(m.fees.WithLabelValues(chain), o.Fee)
+	addWei(m.mevCaptured, o.MEVCaptured)
+}
+
+// ValidationFailed records a task rejected for the given reason
+func (m *Metrics) ValidationFailed(reason string) {
+	if m == nil {
+		return
+	}
+	m.validationFailures.WithLabelValues(reason).Inc()
+}
+
+// DuplicateTask records a task answered from the idempotency store
+func (m *Metrics) DuplicateTask() {
+	if m == nil {
+		return
+	}
+	m.duplicateTasks.Inc()
+}
+
+// SetExecutionMode records the mode distributions run in
+func (m *Metrics) SetExecutionMode(mode string) {
+	if m == nil {
+		return
+	}
+	m.executionMode.Reset()
+	m.executionMode.WithLabelValues(mode).Set(1)
+}
+
+// ShadowCompared records the outcome of comparing a shadow result with the live one
+func (m *Metrics) ShadowCompared(outcome string) {
+	if m == nil {
+		return
+	}
+	m.shadowComparisons.WithLabelValues(outcome).Inc()
+}
+
+// Handler returns the HTTP handler serving the metrics
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Route is an additional handler served alongside the metrics
+type Route struct {
+	Path    string
+	Handler http.Handler
+}
+
+// Serve exposes the metrics, and any extra routes, on port until ctx is cancelled
+func (m *Metrics) Serve(ctx context.Context, port int, routes ...Route) error {
+	mux := http.NewServeMux()
+	mux.Handle(Path, m.Handler())
+	for _, route := range routes {
+		mux.Handle(route.Path, route.Handler)
+	}
+
+	srv := &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("metrics server failed: %w", err)
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("failed to stop metrics server: %w", err)
+		}
+		return nil
+	}
+}
+
+// addWei adds a wei amount to a counter. Counters are float64, so very large
+// totals lose precision in the low digits; the exact totals are in GetStats.
+func addWei(c prometheus.Counter, amount *big.Int) {
+	if amount == nil || amount.Sign() <= 0 {
+		return
+	}
+	f, _ := new(big.Float).SetInt(amount).Float64()
+	c.Add(f)
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"io"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", Path, nil))
+	body, err := io.ReadAll(rec.Body)
+	if err != nil {
+		t.Fatalf("Failed to read metrics: %v", err)
+	}
+	return string(body)
+}
+
+func TestMetrics_StableNames(t *testing.T) {
+	m := New()
+	m.ObserveTask(TaskObservation{
+		RewardType:  "mev",
+		TargetChain: 42161,
+		Success:     true,
+		Duration:    150 * time.Millisecond,
+		Distributed: big.NewInt(999000),
+		Fee:         big.NewInt(1000),
+		MEVCaptured: big.NewInt(1000000),
+	})
+	m.ObserveTask(TaskObservation{RewardType: "liquidity", TargetChain: 10, Duration: time.Second})
+	m.ObserveTask(TaskObservation{RewardType: "swap", TargetChain: 1, Deferred: true})
+	m.ValidationFailed("stale_timestamp")
+	m.DuplicateTask()
+	m.SetExecutionMode("live")
+	m.SetExecutionMode("shadow")
+	m.ShadowCompared(ShadowMismatch)
+
+	body := scrape(t, m)
+
+	// Dashboards and alerts depend on these exact series
+	expected := []string{
+		`rewardflow_tasks_total{reward_type="mev",status="success"} 1`,
+		`rewardflow_tasks_total{reward_type="liquidity",status="failure"} 1`,
+		`rewardflow_tasks_total{reward_type="swap",status="deferred"} 1`,
+		`rewardflow_task_duration_seconds_bucket{reward_type="mev",le="0.25"} 1`,
+		`rewardflow_task_duration_seconds_count{reward_type="liquidity"} 1`,
+		`rewardflow_validation_failures_total{reason="stale_timestamp"} 1`,
+		`rewardflow_duplicate_tasks_total 1`,
+		`rewardflow_distributed_wei_total{target_chain="42161"} 999000`,
+		`rewardflow_fees_wei_total{target_chain="42161"} 1000`,
+		`rewardflow_mev_captured_wei_total 1e+06`,
+		`rewardflow_execution_mode{mode="shadow"} 1`,
+		`rewardflow_shadow_comparisons_total{outcome="mismatch"} 1`,
+		`go_goroutines`,
+	}
+	for _, series := range expected {
+		if !strings.Contains(body, series) {
+			t.Errorf("Expected metrics to contain %q", series)
+		}
+	}
+	if strings.Contains(body, `rewardflow_execution_mode{mode="live"}`) {
+		t.Errorf("Expected only the current execution mode to be exported")
+	}
+}
+
+func TestMetrics_NilIsNoop(t *testing.T) {
+	var m *Metrics
+	m.ObserveTask(TaskObservation{RewardType: "swap", Distributed: big.NewInt(1)})
+	m.ValidationFailed("other")
+	m.DuplicateTask()
+	m.SetExecutionMode("live")
+	m.ShadowCompared(ShadowMatch)
+}
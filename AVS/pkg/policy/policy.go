@@ -0,0 +1,149 @@
+// Package policy provides the reward limits tasks are validated against, either
+// from static configuration or from RewardFlowAVSRegistrar.
+package policy
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Limits are the reward parameters stored in RewardFlowAVSRegistrar.rewardFlowConfig
+type Limits struct {
+	MinRewardAmount *big.Int
+	MaxRewardAmount *big.Int
+	TaskFee         *big.Int
+}
+
+// Validate checks that the limits are usable for task validation
+func (l Limits) Validate() error {
+	if l.MinRewardAmount == nil || l.MinRewardAmount.Sign() <= 0 {
+		return fmt.Errorf("min_reward_amount must be positive")
+	}
+	if l.MaxRewardAmount == nil || l.MaxRewardAmount.Cmp(l.MinRewardAmount) < 0 {
+		return fmt.Errorf("max_reward_amount must not be below min_reward_amount")
+	}
+	if l.TaskFee == nil || l.TaskFee.Sign() < 0 {
+		return fmt.Errorf("task_fee must not be negative")
+	}
+	return nil
+}
+
+// Change is a single field that differs between two Limits
+type Change struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// Diff lists the fields that differ from next, in a fixed order
+func (l Limits) Diff(next Limits) []Change {
+	fields := []struct {
+		name     string
+		old, new *big.Int
+	}{
+		{"min_reward_amount", l.MinRewardAmount, next.MinRewardAmount},
+		{"max_reward_amount", l.MaxRewardAmount, next.MaxRewardAmount},
+		{"task_fee", l.TaskFee, next.TaskFee},
+	}
+
+	var changes []Change
+	for _, f := range fields {
+		if f.old == nil && f.new == nil || f.old != nil && f.new != nil && f.old.Cmp(f.new) == 0 {
+			continue
+		}
+		changes = append(changes, Change{Field: f.name, Old: amountString(f.old), New: amountString(f.new)})
+	}
+	return changes
+}
+
+func amountString(v *big.Int) string {
+	if v == nil {
+		return "unset"
+	}
+	return v.String()
+}
+
+// ValidationPolicy supplies the current reward limits. Limits must be cheap
+// enough to call for every task; Refresh reloads them from the source.
+type ValidationPolicy interface {
+	// Name identifies the policy source in logs
+	Name() string
+	// Limits returns the most recently loaded limits
+	Limits() Limits
+	// Refresh reloads the limits, keeping the previous ones on error
+	Refresh(ctx context.Context) error
+}
+
+// Static is a ValidationPolicy with fixed limits, typically from the operator configuration
+type Static struct {
+	limits Limits
+}
+
+// NewStatic creates a static policy after validating its limits
+func NewStatic(limits Limits) (*Static, error) {
+	if err := limits.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid static policy: %w", err)
+	}
+	return &Static{limits: limits}, nil
+}
+
+// Name returns "static"
+func (s *Static) Name() string {
+	return "static"
+}
+
+// Limits returns the configured limits
+func (s *Static) Limits() Limits {
+	return s.limits
+}
+
+// Refresh is a no-op, static limits never change
+func (s *Static) Refresh(ctx context.Context) error {
+	return nil
+}
+
+// Watch refreshes the policy every interval until ctx is done, logging any
+// change in the limits
+func Watch(ctx context.Context, p ValidationPolicy, interval time.Duration, logger *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			RefreshAndLog(ctx, p, logger)
+		}
+	}
+}
+
+// RefreshAndLog refreshes the policy once and logs the difference. It returns
+// the changes that were applied.
+func RefreshAndLog(ctx context.Context, p ValidationPolicy, logger *zap.Logger) []Change {
+	before := p.Limits()
+	if err := p.Refresh(ctx); err != nil {
+		logger.Warn("Failed to refresh validation policy, keeping previous limits",
+			zap.String("policy", p.Name()),
+			zap.Error(err),
+		)
+		return nil
+	}
+
+	changes := before.Diff(p.Limits())
+	if len(changes) == 0 {
+		logger.Debug("Validation policy unchanged", zap.String("policy", p.Name()))
+		return nil
+	}
+
+	fields := []zap.Field{zap.String("policy", p.Name())}
+	for _, c := range changes {
+		fields = append(fields, zap.String(c.Field, c.Old+" -> "+c.New))
+	}
+	logger.Info("Validation policy updated", fields...)
+	return changes
+}
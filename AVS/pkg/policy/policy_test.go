@@ -0,0 +1,133 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func testLimits(min, max, fee int64) Limits {
+	return Limits{
+		MinRewardAmount: big.NewInt(min),
+		MaxRewardAmount: big.NewInt(max),
+		TaskFee:         big.NewInt(fee),
+	}
+}
+
+func TestNewStatic(t *testing.T) {
+	tests := []struct {
+		name        string
+		limits      Limits
+		expectError bool
+	}{
+		{name: "valid", limits: testLimits(1, 10, 0)},
+		{name: "equal min and max", limits: testLimits(5, 5, 1)},
+		{name: "zero min", limits: testLimits(0, 10, 1), expectError: true},
+		{name: "max below min", limits: testLimits(10, 1, 1), expectError: true},
+		{name: "negative fee", limits: testLimits(1, 10, -1), expectError: true},
+		{name: "missing fields", limits: Limits{}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			static, err := NewStatic(tt.limits)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if err := static.Refresh(context.Background()); err != nil {
+				t.Errorf("Unexpected refresh error: %v", err)
+			}
+			if static.Limits().MaxRewardAmount.Cmp(tt.limits.MaxRewardAmount) != 0 {
+				t.Errorf("Expected configured limits, got %+v", static.Limits())
+			}
+		})
+	}
+}
+
+func TestLimits_Diff(t *testing.T) {
+	old := testLimits(1, 10, 2)
+
+	if changes := old.Diff(testLimits(1, 10, 2)); len(changes) != 0 {
+		t.Errorf("Expected no changes, got %+v", changes)
+	}
+
+	changes := old.Diff(Limits{MinRewardAmount: big.NewInt(3), MaxRewardAmount: big.NewInt(10)})
+	expected := []Change{
+		{Field: "min_reward_amount", Old: "1", New: "3"},
+		{Field: "task_fee", Old: "2", New: "unset"},
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %+v", len(expected), changes)
+	}
+	for i := range expected {
+		if changes[i] != expected[i] {
+			t.Errorf("Expected change %+v, got %+v", expected[i], changes[i])
+		}
+	}
+}
+
+// stubPolicy returns queued limits or errors on each refresh
+type stubPolicy struct {
+	limits  Limits
+	results []interface{}
+}
+
+func (s *stubPolicy) Name() string   { return "stub" }
+func (s *stubPolicy) Limits() Limits { return s.limits }
+func (s *stubPolicy) Refresh(ctx context.Context) error {
+	next := s.results[0]
+	s.results = s.results[1:]
+	if err, ok := next.(error); ok {
+		return err
+	}
+	s.limits = next.(Limits)
+	return nil
+}
+
+func TestRefreshAndLog(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(core)
+
+	stub := &stubPolicy{
+		limits: testLimits(1, 10, 2),
+		results: []interface{}{
+			testLimits(1, 10, 2),
+			errors.New("rpc unavailable"),
+			testLimits(1, 20, 2),
+		},
+	}
+
+	if changes := RefreshAndLog(context.Background(), stub, logger); changes != nil {
+		t.Errorf("Expected no changes, got %+v", changes)
+	}
+	if changes := RefreshAndLog(context.Background(), stub, logger); changes != nil {
+		t.Errorf("Expected no changes on error, got %+v", changes)
+	}
+	changes := RefreshAndLog(context.Background(), stub, logger)
+	if len(changes) != 1 || changes[0].Field != "max_reward_amount" {
+		t.Errorf("Unexpected changes: %+v", changes)
+	}
+
+	entries := logs.All()
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 log entries, got %d", len(entries))
+	}
+	if entries[1].Level != zapcore.WarnLevel {
+		t.Errorf("Expected a warning for the failed refresh, got %s", entries[1].Level)
+	}
+	updated := entries[2]
+	if updated.Message != "Validation policy updated" || updated.ContextMap()["max_reward_amount"] != "10 -> 20" {
+		t.Errorf("Unexpected update log: %s %v", updated.Message, updated.ContextMap())
+	}
+}
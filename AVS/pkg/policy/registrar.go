@@ -0,0 +1,131 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Registrar configuration keys, as written by RewardFlowAVSRegistrar.initialize
+const (
+	KeyMinRewardAmount = "min_reward_amount"
+	KeyMaxRewardAmount = "max_reward_amount"
+	KeyTaskFee         = "task_fee"
+)
+
+// registrarABI is the subset of RewardFlowAVSRegistrar used by the policy
+const registrarABI = `[{
+	"type": "function",
+	"name": "rewardFlowConfig",
+	"stateMutability": "view",
+	"inputs": [{"name": "", "type": "bytes32"}],
+	"outputs": [{"name": "", "type": "bytes"}]
+}]`
+
+var parsedRegistrarABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(registrarABI))
+	if err != nil {
+		panic(fmt.Sprintf("invalid registrar ABI: %v", err))
+	}
+	return parsed
+}()
+
+// Registrar is a ValidationPolicy read from RewardFlowAVSRegistrar.rewardFlowConfig
+type Registrar struct {
+	caller  ethereum.ContractCaller
+	address common.Address
+
+	mu     sync.RWMutex
+	limits Limits
+}
+
+// NewRegistrar creates a registrar policy and loads its limits, failing if the
+// registrar cannot be read
+func NewRegistrar(ctx context.Context, caller ethereum.ContractCaller, address common.Address) (*Registrar, error) {
+	r := &Registrar{caller: caller, address: address}
+	if err := r.Refresh(ctx); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Name returns "registrar"
+func (r *Registrar) Name() string {
+	return "registrar"
+}
+
+// Limits returns the limits read by the last successful refresh
+func (r *Registrar) Limits() Limits {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.limits
+}
+
+// Refresh reads every configuration key from the registrar
+func (r *Registrar) Refresh(ctx context.Context) error {
+	var limits Limits
+	targets := []struct {
+		key    string
+		target **big.Int
+	}{
+		{KeyMinRewardAmount, &limits.MinRewardAmount},
+		{KeyMaxRewardAmount, &limits.MaxRewardAmount},
+		{KeyTaskFee, &limits.TaskFee},
+	}
+	for _, t := range targets {
+		value, err := r.readUint(ctx, t.key)
+		if err != nil {
+			return err
+		}
+		*t.target = value
+	}
+
+	if err := limits.Validate(); err != nil {
+		return fmt.Errorf("registrar %s returned invalid limits: %w", r.address.Hex(), err)
+	}
+
+	r.mu.Lock()
+	r.limits = limits
+	r.mu.Unlock()
+	return nil
+}
+
+// readUint reads a key stored as abi.encode(uint256)
+func (r *Registrar) readUint(ctx context.Context, key string) (*big.Int, error) {
+	data, err := parsedRegistrarABI.Pack("rewardFlowConfig", ConfigKey(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode rewardFlowConfig(%s): %w", key, err)
+	}
+
+	output, err := r.caller.CallContract(ctx, ethereum.CallMsg{To: &r.address, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call rewardFlowConfig(%s) on %s: %w", key, r.address.Hex(), err)
+	}
+
+	values, err := parsedRegistrarABI.Unpack("rewardFlowConfig", output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode rewardFlowConfig(%s): %w", key, err)
+	}
+	value := values[0].([]byte)
+	if len(value) == 0 {
+		return nil, fmt.Errorf("registrar config %s is not set", key)
+	}
+	if len(value) != 32 {
+		return nil, fmt.Errorf("registrar config %s: expected a 32-byte uint256, got %d bytes", key, len(value))
+	}
+	return new(big.Int).SetBytes(value), nil
+}
+
+// ConfigKey converts a configuration name into the bytes32 key Solidity uses
+// for a string literal: the name left-aligned and zero-padded
+func ConfigKey(name string) [32]byte {
+	var key [32]byte
+	copy(key[:], name)
+	return key
+}
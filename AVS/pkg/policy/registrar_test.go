@@ -0,0 +1,200 @@
+package policy
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/params"
+	"go.uber.org/zap"
+)
+
+// mockRegistrarCode stands in for RewardFlowAVSRegistrar. A 36-byte call
+// (selector + bytes32 key) returns abi.encode(bytes(abi.encode(sload(key)))),
+// or empty bytes when the slot is zero, like the rewardFlowConfig getter. A
+// 64-byte call stores (key, value) so tests can update the configuration.
+//
+//	CALLDATASIZE PUSH1 0x40 EQ PUSH1 set JUMPI
+//	PUSH1 0x04 CALLDATALOAD SLOAD DUP1 ISZERO PUSH1 empty JUMPI
+//	PUSH1 0x20 PUSH1 0x00 MSTORE PUSH1 0x20 PUSH1 0x20 MSTORE PUSH1 0x40 MSTORE
+//	PUSH1 0x60 PUSH1 0x00 RETURN
+//	empty: JUMPDEST PUSH1 0x20 PUSH1 0x00 MSTORE PUSH1 0x40 PUSH1 0x00 RETURN
+//	set:   JUMPDEST PUSH1 0x20 CALLDATALOAD PUSH1 0x00 CALLDATALOAD SSTORE STOP
+const mockRegistrarCode = "36604014602d57" +
+	"6004355480156022576020600052602060205260405260606000f3" +
+	"5b602060005260406000f3" +
+	"5b6020356000355500"
+
+var registrarAddress = common.HexToAddress("0x0000000000000000000000000000000000000a55")
+
+type simulatedRegistrar struct {
+	backend *simulated.Backend
+	key     *ecdsa.PrivateKey
+}
+
+func newSimulatedRegistrar(t *testing.T, config map[string]*big.Int) *simulatedRegistrar {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	storage := make(map[common.Hash]common.Hash)
+	for name, value := range config {
+		storage[common.Hash(ConfigKey(name))] = common.BigToHash(value)
+	}
+	backend := simulated.NewBackend(types.GenesisAlloc{
+		crypto.PubkeyToAddress(key.PublicKey): {Balance: big.NewInt(params.Ether)},
+		registrarAddress:                      {Code: common.FromHex(mockRegistrarCode), Storage: storage},
+	})
+	t.Cleanup(func() { backend.Close() })
+
+	return &simulatedRegistrar{backend: backend, key: key}
+}
+
+// set plays updateRewardFlowConfig(key, abi.encode(value)) and mines it
+func (s *simulatedRegistrar) set(t *testing.T, name string, value *big.Int) {
+	t.Helper()
+	ctx := context.Background()
+	client := s.backend.Client()
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get chain ID: %v", err)
+	}
+	nonce, err := client.PendingNonceAt(ctx, crypto.PubkeyToAddress(s.key.PublicKey))
+	if err != nil {
+		t.Fatalf("Failed to get nonce: %v", err)
+	}
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to get head: %v", err)
+	}
+
+	key := ConfigKey(name)
+	tip := big.NewInt(params.GWei)
+	tx, err := types.SignNewTx(s.key, types.LatestSignerForChainID(chainID), &types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasTipCap: tip,
+		GasFeeCap: new(big.Int).Add(tip, new(big.Int).Mul(head.BaseFee, big.NewInt(2))),
+		Gas:       100000,
+		To:        &registrarAddress,
+		Data:      append(key[:], common.BigToHash(value).Bytes()...),
+	})
+	if err != nil {
+		t.Fatalf("Failed to sign transaction: %v", err)
+	}
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		t.Fatalf("Failed to send transaction: %v", err)
+	}
+	s.backend.Commit()
+}
+
+// initialConfig matches RewardFlowAVSRegistrar.initialize
+func initialConfig() map[string]*big.Int {
+	return map[string]*big.Int{
+		KeyMinRewardAmount: big.NewInt(1e15),
+		KeyMaxRewardAmount: new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18)),
+		KeyTaskFee:         big.NewInt(1e14),
+	}
+}
+
+func TestRegistrar_ReadsConfig(t *testing.T) {
+	sim := newSimulatedRegistrar(t, initialConfig())
+
+	registrar, err := NewRegistrar(context.Background(), sim.backend.Client(), registrarAddress)
+	if err != nil {
+		t.Fatalf("NewRegistrar failed: %v", err)
+	}
+
+	limits := registrar.Limits()
+	want := initialConfig()
+	if limits.MinRewardAmount.Cmp(want[KeyMinRewardAmount]) != 0 ||
+		limits.MaxRewardAmount.Cmp(want[KeyMaxRewardAmount]) != 0 ||
+		limits.TaskFee.Cmp(want[KeyTaskFee]) != 0 {
+		t.Errorf("Unexpected limits: min=%s max=%s fee=%s", limits.MinRewardAmount, limits.MaxRewardAmount, limits.TaskFee)
+	}
+}
+
+func TestRegistrar_Refresh(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	sim := newSimulatedRegistrar(t, initialConfig())
+	registrar, err := NewRegistrar(context.Background(), sim.backend.Client(), registrarAddress)
+	if err != nil {
+		t.Fatalf("NewRegistrar failed: %v", err)
+	}
+
+	// Nothing changed on chain
+	if changes := RefreshAndLog(context.Background(), registrar, logger); len(changes) != 0 {
+		t.Errorf("Expected no changes, got %+v", changes)
+	}
+
+	sim.set(t, KeyMinRewardAmount, big.NewInt(5e15))
+	changes := RefreshAndLog(context.Background(), registrar, logger)
+	if len(changes) != 1 || changes[0] != (Change{Field: KeyMinRewardAmount, Old: "1000000000000000", New: "5000000000000000"}) {
+		t.Errorf("Unexpected changes: %+v", changes)
+	}
+	if registrar.Limits().MinRewardAmount.Cmp(big.NewInt(5e15)) != 0 {
+		t.Errorf("Expected refreshed min amount, got %s", registrar.Limits().MinRewardAmount)
+	}
+
+	// Limits that would reject every task are not applied
+	sim.set(t, KeyMaxRewardAmount, big.NewInt(1))
+	if err := registrar.Refresh(context.Background()); err == nil || !strings.Contains(err.Error(), "invalid limits") {
+		t.Errorf("Expected invalid limits error, got %v", err)
+	}
+	if registrar.Limits().MaxRewardAmount.Cmp(initialConfig()[KeyMaxRewardAmount]) != 0 {
+		t.Errorf("Expected previous max amount to be kept, got %s", registrar.Limits().MaxRewardAmount)
+	}
+}
+
+func TestRegistrar_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   map[string]*big.Int
+		address  common.Address
+		errorMsg string
+	}{
+		{
+			name:     "missing key",
+			config:   map[string]*big.Int{KeyMinRewardAmount: big.NewInt(1), KeyMaxRewardAmount: big.NewInt(2)},
+			address:  registrarAddress,
+			errorMsg: "registrar config task_fee is not set",
+		},
+		{
+			name:     "no contract",
+			config:   initialConfig(),
+			address:  common.HexToAddress("0x0000000000000000000000000000000000000b0b"),
+			errorMsg: "failed to decode rewardFlowConfig(min_reward_amount)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := newSimulatedRegistrar(t, tt.config)
+			_, err := NewRegistrar(context.Background(), sim.backend.Client(), tt.address)
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("Expected error containing '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestConfigKey(t *testing.T) {
+	key := ConfigKey(KeyTaskFee)
+	want := common.FromHex("0x7461736b5f666565000000000000000000000000000000000000000000000000")
+	if common.Bytes2Hex(key[:]) != common.Bytes2Hex(want) {
+		t.Errorf("Expected key %x, got %x", want, key)
+	}
+}
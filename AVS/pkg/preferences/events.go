@@ -0,0 +1,136 @@
+package preferences
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"go.uber.org/zap"
+)
+
+// PreferencesUpdatedTopic is the topic of
+// RewardDistributor.PreferencesUpdated(address indexed user, uint256 preferredChain, uint256 claimThreshold)
+var PreferencesUpdatedTopic = crypto.Keccak256Hash([]byte("PreferencesUpdated(address,uint256,uint256)"))
+
+// maxBlockRange bounds a single eth_getLogs request
+const maxBlockRange = 10000
+
+// LogReader is the subset of an Ethereum client used to follow events
+type LogReader interface {
+	ethereum.LogFilterer
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// EventStore is a PreferenceStore populated from the PreferencesUpdated events
+// of a RewardDistributor. The event carries the preferred chain and claim
+// threshold; the other fields keep their previous value or the
+// PreferenceManager default.
+type EventStore struct {
+	*MemoryStore
+	client      LogReader
+	distributor common.Address
+
+	syncMu sync.Mutex
+	next   uint64 // first block not yet applied
+}
+
+// NewEventStore creates a store following distributor from fromBlock on. Call
+// Sync to load the events.
+func NewEventStore(client LogReader, distributor common.Address, fromBlock uint64) *EventStore {
+	return &EventStore{
+		MemoryStore: NewMemoryStore(),
+		client:      client,
+		distributor: distributor,
+		next:        fromBlock,
+	}
+}
+
+// Sync applies every PreferencesUpdated event up to the current head and
+// returns how many were applied
+func (s *EventStore) Sync(ctx context.Context) (int, error) {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	head, err := s.client.BlockNumber(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get head block: %w", err)
+	}
+
+	applied := 0
+	for s.next <= head {
+		to := s.next + maxBlockRange - 1
+		if to > head {
+			to = head
+		}
+
+		logs, err := s.client.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(s.next),
+			ToBlock:   new(big.Int).SetUint64(to),
+			Addresses: []common.Address{s.distributor},
+			Topics:    [][]common.Hash{{PreferencesUpdatedTopic}},
+		})
+		if err != nil {
+			return applied, fmt.Errorf("failed to get PreferencesUpdated logs for blocks %d-%d: %w", s.next, to, err)
+		}
+		for _, log := range logs {
+			if err := s.apply(log); err != nil {
+				return applied, err
+			}
+			applied++
+		}
+		s.next = to + 1
+	}
+	return applied, nil
+}
+
+// Run syncs every interval until ctx is done
+func (s *EventStore) Run(ctx context.Context, interval time.Duration, logger *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			applied, err := s.Sync(ctx)
+			if err != nil {
+				logger.Warn("Failed to sync user preferences", zap.Error(err))
+			}
+			if applied > 0 {
+				logger.Info("User preferences updated", zap.Int("events", applied), zap.Int("users", s.Len()))
+			}
+		}
+	}
+}
+
+// apply decodes a PreferencesUpdated log into the store
+func (s *EventStore) apply(log types.Log) error {
+	if len(log.Topics) != 2 || log.Topics[0] != PreferencesUpdatedTopic {
+		return fmt.Errorf("log %s:%d is not a PreferencesUpdated event", log.TxHash.Hex(), log.Index)
+	}
+	if len(log.Data) != 64 {
+		return fmt.Errorf("PreferencesUpdated log %s:%d: expected 64 data bytes, got %d", log.TxHash.Hex(), log.Index, len(log.Data))
+	}
+
+	preferredChain := new(big.Int).SetBytes(log.Data[:32])
+	if !preferredChain.IsUint64() || preferredChain.Sign() == 0 {
+		return fmt.Errorf("PreferencesUpdated log %s:%d: invalid preferred chain %s", log.TxHash.Hex(), log.Index, preferredChain)
+	}
+
+	user := common.BytesToAddress(log.Topics[1].Bytes()).Hex()
+	p, ok := s.Get(user)
+	if !ok {
+		p = Default()
+	}
+	p.PreferredChain = preferredChain.Uint64()
+	p.ClaimThreshold = new(big.Int).SetBytes(log.Data[32:])
+	s.put(user, p)
+	return nil
+}
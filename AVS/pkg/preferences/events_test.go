@@ -0,0 +1,181 @@
+package preferences
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// mockDistributorCode stands in for RewardDistributor.setUserPreferences: a
+// call with abi.encode(preferredChain, claimThreshold) emits
+// PreferencesUpdated(msg.sender, preferredChain, claimThreshold).
+//
+//	PUSH1 0x40 PUSH1 0x00 PUSH1 0x00 CALLDATACOPY
+//	CALLER PUSH32 topic PUSH1 0x40 PUSH1 0x00 LOG2 STOP
+func mockDistributorCode() []byte {
+	code := common.FromHex("0x60406000600037337f")
+	code = append(code, PreferencesUpdatedTopic.Bytes()...)
+	return append(code, common.FromHex("0x60406000a200")...)
+}
+
+var distributorAddress = common.HexToAddress("0x00000000000000000000000000000000000d1570")
+
+type simulatedDistributor struct {
+	backend *simulated.Backend
+	users   []*ecdsa.PrivateKey
+}
+
+func newSimulatedDistributor(t *testing.T, users int) *simulatedDistributor {
+	t.Helper()
+	alloc := types.GenesisAlloc{distributorAddress: {Code: mockDistributorCode()}}
+	sim := &simulatedDistributor{}
+	for i := 0; i < users; i++ {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatalf("Failed to generate key: %v", err)
+		}
+		alloc[crypto.PubkeyToAddress(key.PublicKey)] = types.Account{Balance: big.NewInt(params.Ether)}
+		sim.users = append(sim.users, key)
+	}
+	sim.backend = simulated.NewBackend(alloc)
+	t.Cleanup(func() { sim.backend.Close() })
+	return sim
+}
+
+// setPreferences sends setUserPreferences from a user and mines it
+func (s *simulatedDistributor) setPreferences(t *testing.T, user int, chain uint64, threshold *big.Int) {
+	t.Helper()
+	ctx := context.Background()
+	client := s.backend.Client()
+	key := s.users[user]
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get chain ID: %v", err)
+	}
+	nonce, err := client.PendingNonceAt(ctx, crypto.PubkeyToAddress(key.PublicKey))
+	if err != nil {
+		t.Fatalf("Failed to get nonce: %v", err)
+	}
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to get head: %v", err)
+	}
+
+	tip := big.NewInt(params.GWei)
+	data := append(common.BigToHash(new(big.Int).SetUint64(chain)).Bytes(), common.BigToHash(threshold).Bytes()...)
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(chainID), &types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasTipCap: tip,
+		GasFeeCap: new(big.Int).Add(tip, new(big.Int).Mul(head.BaseFee, big.NewInt(2))),
+		Gas:       100000,
+		To:        &distributorAddress,
+		Data:      data,
+	})
+	if err != nil {
+		t.Fatalf("Failed to sign transaction: %v", err)
+	}
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		t.Fatalf("Failed to send transaction: %v", err)
+	}
+	s.backend.Commit()
+}
+
+func (s *simulatedDistributor) address(user int) string {
+	return crypto.PubkeyToAddress(s.users[user].PublicKey).Hex()
+}
+
+func TestEventStore_Sync(t *testing.T) {
+	sim := newSimulatedDistributor(t, 2)
+	sim.setPreferences(t, 0, 42161, big.NewInt(5e16))
+	sim.setPreferences(t, 1, 8453, big.NewInt(1e17))
+
+	store := NewEventStore(sim.backend.Client(), distributorAddress, 0)
+	applied, err := store.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if applied != 2 || store.Len() != 2 {
+		t.Fatalf("Expected 2 events for 2 users, got %d events for %d users", applied, store.Len())
+	}
+
+	first, ok := store.Get(sim.address(0))
+	if !ok || first.PreferredChain != 42161 || first.ClaimThreshold.Cmp(big.NewInt(5e16)) != 0 {
+		t.Errorf("Unexpected preferences for first user: %+v", first)
+	}
+	// Fields the event does not carry take the PreferenceManager defaults
+	if !first.AutoClaimEnabled || first.ClaimFrequency != Default().ClaimFrequency {
+		t.Errorf("Expected default claim settings, got %+v", first)
+	}
+
+	// Only new events are applied on the next sync, the latest one wins
+	sim.setPreferences(t, 0, 10, big.NewInt(5e16))
+	applied, err = store.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if applied != 1 {
+		t.Errorf("Expected 1 new event, got %d", applied)
+	}
+	if got, _ := store.Get(strings.ToLower(sim.address(0))); got.PreferredChain != 10 {
+		t.Errorf("Expected updated preferred chain 10, got %d", got.PreferredChain)
+	}
+	if got, _ := store.Get(sim.address(1)); got.PreferredChain != 8453 {
+		t.Errorf("Expected unchanged preferred chain 8453, got %d", got.PreferredChain)
+	}
+
+	// Events before the start block are ignored
+	late := NewEventStore(sim.backend.Client(), distributorAddress, 3)
+	if _, err := late.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if late.Len() != 1 {
+		t.Errorf("Expected 1 user from block 3 on, got %d", late.Len())
+	}
+}
+
+func TestEventStore_ApplyErrors(t *testing.T) {
+	user := common.BytesToHash(common.HexToAddress(testUser).Bytes())
+	word := func(v int64) []byte { return common.BigToHash(big.NewInt(v)).Bytes() }
+
+	tests := []struct {
+		name     string
+		log      types.Log
+		errorMsg string
+	}{
+		{
+			name:     "other event",
+			log:      types.Log{Topics: []common.Hash{crypto.Keccak256Hash([]byte("ChainSupportUpdated(uint256,bool)")), user}},
+			errorMsg: "is not a PreferencesUpdated event",
+		},
+		{
+			name:     "short data",
+			log:      types.Log{Topics: []common.Hash{PreferencesUpdatedTopic, user}, Data: word(1)},
+			errorMsg: "expected 64 data bytes, got 32",
+		},
+		{
+			name:     "zero chain",
+			log:      types.Log{Topics: []common.Hash{PreferencesUpdatedTopic, user}, Data: append(word(0), word(1)...)},
+			errorMsg: "invalid preferred chain 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewEventStore(nil, distributorAddress, 0)
+			err := store.apply(tt.log)
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("Expected error containing '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package preferences
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileStore is a PreferenceStore persisted as a JSON object keyed by user address
+type FileStore struct {
+	*MemoryStore
+	path string
+
+	saveMu sync.Mutex
+}
+
+// OpenFile loads the preferences stored at path. A missing file is an empty store.
+func OpenFile(path string) (*FileStore, error) {
+	s := &FileStore{MemoryStore: NewMemoryStore(), path: path}
+
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read preferences file: %w", err)
+	}
+
+	var users map[string]Preferences
+	if err := json.Unmarshal(raw, &users); err != nil {
+		return nil, fmt.Errorf("failed to parse preferences file %s: %w", path, err)
+	}
+	for user, p := range users {
+		if err := s.MemoryStore.Set(user, p); err != nil {
+			return nil, fmt.Errorf("preferences file %s: %w", path, err)
+		}
+	}
+	return s, nil
+}
+
+// Set stores the preferences of user and rewrites the file
+func (s *FileStore) Set(user string, p Preferences) error {
+	if err := s.MemoryStore.Set(user, p); err != nil {
+		return err
+	}
+	return s.save()
+}
+
+// save atomically replaces the file with the current preferences
+func (s *FileStore) save() error {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	raw, err := json.MarshalIndent(s.snapshot(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode preferences: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create preferences directory: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return fmt.Errorf("failed to write preferences file: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to replace preferences file: %w", err)
+	}
+	return nil
+}
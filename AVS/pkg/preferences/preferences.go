@@ -0,0 +1,106 @@
+// Package preferences holds per-user distribution preferences, mirroring
+// RewardDistributor.UserPreferences.
+package preferences
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+)
+
+// Preferences mirrors RewardDistributor.UserPreferences
+type Preferences struct {
+	PreferredChain   uint64   `json:"preferred_chain"`
+	ClaimThreshold   *big.Int `json:"claim_threshold"`
+	ClaimFrequency   uint64   `json:"claim_frequency"` // seconds
+	AutoClaimEnabled bool     `json:"auto_claim_enabled"`
+	LastUpdate       int64    `json:"last_update"` // unix seconds
+}
+
+// Default returns PreferenceManager.getDefaultPreferences
+func Default() Preferences {
+	return Preferences{
+		PreferredChain:   1,                // Ethereum
+		ClaimThreshold:   big.NewInt(1e16), // 0.01 ETH
+		ClaimFrequency:   24 * 60 * 60,     // 1 day
+		AutoClaimEnabled: true,
+	}
+}
+
+// Validate applies PreferenceManager.validatePreferences
+func (p Preferences) Validate() error {
+	if p.PreferredChain == 0 {
+		return fmt.Errorf("preferred chain is required")
+	}
+	if p.ClaimThreshold == nil || p.ClaimThreshold.Sign() <= 0 {
+		return fmt.Errorf("claim threshold must be positive")
+	}
+	if p.ClaimFrequency == 0 {
+		return fmt.Errorf("claim frequency must be positive")
+	}
+	return nil
+}
+
+// PreferenceStore looks up the preferences of a user
+type PreferenceStore interface {
+	// Get returns the preferences of user, and false if the user has none
+	Get(user string) (Preferences, bool)
+}
+
+// MemoryStore is an in-memory PreferenceStore, safe for concurrent use
+type MemoryStore struct {
+	mu    sync.RWMutex
+	users map[string]Preferences
+}
+
+// NewMemoryStore creates an empty store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{users: make(map[string]Preferences)}
+}
+
+// Get returns the preferences of user
+func (s *MemoryStore) Get(user string) (Preferences, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p, ok := s.users[normalizeUser(user)]
+	return p, ok
+}
+
+// Set replaces the preferences of user after validating them
+func (s *MemoryStore) Set(user string, p Preferences) error {
+	if err := p.Validate(); err != nil {
+		return fmt.Errorf("invalid preferences for %s: %w", user, err)
+	}
+	s.put(user, p)
+	return nil
+}
+
+func (s *MemoryStore) put(user string, p Preferences) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[normalizeUser(user)] = p
+}
+
+// Len returns the number of users with preferences
+func (s *MemoryStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.users)
+}
+
+// snapshot copies every stored preference
+func (s *MemoryStore) snapshot() map[string]Preferences {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	users := make(map[string]Preferences, len(s.users))
+	for user, p := range s.users {
+		users[user] = p
+	}
+	return users
+}
+
+// normalizeUser makes checksummed and lower-case addresses the same key
+func normalizeUser(user string) string {
+	return strings.ToLower(strings.TrimSpace(user))
+}
@@ -0,0 +1,106 @@
+package preferences
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testUser = "0x1234567890AbcdEF1234567890aBcdef12345678"
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	if _, ok := store.Get(testUser); ok {
+		t.Fatalf("Expected no preferences in an empty store")
+	}
+
+	prefs := Default()
+	prefs.PreferredChain = 42161
+	if err := store.Set(testUser, prefs); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	// Addresses match regardless of checksum casing
+	got, ok := store.Get(strings.ToLower(testUser))
+	if !ok || got.PreferredChain != 42161 {
+		t.Errorf("Expected preferred chain 42161, got %+v (found %v)", got, ok)
+	}
+
+	tests := []struct {
+		name     string
+		mutate   func(p *Preferences)
+		errorMsg string
+	}{
+		{name: "no chain", mutate: func(p *Preferences) { p.PreferredChain = 0 }, errorMsg: "preferred chain is required"},
+		{name: "no threshold", mutate: func(p *Preferences) { p.ClaimThreshold = big.NewInt(0) }, errorMsg: "claim threshold must be positive"},
+		{name: "no frequency", mutate: func(p *Preferences) { p.ClaimFrequency = 0 }, errorMsg: "claim frequency must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Default()
+			tt.mutate(&p)
+			err := store.Set(testUser, p)
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("Expected error containing '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestFileStore_Persists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prefs", "preferences.json")
+
+	store, err := OpenFile(path)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	if store.Len() != 0 {
+		t.Fatalf("Expected a missing file to be an empty store")
+	}
+
+	prefs := Default()
+	prefs.PreferredChain = 8453
+	prefs.AutoClaimEnabled = false
+	if err := store.Set(testUser, prefs); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	reopened, err := OpenFile(path)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	got, ok := reopened.Get(testUser)
+	if !ok || got.PreferredChain != 8453 || got.AutoClaimEnabled || got.ClaimThreshold.Cmp(big.NewInt(1e16)) != 0 {
+		t.Errorf("Unexpected preferences after reopen: %+v (found %v)", got, ok)
+	}
+}
+
+func TestFileStore_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		errorMsg string
+	}{
+		{name: "not JSON", contents: "preferred_chain: 1", errorMsg: "failed to parse preferences file"},
+		{
+			name:     "invalid entry",
+			contents: `{"0x1234567890123456789012345678901234567890": {"preferred_chain": 10}}`,
+			errorMsg: "invalid preferences for 0x1234567890123456789012345678901234567890: claim threshold must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "preferences.json")
+			if err := os.WriteFile(path, []byte(tt.contents), 0o600); err != nil {
+				t.Fatalf("Failed to write file: %v", err)
+			}
+			_, err := OpenFile(path)
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("Expected error containing '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,396 @@
+// Package rewardmath is a bit-exact port of the RewardMath library. Every
+// function works on uint256 values held in *big.Int, rounds as the EVM does
+// and fails wherever the library reverts, with the revert mapped onto one of
+// the errors below.
+package rewardmath
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Reverts of RewardMath
+var (
+	// ErrDivisionByZero is revert("Division by zero") of mulDiv
+	ErrDivisionByZero = errors.New("division by zero")
+	// ErrMultiplicationOverflow is require(z / x == y, "Multiplication overflow")
+	// of mulDiv. Checked arithmetic panics first, so the library never reaches it.
+	ErrMultiplicationOverflow = errors.New("multiplication overflow")
+	// ErrLengthMismatch is require(..., "Array length mismatch") of timeWeightedAverage
+	ErrLengthMismatch = errors.New("array length mismatch")
+	// ErrSlippageTooHigh is revert("Slippage too high") of calculateSlippageProtection
+	ErrSlippageTooHigh = errors.New("slippage too high")
+	// ErrArithmetic is Panic(0x11), a checked addition, subtraction or multiplication
+	// leaving the uint256 range
+	ErrArithmetic = errors.New("arithmetic overflow or underflow")
+	// ErrDivisionPanic is Panic(0x12), a division by zero outside of the
+	// denominator check. mulDiv panics so whenever x is zero.
+	ErrDivisionPanic = errors.New("division or modulo by zero")
+	// ErrOutOfRange reports an argument that is not a uint256
+	ErrOutOfRange = errors.New("value out of uint256 range")
+	// ErrTooManyIterations reports a loop the library would run out of gas on
+	ErrTooManyIterations = errors.New("too many iterations")
+)
+
+// MaxIterations bounds the time argument of Compound and ExponentialDecay. The
+// library loops once per period, so longer loops run out of gas on chain.
+const MaxIterations = 100000
+
+var (
+	// Precision is PRECISION, 1e18
+	Precision = big.NewInt(1e18)
+	// MaxMultiplier is MAX_MULTIPLIER, 10x
+	MaxMultiplier = new(big.Int).Mul(big.NewInt(10), Precision)
+	// MinMultiplier is MIN_MULTIPLIER, 0.1x
+	MinMultiplier = big.NewInt(1e17)
+
+	maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+)
+
+// checked returns v, or ErrArithmetic if it left the uint256 range
+func checked(v *big.Int) (*big.Int, error) {
+	if v.Sign() < 0 || v.Cmp(maxUint256) > 0 {
+		return nil, ErrArithmetic
+	}
+	return v, nil
+}
+
+func add(a, b *big.Int) (*big.Int, error) {
+	return checked(new(big.Int).Add(a, b))
+}
+
+func sub(a, b *big.Int) (*big.Int, error) {
+	return checked(new(big.Int).Sub(a, b))
+}
+
+func mul(a, b *big.Int) (*big.Int, error) {
+	return checked(new(big.Int).Mul(a, b))
+}
+
+func div(a, b *big.Int) (*big.Int, error) {
+	if b.Sign() == 0 {
+		return nil, ErrDivisionPanic
+	}
+	return new(big.Int).Quo(a, b), nil
+}
+
+// uint256 checks that every argument is a uint256
+func uint256(values ...*big.Int) error {
+	for _, v := range values {
+		if v == nil || v.Sign() < 0 || v.Cmp(maxUint256) > 0 {
+			return fmt.Errorf("%w: %v", ErrOutOfRange, v)
+		}
+	}
+	return nil
+}
+
+// iterations converts a loop count, failing beyond MaxIterations
+func iterations(time *big.Int) (int, error) {
+	if !time.IsInt64() || time.Int64() > MaxIterations {
+		return 0, fmt.Errorf("%w: %s periods", ErrTooManyIterations, time)
+	}
+	return int(time.Int64()), nil
+}
+
+// MulDiv returns mulDiv: x * y / denominator, rounded down. It reverts on a
+// zero denominator, overflows on x * y and, through its overflow check,
+// panics whenever x is zero.
+func MulDiv(x, y, denominator *big.Int) (*big.Int, error) {
+	if err := uint256(x, y, denominator); err != nil {
+		return nil, err
+	}
+	return mulDiv(x, y, denominator)
+}
+
+func mulDiv(x, y, denominator *big.Int) (*big.Int, error) {
+	if denominator.Sign() == 0 {
+		return nil, ErrDivisionByZero
+	}
+	z, err := mul(x, y)
+	if err != nil {
+		return nil, err
+	}
+	check, err := div(z, x)
+	if err != nil {
+		return nil, err
+	}
+	if check.Cmp(y) != 0 {
+		return nil, ErrMultiplicationOverflow
+	}
+	return z.Quo(z, denominator), nil
+}
+
+// Compound returns compound: principal * (1 + rate) once per period, rounding
+// down every period
+func Compound(principal, rate, time *big.Int) (*big.Int, error) {
+	if err := uint256(principal, rate, time); err != nil {
+		return nil, err
+	}
+	if time.Sign() == 0 {
+		return new(big.Int).Set(principal), nil
+	}
+	n, err := iterations(time)
+	if err != nil {
+		return nil, err
+	}
+
+	result := principal
+	for i := 0; i < n; i++ {
+		factor, err := add(Precision, rate)
+		if err != nil {
+			return nil, err
+		}
+		if result, err = mulDiv(result, factor, Precision); err != nil {
+			return nil, err
+		}
+	}
+	return new(big.Int).Set(result), nil
+}
+
+// ExponentialDecay returns exponentialDecay: value * (1 - decayRate) once per
+// period. A value that decays to zero before the last period panics.
+func ExponentialDecay(value, decayRate, time *big.Int) (*big.Int, error) {
+	if err := uint256(value, decayRate, time); err != nil {
+		return nil, err
+	}
+	if time.Sign() == 0 {
+		return new(big.Int).Set(value), nil
+	}
+	decayFactor, err := sub(Precision, decayRate)
+	if err != nil {
+		return nil, err
+	}
+	n, err := iterations(time)
+	if err != nil {
+		return nil, err
+	}
+
+	result := value
+	for i := 0; i < n; i++ {
+		if result, err = mulDiv(result, decayFactor, Precision); err != nil {
+			return nil, err
+		}
+	}
+	return new(big.Int).Set(result), nil
+}
+
+// CalculateTierMultiplier returns calculateTierMultiplier: the product of the
+// liquidity (up to 2x), loyalty (up to 1.5x) and time (up to 1.2x) bonuses,
+// bounded by MinMultiplier and MaxMultiplier
+func CalculateTierMultiplier(totalLiquidity, loyaltyScore, timeActive *big.Int) (*big.Int, error) {
+	if err := uint256(totalLiquidity, loyaltyScore, timeActive); err != nil {
+		return nil, err
+	}
+
+	bonus := func(value *big.Int, perUnit, unit, limit int64) (*big.Int, error) {
+		scaled, err := mul(value, big.NewInt(perUnit))
+		if err != nil {
+			return nil, err
+		}
+		scaled, err = div(scaled, big.NewInt(unit))
+		if err != nil {
+			return nil, err
+		}
+		b, err := add(Precision, scaled)
+		if err != nil {
+			return nil, err
+		}
+		if b.Cmp(big.NewInt(limit)) > 0 {
+			b.SetInt64(limit)
+		}
+		return b, nil
+	}
+	liquidityBonus, err := bonus(totalLiquidity, 1e15, 1e18, 2e18)
+	if err != nil {
+		return nil, err
+	}
+	loyaltyBonus, err := bonus(loyaltyScore, 5e15, 100, 15e17)
+	if err != nil {
+		return nil, err
+	}
+	timeBonus, err := bonus(timeActive, 1e15, 1e18, 12e17)
+	if err != nil {
+		return nil, err
+	}
+
+	liquidityPart, err := mulDiv(Precision, liquidityBonus, Precision)
+	if err != nil {
+		return nil, err
+	}
+	activityPart, err := mulDiv(loyaltyBonus, timeBonus, Precision)
+	if err != nil {
+		return nil, err
+	}
+	multiplier, err := mulDiv(liquidityPart, activityPart, Precision)
+	if err != nil {
+		return nil, err
+	}
+
+	if multiplier.Cmp(MaxMultiplier) > 0 {
+		multiplier.Set(MaxMultiplier)
+	}
+	if multiplier.Cmp(MinMultiplier) < 0 {
+		multiplier.Set(MinMultiplier)
+	}
+	return multiplier, nil
+}
+
+// TimeWeightedAverage returns timeWeightedAverage: the weighted mean of
+// values, rounded down, or zero when the weights add up to zero
+func TimeWeightedAverage(values, weights []*big.Int) (*big.Int, error) {
+	if err := uint256(values...); err != nil {
+		return nil, err
+	}
+	if err := uint256(weights...); err != nil {
+		return nil, err
+	}
+	if len(values) != len(weights) {
+		return nil, ErrLengthMismatch
+	}
+
+	totalWeight, weightedSum := new(big.Int), new(big.Int)
+	for i := range values {
+		var err error
+		if totalWeight, err = add(totalWeight, weights[i]); err != nil {
+			return nil, err
+		}
+		product, err := mul(values[i], weights[i])
+		if err != nil {
+			return nil, err
+		}
+		if weightedSum, err = add(weightedSum, product); err != nil {
+			return nil, err
+		}
+	}
+
+	if totalWeight.Sign() == 0 {
+		return new(big.Int), nil
+	}
+	return weightedSum.Quo(weightedSum, totalWeight), nil
+}
+
+// GeometricMean returns geometricMean, including the library's ten rounds of
+// its root approximation. Products that round down to zero panic.
+func GeometricMean(values []*big.Int) (*big.Int, error) {
+	if err := uint256(values...); err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return new(big.Int), nil
+	}
+
+	product := big.NewInt(1)
+	for _, v := range values {
+		var err error
+		if product, err = mulDiv(product, v, Precision); err != nil {
+			return nil, err
+		}
+	}
+
+	n := big.NewInt(int64(len(values)))
+	nMinusOne := new(big.Int).Sub(n, big.NewInt(1))
+	root := product
+	for i := 0; i < 10; i++ {
+		prev := root
+		rootFactor, err := mul(nMinusOne, Precision)
+		if err != nil {
+			return nil, err
+		}
+		denominator, err := mul(n, Precision)
+		if err != nil {
+			return nil, err
+		}
+		rootPart, err := mulDiv(root, rootFactor, denominator)
+		if err != nil {
+			return nil, err
+		}
+		productPart, err := mulDiv(product, Precision, denominator)
+		if err != nil {
+			return nil, err
+		}
+		sum, err := add(rootPart, productPart)
+		if err != nil {
+			return nil, err
+		}
+		if root, err = mulDiv(sum, Precision, prev); err != nil {
+			return nil, err
+		}
+	}
+	return new(big.Int).Set(root), nil
+}
+
+// CalculateRewardDistribution returns calculateRewardDistribution: totalReward
+// divided in proportion to shares, each part rounded down so dust is left
+// undistributed. All-zero shares get nothing.
+func CalculateRewardDistribution(totalReward *big.Int, shares []*big.Int) ([]*big.Int, error) {
+	if err := uint256(totalReward); err != nil {
+		return nil, err
+	}
+	if err := uint256(shares...); err != nil {
+		return nil, err
+	}
+
+	rewards := make([]*big.Int, len(shares))
+	totalShares := new(big.Int)
+	for _, share := range shares {
+		var err error
+		if totalShares, err = add(totalShares, share); err != nil {
+			return nil, err
+		}
+	}
+
+	if totalShares.Sign() == 0 {
+		for i := range rewards {
+			rewards[i] = new(big.Int)
+		}
+		return rewards, nil
+	}
+
+	for i, share := range shares {
+		var err error
+		if rewards[i], err = mulDiv(totalReward, share, totalShares); err != nil {
+			return nil, err
+		}
+	}
+	return rewards, nil
+}
+
+// CalculateSlippageProtection returns calculateSlippageProtection: the actual
+// amount, unless it falls short of the expected amount by more than
+// maxSlippage (1e18 is 100%)
+func CalculateSlippageProtection(expectedAmount, actualAmount, maxSlippage *big.Int) (*big.Int, error) {
+	if err := uint256(expectedAmount, actualAmount, maxSlippage); err != nil {
+		return nil, err
+	}
+	if actualAmount.Cmp(expectedAmount) >= 0 {
+		return new(big.Int).Set(actualAmount), nil
+	}
+
+	shortfall, err := sub(expectedAmount, actualAmount)
+	if err != nil {
+		return nil, err
+	}
+	slippage, err := mulDiv(shortfall, Precision, expectedAmount)
+	if err != nil {
+		return nil, err
+	}
+	if slippage.Cmp(maxSlippage) > 0 {
+		return nil, ErrSlippageTooHigh
+	}
+	return new(big.Int).Set(actualAmount), nil
+}
+
+// CalculateFee returns calculateFee: amount * feeRate / 1e18, rounded down
+func CalculateFee(amount, feeRate *big.Int) (*big.Int, error) {
+	return MulDiv(amount, feeRate, Precision)
+}
+
+// CalculateNetAmount returns calculateNetAmount: amount less CalculateFee
+func CalculateNetAmount(amount, feeRate *big.Int) (*big.Int, error) {
+	fee, err := CalculateFee(amount, feeRate)
+	if err != nil {
+		return nil, err
+	}
+	return sub(amount, fee)
+}
@@ -0,0 +1,194 @@
+package rewardmath
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// vectorsPath is shared with test/unit/RewardMathVectors.t.sol, which checks
+// the same vectors against RewardMath
+var vectorsPath = filepath.Join("..", "..", "..", "test", "vectors", "rewardmath.json")
+
+// reverts maps the revert names of the vectors onto errors
+var reverts = map[string]error{
+	"Division by zero":        ErrDivisionByZero,
+	"Multiplication overflow": ErrMultiplicationOverflow,
+	"Array length mismatch":   ErrLengthMismatch,
+	"Slippage too high":       ErrSlippageTooHigh,
+	"panic:arithmetic":        ErrArithmetic,
+	"panic:division":          ErrDivisionPanic,
+}
+
+// vector is one case of a section: named decimal string arguments plus a
+// result or a revert
+type vector map[string]json.RawMessage
+
+func (v vector) str(t *testing.T, key string) string {
+	t.Helper()
+	var s string
+	if err := json.Unmarshal(v[key], &s); err != nil {
+		t.Fatalf("Invalid %s: %v", key, err)
+	}
+	return s
+}
+
+func (v vector) uint(t *testing.T, key string) *big.Int {
+	t.Helper()
+	n, ok := new(big.Int).SetString(v.str(t, key), 10)
+	if !ok {
+		t.Fatalf("Invalid %s %s", key, v[key])
+	}
+	return n
+}
+
+func (v vector) uints(t *testing.T, key string) []*big.Int {
+	t.Helper()
+	var strs []string
+	if err := json.Unmarshal(v[key], &strs); err != nil {
+		t.Fatalf("Invalid %s: %v", key, err)
+	}
+	out := make([]*big.Int, len(strs))
+	for i, s := range strs {
+		n, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			t.Fatalf("Invalid %s[%d] %q", key, i, s)
+		}
+		out[i] = n
+	}
+	return out
+}
+
+// check compares a result with the vector's result or revert
+func (v vector) check(t *testing.T, got interface{}, err error) {
+	t.Helper()
+	if _, ok := v["revert"]; ok {
+		want := reverts[v.str(t, "revert")]
+		if want == nil {
+			t.Fatalf("Unknown revert %s", v["revert"])
+		}
+		if !errors.Is(err, want) {
+			t.Errorf("Expected %v, got %v (%v)", want, err, got)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("Expected a result, got %v", err)
+	}
+	switch got := got.(type) {
+	case *big.Int:
+		if want := v.uint(t, "result"); got.Cmp(want) != 0 {
+			t.Errorf("Expected %s, got %s", want, got)
+		}
+	case []*big.Int:
+		want := v.uints(t, "result")
+		if len(got) != len(want) {
+			t.Fatalf("Expected %d results, got %d", len(want), len(got))
+		}
+		for i := range want {
+			if got[i].Cmp(want[i]) != 0 {
+				t.Errorf("Expected result[%d] %s, got %s", i, want[i], got[i])
+			}
+		}
+	}
+}
+
+func TestRewardMath_Vectors(t *testing.T) {
+	data, err := os.ReadFile(vectorsPath)
+	if err != nil {
+		t.Fatalf("Failed to read RewardMath vectors: %v", err)
+	}
+	var sections map[string]json.RawMessage
+	if err := json.Unmarshal(data, &sections); err != nil {
+		t.Fatalf("Failed to parse RewardMath vectors: %v", err)
+	}
+
+	functions := map[string]func(t *testing.T, v vector) (interface{}, error){
+		"mulDiv": func(t *testing.T, v vector) (interface{}, error) {
+			return MulDiv(v.uint(t, "x"), v.uint(t, "y"), v.uint(t, "denominator"))
+		},
+		"compound": func(t *testing.T, v vector) (interface{}, error) {
+			return Compound(v.uint(t, "principal"), v.uint(t, "rate"), v.uint(t, "time"))
+		},
+		"exponentialDecay": func(t *testing.T, v vector) (interface{}, error) {
+			return ExponentialDecay(v.uint(t, "value"), v.uint(t, "decayRate"), v.uint(t, "time"))
+		},
+		"calculateTierMultiplier": func(t *testing.T, v vector) (interface{}, error) {
+			return CalculateTierMultiplier(v.uint(t, "totalLiquidity"), v.uint(t, "loyaltyScore"), v.uint(t, "timeActive"))
+		},
+		"timeWeightedAverage": func(t *testing.T, v vector) (interface{}, error) {
+			return TimeWeightedAverage(v.uints(t, "values"), v.uints(t, "weights"))
+		},
+		"geometricMean": func(t *testing.T, v vector) (interface{}, error) {
+			return GeometricMean(v.uints(t, "values"))
+		},
+		"calculateRewardDistribution": func(t *testing.T, v vector) (interface{}, error) {
+			return CalculateRewardDistribution(v.uint(t, "totalReward"), v.uints(t, "shares"))
+		},
+		"calculateSlippageProtection": func(t *testing.T, v vector) (interface{}, error) {
+			return CalculateSlippageProtection(v.uint(t, "expectedAmount"), v.uint(t, "actualAmount"), v.uint(t, "maxSlippage"))
+		},
+		"calculateFee": func(t *testing.T, v vector) (interface{}, error) {
+			return CalculateFee(v.uint(t, "amount"), v.uint(t, "feeRate"))
+		},
+		"calculateNetAmount": func(t *testing.T, v vector) (interface{}, error) {
+			return CalculateNetAmount(v.uint(t, "amount"), v.uint(t, "feeRate"))
+		},
+	}
+
+	for name, fn := range functions {
+		t.Run(name, func(t *testing.T) {
+			var vectors []vector
+			if err := json.Unmarshal(sections[name], &vectors); err != nil || len(vectors) == 0 {
+				t.Fatalf("Missing %s vectors: %v", name, err)
+			}
+			for _, v := range vectors {
+				t.Run(v.str(t, "name"), func(t *testing.T) {
+					got, err := fn(t, v)
+					v.check(t, got, err)
+				})
+			}
+		})
+	}
+}
+
+func TestRewardMath_Arguments(t *testing.T) {
+	negative := big.NewInt(-1)
+	tooLarge := new(big.Int).Lsh(big.NewInt(1), 256)
+	if _, err := MulDiv(negative, big.NewInt(1), big.NewInt(1)); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Expected a negative argument to be out of range, got %v", err)
+	}
+	if _, err := GeometricMean([]*big.Int{tooLarge}); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Expected 2^256 to be out of range, got %v", err)
+	}
+	if _, err := Compound(big.NewInt(1), big.NewInt(0), big.NewInt(MaxIterations+1)); !errors.Is(err, ErrTooManyIterations) {
+		t.Errorf("Expected too many iterations, got %v", err)
+	}
+	if _, err := ExponentialDecay(big.NewInt(1), big.NewInt(0), tooLarge.Sub(tooLarge, big.NewInt(1))); !errors.Is(err, ErrTooManyIterations) {
+		t.Errorf("Expected too many iterations, got %v", err)
+	}
+}
+
+func TestMulDiv_DoesNotAliasArguments(t *testing.T) {
+	x, y, d := big.NewInt(6), big.NewInt(7), big.NewInt(3)
+	got, err := MulDiv(x, y, d)
+	if err != nil {
+		t.Fatalf("MulDiv failed: %v", err)
+	}
+	got.SetInt64(0)
+	if x.Int64() != 6 || y.Int64() != 7 || d.Int64() != 3 {
+		t.Errorf("MulDiv modified its arguments: %s %s %s", x, y, d)
+	}
+	principal := big.NewInt(5)
+	result, err := Compound(principal, big.NewInt(0), big.NewInt(0))
+	if err != nil {
+		t.Fatalf("Compound failed: %v", err)
+	}
+	result.SetInt64(0)
+	if principal.Int64() != 5 {
+		t.Errorf("Compound returned its argument")
+	}
+}
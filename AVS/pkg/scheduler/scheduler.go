@@ -0,0 +1,242 @@
+// Package scheduler orders pending distributions by
+// DistributionUtils.calculateDistributionPriority, with a bounded queue and a
+// maximum wait after which a distribution is served before any other.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrQueueFull rejects a distribution when the queue holds its maximum of
+// pending distributions
+var ErrQueueFull = errors.New("distribution queue is full")
+
+var (
+	ether      = big.NewInt(1e18)
+	tierWeight = big.NewInt(10)
+)
+
+// Priority applies DistributionUtils.calculateDistributionPriority: one point
+// per whole ETH, ten per tier level and one per whole day since the last
+// distribution
+func Priority(amount *big.Int, userTier uint64, sinceLast time.Duration) *big.Int {
+	priority := new(big.Int)
+	if amount != nil && amount.Sign() > 0 {
+		priority.Quo(amount, ether)
+	}
+	tier := new(big.Int).SetUint64(userTier)
+	priority.Add(priority, tier.Mul(tier, tierWeight))
+	if sinceLast > 0 {
+		priority.Add(priority, big.NewInt(int64(sinceLast/(24*time.Hour))))
+	}
+	return priority
+}
+
+// Config sizes a Scheduler
+type Config struct {
+	// Concurrency is how many distributions run at once
+	Concurrency int
+	// QueueSize is how many distributions may wait for a slot
+	QueueSize int
+	// MaxWait is how long a distribution waits before it is served ahead of
+	// higher priorities, oldest first
+	MaxWait time.Duration
+}
+
+// Validate checks that every size is positive
+func (c Config) Validate() error {
+	if c.Concurrency <= 0 {
+		return fmt.Errorf("concurrency must be positive, got %d", c.Concurrency)
+	}
+	if c.QueueSize <= 0 {
+		return fmt.Errorf("queue size must be positive, got %d", c.QueueSize)
+	}
+	if c.MaxWait <= 0 {
+		return fmt.Errorf("max wait must be positive, got %s", c.MaxWait)
+	}
+	return nil
+}
+
+// Stats is the state of a Scheduler at a point in time
+type Stats struct {
+	Depth       int   `json:"depth"`
+	QueueSize   int   `json:"queue_size"`
+	Running     int   `json:"running"`
+	Concurrency int   `json:"concurrency"`
+	Rejected    int64 `json:"rejected"`
+}
+
+// job is a distribution waiting for a slot
+type job struct {
+	priority *big.Int
+	enqueued time.Time
+	seq      uint64
+	ready    chan struct{}
+}
+
+// Scheduler hands a limited number of slots to distributions, highest
+// priority first. It is safe for concurrent use.
+type Scheduler struct {
+	cfg Config
+
+	mu       sync.Mutex
+	pending  []*job
+	running  int
+	rejected int64
+	seq      uint64
+
+	now func() time.Time
+}
+
+// New creates a scheduler with cfg
+func New(cfg Config) (*Scheduler, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &Scheduler{cfg: cfg, now: time.Now}, nil
+}
+
+// Acquire waits until a distribution of the given priority may run and
+// returns the function releasing its slot along with how long it waited. It
+// fails with ErrQueueFull when the queue is full, or with the context error
+// when ctx ends first.
+func (s *Scheduler) Acquire(ctx context.Context, priority *big.Int) (func(), time.Duration, error) {
+	s.mu.Lock()
+	if s.running < s.cfg.Concurrency && len(s.pending) == 0 {
+		s.running++
+		s.mu.Unlock()
+		return s.release, 0, nil
+	}
+	if len(s.pending) >= s.cfg.QueueSize {
+		s.rejected++
+		s.mu.Unlock()
+		return nil, 0, fmt.Errorf("%w: %d distributions pending", ErrQueueFull, s.cfg.QueueSize)
+	}
+
+	s.seq++
+	j := &job{priority: priority, enqueued: s.now(), seq: s.seq, ready: make(chan struct{})}
+	s.pending = append(s.pending, j)
+	s.mu.Unlock()
+
+	select {
+	case <-j.ready:
+		return s.release, s.now().Sub(j.enqueued), nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		select {
+		case <-j.ready:
+			// Dispatched while giving up, so hand the slot on
+			s.running--
+			s.dispatch()
+		default:
+			s.remove(j)
+		}
+		return nil, s.now().Sub(j.enqueued), ctx.Err()
+	}
+}
+
+// release frees a slot for the next pending distribution
+func (s *Scheduler) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running--
+	s.dispatch()
+}
+
+// dispatch hands free slots to pending distributions. s.mu must be held.
+func (s *Scheduler) dispatch() {
+	for s.running < s.cfg.Concurrency && len(s.pending) > 0 {
+		next := s.next()
+		s.remove(next)
+		s.running++
+		close(next.ready)
+	}
+}
+
+// next picks the oldest distribution that waited MaxWait or more, otherwise
+// the highest priority, oldest first among equals. s.mu must be held.
+func (s *Scheduler) next() *job {
+	now := s.now()
+	var best, starved *job
+	for _, j := range s.pending {
+		if now.Sub(j.enqueued) >= s.cfg.MaxWait && (starved == nil || j.seq < starved.seq) {
+			starved = j
+		}
+		if best == nil {
+			best = j
+			continue
+		}
+		if c := j.priority.Cmp(best.priority); c > 0 || c == 0 && j.seq < best.seq {
+			best = j
+		}
+	}
+	if starved != nil {
+		return starved
+	}
+	return best
+}
+
+// remove drops j from the pending distributions. s.mu must be held.
+func (s *Scheduler) remove(j *job) {
+	for i, p := range s.pending {
+		if p == j {
+			s.pending = append(s.pending[:i], s.pending[i+1:]...)
+			return
+		}
+	}
+}
+
+// Stats returns the queue depth, running distributions and rejections
+func (s *Scheduler) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return Stats{
+		Depth:       len(s.pending),
+		QueueSize:   s.cfg.QueueSize,
+		Running:     s.running,
+		Concurrency: s.cfg.Concurrency,
+		Rejected:    s.rejected,
+	}
+}
+
+// History remembers when each user or pool last got a distribution, for the
+// staleness part of the priority. It is safe for concurrent use.
+type History struct {
+	mu   sync.RWMutex
+	last map[string]time.Time
+}
+
+// NewHistory creates an empty history
+func NewHistory() *History {
+	return &History{last: make(map[string]time.Time)}
+}
+
+// Since returns how long ago key last got a distribution, and false if it never did
+func (h *History) Since(key string, now time.Time) (time.Duration, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	last, ok := h.last[normalizeKey(key)]
+	if !ok {
+		return 0, false
+	}
+	return now.Sub(last), true
+}
+
+// Mark records a distribution to key at t
+func (h *History) Mark(key string, t time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.last[normalizeKey(key)] = t
+}
+
+// normalizeKey makes checksummed and lower-case addresses the same key
+func normalizeKey(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
+}
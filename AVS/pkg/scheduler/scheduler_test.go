@@ -0,0 +1,276 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+)
+
+func eth(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18))
+}
+
+func TestPriority(t *testing.T) {
+	tests := []struct {
+		name      string
+		amount    *big.Int
+		tier      uint64
+		sinceLast time.Duration
+		expected  int64
+	}{
+		{name: "nothing", amount: big.NewInt(0), expected: 0},
+		{name: "nil amount", tier: 1, expected: 10},
+		{name: "whole ether", amount: eth(5), expected: 5},
+		{name: "fractions of ether are dropped", amount: big.NewInt(1999999999999999999), expected: 1},
+		{name: "tier", amount: eth(1), tier: 3, expected: 31},
+		{name: "whole days", amount: eth(1), sinceLast: 50 * time.Hour, expected: 3},
+		{name: "all parts", amount: eth(100), tier: 4, sinceLast: 7 * 24 * time.Hour, expected: 147},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Priority(tt.amount, tt.tier, tt.sinceLast); got.Int64() != tt.expected {
+				t.Errorf("Expected priority %d, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      Config
+		errorMsg string
+	}{
+		{name: "valid", cfg: Config{Concurrency: 1, QueueSize: 1, MaxWait: time.Second}},
+		{name: "no concurrency", cfg: Config{QueueSize: 1, MaxWait: time.Second}, errorMsg: "concurrency must be positive, got 0"},
+		{name: "no queue", cfg: Config{Concurrency: 1, MaxWait: time.Second}, errorMsg: "queue size must be positive, got 0"},
+		{name: "no max wait", cfg: Config{Concurrency: 1, QueueSize: 1}, errorMsg: "max wait must be positive, got 0s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("Expected config to be valid, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("Expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+// waitForDepth blocks until depth distributions are pending
+func waitForDepth(t *testing.T, s *Scheduler, depth int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for s.Stats().Depth != depth {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d pending distributions, got %d", depth, s.Stats().Depth)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// enqueue acquires a slot in the background, appending name to order once it
+// runs and releasing the slot straight away
+func enqueue(t *testing.T, s *Scheduler, wg *sync.WaitGroup, mu *sync.Mutex, order *[]string, name string, priority int64) {
+	t.Helper()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		release, _, err := s.Acquire(context.Background(), big.NewInt(priority))
+		if err != nil {
+			t.Errorf("Acquire %s failed: %v", name, err)
+			return
+		}
+		mu.Lock()
+		*order = append(*order, name)
+		mu.Unlock()
+		release()
+	}()
+}
+
+func TestScheduler_Order(t *testing.T) {
+	s, err := New(Config{Concurrency: 1, QueueSize: 10, MaxWait: time.Hour})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	release, waited, err := s.Acquire(context.Background(), big.NewInt(0))
+	if err != nil || waited != 0 {
+		t.Fatalf("Expected an immediate slot, got %v after %s", err, waited)
+	}
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		order []string
+	)
+	for i, job := range []struct {
+		name     string
+		priority int64
+	}{{"low", 1}, {"high", 100}, {"medium", 10}, {"medium again", 10}} {
+		enqueue(t, s, &wg, &mu, &order, job.name, job.priority)
+		waitForDepth(t, s, i+1)
+	}
+
+	if stats := s.Stats(); stats.Running != 1 || stats.Concurrency != 1 || stats.QueueSize != 10 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+	release()
+	wg.Wait()
+
+	expected := []string{"high", "medium", "medium again", "low"}
+	for i := range expected {
+		if i >= len(order) || order[i] != expected[i] {
+			t.Fatalf("Expected order %v, got %v", expected, order)
+		}
+	}
+	if stats := s.Stats(); stats.Depth != 0 || stats.Running != 0 {
+		t.Errorf("Expected an idle scheduler, got %+v", stats)
+	}
+}
+
+func TestScheduler_MaxWait(t *testing.T) {
+	s, err := New(Config{Concurrency: 1, QueueSize: 10, MaxWait: time.Minute})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	var (
+		clockMu sync.Mutex
+		clock   = time.Unix(1700000000, 0)
+	)
+	s.now = func() time.Time {
+		clockMu.Lock()
+		defer clockMu.Unlock()
+		return clock
+	}
+	advance := func(d time.Duration) {
+		clockMu.Lock()
+		clock = clock.Add(d)
+		clockMu.Unlock()
+	}
+
+	release, _, err := s.Acquire(context.Background(), big.NewInt(0))
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		order []string
+	)
+	// The low priority distributions wait past the maximum before the whales arrive
+	enqueue(t, s, &wg, &mu, &order, "old", 0)
+	waitForDepth(t, s, 1)
+	enqueue(t, s, &wg, &mu, &order, "older than max wait", 1)
+	waitForDepth(t, s, 2)
+	advance(2 * time.Minute)
+	enqueue(t, s, &wg, &mu, &order, "whale", 1000000)
+	waitForDepth(t, s, 3)
+	enqueue(t, s, &wg, &mu, &order, "whale again", 1000000)
+	waitForDepth(t, s, 4)
+
+	release()
+	wg.Wait()
+
+	// Starved distributions go oldest first, then the rest by priority
+	expected := []string{"old", "older than max wait", "whale", "whale again"}
+	for i := range expected {
+		if i >= len(order) || order[i] != expected[i] {
+			t.Fatalf("Expected order %v, got %v", expected, order)
+		}
+	}
+}
+
+func TestScheduler_QueueFull(t *testing.T) {
+	s, err := New(Config{Concurrency: 1, QueueSize: 1, MaxWait: time.Hour})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	release, _, err := s.Acquire(context.Background(), big.NewInt(0))
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		order []string
+	)
+	enqueue(t, s, &wg, &mu, &order, "queued", 0)
+	waitForDepth(t, s, 1)
+
+	_, _, err = s.Acquire(context.Background(), big.NewInt(100))
+	if !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("Expected ErrQueueFull, got %v", err)
+	}
+	if expected := "distribution queue is full: 1 distributions pending"; err.Error() != expected {
+		t.Errorf("Expected error message '%s', got '%v'", expected, err)
+	}
+	if stats := s.Stats(); stats.Rejected != 1 || stats.Depth != 1 {
+		t.Errorf("Expected 1 rejection with 1 pending, got %+v", stats)
+	}
+
+	release()
+	wg.Wait()
+	if len(order) != 1 {
+		t.Errorf("Expected the queued distribution to run, got %v", order)
+	}
+}
+
+func TestScheduler_Cancel(t *testing.T) {
+	s, err := New(Config{Concurrency: 1, QueueSize: 1, MaxWait: time.Hour})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	release, _, err := s.Acquire(context.Background(), big.NewInt(0))
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := s.Acquire(ctx, big.NewInt(0))
+		done <- err
+	}()
+	waitForDepth(t, s, 1)
+	cancel()
+
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if stats := s.Stats(); stats.Depth != 0 {
+		t.Errorf("Expected the cancelled distribution to leave the queue, got %+v", stats)
+	}
+
+	// The slot is still handed on normally
+	release()
+	release, _, err = s.Acquire(context.Background(), big.NewInt(0))
+	if err != nil {
+		t.Fatalf("Acquire after cancel failed: %v", err)
+	}
+	release()
+}
+
+func TestHistory(t *testing.T) {
+	history := NewHistory()
+	now := time.Unix(1700000000, 0)
+
+	if _, ok := history.Since("0xAbC", now); ok {
+		t.Fatal("Expected no distribution for an unknown key")
+	}
+	history.Mark("0xAbC", now.Add(-time.Hour))
+	if since, ok := history.Since("0xabc", now); !ok || since != time.Hour {
+		t.Errorf("Expected 1h since the last distribution, got %s (%v)", since, ok)
+	}
+}
@@ -0,0 +1,43 @@
+package signer
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ErrNoKey is returned by LoadKey when neither a keystore nor a raw key is given
+var ErrNoKey = errors.New("no signing key")
+
+// LoadKey decrypts the encrypted JSON keystore at keystorePath with password,
+// or parses rawKey, a hex private key, when no keystore is given
+func LoadKey(keystorePath, password, rawKey string) (*ecdsa.PrivateKey, error) {
+	rawKey = strings.TrimPrefix(strings.TrimSpace(rawKey), "0x")
+	switch {
+	case keystorePath != "" && rawKey != "":
+		return nil, errors.New("a keystore and a raw key are both set")
+	case keystorePath != "":
+		data, err := os.ReadFile(keystorePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read keystore: %w", err)
+		}
+		key, err := keystore.DecryptKey(data, password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt keystore %s: %w", keystorePath, err)
+		}
+		return key.PrivateKey, nil
+	case rawKey != "":
+		key, err := crypto.HexToECDSA(rawKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid private key: %w", err)
+		}
+		return key, nil
+	default:
+		return nil, ErrNoKey
+	}
+}
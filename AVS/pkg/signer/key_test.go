@@ -0,0 +1,62 @@
+package signer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestLoadKey(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	account, err := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP).ImportECDSA(key, "secret")
+	if err != nil {
+		t.Fatalf("Failed to write keystore: %v", err)
+	}
+	path := account.URL.Path
+	raw := hexutil.Encode(crypto.FromECDSA(key))
+
+	tests := []struct {
+		name     string
+		keystore string
+		password string
+		raw      string
+		errorMsg string
+	}{
+		{name: "keystore", keystore: path, password: "secret"},
+		{name: "raw key", raw: raw},
+		{name: "raw key without prefix", raw: " " + raw[2:] + "\n"},
+		{name: "wrong password", keystore: path, password: "guess", errorMsg: "failed to decrypt keystore " + path + ": could not decrypt key with given password"},
+		{name: "both", keystore: path, raw: raw, errorMsg: "a keystore and a raw key are both set"},
+		{name: "invalid raw key", raw: "0x1234", errorMsg: "invalid private key: invalid length, need 256 bits"},
+		{name: "none", errorMsg: "no signing key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loaded, err := LoadKey(tt.keystore, tt.password, tt.raw)
+			if tt.errorMsg != "" {
+				if err == nil || err.Error() != tt.errorMsg {
+					t.Errorf("Expected error message '%s', got '%v'", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadKey failed: %v", err)
+			}
+			if crypto.PubkeyToAddress(loaded.PublicKey) != address {
+				t.Errorf("Expected key of %s, got %s", address, crypto.PubkeyToAddress(loaded.PublicKey))
+			}
+		})
+	}
+
+	if _, err := LoadKey("", "", ""); !errors.Is(err, ErrNoKey) {
+		t.Errorf("Expected ErrNoKey, got %v", err)
+	}
+}
@@ -0,0 +1,308 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// NonceClient is the part of an Ethereum client nonces are tracked through
+type NonceClient interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// pendingTx is a sent transaction whose nonce is not yet mined
+type pendingTx struct {
+	tx     *types.Transaction
+	sentAt time.Time
+	// previous holds the hashes of the transactions tx was resent in place of
+	previous []common.Hash
+}
+
+// Recovery is what a pass of Recover found
+type Recovery struct {
+	// Confirmed counts the transactions mined as sent or as resent
+	Confirmed int
+	// Replaced holds the transactions whose nonce another transaction took
+	Replaced []common.Hash
+	// Resent holds the transactions sent in place of stuck ones
+	Resent []common.Hash
+}
+
+// NonceManager hands out the nonces of one account on one chain. Concurrent
+// callers never pick the same nonce, and a nonce is only used up by a
+// transaction the node accepted. Nodes are called without holding the
+// manager's lock, so one slow send does not hold up the others. It is safe for
+// concurrent use.
+type NonceManager struct {
+	client  NonceClient
+	account common.Address
+	now     func() time.Time
+
+	mu       sync.Mutex
+	synced   bool
+	next     uint64
+	pending  map[uint64]*pendingTx
+	resolved map[common.Hash]common.Hash
+	// free holds the nonces below next whose transactions the node rejected,
+	// in ascending order, to be handed out again first
+	free []uint64
+	// uncertain holds the nonces whose send failed without an answer from the
+	// node, which may have accepted the transaction
+	uncertain map[uint64]bool
+}
+
+// NewNonceManager creates a nonce manager for account, which syncs with the
+// pending nonce of the chain on its first send
+func NewNonceManager(client NonceClient, account common.Address) *NonceManager {
+	return &NonceManager{
+		client:    client,
+		account:   account,
+		now:       time.Now,
+		pending:   make(map[uint64]*pendingTx),
+		resolved:  make(map[common.Hash]common.Hash),
+		uncertain: make(map[uint64]bool),
+	}
+}
+
+// Send calls send with the next nonce. When the node reports the nonce as
+// already used, the manager syncs with the chain and send is retried once.
+// A nonce the node rejected is handed out again. A nonce whose send failed
+// without an answer from the node, such as on a timeout, is held back until
+// the next send checks the pending nonce of the chain: it is handed out again
+// only if the node never received its transaction.
+func (m *NonceManager) Send(ctx context.Context, send func(nonce uint64) (*types.Transaction, error)) (*types.Transaction, error) {
+	for retried := false; ; retried = true {
+		nonce, err := m.reserve(ctx)
+		if err != nil {
+			return nil, err
+		}
+		tx, err := send(nonce)
+		if err == nil {
+			m.mu.Lock()
+			m.pending[nonce] = &pendingTx{tx: tx, sentAt: m.now()}
+			m.mu.Unlock()
+			return tx, nil
+		}
+
+		m.mu.Lock()
+		switch {
+		case isNonceTooLow(err):
+			// The nonce is used on chain, so it is dropped and the manager syncs
+			m.synced = false
+		case isRejected(err):
+			m.release(nonce)
+		default:
+			m.uncertain[nonce] = true
+		}
+		m.mu.Unlock()
+		if retried || !isNonceTooLow(err) {
+			return nil, err
+		}
+	}
+}
+
+// reserve takes the lowest free nonce, or the next one, after syncing with
+// the chain when the manager is not synced or has uncertain nonces
+func (m *NonceManager) reserve(ctx context.Context) (uint64, error) {
+	m.mu.Lock()
+	var check []uint64
+	for nonce := range m.uncertain {
+		check = append(check, nonce)
+	}
+	syncing := !m.synced || len(check) > 0
+	m.mu.Unlock()
+
+	var pending uint64
+	if syncing {
+		var err error
+		pending, err = m.client.PendingNonceAt(ctx, m.account)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get pending nonce of %s: %w", m.account, err)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if syncing {
+		m.sync(pending, check)
+	}
+	if len(m.free) > 0 {
+		nonce := m.free[0]
+		m.free = m.free[1:]
+		return nonce, nil
+	}
+	nonce := m.next
+	m.next++
+	return nonce, nil
+}
+
+// sync moves the next nonce up to the pending nonce of the chain. Of the
+// uncertain nonces checked, those below it were received by the node and stay
+// used, and the others are freed. Free nonces below it were used elsewhere.
+func (m *NonceManager) sync(pending uint64, checked []uint64) {
+	if pending > m.next {
+		m.next = pending
+	}
+	for _, nonce := range checked {
+		delete(m.uncertain, nonce)
+		if nonce >= pending {
+			m.release(nonce)
+		}
+	}
+	m.dropFree(pending)
+	m.synced = true
+}
+
+// release hands nonce out again before any new one
+func (m *NonceManager) release(nonce uint64) {
+	i := sort.Search(len(m.free), func(i int) bool { return m.free[i] >= nonce })
+	if i < len(m.free) && m.free[i] == nonce {
+		return
+	}
+	m.free = append(m.free, 0)
+	copy(m.free[i+1:], m.free[i:])
+	m.free[i] = nonce
+}
+
+// dropFree forgets the free nonces below mined, which are used on chain
+func (m *NonceManager) dropFree(mined uint64) {
+	i := sort.Search(len(m.free), func(i int) bool { return m.free[i] >= mined })
+	m.free = m.free[i:]
+}
+
+// Pending returns the number of sent transactions whose nonce is not yet mined
+func (m *NonceManager) Pending() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.pending)
+}
+
+// Resolve returns the transaction mined or last sent in place of hash, or
+// hash itself if it was never resent
+func (m *NonceManager) Resolve(hash common.Hash) common.Hash {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if resolved, ok := m.resolved[hash]; ok {
+		return resolved
+	}
+	return hash
+}
+
+// Recover forgets the transactions whose nonce was mined, telling those mined
+// from those another transaction replaced, and passes those pending for longer
+// than stuckAfter to resend, which returns the transaction sent in their place
+func (m *NonceManager) Recover(ctx context.Context, stuckAfter time.Duration, resend func(tx *types.Transaction) (*types.Transaction, error)) (Recovery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var recovery Recovery
+	mined, err := m.client.NonceAt(ctx, m.account, nil)
+	if err != nil {
+		return recovery, fmt.Errorf("failed to get nonce of %s: %w", m.account, err)
+	}
+	if m.synced && mined > m.next {
+		m.next = mined
+	}
+	m.dropFree(mined)
+
+	nonces := make([]uint64, 0, len(m.pending))
+	for nonce := range m.pending {
+		nonces = append(nonces, nonce)
+	}
+	sort.Slice(nonces, func(i, j int) bool { return nonces[i] < nonces[j] })
+
+	var errs []error
+	for _, nonce := range nonces {
+		p := m.pending[nonce]
+		if nonce < mined {
+			hash, err := m.minedHash(ctx, p)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if hash == (common.Hash{}) {
+				recovery.Replaced = append(recovery.Replaced, p.tx.Hash())
+			} else {
+				recovery.Confirmed++
+				m.resolve(p, hash)
+			}
+			delete(m.pending, nonce)
+			continue
+		}
+		if m.now().Sub(p.sentAt) < stuckAfter {
+			continue
+		}
+		tx, err := resend(p.tx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to resend nonce %d: %w", nonce, err))
+			continue
+		}
+		if tx.Hash() != p.tx.Hash() {
+			p.previous = append(p.previous, p.tx.Hash())
+			m.resolve(p, tx.Hash())
+			recovery.Resent = append(recovery.Resent, tx.Hash())
+		}
+		p.tx, p.sentAt = tx, m.now()
+	}
+	return recovery, errors.Join(errs...)
+}
+
+// minedHash returns which version of p was mined, zero if none was
+func (m *NonceManager) minedHash(ctx context.Context, p *pendingTx) (common.Hash, error) {
+	for _, hash := range append([]common.Hash{p.tx.Hash()}, p.previous...) {
+		_, err := m.client.TransactionReceipt(ctx, hash)
+		if IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("failed to get receipt of %s: %w", hash, err)
+		}
+		return hash, nil
+	}
+	return common.Hash{}, nil
+}
+
+// resolve points every version of p at hash
+func (m *NonceManager) resolve(p *pendingTx, hash common.Hash) {
+	for _, previous := range append([]common.Hash{p.tx.Hash()}, p.previous...) {
+		if previous != hash {
+			m.resolved[previous] = hash
+		} else {
+			delete(m.resolved, previous)
+		}
+	}
+}
+
+// IsNotFound reports whether a node does not know a transaction. Nodes still
+// indexing transactions report recent ones as not yet indexed instead.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ethereum.NotFound) || err != nil && strings.Contains(err.Error(), "transaction indexing is in progress")
+}
+
+// isRejected reports whether err is the answer of a node that refused a
+// transaction, rather than a failure to reach it, so that the transaction was
+// not accepted
+func isRejected(err error) bool {
+	var rpcErr rpc.Error
+	return errors.As(err, &rpcErr)
+}
+
+// isNonceTooLow reports whether a node rejected a transaction for a used
+// nonce. An in-process node returns core.ErrNonceTooLow; over JSON-RPC only
+// its message survives, so the message is matched as a fallback.
+func isNonceTooLow(err error) bool {
+	return errors.Is(err, core.ErrNonceTooLow) || strings.Contains(err.Error(), core.ErrNonceTooLow.Error())
+}
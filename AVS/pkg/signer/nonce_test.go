@@ -0,0 +1,294 @@
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// sendExternal sends a transfer at nonce from key outside of the signer, as
+// another process sharing the key would
+func sendExternal(t *testing.T, s *Signer, key *ecdsa.PrivateKey, nonce uint64, tipCap, feeCap *big.Int) *types.Transaction {
+	t.Helper()
+	tx, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+		ChainID:   s.chainID,
+		Nonce:     nonce,
+		GasTipCap: tipCap,
+		GasFeeCap: feeCap,
+		Gas:       params.TxGas,
+		To:        &recipient,
+		Value:     big.NewInt(2),
+	}), s.signer, key)
+	if err != nil {
+		t.Fatalf("Failed to sign: %v", err)
+	}
+	if err := s.Client().SendTransaction(context.Background(), tx); err != nil {
+		t.Fatalf("Failed to send: %v", err)
+	}
+	return tx
+}
+
+func TestNonceManager_NonceTooLow(t *testing.T) {
+	backend, s, key := newSimulatedSigner(t, FeeCaps{})
+	ctx := context.Background()
+
+	first := transfer(t, s)
+	tipCap, feeCap, err := s.Fees(ctx)
+	if err != nil {
+		t.Fatalf("Fees failed: %v", err)
+	}
+	sendExternal(t, s, key, 1, tipCap, feeCap)
+	backend.Commit()
+
+	// Nonce 1 was used behind the manager's back, so it syncs and moves on
+	second := transfer(t, s)
+	if first.Nonce() != 0 || second.Nonce() != 2 {
+		t.Errorf("Expected nonces 0 and 2, got %d and %d", first.Nonce(), second.Nonce())
+	}
+	backend.Commit()
+
+	recovery, err := s.Recover(ctx)
+	if err != nil {
+		t.Fatalf("Recover failed: %v", err)
+	}
+	if recovery.Confirmed != 2 || len(recovery.Replaced) != 0 {
+		t.Errorf("Expected 2 confirmed transactions, got %+v", recovery)
+	}
+}
+
+func TestNonceManager_Replaced(t *testing.T) {
+	backend, s, key := newSimulatedSigner(t, FeeCaps{})
+	ctx := context.Background()
+
+	tx := transfer(t, s)
+	sendExternal(t, s, key, tx.Nonce(), bump(tx.GasTipCap()), bump(tx.GasFeeCap()))
+	backend.Commit()
+
+	recovery, err := s.Recover(ctx)
+	if err != nil {
+		t.Fatalf("Recover failed: %v", err)
+	}
+	if recovery.Confirmed != 0 || len(recovery.Replaced) != 1 || recovery.Replaced[0] != tx.Hash() {
+		t.Errorf("Expected %s to be replaced, got %+v", tx.Hash(), recovery)
+	}
+	if s.Nonces().Pending() != 0 || s.Resolve(tx.Hash()) != tx.Hash() {
+		t.Errorf("Expected the replaced transaction to be forgotten")
+	}
+	if next := transfer(t, s); next.Nonce() != 1 {
+		t.Errorf("Expected nonce 1 after the replacement, got %d", next.Nonce())
+	}
+}
+
+func TestNonceManager_Stuck(t *testing.T) {
+	backend, s, _ := newSimulatedSigner(t, FeeCaps{})
+	ctx := context.Background()
+	now := time.Now()
+	s.nonces.now = func() time.Time { return now }
+
+	tx := transfer(t, s)
+	if recovery, err := s.Recover(ctx); err != nil || len(recovery.Resent) != 0 {
+		t.Fatalf("Expected nothing to recover yet, got %+v (%v)", recovery, err)
+	}
+
+	// A transaction pending past stuckAfter is resent with higher fees
+	now = now.Add(DefaultStuckAfter)
+	recovery, err := s.Recover(ctx)
+	if err != nil {
+		t.Fatalf("Recover failed: %v", err)
+	}
+	if len(recovery.Resent) != 1 || recovery.Resent[0] == tx.Hash() {
+		t.Fatalf("Expected a replacement of %s, got %+v", tx.Hash(), recovery)
+	}
+	replacement := recovery.Resent[0]
+	if s.Resolve(tx.Hash()) != replacement {
+		t.Errorf("Expected %s to resolve to %s, got %s", tx.Hash(), replacement, s.Resolve(tx.Hash()))
+	}
+	resent, _, err := backend.Client().TransactionByHash(ctx, replacement)
+	if err != nil {
+		t.Fatalf("Failed to get replacement: %v", err)
+	}
+	if resent.Nonce() != tx.Nonce() || resent.GasTipCap().Cmp(bump(tx.GasTipCap())) < 0 || resent.GasFeeCap().Cmp(bump(tx.GasFeeCap())) < 0 {
+		t.Errorf("Expected nonce %d with raised fees, got nonce %d at %s/%s", tx.Nonce(), resent.Nonce(), resent.GasTipCap(), resent.GasFeeCap())
+	}
+
+	backend.Commit()
+	recovery, err = s.Recover(ctx)
+	if err != nil {
+		t.Fatalf("Recover failed: %v", err)
+	}
+	if recovery.Confirmed != 1 || s.Resolve(tx.Hash()) != replacement {
+		t.Errorf("Expected the replacement to be confirmed, got %+v", recovery)
+	}
+}
+
+func TestNonceManager_StuckAtFeeCap(t *testing.T) {
+	_, s, _ := newSimulatedSigner(t, FeeCaps{})
+	ctx := context.Background()
+	now := time.Now()
+	s.nonces.now = func() time.Time { return now }
+
+	// With the fees at their caps the transaction is only sent again
+	tx := transfer(t, s)
+	s.caps = FeeCaps{MaxFeePerGas: tx.GasFeeCap(), MaxPriorityFeePerGas: tx.GasTipCap()}
+	now = now.Add(DefaultStuckAfter)
+	recovery, err := s.Recover(ctx)
+	if err != nil {
+		t.Fatalf("Recover failed: %v", err)
+	}
+	if len(recovery.Resent) != 0 || s.Resolve(tx.Hash()) != tx.Hash() || s.Nonces().Pending() != 1 {
+		t.Errorf("Expected %s to be sent again unchanged, got %+v", tx.Hash(), recovery)
+	}
+}
+
+// pendingNonceClient reports a fixed pending nonce and counts the calls for it
+type pendingNonceClient struct {
+	NonceClient
+	mu      sync.Mutex
+	pending uint64
+	calls   int
+}
+
+func (c *pendingNonceClient) PendingNonceAt(context.Context, common.Address) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	return c.pending, nil
+}
+
+func (c *pendingNonceClient) set(pending uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pending = pending
+}
+
+// rejectedError is the answer of a node refusing a transaction
+type rejectedError struct{}
+
+func (rejectedError) Error() string  { return "insufficient funds for gas * price + value" }
+func (rejectedError) ErrorCode() int { return -32000 }
+
+// sendAt returns a send that answers err, or a transaction at its nonce
+func sendAt(err error) func(nonce uint64) (*types.Transaction, error) {
+	return func(nonce uint64) (*types.Transaction, error) {
+		if err != nil {
+			return nil, err
+		}
+		return types.NewTx(&types.DynamicFeeTx{Nonce: nonce}), nil
+	}
+}
+
+func TestNonceManager_FailedSends(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		// received is whether the node got the transaction despite the error
+		received bool
+		next     uint64
+		calls    int
+	}{
+		{name: "rejected", err: rejectedError{}, next: 0, calls: 1},
+		{name: "timeout, not received", err: context.DeadlineExceeded, next: 0, calls: 2},
+		{name: "timeout, received", err: context.DeadlineExceeded, received: true, next: 1, calls: 2},
+		{name: "connection dropped, received", err: io.ErrUnexpectedEOF, received: true, next: 1, calls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &pendingNonceClient{}
+			m := NewNonceManager(client, recipient)
+			if _, err := m.Send(context.Background(), sendAt(tt.err)); !errors.Is(err, tt.err) {
+				t.Fatalf("Expected error '%v', got '%v'", tt.err, err)
+			}
+			if tt.received {
+				client.set(1)
+			}
+
+			// A nonce the node may hold is only handed out again once the chain shows it does not
+			tx, err := m.Send(context.Background(), sendAt(nil))
+			if err != nil {
+				t.Fatalf("Send failed: %v", err)
+			}
+			if tx.Nonce() != tt.next || client.calls != tt.calls {
+				t.Errorf("Expected nonce %d after %d pending nonce calls, got %d after %d", tt.next, tt.calls, tx.Nonce(), client.calls)
+			}
+			if tx, err := m.Send(context.Background(), sendAt(nil)); err != nil || tx.Nonce() != tt.next+1 {
+				t.Errorf("Expected nonce %d next, got %v (%v)", tt.next+1, tx, err)
+			}
+		})
+	}
+}
+
+func TestNonceManager_ConcurrentSlowSend(t *testing.T) {
+	m := NewNonceManager(&pendingNonceClient{}, recipient)
+
+	// A send waiting on a slow node does not hold up the next one
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan *types.Transaction, 1)
+	go func() {
+		tx, err := m.Send(context.Background(), func(nonce uint64) (*types.Transaction, error) {
+			close(started)
+			<-release
+			return sendAt(nil)(nonce)
+		})
+		if err != nil {
+			t.Errorf("Send failed: %v", err)
+		}
+		done <- tx
+	}()
+	<-started
+
+	fast := make(chan *types.Transaction, 1)
+	go func() {
+		tx, err := m.Send(context.Background(), sendAt(nil))
+		if err != nil {
+			t.Errorf("Send failed: %v", err)
+		}
+		fast <- tx
+	}()
+	select {
+	case tx := <-fast:
+		if tx.Nonce() != 1 {
+			t.Errorf("Expected nonce 1, got %d", tx.Nonce())
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected a send to proceed while another waits on the node")
+	}
+	close(release)
+	if tx := <-done; tx.Nonce() != 0 {
+		t.Errorf("Expected nonce 0, got %d", tx.Nonce())
+	}
+	if m.Pending() != 2 {
+		t.Errorf("Expected 2 pending transactions, got %d", m.Pending())
+	}
+}
+
+func TestIsNonceTooLow(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "in-process node", err: fmt.Errorf("%w: address %s, tx: 1 state: 2", core.ErrNonceTooLow, recipient), want: true},
+		{name: "json-rpc answer", err: errors.New("nonce too low: address 0x00000000000000000000000000000000000000b0, tx: 1 state: 2"), want: true},
+		{name: "other rejection", err: core.ErrNonceTooHigh, want: false},
+		{name: "timeout", err: context.DeadlineExceeded, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := isNonceTooLow(tt.err); got != tt.want {
+			t.Errorf("%s: expected %t, got %t", tt.name, tt.want, got)
+		}
+	}
+}
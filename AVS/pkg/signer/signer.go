@@ -0,0 +1,279 @@
+// Package signer signs and sends the transactions distributions are made
+// with. A Signer holds one key on one chain: it prices transactions with
+// capped EIP-1559 fees, hands out nonces through a NonceManager and resends
+// transactions that are stuck in the mempool.
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"go.uber.org/zap"
+)
+
+// DefaultStuckAfter is how long a transaction may stay pending before it is resent
+const DefaultStuckAfter = 3 * time.Minute
+
+// ErrFeeCapExceeded is returned when the base fee is above the fee cap
+var ErrFeeCapExceeded = errors.New("base fee above the fee cap")
+
+// Client is the part of an Ethereum client transactions are sent through
+type Client interface {
+	bind.ContractBackend
+	NonceClient
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+// FeeCaps bound the fees of a transaction, nil leaving them unbounded
+type FeeCaps struct {
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+}
+
+// Signer signs and sends transactions from one key on one chain. It is safe
+// for concurrent use.
+type Signer struct {
+	client     Client
+	key        *ecdsa.PrivateKey
+	from       common.Address
+	chainID    *big.Int
+	signer     types.Signer
+	caps       FeeCaps
+	stuckAfter time.Duration
+	nonces     *NonceManager
+	dryRun     bool
+}
+
+// New creates a signer of transactions on the chain client is connected to.
+// Transactions pending for longer than stuckAfter are resent by Recover.
+func New(ctx context.Context, client Client, key *ecdsa.PrivateKey, caps FeeCaps, stuckAfter time.Duration) (*Signer, error) {
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain ID: %w", err)
+	}
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	return &Signer{
+		client:     client,
+		key:        key,
+		from:       from,
+		chainID:    chainID,
+		signer:     types.LatestSignerForChainID(chainID),
+		caps:       caps,
+		stuckAfter: stuckAfter,
+		nonces:     NewNonceManager(client, from),
+	}, nil
+}
+
+// Address returns the account transactions are sent from
+func (s *Signer) Address() common.Address {
+	return s.from
+}
+
+// ChainID returns the chain transactions are sent on
+func (s *Signer) ChainID() uint64 {
+	return s.chainID.Uint64()
+}
+
+// Client returns the client transactions are sent through
+func (s *Signer) Client() Client {
+	return s.client
+}
+
+// SetDryRun makes Transact build and sign transactions without sending them.
+// It must be called before the signer is used.
+func (s *Signer) SetDryRun(dryRun bool) {
+	s.dryRun = dryRun
+}
+
+// DryRun reports whether transactions are built without being sent
+func (s *Signer) DryRun() bool {
+	return s.dryRun
+}
+
+// Nonces returns the nonce manager of the signer
+func (s *Signer) Nonces() *NonceManager {
+	return s.nonces
+}
+
+// Resolve returns the transaction sent in place of hash, see NonceManager.Resolve
+func (s *Signer) Resolve(hash common.Hash) common.Hash {
+	return s.nonces.Resolve(hash)
+}
+
+// Fees returns the priority fee and fee cap of a transaction sent now: the
+// suggested priority fee, and twice the base fee plus it as the fee cap, both
+// within the caps. The priority fee shrinks to keep the fee cap within its cap.
+func (s *Signer) Fees(ctx context.Context) (tipCap, feeCap *big.Int, err error) {
+	head, err := s.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get latest header: %w", err)
+	}
+	if head.BaseFee == nil {
+		return nil, nil, fmt.Errorf("chain %s does not support EIP-1559", s.chainID)
+	}
+	tipCap, err = s.client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to suggest priority fee: %w", err)
+	}
+	if s.caps.MaxPriorityFeePerGas != nil && tipCap.Cmp(s.caps.MaxPriorityFeePerGas) > 0 {
+		tipCap = new(big.Int).Set(s.caps.MaxPriorityFeePerGas)
+	}
+	feeCap = new(big.Int).Mul(head.BaseFee, big.NewInt(2))
+	feeCap.Add(feeCap, tipCap)
+	if max := s.caps.MaxFeePerGas; max != nil && feeCap.Cmp(max) > 0 {
+		if head.BaseFee.Cmp(max) > 0 {
+			return nil, nil, fmt.Errorf("%w: base fee %s, cap %s", ErrFeeCapExceeded, head.BaseFee, max)
+		}
+		feeCap = new(big.Int).Set(max)
+		if room := new(big.Int).Sub(max, head.BaseFee); tipCap.Cmp(room) > 0 {
+			tipCap = room
+		}
+	}
+	return tipCap, feeCap, nil
+}
+
+// Transact calls fn with transaction options carrying the next nonce and the
+// current fees. fn sends one transaction, typically through a bound contract.
+// In a dry run the options ask fn not to send it, and the transaction takes
+// the pending nonce of the chain without going through the NonceManager.
+func (s *Signer) Transact(ctx context.Context, fn func(opts *bind.TransactOpts) (*types.Transaction, error)) (*types.Transaction, error) {
+	if s.dryRun {
+		nonce, err := s.client.PendingNonceAt(ctx, s.from)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pending nonce: %w", err)
+		}
+		return s.transact(ctx, nonce, fn)
+	}
+	return s.nonces.Send(ctx, func(nonce uint64) (*types.Transaction, error) {
+		return s.transact(ctx, nonce, fn)
+	})
+}
+
+// transact calls fn with the options of a transaction at nonce
+func (s *Signer) transact(ctx context.Context, nonce uint64, fn func(opts *bind.TransactOpts) (*types.Transaction, error)) (*types.Transaction, error) {
+	tipCap, feeCap, err := s.Fees(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return fn(&bind.TransactOpts{
+		From:      s.from,
+		Nonce:     new(big.Int).SetUint64(nonce),
+		Signer:    s.sign,
+		GasTipCap: tipCap,
+		GasFeeCap: feeCap,
+		Context:   ctx,
+		NoSend:    s.dryRun,
+	})
+}
+
+// Recover forgets mined transactions and resends stuck ones, see NonceManager.Recover
+func (s *Signer) Recover(ctx context.Context) (Recovery, error) {
+	return s.nonces.Recover(ctx, s.stuckAfter, func(tx *types.Transaction) (*types.Transaction, error) {
+		return s.resend(ctx, tx)
+	})
+}
+
+// Run recovers transactions every interval until ctx is done
+func (s *Signer) Run(ctx context.Context, interval time.Duration, logger *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			recovery, err := s.Recover(ctx)
+			if err != nil {
+				logger.Warn("Failed to recover transactions", zap.Uint64("chain_id", s.ChainID()), zap.Error(err))
+			}
+			for _, hash := range recovery.Replaced {
+				logger.Warn("Transaction replaced", zap.Uint64("chain_id", s.ChainID()), zap.String("transaction_hash", hash.Hex()))
+			}
+			for _, hash := range recovery.Resent {
+				logger.Info("Stuck transaction resent", zap.Uint64("chain_id", s.ChainID()), zap.String("transaction_hash", hash.Hex()))
+			}
+		}
+	}
+}
+
+// sign signs tx for the signer's account
+func (s *Signer) sign(from common.Address, tx *types.Transaction) (*types.Transaction, error) {
+	if from != s.from {
+		return nil, bind.ErrNotAuthorized
+	}
+	return types.SignTx(tx, s.signer, s.key)
+}
+
+// resend replaces tx with the same transaction at the current fees, raised
+// enough for the node to accept the replacement. When the caps leave no room
+// for that, tx itself is sent again, in case the node dropped it.
+func (s *Signer) resend(ctx context.Context, tx *types.Transaction) (*types.Transaction, error) {
+	tipCap, feeCap, err := s.Fees(ctx)
+	if err != nil && !errors.Is(err, ErrFeeCapExceeded) {
+		return nil, err
+	}
+	if err == nil {
+		tipCap = maxInt(tipCap, bump(tx.GasTipCap()))
+		feeCap = maxInt(feeCap, bump(tx.GasFeeCap()))
+		if s.withinCaps(tipCap, feeCap) {
+			replacement, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+				ChainID:   s.chainID,
+				Nonce:     tx.Nonce(),
+				GasTipCap: tipCap,
+				GasFeeCap: feeCap,
+				Gas:       tx.Gas(),
+				To:        tx.To(),
+				Value:     tx.Value(),
+				Data:      tx.Data(),
+			}), s.signer, s.key)
+			if err != nil {
+				return nil, err
+			}
+			if err := s.client.SendTransaction(ctx, replacement); err != nil {
+				return nil, err
+			}
+			return replacement, nil
+		}
+	}
+	if err := s.client.SendTransaction(ctx, tx); err != nil && !isKnown(err) {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (s *Signer) withinCaps(tipCap, feeCap *big.Int) bool {
+	if s.caps.MaxPriorityFeePerGas != nil && tipCap.Cmp(s.caps.MaxPriorityFeePerGas) > 0 {
+		return false
+	}
+	return s.caps.MaxFeePerGas == nil || feeCap.Cmp(s.caps.MaxFeePerGas) <= 0
+}
+
+// bump raises a fee by an eighth, above the tenth nodes require of replacements
+func bump(fee *big.Int) *big.Int {
+	bumped := new(big.Int).Rsh(fee, 3)
+	bumped.Add(bumped, fee)
+	return bumped.Add(bumped, common.Big1)
+}
+
+func maxInt(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
+
+// isKnown reports whether a node rejected a transaction it already has, or
+// one whose nonce was mined since
+func isKnown(err error) bool {
+	return strings.Contains(err.Error(), "already known") || isNonceTooLow(err)
+}
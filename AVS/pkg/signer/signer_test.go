@@ -0,0 +1,214 @@
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var recipient = common.HexToAddress("0x00000000000000000000000000000000000000b0")
+
+// newSimulatedSigner starts a simulated chain with a funded key and a signer for it
+func newSimulatedSigner(t *testing.T, caps FeeCaps) (*simulated.Backend, *Signer, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	backend := simulated.NewBackend(types.GenesisAlloc{
+		crypto.PubkeyToAddress(key.PublicKey): {Balance: big.NewInt(5 * params.Ether)},
+	})
+	t.Cleanup(func() { backend.Close() })
+	s, err := New(context.Background(), backend.Client(), key, caps, DefaultStuckAfter)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return backend, s, key
+}
+
+// transfer sends a plain value transfer through s
+func transfer(t *testing.T, s *Signer) *types.Transaction {
+	t.Helper()
+	tx, err := s.Transact(context.Background(), func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		opts.Value = big.NewInt(1)
+		opts.GasLimit = params.TxGas
+		return bind.NewBoundContract(recipient, abi.ABI{}, s.Client(), s.Client(), s.Client()).Transfer(opts)
+	})
+	if err != nil {
+		t.Fatalf("Transact failed: %v", err)
+	}
+	return tx
+}
+
+func baseFee(t *testing.T, s *Signer) *big.Int {
+	t.Helper()
+	head, err := s.Client().HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to get header: %v", err)
+	}
+	return head.BaseFee
+}
+
+func TestSigner_Fees(t *testing.T) {
+	_, s, _ := newSimulatedSigner(t, FeeCaps{})
+	ctx := context.Background()
+	base := baseFee(t, s)
+
+	tipCap, feeCap, err := s.Fees(ctx)
+	if err != nil {
+		t.Fatalf("Fees failed: %v", err)
+	}
+	if expected := new(big.Int).Add(new(big.Int).Mul(base, big.NewInt(2)), tipCap); feeCap.Cmp(expected) != 0 {
+		t.Errorf("Expected a fee cap of %s, got %s", expected, feeCap)
+	}
+
+	tests := []struct {
+		name     string
+		caps     FeeCaps
+		tipCap   *big.Int
+		feeCap   *big.Int
+		errorMsg string
+	}{
+		{
+			name:   "priority fee cap",
+			caps:   FeeCaps{MaxPriorityFeePerGas: big.NewInt(1)},
+			tipCap: big.NewInt(1),
+			feeCap: new(big.Int).Add(new(big.Int).Mul(base, big.NewInt(2)), big.NewInt(1)),
+		},
+		{
+			name:   "fee cap leaves room for part of the priority fee",
+			caps:   FeeCaps{MaxFeePerGas: new(big.Int).Add(base, big.NewInt(1))},
+			tipCap: big.NewInt(1),
+			feeCap: new(big.Int).Add(base, big.NewInt(1)),
+		},
+		{
+			name:   "fee cap at the base fee",
+			caps:   FeeCaps{MaxFeePerGas: base},
+			tipCap: new(big.Int),
+			feeCap: base,
+		},
+		{
+			name:     "base fee above the fee cap",
+			caps:     FeeCaps{MaxFeePerGas: new(big.Int).Sub(base, big.NewInt(1))},
+			errorMsg: "base fee above the fee cap: base fee " + base.String() + ", cap " + new(big.Int).Sub(base, big.NewInt(1)).String(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.caps = tt.caps
+			tipCap, feeCap, err := s.Fees(ctx)
+			if tt.errorMsg != "" {
+				if err == nil || err.Error() != tt.errorMsg || !errors.Is(err, ErrFeeCapExceeded) {
+					t.Errorf("Expected error message '%s', got '%v'", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Fees failed: %v", err)
+			}
+			if tipCap.Cmp(tt.tipCap) != 0 || feeCap.Cmp(tt.feeCap) != 0 {
+				t.Errorf("Expected %s/%s, got %s/%s", tt.tipCap, tt.feeCap, tipCap, feeCap)
+			}
+		})
+	}
+}
+
+func TestSigner_ConcurrentTransact(t *testing.T) {
+	backend, s, _ := newSimulatedSigner(t, FeeCaps{})
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			transfer(t, s)
+		}()
+	}
+	wg.Wait()
+	backend.Commit()
+
+	nonce, err := s.Client().NonceAt(ctx, s.Address(), nil)
+	if err != nil {
+		t.Fatalf("Failed to get nonce: %v", err)
+	}
+	if nonce != 10 {
+		t.Errorf("Expected 10 mined transactions, got %d", nonce)
+	}
+	recovery, err := s.Recover(ctx)
+	if err != nil {
+		t.Fatalf("Recover failed: %v", err)
+	}
+	if recovery.Confirmed != 10 || s.Nonces().Pending() != 0 {
+		t.Errorf("Expected 10 confirmed transactions, got %+v with %d pending", recovery, s.Nonces().Pending())
+	}
+}
+
+func TestSigner_TransactFailure(t *testing.T) {
+	backend, s, _ := newSimulatedSigner(t, FeeCaps{})
+
+	// A transaction the node never accepted does not use up its nonce
+	_, err := s.Transact(context.Background(), func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return nil, errors.New("execution reverted")
+	})
+	if err == nil || err.Error() != "execution reverted" {
+		t.Errorf("Expected error message 'execution reverted', got '%v'", err)
+	}
+	if tx := transfer(t, s); tx.Nonce() != 0 {
+		t.Errorf("Expected nonce 0, got %d", tx.Nonce())
+	}
+	backend.Commit()
+}
+
+func TestSigner_DryRun(t *testing.T) {
+	backend, s, key := newSimulatedSigner(t, FeeCaps{})
+	ctx := context.Background()
+	s.SetDryRun(true)
+
+	// Dry runs sign every transaction at the pending nonce and send none
+	first := transfer(t, s)
+	second := transfer(t, s)
+	if first.Nonce() != 0 || second.Nonce() != 0 {
+		t.Errorf("Expected both transactions at nonce 0, got %d and %d", first.Nonce(), second.Nonce())
+	}
+	if from, err := types.Sender(types.LatestSignerForChainID(big.NewInt(int64(s.ChainID()))), first); err != nil || from != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Errorf("Expected a transaction signed by the signer, got %s (%v)", from.Hex(), err)
+	}
+	backend.Commit()
+	if nonce, err := s.Client().NonceAt(ctx, s.Address(), nil); err != nil || nonce != 0 {
+		t.Errorf("Expected no transaction to be mined, got nonce %d (%v)", nonce, err)
+	}
+	if s.Nonces().Pending() != 0 {
+		t.Errorf("Expected no pending transactions, got %d", s.Nonces().Pending())
+	}
+
+	// Switching dry runs off sends from the same nonce
+	s.SetDryRun(false)
+	if tx := transfer(t, s); tx.Nonce() != 0 {
+		t.Errorf("Expected nonce 0, got %d", tx.Nonce())
+	}
+	backend.Commit()
+}
+
+func TestKeys(t *testing.T) {
+	_, s, key := newSimulatedSigner(t, FeeCaps{})
+	if s.Address() != crypto.PubkeyToAddress(key.PublicKey) || s.ChainID() != 1337 {
+		t.Errorf("Unexpected signer %s on chain %d", s.Address(), s.ChainID())
+	}
+	_, err := s.sign(recipient, types.NewTx(&types.DynamicFeeTx{}))
+	if !errors.Is(err, bind.ErrNotAuthorized) {
+		t.Errorf("Expected other accounts not to be signed for, got %v", err)
+	}
+}
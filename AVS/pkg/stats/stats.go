@@ -0,0 +1,348 @@
+// Package stats aggregates RewardFlow task outcomes into counters, rolling-window
+// rates and latency percentiles that can be read concurrently with updates.
+package stats
+
+import (
+	"math"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultWindow is the rolling window used for rates when none is given
+	DefaultWindow = 5 * time.Minute
+	// maxLatencySamples bounds the number of recent latencies kept for percentiles
+	maxLatencySamples = 1024
+)
+
+// Observation is the outcome of a single processed task
+type Observation struct {
+	Success     bool
+	Latency     time.Duration
+	RewardType  string
+	SourceChain uint64
+	TargetChain uint64
+	// Distributed is the amount delivered to recipients
+	Distributed *big.Int
+	// MEVCaptured is the amount counted towards MEV captured, if any
+	MEVCaptured *big.Int
+	// FeeSplit and MEVSplit are the fee and MEV parts of each beneficiary
+	FeeSplit map[string]*big.Int
+	MEVSplit map[string]*big.Int
+	// QueueWait is how long the task waited for the distribution scheduler
+	QueueWait time.Duration
+	// Deferred tasks were not distributed yet and only count towards TotalDeferred
+	Deferred bool
+}
+
+// Breakdown aggregates the tasks sharing a reward type or chain
+type Breakdown struct {
+	Tasks       int64    `json:"tasks"`
+	Succeeded   int64    `json:"succeeded"`
+	Failed      int64    `json:"failed"`
+	Distributed *big.Int `json:"distributed"`
+}
+
+// Earnings are the running totals of a fee split beneficiary
+type Earnings struct {
+	Fees  *big.Int `json:"fees"`
+	MEV   *big.Int `json:"mev"`
+	Total *big.Int `json:"total"`
+}
+
+// Latency holds latency percentiles in milliseconds
+type Latency struct {
+	P50 float64 `json:"p50_ms"`
+	P95 float64 `json:"p95_ms"`
+	P99 float64 `json:"p99_ms"`
+}
+
+// Queue describes the distribution scheduler. The engine fills in the waits
+// of recorded tasks; the depth, sizes and rejections come from the scheduler.
+type Queue struct {
+	Depth       int     `json:"depth"`
+	Capacity    int     `json:"capacity"`
+	Running     int     `json:"running"`
+	Concurrency int     `json:"concurrency"`
+	Rejected    int64   `json:"rejected"`
+	AverageWait float64 `json:"average_wait_ms"`
+	Wait        Latency `json:"wait"`
+}
+
+// Window holds the counts and rates over the rolling window
+type Window struct {
+	Duration       time.Duration `json:"duration"`
+	Tasks          int64         `json:"tasks"`
+	Succeeded      int64         `json:"succeeded"`
+	Failed         int64         `json:"failed"`
+	SuccessRate    float64       `json:"success_rate"`
+	TasksPerSecond float64       `json:"tasks_per_second"`
+}
+
+// Snapshot is an immutable copy of the statistics at a point in time
+type Snapshot struct {
+	TotalTasksProcessed     int64                `json:"total_tasks_processed"`
+	TotalSucceeded          int64                `json:"total_succeeded"`
+	TotalFailed             int64                `json:"total_failed"`
+	TotalDeferred           int64                `json:"total_deferred"`
+	SuccessRate             float64              `json:"success_rate"`
+	TotalRewardsDistributed *big.Int             `json:"total_rewards_distributed"`
+	TotalMEVCaptured        *big.Int             `json:"total_mev_captured"`
+	AverageProcessingTime   float64              `json:"average_processing_time_ms"`
+	Latency                 Latency              `json:"latency"`
+	Window                  Window               `json:"window"`
+	Queue                   Queue                `json:"queue"`
+	ByRewardType            map[string]Breakdown `json:"by_reward_type"`
+	BySourceChain           map[uint64]Breakdown `json:"by_source_chain"`
+	ByTargetChain           map[uint64]Breakdown `json:"by_target_chain"`
+	ByBeneficiary           map[string]Earnings  `json:"by_beneficiary"`
+	TakenAt                 time.Time            `json:"taken_at"`
+}
+
+// bucket counts the outcomes recorded during one second of the rolling window
+type bucket struct {
+	second    int64
+	succeeded int64
+	failed    int64
+}
+
+// Engine is a concurrency-safe statistics aggregator
+type Engine struct {
+	mu sync.RWMutex
+
+	succeeded    int64
+	failed       int64
+	deferred     int64
+	distributed  *big.Int
+	mevCaptured  *big.Int
+	totalLatency time.Duration
+
+	// latencies is a ring buffer of the most recent latencies
+	latencies []time.Duration
+	next      int
+
+	// queueWaits is a ring buffer of the most recent scheduler waits
+	queueWaits     []time.Duration
+	totalQueueWait time.Duration
+
+	window  time.Duration
+	buckets []bucket
+
+	byRewardType  map[string]*Breakdown
+	bySourceChain map[uint64]*Breakdown
+	byTargetChain map[uint64]*Breakdown
+	byBeneficiary map[string]*Earnings
+
+	now func() time.Time
+}
+
+// NewEngine creates an Engine computing rates over the given rolling window
+func NewEngine(window time.Duration) *Engine {
+	if window < time.Second {
+		window = DefaultWindow
+	}
+	return &Engine{
+		distributed:   new(big.Int),
+		mevCaptured:   new(big.Int),
+		latencies:     make([]time.Duration, 0, maxLatencySamples),
+		queueWaits:    make([]time.Duration, 0, maxLatencySamples),
+		window:        window,
+		buckets:       make([]bucket, int(window/time.Second)),
+		byRewardType:  make(map[string]*Breakdown),
+		bySourceChain: make(map[uint64]*Breakdown),
+		byTargetChain: make(map[uint64]*Breakdown),
+		byBeneficiary: make(map[string]*Earnings),
+		now:           time.Now,
+	}
+}
+
+// Record adds a task outcome
+func (e *Engine) Record(o Observation) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if o.Deferred {
+		e.deferred++
+		return
+	}
+	if o.Success {
+		e.succeeded++
+	} else {
+		e.failed++
+	}
+	if o.Distributed != nil {
+		e.distributed.Add(e.distributed, o.Distributed)
+	}
+	if o.MEVCaptured != nil {
+		e.mevCaptured.Add(e.mevCaptured, o.MEVCaptured)
+	}
+
+	e.totalLatency += o.Latency
+	if len(e.latencies) < maxLatencySamples {
+		e.latencies = append(e.latencies, o.Latency)
+	} else {
+		e.latencies[e.next] = o.Latency
+	}
+	e.totalQueueWait += o.QueueWait
+	if len(e.queueWaits) < maxLatencySamples {
+		e.queueWaits = append(e.queueWaits, o.QueueWait)
+	} else {
+		e.queueWaits[e.next] = o.QueueWait
+	}
+	e.next = (e.next + 1) % maxLatencySamples
+
+	second := e.now().Unix()
+	b := &e.buckets[second%int64(len(e.buckets))]
+	if b.second != second {
+		*b = bucket{second: second}
+	}
+	if o.Success {
+		b.succeeded++
+	} else {
+		b.failed++
+	}
+
+	addTo(breakdownFor(e.byRewardType, o.RewardType), o)
+	addTo(breakdownFor(e.bySourceChain, o.SourceChain), o)
+	addTo(breakdownFor(e.byTargetChain, o.TargetChain), o)
+
+	for beneficiary, amount := range o.FeeSplit {
+		earn(e.earningsFor(beneficiary), amount, nil)
+	}
+	for beneficiary, amount := range o.MEVSplit {
+		earn(e.earningsFor(beneficiary), nil, amount)
+	}
+}
+
+// Snapshot returns a copy of the current statistics that is safe to retain
+func (e *Engine) Snapshot() Snapshot {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	now := e.now()
+	total := e.succeeded + e.failed
+	snapshot := Snapshot{
+		TotalTasksProcessed:     total,
+		TotalSucceeded:          e.succeeded,
+		TotalFailed:             e.failed,
+		TotalDeferred:           e.deferred,
+		SuccessRate:             rate(e.succeeded, total),
+		TotalRewardsDistributed: new(big.Int).Set(e.distributed),
+		TotalMEVCaptured:        new(big.Int).Set(e.mevCaptured),
+		Latency:                 percentiles(e.latencies),
+		Window:                  Window{Duration: e.window},
+		Queue:                   Queue{Wait: percentiles(e.queueWaits)},
+		ByRewardType:            copyBreakdowns(e.byRewardType),
+		BySourceChain:           copyBreakdowns(e.bySourceChain),
+		ByTargetChain:           copyBreakdowns(e.byTargetChain),
+		ByBeneficiary:           make(map[string]Earnings, len(e.byBeneficiary)),
+		TakenAt:                 now,
+	}
+	for beneficiary, earnings := range e.byBeneficiary {
+		snapshot.ByBeneficiary[beneficiary] = Earnings{
+			Fees:  new(big.Int).Set(earnings.Fees),
+			MEV:   new(big.Int).Set(earnings.MEV),
+			Total: new(big.Int).Set(earnings.Total),
+		}
+	}
+	if total > 0 {
+		snapshot.AverageProcessingTime = milliseconds(e.totalLatency) / float64(total)
+		snapshot.Queue.AverageWait = milliseconds(e.totalQueueWait) / float64(total)
+	}
+
+	oldest := now.Unix() - int64(len(e.buckets))
+	for _, b := range e.buckets {
+		if b.second > oldest {
+			snapshot.Window.Succeeded += b.succeeded
+			snapshot.Window.Failed += b.failed
+		}
+	}
+	snapshot.Window.Tasks = snapshot.Window.Succeeded + snapshot.Window.Failed
+	snapshot.Window.SuccessRate = rate(snapshot.Window.Succeeded, snapshot.Window.Tasks)
+	snapshot.Window.TasksPerSecond = float64(snapshot.Window.Tasks) / e.window.Seconds()
+
+	return snapshot
+}
+
+func breakdownFor[K comparable](m map[K]*Breakdown, key K) *Breakdown {
+	b, ok := m[key]
+	if !ok {
+		b = &Breakdown{Distributed: new(big.Int)}
+		m[key] = b
+	}
+	return b
+}
+
+func addTo(b *Breakdown, o Observation) {
+	b.Tasks++
+	if o.Success {
+		b.Succeeded++
+	} else {
+		b.Failed++
+	}
+	if o.Distributed != nil {
+		b.Distributed.Add(b.Distributed, o.Distributed)
+	}
+}
+
+func (e *Engine) earningsFor(beneficiary string) *Earnings {
+	earnings, ok := e.byBeneficiary[beneficiary]
+	if !ok {
+		earnings = &Earnings{Fees: new(big.Int), MEV: new(big.Int), Total: new(big.Int)}
+		e.byBeneficiary[beneficiary] = earnings
+	}
+	return earnings
+}
+
+func earn(earnings *Earnings, fees, mev *big.Int) {
+	if fees != nil {
+		earnings.Fees.Add(earnings.Fees, fees)
+		earnings.Total.Add(earnings.Total, fees)
+	}
+	if mev != nil {
+		earnings.MEV.Add(earnings.MEV, mev)
+		earnings.Total.Add(earnings.Total, mev)
+	}
+}
+
+func copyBreakdowns[K comparable](m map[K]*Breakdown) map[K]Breakdown {
+	out := make(map[K]Breakdown, len(m))
+	for k, b := range m {
+		c := *b
+		c.Distributed = new(big.Int).Set(b.Distributed)
+		out[k] = c
+	}
+	return out
+}
+
+// percentiles computes nearest-rank percentiles over the latency samples
+func percentiles(samples []time.Duration) Latency {
+	if len(samples) == 0 {
+		return Latency{}
+	}
+	sorted := append([]time.Duration(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := func(p float64) float64 {
+		idx := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+		if idx < 0 {
+			idx = 0
+		}
+		return milliseconds(sorted[idx])
+	}
+	return Latency{P50: rank(50), P95: rank(95), P99: rank(99)}
+}
+
+// rate returns part as a percentage of total
+func rate(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
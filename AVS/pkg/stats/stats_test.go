@@ -0,0 +1,209 @@
+package stats
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestEngine_SuccessRateAndAverage(t *testing.T) {
+	engine := NewEngine(time.Minute)
+
+	engine.Record(Observation{Success: true, Latency: 100 * time.Millisecond, Distributed: big.NewInt(10)})
+	engine.Record(Observation{Success: true, Latency: 200 * time.Millisecond, Distributed: big.NewInt(20)})
+	engine.Record(Observation{Success: true, Latency: 300 * time.Millisecond, MEVCaptured: big.NewInt(5)})
+	engine.Record(Observation{Success: false, Latency: 400 * time.Millisecond})
+	// Deferred tasks count neither as processed nor towards the average
+	engine.Record(Observation{Deferred: true, Latency: time.Second})
+
+	snapshot := engine.Snapshot()
+	if snapshot.TotalTasksProcessed != 4 || snapshot.TotalSucceeded != 3 || snapshot.TotalFailed != 1 || snapshot.TotalDeferred != 1 {
+		t.Errorf("Unexpected counts: %+v", snapshot)
+	}
+	if snapshot.SuccessRate != 75 {
+		t.Errorf("Expected success rate 75, got %v", snapshot.SuccessRate)
+	}
+	if snapshot.AverageProcessingTime != 250 {
+		t.Errorf("Expected average processing time 250ms, got %v", snapshot.AverageProcessingTime)
+	}
+	if snapshot.TotalRewardsDistributed.Cmp(big.NewInt(30)) != 0 {
+		t.Errorf("Expected 30 distributed, got %v", snapshot.TotalRewardsDistributed)
+	}
+	if snapshot.TotalMEVCaptured.Cmp(big.NewInt(5)) != 0 {
+		t.Errorf("Expected 5 MEV captured, got %v", snapshot.TotalMEVCaptured)
+	}
+}
+
+func TestEngine_LatencyPercentiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		samples  int
+		expected Latency
+	}{
+		{name: "empty", samples: 0, expected: Latency{}},
+		{name: "single sample", samples: 1, expected: Latency{P50: 1, P95: 1, P99: 1}},
+		{name: "hundred samples", samples: 100, expected: Latency{P50: 50, P95: 95, P99: 99}},
+		{name: "thousand samples", samples: 1000, expected: Latency{P50: 500, P95: 950, P99: 990}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewEngine(time.Minute)
+			// Record in reverse so ordering cannot be relied on
+			for i := tt.samples; i >= 1; i-- {
+				engine.Record(Observation{Success: true, Latency: time.Duration(i) * time.Millisecond})
+			}
+			if got := engine.Snapshot().Latency; got != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestEngine_RollingWindow(t *testing.T) {
+	engine := NewEngine(10 * time.Second)
+	now := time.Unix(1700000000, 0)
+	engine.now = func() time.Time { return now }
+
+	engine.Record(Observation{Success: true})
+	engine.Record(Observation{Success: false})
+
+	now = now.Add(5 * time.Second)
+	engine.Record(Observation{Success: true})
+
+	snapshot := engine.Snapshot()
+	if snapshot.Window.Tasks != 3 || snapshot.Window.Succeeded != 2 || snapshot.Window.Failed != 1 {
+		t.Errorf("Unexpected window counts: %+v", snapshot.Window)
+	}
+	if snapshot.Window.TasksPerSecond != 0.3 {
+		t.Errorf("Expected 0.3 tasks per second, got %v", snapshot.Window.TasksPerSecond)
+	}
+
+	// The first two observations fall out of the window, but remain in the totals
+	now = now.Add(7 * time.Second)
+	snapshot = engine.Snapshot()
+	if snapshot.Window.Tasks != 1 || snapshot.Window.SuccessRate != 100 {
+		t.Errorf("Unexpected window after expiry: %+v", snapshot.Window)
+	}
+	if snapshot.TotalTasksProcessed != 3 {
+		t.Errorf("Expected 3 total tasks, got %d", snapshot.TotalTasksProcessed)
+	}
+
+	// A reused bucket is reset rather than accumulated
+	now = now.Add(5 * time.Second)
+	engine.Record(Observation{Success: true})
+	if got := engine.Snapshot().Window.Tasks; got != 1 {
+		t.Errorf("Expected 1 task in window, got %d", got)
+	}
+}
+
+func TestEngine_Breakdowns(t *testing.T) {
+	engine := NewEngine(time.Minute)
+	engine.Record(Observation{Success: true, RewardType: "liquidity", SourceChain: 1, TargetChain: 10, Distributed: big.NewInt(7)})
+	engine.Record(Observation{Success: false, RewardType: "liquidity", SourceChain: 1, TargetChain: 42161})
+	engine.Record(Observation{Success: true, RewardType: "mev", SourceChain: 8453, TargetChain: 10, Distributed: big.NewInt(3)})
+
+	snapshot := engine.Snapshot()
+	if b := snapshot.ByRewardType["liquidity"]; b.Tasks != 2 || b.Succeeded != 1 || b.Failed != 1 || b.Distributed.Cmp(big.NewInt(7)) != 0 {
+		t.Errorf("Unexpected liquidity breakdown: %+v", b)
+	}
+	if b := snapshot.BySourceChain[1]; b.Tasks != 2 {
+		t.Errorf("Unexpected source chain 1 breakdown: %+v", b)
+	}
+	if b := snapshot.ByTargetChain[10]; b.Tasks != 2 || b.Distributed.Cmp(big.NewInt(10)) != 0 {
+		t.Errorf("Unexpected target chain 10 breakdown: %+v", b)
+	}
+
+	// Mutating a snapshot must not affect the engine
+	snapshot.TotalRewardsDistributed.SetInt64(0)
+	snapshot.ByTargetChain[10].Distributed.SetInt64(0)
+	delete(snapshot.ByRewardType, "mev")
+
+	fresh := engine.Snapshot()
+	if fresh.TotalRewardsDistributed.Cmp(big.NewInt(10)) != 0 {
+		t.Errorf("Snapshot mutation leaked into totals: %v", fresh.TotalRewardsDistributed)
+	}
+	if fresh.ByTargetChain[10].Distributed.Cmp(big.NewInt(10)) != 0 {
+		t.Errorf("Snapshot mutation leaked into breakdown: %v", fresh.ByTargetChain[10].Distributed)
+	}
+	if _, ok := fresh.ByRewardType["mev"]; !ok {
+		t.Errorf("Snapshot mutation leaked into breakdown map")
+	}
+}
+
+func TestEngine_Beneficiaries(t *testing.T) {
+	engine := NewEngine(time.Minute)
+	engine.Record(Observation{Success: true, FeeSplit: map[string]*big.Int{"lp": big.NewInt(8), "operator": big.NewInt(2)}})
+	engine.Record(Observation{
+		Success:  true,
+		FeeSplit: map[string]*big.Int{"lp": big.NewInt(4), "operator": big.NewInt(1)},
+		MEVSplit: map[string]*big.Int{"lp": big.NewInt(85), "protocol": big.NewInt(15)},
+	})
+
+	snapshot := engine.Snapshot()
+	if e := snapshot.ByBeneficiary["lp"]; e.Fees.Int64() != 12 || e.MEV.Int64() != 85 || e.Total.Int64() != 97 {
+		t.Errorf("Unexpected lp earnings: %+v", e)
+	}
+	if e := snapshot.ByBeneficiary["operator"]; e.Fees.Int64() != 3 || e.MEV.Sign() != 0 || e.Total.Int64() != 3 {
+		t.Errorf("Unexpected operator earnings: %+v", e)
+	}
+	if e := snapshot.ByBeneficiary["protocol"]; e.Fees.Sign() != 0 || e.MEV.Int64() != 15 {
+		t.Errorf("Unexpected protocol earnings: %+v", e)
+	}
+
+	// Mutating a snapshot must not affect the engine
+	snapshot.ByBeneficiary["lp"].Total.SetInt64(0)
+	if got := engine.Snapshot().ByBeneficiary["lp"].Total; got.Int64() != 97 {
+		t.Errorf("Snapshot mutation leaked into earnings: %v", got)
+	}
+}
+
+func TestEngine_QueueWait(t *testing.T) {
+	engine := NewEngine(time.Minute)
+	for _, wait := range []time.Duration{0, 0, 30 * time.Millisecond, 50 * time.Millisecond} {
+		engine.Record(Observation{Success: true, QueueWait: wait})
+	}
+
+	queue := engine.Snapshot().Queue
+	if queue.AverageWait != 20 {
+		t.Errorf("Expected average wait 20ms, got %v", queue.AverageWait)
+	}
+	if expected := (Latency{P50: 0, P95: 50, P99: 50}); queue.Wait != expected {
+		t.Errorf("Expected wait %+v, got %+v", expected, queue.Wait)
+	}
+}
+
+func TestEngine_Concurrent(t *testing.T) {
+	engine := NewEngine(time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for j := 0; j < 250; j++ {
+				engine.Record(Observation{
+					Success:     j%5 != 0,
+					Latency:     time.Millisecond,
+					RewardType:  "swap",
+					SourceChain: uint64(worker),
+					Distributed: big.NewInt(1),
+				})
+				_ = engine.Snapshot()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	snapshot := engine.Snapshot()
+	if snapshot.TotalTasksProcessed != 2000 || snapshot.TotalFailed != 400 {
+		t.Errorf("Unexpected counts: %d processed, %d failed", snapshot.TotalTasksProcessed, snapshot.TotalFailed)
+	}
+	if snapshot.TotalRewardsDistributed.Cmp(big.NewInt(2000)) != 0 {
+		t.Errorf("Expected 2000 distributed, got %v", snapshot.TotalRewardsDistributed)
+	}
+	if snapshot.SuccessRate != 80 {
+		t.Errorf("Expected success rate 80, got %v", snapshot.SuccessRate)
+	}
+}
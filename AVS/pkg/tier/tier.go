@@ -0,0 +1,233 @@
+// Package tier mirrors the TierCalculations library, so that the performer
+// ranks users and applies tier multipliers exactly as the hook does.
+package tier
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Level is TierCalculations.TierLevel
+type Level uint8
+
+// Tier levels
+const (
+	Bronze Level = iota
+	Silver
+	Gold
+	Platinum
+	Diamond
+)
+
+// Constants of TierCalculations
+const (
+	// ConsecutiveDayBonus is CONSECUTIVE_DAY_BONUS, the points per consecutive day
+	ConsecutiveDayBonus = 2
+	// MaxTierPoints is MAX_TIER_POINTS
+	MaxTierPoints = 2000000
+	// MultiplierDenominator turns a multiplier into a factor: 125 is 1.25x
+	MultiplierDenominator = 100
+)
+
+// pointsPerETH is TIER_POINTS_PER_ETH, the wei of liquidity per tier point
+var pointsPerETH = big.NewInt(1e18)
+
+// maxUint256 bounds the products calculateTierBonus can compute without reverting
+var maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+// Valid reports whether the level is BRONZE..DIAMOND
+func (l Level) Valid() bool {
+	return l <= Diamond
+}
+
+// String returns getTierName
+func (l Level) String() string {
+	switch l {
+	case Diamond:
+		return "Diamond"
+	case Platinum:
+		return "Platinum"
+	case Gold:
+		return "Gold"
+	case Silver:
+		return "Silver"
+	case Bronze:
+		return "Bronze"
+	}
+	return fmt.Sprintf("Level(%d)", uint8(l))
+}
+
+// Thresholds are the minimum tier points of every level
+type Thresholds struct {
+	Bronze, Silver, Gold, Platinum, Diamond uint64
+}
+
+// DefaultThresholds returns getDefaultThresholds
+func DefaultThresholds() Thresholds {
+	return Thresholds{Bronze: 0, Silver: 1000, Gold: 1100, Platinum: 100000, Diamond: 1000100}
+}
+
+// Multipliers are the reward multipliers of every level, in hundredths
+type Multipliers struct {
+	Bronze, Silver, Gold, Platinum, Diamond uint64
+}
+
+// DefaultMultipliers returns getDefaultMultipliers: 1.00x to 2.00x
+func DefaultMultipliers() Multipliers {
+	return Multipliers{Bronze: 100, Silver: 110, Gold: 125, Platinum: 150, Diamond: 200}
+}
+
+// CalculateTierPoints returns _calculateTierPoints: a point per whole ETH of
+// liquidity, plus the loyalty score, plus two points per consecutive day,
+// capped at MaxTierPoints. totalLiquidity is a uint256 wei amount.
+func CalculateTierPoints(totalLiquidity *big.Int, loyaltyScore, consecutiveDays uint64) uint64 {
+	points := new(big.Int)
+	if totalLiquidity != nil {
+		points.Quo(totalLiquidity, pointsPerETH)
+	}
+	points.Add(points, new(big.Int).SetUint64(loyaltyScore))
+	bonus := new(big.Int).SetUint64(consecutiveDays)
+	points.Add(points, bonus.Mul(bonus, big.NewInt(ConsecutiveDayBonus)))
+	if points.Cmp(big.NewInt(MaxTierPoints)) > 0 {
+		return MaxTierPoints
+	}
+	return points.Uint64()
+}
+
+// CalculateTier returns calculateTier: the highest level whose threshold the
+// tier points reach
+func CalculateTier(totalLiquidity *big.Int, loyaltyScore, consecutiveDays uint64) Level {
+	points := CalculateTierPoints(totalLiquidity, loyaltyScore, consecutiveDays)
+	thresholds := DefaultThresholds()
+	switch {
+	case points >= thresholds.Diamond:
+		return Diamond
+	case points >= thresholds.Platinum:
+		return Platinum
+	case points >= thresholds.Gold:
+		return Gold
+	case points >= thresholds.Silver:
+		return Silver
+	}
+	return Bronze
+}
+
+// GetTierMultiplier returns getTierMultiplier. Unknown levels get the BRONZE
+// multiplier, as the library's fall-through does.
+func GetTierMultiplier(level Level) uint64 {
+	multipliers := DefaultMultipliers()
+	switch level {
+	case Diamond:
+		return multipliers.Diamond
+	case Platinum:
+		return multipliers.Platinum
+	case Gold:
+		return multipliers.Gold
+	case Silver:
+		return multipliers.Silver
+	}
+	return multipliers.Bronze
+}
+
+// CalculateTierBonus returns calculateTierBonus: baseReward * multiplier / 100,
+// rounded down. It fails where RewardMath.mulDiv would overflow.
+func CalculateTierBonus(baseReward *big.Int, level Level) (*big.Int, error) {
+	if baseReward == nil || baseReward.Sign() == 0 {
+		return new(big.Int), nil
+	}
+	product := new(big.Int).Mul(baseReward, new(big.Int).SetUint64(GetTierMultiplier(level)))
+	if product.Cmp(maxUint256) > 0 {
+		return nil, fmt.Errorf("tier bonus of %s at %s overflows uint256", baseReward, level)
+	}
+	return product.Quo(product, big.NewInt(MultiplierDenominator)), nil
+}
+
+// Benefits are the getTierBenefits of a level
+type Benefits struct {
+	Multiplier     uint64
+	PriorityLevel  uint64
+	ClaimThreshold *big.Int
+	MaxPositions   uint64
+}
+
+// GetTierBenefits returns getTierBenefits
+func GetTierBenefits(level Level) Benefits {
+	benefits := Benefits{Multiplier: GetTierMultiplier(level)}
+	switch level {
+	case Diamond:
+		benefits.PriorityLevel, benefits.ClaimThreshold, benefits.MaxPositions = 5, big.NewInt(1e15), 50
+	case Platinum:
+		benefits.PriorityLevel, benefits.ClaimThreshold, benefits.MaxPositions = 4, big.NewInt(5e15), 30
+	case Gold:
+		benefits.PriorityLevel, benefits.ClaimThreshold, benefits.MaxPositions = 3, big.NewInt(1e16), 20
+	case Silver:
+		benefits.PriorityLevel, benefits.ClaimThreshold, benefits.MaxPositions = 2, big.NewInt(5e16), 10
+	default:
+		benefits.PriorityLevel, benefits.ClaimThreshold, benefits.MaxPositions = 1, big.NewInt(1e17), 5
+	}
+	return benefits
+}
+
+// UserTier is TierCalculations.UserTier
+type UserTier struct {
+	Level           Level
+	TotalLiquidity  *big.Int
+	LoyaltyScore    uint64
+	LastUpdate      uint64
+	TierPoints      uint64
+	ConsecutiveDays uint64
+	IsActive        bool
+}
+
+// liquidity returns the total liquidity, zero if unset
+func (u *UserTier) liquidity() *big.Int {
+	if u.TotalLiquidity == nil {
+		return new(big.Int)
+	}
+	return u.TotalLiquidity
+}
+
+// CalculateTierProgression returns calculateTierProgression: the liquidity is
+// added, the loyalty score replaced and the tier recalculated. As in the
+// library, the tier points are only refreshed when the level changes.
+func CalculateTierProgression(u *UserTier, newLiquidity *big.Int, newLoyaltyScore, now uint64) Level {
+	total := new(big.Int).Set(u.liquidity())
+	if newLiquidity != nil {
+		total.Add(total, newLiquidity)
+	}
+	u.TotalLiquidity = total
+	u.LoyaltyScore = newLoyaltyScore
+	u.LastUpdate = now
+
+	newTier := CalculateTier(u.TotalLiquidity, u.LoyaltyScore, u.ConsecutiveDays)
+	if newTier != u.Level {
+		u.Level = newTier
+		u.TierPoints = CalculateTierPoints(u.TotalLiquidity, u.LoyaltyScore, u.ConsecutiveDays)
+	}
+	return newTier
+}
+
+// CalculateTierDecay returns calculateTierDecay: a tier point is lost per
+// inactive day, floored at zero. The level is recalculated from liquidity,
+// loyalty and consecutive days, so the decay itself never lowers it.
+func CalculateTierDecay(u *UserTier, inactiveDays uint64) Level {
+	if inactiveDays > u.TierPoints {
+		u.TierPoints = 0
+	} else {
+		u.TierPoints -= inactiveDays
+	}
+
+	newTier := CalculateTier(u.liquidity(), u.LoyaltyScore, u.ConsecutiveDays)
+	u.Level = newTier
+	return newTier
+}
+
+// CanUpgradeTier returns canUpgradeTier: whether the additional liquidity
+// would lift the user above their current level
+func CanUpgradeTier(u *UserTier, additionalLiquidity *big.Int) bool {
+	total := new(big.Int).Set(u.liquidity())
+	if additionalLiquidity != nil {
+		total.Add(total, additionalLiquidity)
+	}
+	return CalculateTier(total, u.LoyaltyScore, u.ConsecutiveDays) > u.Level
+}
@@ -0,0 +1,205 @@
+package tier
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// vectorsPath is shared with test/unit/TierVectors.t.sol, which checks the
+// same vectors against TierCalculations
+var vectorsPath = filepath.Join("..", "..", "..", "test", "vectors", "tiers.json")
+
+// userState is the UserTier a stateful vector starts from
+type userState struct {
+	Name            string `json:"name"`
+	Level           Level  `json:"level"`
+	TotalLiquidity  string `json:"totalLiquidity"`
+	LoyaltyScore    uint64 `json:"loyaltyScore"`
+	ConsecutiveDays uint64 `json:"consecutiveDays"`
+	TierPoints      uint64 `json:"tierPoints"`
+}
+
+type tierVectors struct {
+	CalculateTier []struct {
+		Name            string `json:"name"`
+		TotalLiquidity  string `json:"totalLiquidity"`
+		LoyaltyScore    uint64 `json:"loyaltyScore"`
+		ConsecutiveDays uint64 `json:"consecutiveDays"`
+		Points          uint64 `json:"points"`
+		Tier            Level  `json:"tier"`
+	} `json:"calculateTier"`
+	Multipliers []struct {
+		Tier       Level  `json:"tier"`
+		Multiplier uint64 `json:"multiplier"`
+	} `json:"multipliers"`
+	TierBonus []struct {
+		Name       string `json:"name"`
+		BaseReward string `json:"baseReward"`
+		Tier       Level  `json:"tier"`
+		Bonus      string `json:"bonus"`
+	} `json:"tierBonus"`
+	Progression []struct {
+		userState
+		NewLiquidity         string `json:"newLiquidity"`
+		NewLoyaltyScore      uint64 `json:"newLoyaltyScore"`
+		ExpectLevel          Level  `json:"expectLevel"`
+		ExpectTotalLiquidity string `json:"expectTotalLiquidity"`
+		ExpectTierPoints     uint64 `json:"expectTierPoints"`
+	} `json:"progression"`
+	Decay []struct {
+		userState
+		InactiveDays     uint64 `json:"inactiveDays"`
+		ExpectLevel      Level  `json:"expectLevel"`
+		ExpectTierPoints uint64 `json:"expectTierPoints"`
+	} `json:"decay"`
+	CanUpgrade []struct {
+		userState
+		AdditionalLiquidity string `json:"additionalLiquidity"`
+		Expect              bool   `json:"expect"`
+	} `json:"canUpgrade"`
+}
+
+func loadVectors(t *testing.T) tierVectors {
+	t.Helper()
+	data, err := os.ReadFile(vectorsPath)
+	if err != nil {
+		t.Fatalf("Failed to read tier vectors: %v", err)
+	}
+	var vectors tierVectors
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		t.Fatalf("Failed to parse tier vectors: %v", err)
+	}
+	if len(vectors.CalculateTier) == 0 || len(vectors.TierBonus) == 0 || len(vectors.Progression) == 0 || len(vectors.Decay) == 0 || len(vectors.CanUpgrade) == 0 {
+		t.Fatalf("Tier vectors are missing a section")
+	}
+	return vectors
+}
+
+func wei(t *testing.T, s string) *big.Int {
+	t.Helper()
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		t.Fatalf("Invalid wei amount %q", s)
+	}
+	return v
+}
+
+func (s userState) user(t *testing.T) *UserTier {
+	return &UserTier{
+		Level:           s.Level,
+		TotalLiquidity:  wei(t, s.TotalLiquidity),
+		LoyaltyScore:    s.LoyaltyScore,
+		ConsecutiveDays: s.ConsecutiveDays,
+		TierPoints:      s.TierPoints,
+	}
+}
+
+func TestCalculateTier_Vectors(t *testing.T) {
+	for _, v := range loadVectors(t).CalculateTier {
+		t.Run(v.Name, func(t *testing.T) {
+			liquidity := wei(t, v.TotalLiquidity)
+			if got := CalculateTierPoints(liquidity, v.LoyaltyScore, v.ConsecutiveDays); got != v.Points {
+				t.Errorf("Expected %d points, got %d", v.Points, got)
+			}
+			if got := CalculateTier(liquidity, v.LoyaltyScore, v.ConsecutiveDays); got != v.Tier {
+				t.Errorf("Expected %s, got %s", v.Tier, got)
+			}
+		})
+	}
+}
+
+func TestGetTierMultiplier_Vectors(t *testing.T) {
+	for _, v := range loadVectors(t).Multipliers {
+		if got := GetTierMultiplier(v.Tier); got != v.Multiplier {
+			t.Errorf("Expected %s multiplier %d, got %d", v.Tier, v.Multiplier, got)
+		}
+	}
+}
+
+func TestCalculateTierBonus_Vectors(t *testing.T) {
+	for _, v := range loadVectors(t).TierBonus {
+		t.Run(v.Name, func(t *testing.T) {
+			bonus, err := CalculateTierBonus(wei(t, v.BaseReward), v.Tier)
+			if v.Bonus == "" {
+				if err == nil {
+					t.Errorf("Expected the bonus to overflow, got %s", bonus)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CalculateTierBonus failed: %v", err)
+			}
+			if bonus.Cmp(wei(t, v.Bonus)) != 0 {
+				t.Errorf("Expected bonus %s, got %s", v.Bonus, bonus)
+			}
+		})
+	}
+}
+
+func TestCalculateTierProgression_Vectors(t *testing.T) {
+	for _, v := range loadVectors(t).Progression {
+		t.Run(v.Name, func(t *testing.T) {
+			user := v.user(t)
+			if got := CalculateTierProgression(user, wei(t, v.NewLiquidity), v.NewLoyaltyScore, 1700000000); got != v.ExpectLevel || user.Level != v.ExpectLevel {
+				t.Errorf("Expected %s, got %s (stored %s)", v.ExpectLevel, got, user.Level)
+			}
+			if user.TotalLiquidity.Cmp(wei(t, v.ExpectTotalLiquidity)) != 0 || user.TierPoints != v.ExpectTierPoints {
+				t.Errorf("Expected liquidity %s and %d points, got %s and %d", v.ExpectTotalLiquidity, v.ExpectTierPoints, user.TotalLiquidity, user.TierPoints)
+			}
+			if user.LoyaltyScore != v.NewLoyaltyScore || user.LastUpdate != 1700000000 {
+				t.Errorf("Expected loyalty %d updated at 1700000000, got %d at %d", v.NewLoyaltyScore, user.LoyaltyScore, user.LastUpdate)
+			}
+		})
+	}
+}
+
+func TestCalculateTierDecay_Vectors(t *testing.T) {
+	for _, v := range loadVectors(t).Decay {
+		t.Run(v.Name, func(t *testing.T) {
+			user := v.user(t)
+			if got := CalculateTierDecay(user, v.InactiveDays); got != v.ExpectLevel || user.Level != v.ExpectLevel {
+				t.Errorf("Expected %s, got %s (stored %s)", v.ExpectLevel, got, user.Level)
+			}
+			if user.TierPoints != v.ExpectTierPoints {
+				t.Errorf("Expected %d points, got %d", v.ExpectTierPoints, user.TierPoints)
+			}
+		})
+	}
+}
+
+func TestCanUpgradeTier_Vectors(t *testing.T) {
+	for _, v := range loadVectors(t).CanUpgrade {
+		t.Run(v.Name, func(t *testing.T) {
+			if got := CanUpgradeTier(v.user(t), wei(t, v.AdditionalLiquidity)); got != v.Expect {
+				t.Errorf("Expected %v, got %v", v.Expect, got)
+			}
+		})
+	}
+}
+
+func TestGetTierBenefits(t *testing.T) {
+	tests := []struct {
+		level     Level
+		priority  uint64
+		threshold int64
+		positions uint64
+	}{
+		{Bronze, 1, 1e17, 5},
+		{Silver, 2, 5e16, 10},
+		{Gold, 3, 1e16, 20},
+		{Platinum, 4, 5e15, 30},
+		{Diamond, 5, 1e15, 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			b := GetTierBenefits(tt.level)
+			if b.Multiplier != GetTierMultiplier(tt.level) || b.PriorityLevel != tt.priority || b.ClaimThreshold.Int64() != tt.threshold || b.MaxPositions != tt.positions {
+				t.Errorf("Unexpected benefits %+v", b)
+			}
+		})
+	}
+}
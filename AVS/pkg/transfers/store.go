@@ -0,0 +1,185 @@
+// Package transfers follows cross-chain transfers from their bridge deposit
+// until they are delivered, after the CrossChainTransfer lifecycle of the
+// contracts: undelivered deposits are retried with a higher relayer fee, and
+// transfers still undelivered at the timeout fail and are refunded. Transfers
+// are kept by task ID in a file so that they survive restarts.
+package transfers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/bridge"
+)
+
+var transfersBucket = []byte("transfers")
+
+// ErrInvalidTransfer is returned when a transfer cannot be stored
+var ErrInvalidTransfer = errors.New("invalid transfer")
+
+// State is the stage of a transfer in its lifecycle
+type State string
+
+const (
+	// StatePending transfers were initiated and their last deposit is in flight
+	StatePending State = "pending"
+	// StateCompleted transfers were delivered to the recipient
+	StateCompleted State = "completed"
+	// StateRefunding transfers failed and wait for their last deposit to be refunded
+	StateRefunding State = "refunding"
+	// StateRefunded transfers failed and their last deposit was refunded
+	StateRefunded State = "refunded"
+	// StateFailed transfers failed with nothing to refund, their last deposit
+	// having moved no funds
+	StateFailed State = "failed"
+)
+
+// Final reports whether a transfer in the state is no longer followed
+func (s State) Final() bool {
+	return s == StateCompleted || s == StateRefunded || s == StateFailed
+}
+
+// Transfer is the lifecycle of the distribution of one task, or of one
+// recipient of a batch task
+type Transfer struct {
+	TaskID string `json:"task_id"`
+	// Recipient is the index of the batch recipient the transfer pays, unset
+	// for single distributions
+	Recipient *int  `json:"recipient,omitempty"`
+	State     State `json:"state"`
+	// Deposits holds every deposit sent for the transfer, the last one current
+	Deposits []bridge.Deposit `json:"deposits"`
+	// Retries counts the deposits sent after the first
+	Retries int `json:"retries"`
+	// Reason explains why the transfer failed
+	Reason      string    `json:"reason,omitempty"`
+	InitiatedAt time.Time `json:"initiated_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Deposit returns the current deposit of the transfer
+func (t *Transfer) Deposit() bridge.Deposit {
+	return t.Deposits[len(t.Deposits)-1]
+}
+
+// key is the task ID, followed for batch recipients by a zero byte and the
+// big-endian recipient index so that they sort in recipient order
+func (t *Transfer) key() []byte {
+	if t.Recipient == nil {
+		return []byte(t.TaskID)
+	}
+	return binary.BigEndian.AppendUint32(append([]byte(t.TaskID), 0), uint32(*t.Recipient))
+}
+
+// Store keeps transfers keyed by task ID, and batch transfers by task ID and recipient
+type Store interface {
+	// Get returns the transfers of a task: its single transfer, or one per
+	// recipient of a batch task in recipient order
+	Get(taskID string) ([]*Transfer, error)
+	// Put stores a transfer under its task ID and recipient
+	Put(transfer *Transfer) error
+	// Active returns the transfers not yet in a final state
+	Active() ([]*Transfer, error)
+	// Close releases the underlying resources
+	Close() error
+}
+
+// BoltStore is a file-backed Store built on bbolt
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// Open opens or creates a BoltStore at path
+func Open(path string) (*BoltStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create store directory: %w", err)
+	}
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open transfer store %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(transfersBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize transfer store: %w", err)
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+// Get implements Store
+func (s *BoltStore) Get(taskID string) ([]*Transfer, error) {
+	var found []*Transfer
+	single := []byte(taskID)
+	batch := append([]byte(taskID), 0)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(transfersBucket).Cursor()
+		for k, v := c.Seek(single); k != nil && (bytes.Equal(k, single) || bytes.HasPrefix(k, batch)); k, v = c.Next() {
+			transfer := &Transfer{}
+			if err := json.Unmarshal(v, transfer); err != nil {
+				return err
+			}
+			found = append(found, transfer)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transfers of task %s: %w", taskID, err)
+	}
+	return found, nil
+}
+
+// Put implements Store
+func (s *BoltStore) Put(transfer *Transfer) error {
+	if transfer == nil || transfer.TaskID == "" || len(transfer.Deposits) == 0 || (transfer.Recipient != nil && *transfer.Recipient < 0) {
+		return ErrInvalidTransfer
+	}
+
+	raw, err := json.Marshal(transfer)
+	if err != nil {
+		return fmt.Errorf("failed to encode transfer: %w", err)
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(transfersBucket).Put(transfer.key(), raw)
+	})
+}
+
+// Active implements Store
+func (s *BoltStore) Active() ([]*Transfer, error) {
+	var active []*Transfer
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(transfersBucket).ForEach(func(k, v []byte) error {
+			var transfer Transfer
+			if err := json.Unmarshal(v, &transfer); err != nil {
+				return fmt.Errorf("corrupt transfer %s: %w", string(k), err)
+			}
+			if !transfer.State.Final() {
+				active = append(active, &transfer)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transfers: %w", err)
+	}
+	return active, nil
+}
+
+// Close implements Store
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
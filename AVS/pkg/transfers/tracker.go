@@ -0,0 +1,273 @@
+package transfers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/bridge"
+)
+
+const (
+	// DefaultTimeout is how long a transfer has to be delivered, the
+	// CROSS_CHAIN_TIMEOUT of the contracts
+	DefaultTimeout = time.Hour
+	// DefaultMaxRetries is how many times an undelivered deposit is sent
+	// again, the MAX_CROSS_CHAIN_RETRIES of the contracts
+	DefaultMaxRetries = 3
+	// DefaultFeeBumpBps raises the relayer fee of each retry by 25%
+	DefaultFeeBumpBps = 2500
+)
+
+// bpsDenominator is 100% in basis points
+const bpsDenominator = 10000
+
+// Config bounds how a transfer is retried
+type Config struct {
+	// Timeout is how long after its first deposit a transfer fails
+	Timeout time.Duration
+	// MaxRetries is how many deposits may follow the first
+	MaxRetries int
+	// FeeBumpBps is how much each retry raises the relayer fee of the last deposit
+	FeeBumpBps uint64
+}
+
+// DefaultConfig returns the retry bounds of the contracts
+func DefaultConfig() Config {
+	return Config{Timeout: DefaultTimeout, MaxRetries: DefaultMaxRetries, FeeBumpBps: DefaultFeeBumpBps}
+}
+
+// Event is a step a transfer took, after the CrossChainTransfer events
+type Event string
+
+const (
+	// EventRetried transfers sent a new deposit in place of an undelivered one
+	EventRetried Event = "retried"
+	// EventCompleted transfers were delivered
+	EventCompleted Event = "completed"
+	// EventFailed transfers gave up on delivery
+	EventFailed Event = "failed"
+	// EventRefunded transfers had their last deposit refunded
+	EventRefunded Event = "refunded"
+)
+
+// Change is an event of a transfer found by Poll
+type Change struct {
+	Transfer *Transfer
+	Event    Event
+}
+
+// Tracker follows the deposits of transfers through the bridges that sent
+// them. A deposit that reverted or expired undelivered is sent again through
+// the router at a higher fee until the retries or the timeout run out. The
+// transfer then fails and its last deposit is refunded.
+type Tracker struct {
+	router *bridge.Router
+	store  Store
+	cfg    Config
+	now    func() time.Time
+}
+
+// NewTracker creates a tracker of the transfers in store, sent through router
+func NewTracker(router *bridge.Router, store Store, cfg Config) *Tracker {
+	return &Tracker{router: router, store: store, cfg: cfg, now: time.Now}
+}
+
+// Track starts following the transfer of a task, initiated with deposit d
+func (t *Tracker) Track(taskID string, d bridge.Deposit) error {
+	return t.track(&Transfer{TaskID: taskID}, d)
+}
+
+// TrackRecipient starts following the transfer to the recipient at index of a
+// batch task, initiated with deposit d
+func (t *Tracker) TrackRecipient(taskID string, index int, d bridge.Deposit) error {
+	return t.track(&Transfer{TaskID: taskID, Recipient: &index}, d)
+}
+
+func (t *Tracker) track(transfer *Transfer, d bridge.Deposit) error {
+	tracked, err := t.store.Get(transfer.TaskID)
+	if err != nil {
+		return err
+	}
+	for _, other := range tracked {
+		if other.Recipient == nil && transfer.Recipient == nil {
+			return fmt.Errorf("transfer of task %s is already tracked", transfer.TaskID)
+		}
+		if other.Recipient != nil && transfer.Recipient != nil && *other.Recipient == *transfer.Recipient {
+			return fmt.Errorf("transfer to recipient %d of task %s is already tracked", *transfer.Recipient, transfer.TaskID)
+		}
+	}
+	now := t.now()
+	transfer.State = StatePending
+	transfer.Deposits = []bridge.Deposit{d}
+	transfer.InitiatedAt = now
+	transfer.UpdatedAt = now
+	return t.store.Put(transfer)
+}
+
+// Status returns the transfers of a task: its single transfer, or one per
+// recipient of a batch task in recipient order. It returns none for a task
+// without transfers.
+func (t *Tracker) Status(taskID string) ([]*Transfer, error) {
+	return t.store.Get(taskID)
+}
+
+// Poll advances every transfer not yet in a final state and returns their
+// events. A transfer that fails to advance is tried again on the next poll.
+func (t *Tracker) Poll(ctx context.Context) ([]Change, error) {
+	active, err := t.store.Active()
+	if err != nil {
+		return nil, err
+	}
+	var changes []Change
+	var errs []error
+	for _, transfer := range active {
+		events, err := t.advance(ctx, transfer)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("task %s: %w", transfer.TaskID, err))
+		}
+		if len(events) == 0 {
+			continue
+		}
+		transfer.UpdatedAt = t.now()
+		if err := t.store.Put(transfer); err != nil {
+			errs = append(errs, fmt.Errorf("task %s: %w", transfer.TaskID, err))
+			continue
+		}
+		for _, event := range events {
+			changes = append(changes, Change{Transfer: transfer, Event: event})
+		}
+	}
+	return changes, errors.Join(errs...)
+}
+
+// Run polls the transfers every interval until ctx is cancelled
+func (t *Tracker) Run(ctx context.Context, interval time.Duration, logger *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changes, err := t.Poll(ctx)
+			if err != nil {
+				logger.Warn("Failed to track cross-chain transfers", zap.Error(err))
+			}
+			LogChanges(logger, changes)
+		}
+	}
+}
+
+// LogChanges logs each event of a transfer
+func LogChanges(logger *zap.Logger, changes []Change) {
+	for _, change := range changes {
+		transfer := change.Transfer
+		d := transfer.Deposit()
+		fields := []zap.Field{
+			zap.String("task_id", transfer.TaskID),
+			zap.String("bridge", d.Bridge),
+			zap.Uint64("target_chain", d.Transfer.TargetChain),
+			zap.Int("retries", transfer.Retries),
+		}
+		if transfer.Recipient != nil {
+			fields = append(fields, zap.Int("recipient", *transfer.Recipient))
+		}
+		switch change.Event {
+		case EventRetried:
+			logger.Info("Cross-chain transfer retried", append(fields,
+				zap.Stringer("fee", d.Fee),
+				zap.String("transaction_hash", d.TransactionHash.Hex()),
+			)...)
+		case EventCompleted:
+			logger.Info("Cross-chain transfer completed", fields...)
+		case EventFailed:
+			logger.Warn("Cross-chain transfer failed", append(fields, zap.String("reason", transfer.Reason))...)
+		case EventRefunded:
+			logger.Info("Cross-chain transfer refunded", append(fields,
+				zap.String("transaction_hash", d.TransactionHash.Hex()),
+			)...)
+		}
+	}
+}
+
+// advance moves a transfer on by the status of its current deposit
+func (t *Tracker) advance(ctx context.Context, transfer *Transfer) ([]Event, error) {
+	d := transfer.Deposit()
+	status, err := t.router.Status(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	switch status {
+	case bridge.StatusFilled:
+		transfer.State = StateCompleted
+		return []Event{EventCompleted}, nil
+	case bridge.StatusRefunded:
+		transfer.State = StateRefunded
+		return []Event{EventRefunded}, nil
+	case bridge.StatusPending:
+		// A deposit in flight may still be delivered, so it is only refunded once it expires
+		if transfer.State == StatePending && t.timedOut(transfer) {
+			t.fail(transfer)
+			return []Event{EventFailed}, nil
+		}
+		return nil, nil
+	}
+
+	// The deposit reverted or expired undelivered
+	var events []Event
+	if transfer.State == StatePending {
+		if !t.timedOut(transfer) && transfer.Retries < t.cfg.MaxRetries {
+			retry, err := t.router.Redeposit(ctx, d, t.bumpFee(d.Fee))
+			if err != nil {
+				return nil, fmt.Errorf("failed to retry deposit %s: %w", d.TransactionHash, err)
+			}
+			transfer.Deposits = append(transfer.Deposits, retry)
+			transfer.Retries++
+			return []Event{EventRetried}, nil
+		}
+		t.fail(transfer)
+		events = append(events, EventFailed)
+	}
+	if status == bridge.StatusFailed {
+		transfer.State = StateFailed
+		return events, nil
+	}
+	err = t.router.Refund(ctx, d)
+	if errors.Is(err, bridge.ErrNotRefundable) {
+		return events, nil
+	}
+	if err != nil {
+		return events, fmt.Errorf("failed to refund deposit %s: %w", d.TransactionHash, err)
+	}
+	transfer.State = StateRefunded
+	return append(events, EventRefunded), nil
+}
+
+// fail gives up on delivering a transfer, leaving its last deposit to refund
+func (t *Tracker) fail(transfer *Transfer) {
+	transfer.State = StateRefunding
+	if t.timedOut(transfer) {
+		transfer.Reason = fmt.Sprintf("not delivered within %s", t.cfg.Timeout)
+	} else {
+		transfer.Reason = fmt.Sprintf("not delivered after %d retries", transfer.Retries)
+	}
+}
+
+func (t *Tracker) timedOut(transfer *Transfer) bool {
+	return t.now().Sub(transfer.InitiatedAt) >= t.cfg.Timeout
+}
+
+// bumpFee raises the fee of a deposit by FeeBumpBps
+func (t *Tracker) bumpFee(fee *big.Int) *big.Int {
+	if fee == nil {
+		return nil
+	}
+	bumped := new(big.Int).Mul(fee, new(big.Int).SetUint64(bpsDenominator+t.cfg.FeeBumpBps))
+	return bumped.Div(bumped, big.NewInt(bpsDenominator))
+}
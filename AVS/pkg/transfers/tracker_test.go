@@ -0,0 +1,277 @@
+package transfers
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/RewardFlow/RewardFlowAVS/pkg/bridge"
+)
+
+// newTestTracker creates a tracker over a mock bridge quoting 1e15, with a
+// store in path and a clock the test moves
+func newTestTracker(t *testing.T, path string, cfg Config) (*Tracker, *bridge.Mock, *time.Time) {
+	t.Helper()
+	mock := bridge.NewMock("mock", big.NewInt(1e15))
+	router, err := bridge.NewRouter(time.Minute, mock)
+	if err != nil {
+		t.Fatalf("NewRouter failed: %v", err)
+	}
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	now := time.Unix(1700000000, 0)
+	tracker := NewTracker(router, store, cfg)
+	tracker.now = func() time.Time { return now }
+	return tracker, mock, &now
+}
+
+// initiate deposits a transfer through the tracker's router and tracks it
+func initiate(t *testing.T, tracker *Tracker, taskID string) bridge.Deposit {
+	t.Helper()
+	d, err := tracker.router.Deposit(context.Background(), bridge.Transfer{
+		Recipient:   common.HexToAddress("0x00000000000000000000000000000000000000b0"),
+		SourceChain: 1,
+		TargetChain: 10,
+		Amount:      big.NewInt(1e17),
+	})
+	if err != nil {
+		t.Fatalf("Deposit failed: %v", err)
+	}
+	if err := tracker.Track(taskID, d); err != nil {
+		t.Fatalf("Track failed: %v", err)
+	}
+	return d
+}
+
+// poll polls once and returns the events found
+func poll(t *testing.T, tracker *Tracker) []Event {
+	t.Helper()
+	changes, err := tracker.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+	var events []Event
+	for _, change := range changes {
+		events = append(events, change.Event)
+	}
+	return events
+}
+
+// transferOf returns the stored transfer of a task
+func transferOf(t *testing.T, tracker *Tracker, taskID string) *Transfer {
+	t.Helper()
+	transfers, err := tracker.Status(taskID)
+	if err != nil || len(transfers) != 1 {
+		t.Fatalf("Expected a transfer for %s, got %d (%v)", taskID, len(transfers), err)
+	}
+	return transfers[0]
+}
+
+func equalEvents(a, b []Event) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestTracker_Completed(t *testing.T) {
+	tracker, mock, _ := newTestTracker(t, filepath.Join(t.TempDir(), "transfers.db"), DefaultConfig())
+	d := initiate(t, tracker, "task-1")
+
+	if events := poll(t, tracker); len(events) != 0 {
+		t.Errorf("Expected no events while the deposit is in flight, got %v", events)
+	}
+	if err := tracker.Track("task-1", d); err == nil || err.Error() != "transfer of task task-1 is already tracked" {
+		t.Errorf("Expected error message 'transfer of task task-1 is already tracked', got '%v'", err)
+	}
+
+	mock.SetStatus(d.TransactionHash, bridge.StatusFilled)
+	if events := poll(t, tracker); !equalEvents(events, []Event{EventCompleted}) {
+		t.Errorf("Expected the transfer to complete, got %v", events)
+	}
+	if transfer := transferOf(t, tracker, "task-1"); transfer.State != StateCompleted || transfer.Retries != 0 {
+		t.Errorf("Expected a completed transfer, got %+v", transfer)
+	}
+	if events := poll(t, tracker); len(events) != 0 {
+		t.Errorf("Expected completed transfers not to be followed, got %v", events)
+	}
+	if transfers, err := tracker.Status("task-2"); len(transfers) != 0 || err != nil {
+		t.Errorf("Expected no transfer for an unknown task, got %d (%v)", len(transfers), err)
+	}
+}
+
+func TestTracker_Recipients(t *testing.T) {
+	tracker, mock, _ := newTestTracker(t, filepath.Join(t.TempDir(), "transfers.db"), DefaultConfig())
+	var deposits []bridge.Deposit
+	for _, index := range []int{2, 0, 10, 1} {
+		d, err := tracker.router.Deposit(context.Background(), bridge.Transfer{
+			Recipient:   common.BigToAddress(big.NewInt(int64(0xb0 + index))),
+			SourceChain: 1,
+			TargetChain: 10,
+			Amount:      big.NewInt(1e17),
+		})
+		if err != nil {
+			t.Fatalf("Deposit failed: %v", err)
+		}
+		if err := tracker.TrackRecipient("batch", index, d); err != nil {
+			t.Fatalf("TrackRecipient failed: %v", err)
+		}
+		deposits = append(deposits, d)
+	}
+	if err := tracker.TrackRecipient("batch", 1, deposits[0]); err == nil || err.Error() != "transfer to recipient 1 of task batch is already tracked" {
+		t.Errorf("Expected error message 'transfer to recipient 1 of task batch is already tracked', got '%v'", err)
+	}
+	// Task IDs sharing a prefix with the batch are kept apart
+	initiate(t, tracker, "batc")
+	initiate(t, tracker, "batch-2")
+
+	// Every recipient of the batch is followed on its own, under the task ID
+	mock.SetStatus(deposits[1].TransactionHash, bridge.StatusFilled)
+	if events := poll(t, tracker); !equalEvents(events, []Event{EventCompleted}) {
+		t.Errorf("Expected one recipient to complete, got %v", events)
+	}
+	transfers, err := tracker.Status("batch")
+	if err != nil || len(transfers) != 4 {
+		t.Fatalf("Expected 4 transfers for the batch, got %d (%v)", len(transfers), err)
+	}
+	for i, index := range []int{0, 1, 2, 10} {
+		transfer := transfers[i]
+		if transfer.TaskID != "batch" || transfer.Recipient == nil || *transfer.Recipient != index {
+			t.Fatalf("Expected recipient %d at %d, got %+v", index, i, transfer)
+		}
+		if expected := index == 0; (transfer.State == StateCompleted) != expected {
+			t.Errorf("Recipient %d: expected completed %t, got %s", index, expected, transfer.State)
+		}
+	}
+}
+
+func TestTracker_Retries(t *testing.T) {
+	tracker, mock, _ := newTestTracker(t, filepath.Join(t.TempDir(), "transfers.db"), DefaultConfig())
+	first := initiate(t, tracker, "task-1")
+
+	// Expired and reverted deposits are sent again, each at a 25% higher fee
+	fees := []int64{1250e12, 1562500e9, 1953125e9}
+	current := first
+	for i, fee := range fees {
+		status := bridge.StatusExpired
+		if i == 1 {
+			status = bridge.StatusFailed
+		}
+		mock.SetStatus(current.TransactionHash, status)
+		if events := poll(t, tracker); !equalEvents(events, []Event{EventRetried}) {
+			t.Fatalf("Expected retry %d, got %v", i+1, events)
+		}
+		transfer := transferOf(t, tracker, "task-1")
+		current = transfer.Deposit()
+		if transfer.Retries != i+1 || len(transfer.Deposits) != i+2 || current.Fee.Cmp(big.NewInt(fee)) != 0 {
+			t.Fatalf("Expected retry %d at a fee of %d, got %d retries at %s", i+1, fee, transfer.Retries, current.Fee)
+		}
+	}
+
+	// With the retries used up the transfer fails and its deposit is refunded
+	mock.SetStatus(current.TransactionHash, bridge.StatusExpired)
+	if events := poll(t, tracker); !equalEvents(events, []Event{EventFailed, EventRefunded}) {
+		t.Fatalf("Expected the transfer to fail and be refunded, got %v", events)
+	}
+	transfer := transferOf(t, tracker, "task-1")
+	if transfer.State != StateRefunded || transfer.Reason != "not delivered after 3 retries" {
+		t.Errorf("Expected a refunded transfer, got %+v", transfer)
+	}
+	if len(mock.Deposits()) != 4 {
+		t.Errorf("Expected 4 deposits, got %d", len(mock.Deposits()))
+	}
+}
+
+func TestTracker_Timeout(t *testing.T) {
+	tests := []struct {
+		name   string
+		status bridge.Status
+		state  State
+		events []Event
+	}{
+		{name: "expired", status: bridge.StatusExpired, state: StateRefunded, events: []Event{EventRefunded}},
+		{name: "filled late", status: bridge.StatusFilled, state: StateCompleted, events: []Event{EventCompleted}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker, mock, now := newTestTracker(t, filepath.Join(t.TempDir(), "transfers.db"), DefaultConfig())
+			d := initiate(t, tracker, "task-1")
+
+			// A deposit still in flight at the timeout fails the transfer
+			*now = now.Add(DefaultTimeout)
+			if events := poll(t, tracker); !equalEvents(events, []Event{EventFailed}) {
+				t.Fatalf("Expected the transfer to fail, got %v", events)
+			}
+			transfer := transferOf(t, tracker, "task-1")
+			if transfer.State != StateRefunding || transfer.Reason != "not delivered within 1h0m0s" {
+				t.Fatalf("Expected a transfer waiting for its refund, got %+v", transfer)
+			}
+			if events := poll(t, tracker); len(events) != 0 {
+				t.Errorf("Expected no events before the deposit settles, got %v", events)
+			}
+
+			// It is refunded once its deposit expires, unless it was filled after all
+			mock.SetStatus(d.TransactionHash, tt.status)
+			if events := poll(t, tracker); !equalEvents(events, tt.events) {
+				t.Errorf("Expected events %v, got %v", tt.events, events)
+			}
+			if transfer := transferOf(t, tracker, "task-1"); transfer.State != tt.state || transfer.Retries != 0 {
+				t.Errorf("Expected a %s transfer without retries, got %+v", tt.state, transfer)
+			}
+		})
+	}
+}
+
+func TestTracker_RevertedWithoutRetries(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxRetries = 0
+	tracker, mock, _ := newTestTracker(t, filepath.Join(t.TempDir(), "transfers.db"), cfg)
+	d := initiate(t, tracker, "task-1")
+
+	// A reverted deposit moved no funds, so there is nothing to refund
+	mock.SetStatus(d.TransactionHash, bridge.StatusFailed)
+	if events := poll(t, tracker); !equalEvents(events, []Event{EventFailed}) {
+		t.Fatalf("Expected the transfer to fail, got %v", events)
+	}
+	if transfer := transferOf(t, tracker, "task-1"); transfer.State != StateFailed || transfer.Reason != "not delivered after 0 retries" {
+		t.Errorf("Expected a failed transfer, got %+v", transfer)
+	}
+}
+
+func TestTracker_Restart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transfers.db")
+	tracker, _, _ := newTestTracker(t, path, DefaultConfig())
+	d := initiate(t, tracker, "task-1")
+	if err := tracker.store.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// The transfer and its deposit are still followed after a restart
+	reopened, _, _ := newTestTracker(t, path, DefaultConfig())
+	transfer := transferOf(t, reopened, "task-1")
+	if transfer.State != StatePending || len(transfer.Deposits) != 1 {
+		t.Fatalf("Expected the pending transfer back, got %+v", transfer)
+	}
+	got := transfer.Deposit()
+	if got.TransactionHash != d.TransactionHash || got.Fee.Cmp(d.Fee) != 0 || got.Transfer.Amount.Cmp(d.Transfer.Amount) != 0 || got.Transfer.Recipient != d.Transfer.Recipient {
+		t.Errorf("Expected deposit %+v, got %+v", d, got)
+	}
+	active, err := reopened.store.Active()
+	if err != nil || len(active) != 1 {
+		t.Errorf("Expected 1 active transfer, got %d (%v)", len(active), err)
+	}
+}
@@ -0,0 +1,142 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.24;
+
+import {Test} from "forge-std/Test.sol";
+import {ActivityTracking} from "../../src/hooks/libraries/ActivityTracking.sol";
+import {EngagementMetrics} from "../../src/tracking/libraries/EngagementMetrics.sol";
+import {PoolKey} from "@uniswap/v4-core/types/PoolKey.sol";
+import {Currency} from "@uniswap/v4-core/types/Currency.sol";
+import {toBalanceDelta} from "@uniswap/v4-core/types/BalanceDelta.sol";
+import {IHooks} from "@uniswap/v4-core/interfaces/IHooks.sol";
+
+/// @notice Checks the engagement vectors the Go performer is tested against (AVS/pkg/engagement)
+contract EngagementVectorsTest is Test {
+    string internal vectors;
+
+    /// @notice Activity per vector, so that every vector starts from zero
+    mapping(uint256 => ActivityTracking.UserActivity) internal activities;
+    mapping(uint256 => EngagementMetrics.UserEngagement) internal engagements;
+
+    PoolKey internal key = PoolKey({
+        currency0: Currency.wrap(address(0x1)),
+        currency1: Currency.wrap(address(0x2)),
+        fee: 3000,
+        tickSpacing: 60,
+        hooks: IHooks(address(0))
+    });
+
+    uint256 internal constant START = 1700000000;
+
+    function setUp() public {
+        vectors = vm.readFile(string.concat(vm.projectRoot(), "/test/vectors/engagement.json"));
+    }
+
+    function testSequenceVectors() public {
+        for (uint256 i = 0; vm.keyExistsJson(vectors, _key("sequences", i)); i++) {
+            string memory seq = _key("sequences", i);
+            string memory name = vm.parseJsonString(vectors, string.concat(seq, ".name"));
+            ActivityTracking.UserActivity storage activity = activities[i];
+            EngagementMetrics.UserEngagement storage engagement = engagements[i];
+
+            for (uint256 j = 0; vm.keyExistsJson(vectors, _index(string.concat(seq, ".events"), j)); j++) {
+                string memory e = _index(string.concat(seq, ".events"), j);
+                uint256 activityType = _uint(e, ".type");
+                uint256 amount = _uint(e, ".amount");
+                vm.warp(_uint(e, ".timestamp"));
+
+                if (activityType == uint256(ActivityTracking.ActivityType.LIQUIDITY_PROVISION)) {
+                    ActivityTracking.updateLiquidityProvision(activity, toBalanceDelta(int128(int256(amount)), 0), key);
+                    EngagementMetrics.updateLiquidityActivity(engagement, amount);
+                } else {
+                    ActivityTracking.updateSwapVolume(activity, amount);
+                    EngagementMetrics.updateSwapActivity(engagement, amount);
+                }
+            }
+
+            string memory expect = string.concat(seq, ".expect");
+            assertEq(activity.totalLiquidity, _uint(expect, ".totalLiquidity"), name);
+            assertEq(activity.swapVolume, _uint(expect, ".swapVolume"), name);
+            assertEq(activity.lastActivity, _uint(expect, ".lastActivity"), name);
+            assertEq(activity.loyaltyScore, _uint(expect, ".loyaltyScore"), name);
+            assertEq(activity.transactionCount, _uint(expect, ".transactionCount"), name);
+            assertEq(activity.averagePositionSize, _uint(expect, ".averagePositionSize"), name);
+            assertEq(activity.consistencyScore, _uint(expect, ".consistencyScore"), name);
+            assertEq(ActivityTracking.getEngagementScore(activity), _uint(expect, ".engagementScore"), name);
+            assertEq(ActivityTracking.getUserTier(activity), _uint(expect, ".tier"), name);
+
+            // EngagementMetrics keeps the same scores, without the average position size
+            assertEq(engagement.totalLiquidity, _uint(expect, ".totalLiquidity"), name);
+            assertEq(engagement.swapVolume, _uint(expect, ".swapVolume"), name);
+            assertEq(engagement.loyaltyScore, _uint(expect, ".loyaltyScore"), name);
+            assertEq(engagement.transactionCount, _uint(expect, ".transactionCount"), name);
+            assertEq(engagement.consistencyScore, _uint(expect, ".consistencyScore"), name);
+            assertEq(EngagementMetrics.getEngagementScore(engagement), _uint(expect, ".engagementScore"), name);
+            assertEq(EngagementMetrics.getTier(engagement), _uint(expect, ".tier"), name);
+
+            for (uint256 j = 0; vm.keyExistsJson(vectors, _index(string.concat(seq, ".active"), j)); j++) {
+                string memory check = _index(string.concat(seq, ".active"), j);
+                uint256 threshold = _uint(check, ".inactiveThreshold");
+                bool active = vm.parseJsonBool(vectors, string.concat(check, ".expect"));
+                vm.warp(_uint(check, ".now"));
+
+                assertEq(ActivityTracking.isUserActive(activity, threshold), active, name);
+                assertEq(EngagementMetrics.isUserActive(engagement, threshold), active, name);
+            }
+        }
+    }
+
+    function testLoyaltyVectors() public {
+        for (uint256 i = 0; vm.keyExistsJson(vectors, _key("loyalty", i)); i++) {
+            string memory v = _key("loyalty", i);
+            string memory name = vm.parseJsonString(vectors, string.concat(v, ".name"));
+            uint256 activityType = _uint(v, ".activityType");
+            ActivityTracking.UserActivity storage activity = activities[i];
+            EngagementMetrics.UserEngagement storage engagement = engagements[i];
+
+            activity.loyaltyScore = _uint(v, ".score");
+            activity.lastActivity = START;
+            engagement.loyaltyScore = _uint(v, ".score");
+            engagement.lastActivity = START;
+            vm.warp(START + _uint(v, ".elapsed"));
+
+            ActivityTracking._updateLoyaltyScore(activity, ActivityTracking.ActivityType(activityType));
+            EngagementMetrics._updateLoyaltyScore(engagement, EngagementMetrics.EngagementType(activityType));
+
+            assertEq(activity.loyaltyScore, _uint(v, ".expect"), name);
+            assertEq(engagement.loyaltyScore, _uint(v, ".expect"), name);
+        }
+    }
+
+    function testConsistencyVectors() public {
+        for (uint256 i = 0; vm.keyExistsJson(vectors, _key("consistency", i)); i++) {
+            string memory v = _key("consistency", i);
+            string memory name = vm.parseJsonString(vectors, string.concat(v, ".name"));
+            ActivityTracking.UserActivity storage activity = activities[i];
+            EngagementMetrics.UserEngagement storage engagement = engagements[i];
+
+            activity.transactionCount = _uint(v, ".transactionCount");
+            activity.lastActivity = START;
+            engagement.transactionCount = _uint(v, ".transactionCount");
+            engagement.lastActivity = START;
+            vm.warp(START + _uint(v, ".elapsed"));
+
+            ActivityTracking._updateConsistencyScore(activity);
+            EngagementMetrics._updateConsistencyScore(engagement);
+
+            assertEq(activity.consistencyScore, _uint(v, ".expect"), name);
+            assertEq(engagement.consistencyScore, _uint(v, ".expect"), name);
+        }
+    }
+
+    function _uint(string memory path, string memory field) internal view returns (uint256) {
+        return vm.parseJsonUint(vectors, string.concat(path, field));
+    }
+
+    function _index(string memory path, uint256 i) internal pure returns (string memory) {
+        return string.concat(path, "[", vm.toString(i), "]");
+    }
+
+    function _key(string memory section, uint256 i) internal pure returns (string memory) {
+        return string.concat(".", section, "[", vm.toString(i), "]");
+    }
+}
@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.24;
+
+import {Test} from "forge-std/Test.sol";
+import {DistributionUtils} from "../../src/distribution/libraries/DistributionUtils.sol";
+import {RewardMath} from "../../src/hooks/libraries/RewardMath.sol";
+
+/// @notice Checks the fee vectors the Go performer is tested against (AVS/pkg/fees)
+contract FeeVectorsTest is Test {
+    /// @notice A basis point as a RewardMath fee rate
+    uint256 internal constant BPS_RATE = 1e14;
+
+    string internal vectors;
+
+    function setUp() public {
+        vectors = vm.readFile(string.concat(vm.projectRoot(), "/test/vectors/fees.json"));
+    }
+
+    function testCalculateFeesVectors() public {
+        for (uint256 i = 0; vm.keyExistsJson(vectors, _key("calculateFees", i)); i++) {
+            string memory key = _key("calculateFees", i);
+            string memory name = vm.parseJsonString(vectors, string.concat(key, ".name"));
+            uint256 amount = vm.parseJsonUint(vectors, string.concat(key, ".amount"));
+            uint256 targetChain = vm.parseJsonUint(vectors, string.concat(key, ".targetChain"));
+
+            assertEq(
+                DistributionUtils.calculateFees(amount, targetChain),
+                vm.parseJsonUint(vectors, string.concat(key, ".fee")),
+                name
+            );
+            _checkNetAmount(key, amount, targetChain, name);
+        }
+    }
+
+    function testProportionalVectors() public view {
+        for (uint256 i = 0; vm.keyExistsJson(vectors, _key("proportional", i)); i++) {
+            string memory key = _key("proportional", i);
+            uint256 amount = vm.parseJsonUint(vectors, string.concat(key, ".amount"));
+            uint256 bps = vm.parseJsonUint(vectors, string.concat(key, ".bps"));
+
+            assertEq(
+                RewardMath.calculateFee(amount, bps * BPS_RATE),
+                vm.parseJsonUint(vectors, string.concat(key, ".fee")),
+                vm.parseJsonString(vectors, string.concat(key, ".name"))
+            );
+        }
+    }
+
+    function testBasePlusBpsVectors() public view {
+        for (uint256 i = 0; vm.keyExistsJson(vectors, _key("basePlusBps", i)); i++) {
+            string memory key = _key("basePlusBps", i);
+            string memory name = vm.parseJsonString(vectors, string.concat(key, ".name"));
+            uint256 amount = vm.parseJsonUint(vectors, string.concat(key, ".amount"));
+            uint256 targetChain = vm.parseJsonUint(vectors, string.concat(key, ".targetChain"));
+            uint256 bps = vm.parseJsonUint(vectors, string.concat(key, ".bps"));
+
+            uint256 bridgeFee = DistributionUtils.calculateFees(amount, targetChain);
+            uint256 proportionalFee = RewardMath.calculateFee(amount, bps * BPS_RATE);
+            assertEq(bridgeFee, vm.parseJsonUint(vectors, string.concat(key, ".bridgeFee")), name);
+            assertEq(
+                proportionalFee,
+                vm.parseJsonUint(vectors, string.concat(key, ".protocolFee"))
+                    + vm.parseJsonUint(vectors, string.concat(key, ".operatorFee")),
+                name
+            );
+            assertEq(bridgeFee + proportionalFee, vm.parseJsonUint(vectors, string.concat(key, ".fee")), name);
+        }
+    }
+
+    /// @notice External so that a reverting calculateNetAmount can be expected
+    function netAmount(uint256 amount, uint256 targetChain) external pure returns (uint256) {
+        return DistributionUtils.calculateNetAmount(amount, targetChain);
+    }
+
+    function _checkNetAmount(string memory key, uint256 amount, uint256 targetChain, string memory name) internal {
+        string memory netKey = string.concat(key, ".netAmount");
+        if (vm.keyExistsJson(vectors, netKey)) {
+            assertEq(this.netAmount(amount, targetChain), vm.parseJsonUint(vectors, netKey), name);
+        } else {
+            vm.expectRevert();
+            this.netAmount(amount, targetChain);
+        }
+    }
+
+    function _key(string memory section, uint256 i) internal pure returns (string memory) {
+        return string.concat(".", section, "[", vm.toString(i), "]");
+    }
+}
@@ -0,0 +1,255 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.24;
+
+import {Test} from "forge-std/Test.sol";
+import {stdError} from "forge-std/StdError.sol";
+import {RewardMath} from "../../src/hooks/libraries/RewardMath.sol";
+
+/// @notice Checks the RewardMath vectors the Go performer is tested against (AVS/pkg/rewardmath)
+contract RewardMathVectorsTest is Test {
+    string internal vectors;
+
+    function setUp() public {
+        vectors = vm.readFile(string.concat(vm.projectRoot(), "/test/vectors/rewardmath.json"));
+    }
+
+    function testMulDivVectors() public {
+        for (uint256 i = 0; vm.keyExistsJson(vectors, _key("mulDiv", i)); i++) {
+            string memory key = _key("mulDiv", i);
+            uint256 x = _uint(key, ".x");
+            uint256 y = _uint(key, ".y");
+            uint256 denominator = _uint(key, ".denominator");
+
+            if (_expectRevert(key)) {
+                this.mulDiv(x, y, denominator);
+            } else {
+                assertEq(this.mulDiv(x, y, denominator), _uint(key, ".result"), _name(key));
+            }
+        }
+    }
+
+    function testCompoundVectors() public {
+        for (uint256 i = 0; vm.keyExistsJson(vectors, _key("compound", i)); i++) {
+            string memory key = _key("compound", i);
+            uint256 principal = _uint(key, ".principal");
+            uint256 rate = _uint(key, ".rate");
+            uint256 time = _uint(key, ".time");
+
+            if (_expectRevert(key)) {
+                this.compound(principal, rate, time);
+            } else {
+                assertEq(this.compound(principal, rate, time), _uint(key, ".result"), _name(key));
+            }
+        }
+    }
+
+    function testExponentialDecayVectors() public {
+        for (uint256 i = 0; vm.keyExistsJson(vectors, _key("exponentialDecay", i)); i++) {
+            string memory key = _key("exponentialDecay", i);
+            uint256 value = _uint(key, ".value");
+            uint256 decayRate = _uint(key, ".decayRate");
+            uint256 time = _uint(key, ".time");
+
+            if (_expectRevert(key)) {
+                this.exponentialDecay(value, decayRate, time);
+            } else {
+                assertEq(this.exponentialDecay(value, decayRate, time), _uint(key, ".result"), _name(key));
+            }
+        }
+    }
+
+    function testCalculateTierMultiplierVectors() public {
+        for (uint256 i = 0; vm.keyExistsJson(vectors, _key("calculateTierMultiplier", i)); i++) {
+            string memory key = _key("calculateTierMultiplier", i);
+            uint256 totalLiquidity = _uint(key, ".totalLiquidity");
+            uint256 loyaltyScore = _uint(key, ".loyaltyScore");
+            uint256 timeActive = _uint(key, ".timeActive");
+
+            if (_expectRevert(key)) {
+                this.calculateTierMultiplier(totalLiquidity, loyaltyScore, timeActive);
+            } else {
+                assertEq(
+                    this.calculateTierMultiplier(totalLiquidity, loyaltyScore, timeActive),
+                    _uint(key, ".result"),
+                    _name(key)
+                );
+            }
+        }
+    }
+
+    function testTimeWeightedAverageVectors() public {
+        for (uint256 i = 0; vm.keyExistsJson(vectors, _key("timeWeightedAverage", i)); i++) {
+            string memory key = _key("timeWeightedAverage", i);
+            uint256[] memory values = _uints(key, ".values");
+            uint256[] memory weights = _uints(key, ".weights");
+
+            if (_expectRevert(key)) {
+                this.timeWeightedAverage(values, weights);
+            } else {
+                assertEq(this.timeWeightedAverage(values, weights), _uint(key, ".result"), _name(key));
+            }
+        }
+    }
+
+    function testGeometricMeanVectors() public {
+        for (uint256 i = 0; vm.keyExistsJson(vectors, _key("geometricMean", i)); i++) {
+            string memory key = _key("geometricMean", i);
+            uint256[] memory values = _uints(key, ".values");
+
+            if (_expectRevert(key)) {
+                this.geometricMean(values);
+            } else {
+                assertEq(this.geometricMean(values), _uint(key, ".result"), _name(key));
+            }
+        }
+    }
+
+    function testCalculateRewardDistributionVectors() public {
+        for (uint256 i = 0; vm.keyExistsJson(vectors, _key("calculateRewardDistribution", i)); i++) {
+            string memory key = _key("calculateRewardDistribution", i);
+            uint256 totalReward = _uint(key, ".totalReward");
+            uint256[] memory shares = _uints(key, ".shares");
+
+            if (_expectRevert(key)) {
+                this.calculateRewardDistribution(totalReward, shares);
+            } else {
+                assertEq(this.calculateRewardDistribution(totalReward, shares), _uints(key, ".result"), _name(key));
+            }
+        }
+    }
+
+    function testCalculateSlippageProtectionVectors() public {
+        for (uint256 i = 0; vm.keyExistsJson(vectors, _key("calculateSlippageProtection", i)); i++) {
+            string memory key = _key("calculateSlippageProtection", i);
+            uint256 expectedAmount = _uint(key, ".expectedAmount");
+            uint256 actualAmount = _uint(key, ".actualAmount");
+            uint256 maxSlippage = _uint(key, ".maxSlippage");
+
+            if (_expectRevert(key)) {
+                this.calculateSlippageProtection(expectedAmount, actualAmount, maxSlippage);
+            } else {
+                assertEq(
+                    this.calculateSlippageProtection(expectedAmount, actualAmount, maxSlippage),
+                    _uint(key, ".result"),
+                    _name(key)
+                );
+            }
+        }
+    }
+
+    function testCalculateFeeVectors() public {
+        for (uint256 i = 0; vm.keyExistsJson(vectors, _key("calculateFee", i)); i++) {
+            string memory key = _key("calculateFee", i);
+            uint256 amount = _uint(key, ".amount");
+            uint256 feeRate = _uint(key, ".feeRate");
+
+            if (_expectRevert(key)) {
+                this.calculateFee(amount, feeRate);
+            } else {
+                assertEq(this.calculateFee(amount, feeRate), _uint(key, ".result"), _name(key));
+            }
+        }
+    }
+
+    function testCalculateNetAmountVectors() public {
+        for (uint256 i = 0; vm.keyExistsJson(vectors, _key("calculateNetAmount", i)); i++) {
+            string memory key = _key("calculateNetAmount", i);
+            uint256 amount = _uint(key, ".amount");
+            uint256 feeRate = _uint(key, ".feeRate");
+
+            if (_expectRevert(key)) {
+                this.calculateNetAmount(amount, feeRate);
+            } else {
+                assertEq(this.calculateNetAmount(amount, feeRate), _uint(key, ".result"), _name(key));
+            }
+        }
+    }
+
+    // External wrappers so that reverting RewardMath calls can be expected
+
+    function mulDiv(uint256 x, uint256 y, uint256 denominator) external pure returns (uint256) {
+        return RewardMath.mulDiv(x, y, denominator);
+    }
+
+    function compound(uint256 principal, uint256 rate, uint256 time) external pure returns (uint256) {
+        return RewardMath.compound(principal, rate, time);
+    }
+
+    function exponentialDecay(uint256 value, uint256 decayRate, uint256 time) external pure returns (uint256) {
+        return RewardMath.exponentialDecay(value, decayRate, time);
+    }
+
+    function calculateTierMultiplier(uint256 totalLiquidity, uint256 loyaltyScore, uint256 timeActive)
+        external
+        pure
+        returns (uint256)
+    {
+        return RewardMath.calculateTierMultiplier(totalLiquidity, loyaltyScore, timeActive);
+    }
+
+    function timeWeightedAverage(uint256[] memory values, uint256[] memory weights) external pure returns (uint256) {
+        return RewardMath.timeWeightedAverage(values, weights);
+    }
+
+    function geometricMean(uint256[] memory values) external pure returns (uint256) {
+        return RewardMath.geometricMean(values);
+    }
+
+    function calculateRewardDistribution(uint256 totalReward, uint256[] memory shares)
+        external
+        pure
+        returns (uint256[] memory)
+    {
+        return RewardMath.calculateRewardDistribution(totalReward, shares);
+    }
+
+    function calculateSlippageProtection(uint256 expectedAmount, uint256 actualAmount, uint256 maxSlippage)
+        external
+        pure
+        returns (uint256)
+    {
+        return RewardMath.calculateSlippageProtection(expectedAmount, actualAmount, maxSlippage);
+    }
+
+    function calculateFee(uint256 amount, uint256 feeRate) external pure returns (uint256) {
+        return RewardMath.calculateFee(amount, feeRate);
+    }
+
+    function calculateNetAmount(uint256 amount, uint256 feeRate) external pure returns (uint256) {
+        return RewardMath.calculateNetAmount(amount, feeRate);
+    }
+
+    /// @notice Expects the vector's revert, if it has one, on the next call
+    function _expectRevert(string memory key) internal returns (bool) {
+        string memory revertKey = string.concat(key, ".revert");
+        if (!vm.keyExistsJson(vectors, revertKey)) {
+            return false;
+        }
+
+        bytes32 reason = keccak256(bytes(vm.parseJsonString(vectors, revertKey)));
+        if (reason == keccak256("panic:arithmetic")) {
+            vm.expectRevert(stdError.arithmeticError);
+        } else if (reason == keccak256("panic:division")) {
+            vm.expectRevert(stdError.divisionError);
+        } else {
+            vm.expectRevert(bytes(vm.parseJsonString(vectors, revertKey)));
+        }
+        return true;
+    }
+
+    function _uint(string memory key, string memory field) internal view returns (uint256) {
+        return vm.parseJsonUint(vectors, string.concat(key, field));
+    }
+
+    function _uints(string memory key, string memory field) internal view returns (uint256[] memory) {
+        return vm.parseJsonUintArray(vectors, string.concat(key, field));
+    }
+
+    function _name(string memory key) internal view returns (string memory) {
+        return vm.parseJsonString(vectors, string.concat(key, ".name"));
+    }
+
+    function _key(string memory section, uint256 i) internal pure returns (string memory) {
+        return string.concat(".", section, "[", vm.toString(i), "]");
+    }
+}
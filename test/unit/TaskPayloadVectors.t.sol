@@ -0,0 +1,63 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.24;
+
+import {Test} from "forge-std/Test.sol";
+
+/// @notice Checks the task payload vectors the Go performer decodes (AVS/cmd) against abi.encode
+contract TaskPayloadVectorsTest is Test {
+    string internal vectors;
+
+    function setUp() public {
+        vectors = vm.readFile(string.concat(vm.projectRoot(), "/test/vectors/payloads.json"));
+    }
+
+    function testBasicVectors() public {
+        for (uint256 i = 0; vm.keyExistsJson(vectors, _key("basic", i)); i++) {
+            string memory key = _key("basic", i);
+            string memory name = vm.parseJsonString(vectors, string.concat(key, ".name"));
+            address user = _user(key, name);
+            uint256 amount = vm.parseJsonUint(vectors, string.concat(key, ".amount"));
+            uint256 chainId = vm.parseJsonUint(vectors, string.concat(key, ".chainId"));
+
+            bytes memory encoded = abi.encode(user, amount, chainId);
+            assertEq(encoded, vm.parseJsonBytes(vectors, string.concat(key, ".encoded")), name);
+
+            // RewardFlowTaskHook.validatePreTaskCreation decodes the same fields back
+            (address decodedUser, uint256 decodedAmount, uint256 decodedChainId) =
+                abi.decode(encoded, (address, uint256, uint256));
+            assertEq(decodedUser, user, name);
+            assertEq(decodedAmount, amount, name);
+            assertEq(decodedChainId, chainId, name);
+        }
+    }
+
+    function testExtendedVectors() public {
+        for (uint256 i = 0; vm.keyExistsJson(vectors, _key("extended", i)); i++) {
+            string memory key = _key("extended", i);
+            string memory name = vm.parseJsonString(vectors, string.concat(key, ".name"));
+            address user = _user(key, name);
+
+            bytes memory encoded = abi.encode(
+                user,
+                vm.parseJsonUint(vectors, string.concat(key, ".amount")),
+                vm.parseJsonUint(vectors, string.concat(key, ".chainId")),
+                vm.parseJsonBytes32(vectors, string.concat(key, ".poolId")),
+                uint8(vm.parseJsonUint(vectors, string.concat(key, ".rewardType"))),
+                vm.parseJsonAddress(vectors, string.concat(key, ".hook")),
+                vm.parseJsonBytes32(vectors, string.concat(key, ".txHash")),
+                vm.parseJsonUint(vectors, string.concat(key, ".timestamp"))
+            );
+            assertEq(encoded, vm.parseJsonBytes(vectors, string.concat(key, ".encoded")), name);
+        }
+    }
+
+    /// @notice The makeAddr user of a vector, checked against its recorded address
+    function _user(string memory key, string memory name) internal returns (address user) {
+        user = makeAddr(vm.parseJsonString(vectors, string.concat(key, ".user")));
+        assertEq(user, vm.parseJsonAddress(vectors, string.concat(key, ".userAddress")), name);
+    }
+
+    function _key(string memory section, uint256 i) internal pure returns (string memory) {
+        return string.concat(".", section, "[", vm.toString(i), "]");
+    }
+}
@@ -0,0 +1,137 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.24;
+
+import {Test} from "forge-std/Test.sol";
+import {TierCalculations} from "../../src/hooks/libraries/TierCalculations.sol";
+
+/// @notice Checks the tier vectors the Go performer is tested against (AVS/pkg/tier)
+contract TierVectorsTest is Test {
+    string internal vectors;
+
+    /// @notice The UserTier stateful vectors start from
+    TierCalculations.UserTier internal userTier;
+
+    function setUp() public {
+        vectors = vm.readFile(string.concat(vm.projectRoot(), "/test/vectors/tiers.json"));
+    }
+
+    function testCalculateTierVectors() public view {
+        for (uint256 i = 0; vm.keyExistsJson(vectors, _key("calculateTier", i)); i++) {
+            string memory key = _key("calculateTier", i);
+            string memory name = vm.parseJsonString(vectors, string.concat(key, ".name"));
+            uint256 totalLiquidity = vm.parseJsonUint(vectors, string.concat(key, ".totalLiquidity"));
+            uint256 loyaltyScore = vm.parseJsonUint(vectors, string.concat(key, ".loyaltyScore"));
+            uint256 consecutiveDays = vm.parseJsonUint(vectors, string.concat(key, ".consecutiveDays"));
+
+            assertEq(
+                TierCalculations._calculateTierPoints(totalLiquidity, loyaltyScore, consecutiveDays),
+                vm.parseJsonUint(vectors, string.concat(key, ".points")),
+                name
+            );
+            assertEq(
+                uint8(TierCalculations.calculateTier(totalLiquidity, loyaltyScore, consecutiveDays)),
+                vm.parseJsonUint(vectors, string.concat(key, ".tier")),
+                name
+            );
+        }
+    }
+
+    function testMultiplierVectors() public view {
+        for (uint256 i = 0; vm.keyExistsJson(vectors, _key("multipliers", i)); i++) {
+            string memory key = _key("multipliers", i);
+            uint256 tier = vm.parseJsonUint(vectors, string.concat(key, ".tier"));
+
+            assertEq(
+                TierCalculations.getTierMultiplier(TierCalculations.TierLevel(tier)),
+                vm.parseJsonUint(vectors, string.concat(key, ".multiplier"))
+            );
+        }
+    }
+
+    function testTierBonusVectors() public {
+        for (uint256 i = 0; vm.keyExistsJson(vectors, _key("tierBonus", i)); i++) {
+            string memory key = _key("tierBonus", i);
+            string memory name = vm.parseJsonString(vectors, string.concat(key, ".name"));
+            uint256 baseReward = vm.parseJsonUint(vectors, string.concat(key, ".baseReward"));
+            uint256 tier = vm.parseJsonUint(vectors, string.concat(key, ".tier"));
+
+            string memory bonusKey = string.concat(key, ".bonus");
+            if (vm.keyExistsJson(vectors, bonusKey)) {
+                assertEq(this.tierBonus(baseReward, tier), vm.parseJsonUint(vectors, bonusKey), name);
+            } else {
+                vm.expectRevert();
+                this.tierBonus(baseReward, tier);
+            }
+        }
+    }
+
+    function testProgressionVectors() public {
+        for (uint256 i = 0; vm.keyExistsJson(vectors, _key("progression", i)); i++) {
+            string memory key = _key("progression", i);
+            string memory name = vm.parseJsonString(vectors, string.concat(key, ".name"));
+            _loadUserTier(key);
+
+            TierCalculations.TierLevel level = TierCalculations.calculateTierProgression(
+                userTier,
+                vm.parseJsonUint(vectors, string.concat(key, ".newLiquidity")),
+                vm.parseJsonUint(vectors, string.concat(key, ".newLoyaltyScore"))
+            );
+
+            uint256 expectLevel = vm.parseJsonUint(vectors, string.concat(key, ".expectLevel"));
+            assertEq(uint8(level), expectLevel, name);
+            assertEq(uint8(userTier.level), expectLevel, name);
+            assertEq(userTier.totalLiquidity, vm.parseJsonUint(vectors, string.concat(key, ".expectTotalLiquidity")), name);
+            assertEq(userTier.tierPoints, vm.parseJsonUint(vectors, string.concat(key, ".expectTierPoints")), name);
+        }
+    }
+
+    function testDecayVectors() public {
+        for (uint256 i = 0; vm.keyExistsJson(vectors, _key("decay", i)); i++) {
+            string memory key = _key("decay", i);
+            string memory name = vm.parseJsonString(vectors, string.concat(key, ".name"));
+            _loadUserTier(key);
+
+            TierCalculations.TierLevel level = TierCalculations.calculateTierDecay(
+                userTier, vm.parseJsonUint(vectors, string.concat(key, ".inactiveDays"))
+            );
+
+            uint256 expectLevel = vm.parseJsonUint(vectors, string.concat(key, ".expectLevel"));
+            assertEq(uint8(level), expectLevel, name);
+            assertEq(uint8(userTier.level), expectLevel, name);
+            assertEq(userTier.tierPoints, vm.parseJsonUint(vectors, string.concat(key, ".expectTierPoints")), name);
+        }
+    }
+
+    function testCanUpgradeVectors() public {
+        for (uint256 i = 0; vm.keyExistsJson(vectors, _key("canUpgrade", i)); i++) {
+            string memory key = _key("canUpgrade", i);
+            _loadUserTier(key);
+
+            assertEq(
+                TierCalculations.canUpgradeTier(
+                    userTier, vm.parseJsonUint(vectors, string.concat(key, ".additionalLiquidity"))
+                ),
+                vm.parseJsonBool(vectors, string.concat(key, ".expect")),
+                vm.parseJsonString(vectors, string.concat(key, ".name"))
+            );
+        }
+    }
+
+    /// @notice External so that a reverting calculateTierBonus can be expected
+    function tierBonus(uint256 baseReward, uint256 tier) external pure returns (uint256) {
+        return TierCalculations.calculateTierBonus(baseReward, TierCalculations.TierLevel(tier));
+    }
+
+    function _loadUserTier(string memory key) internal {
+        userTier.level = TierCalculations.TierLevel(vm.parseJsonUint(vectors, string.concat(key, ".level")));
+        userTier.totalLiquidity = vm.parseJsonUint(vectors, string.concat(key, ".totalLiquidity"));
+        userTier.loyaltyScore = vm.parseJsonUint(vectors, string.concat(key, ".loyaltyScore"));
+        userTier.consecutiveDays = vm.parseJsonUint(vectors, string.concat(key, ".consecutiveDays"));
+        string memory pointsKey = string.concat(key, ".tierPoints");
+        userTier.tierPoints = vm.keyExistsJson(vectors, pointsKey) ? vm.parseJsonUint(vectors, pointsKey) : 0;
+    }
+
+    function _key(string memory section, uint256 i) internal pure returns (string memory) {
+        return string.concat(".", section, "[", vm.toString(i), "]");
+    }
+}